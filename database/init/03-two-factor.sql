@@ -0,0 +1,50 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Autenticación de dos factores (TOTP)
+-- ========================================
+
+-- Configuración TOTP por usuario (RFC 6238)
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    id SERIAL PRIMARY KEY,
+    user_id UUID UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL, -- Secreto base32 compartido con la app autenticadora
+    is_enabled BOOLEAN DEFAULT false,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT DEFAULT 0, -- Último paso TOTP aceptado (previene reutilización de códigos)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Códigos de recuperación (un solo uso, almacenados como hash)
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Requerimiento de 2FA por rol (editable por administradores)
+CREATE TABLE IF NOT EXISTS role_two_factor_requirements (
+    role VARCHAR(20) PRIMARY KEY CHECK (role IN ('admin', 'input', 'output')),
+    required BOOLEAN DEFAULT false,
+    updated_by UUID REFERENCES users(id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO role_two_factor_requirements (role, required) VALUES
+('admin', false),
+('input', false),
+('output', false)
+ON CONFLICT (role) DO NOTHING;
+
+-- Índices
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user ON user_recovery_codes(user_id, used_at);
+
+-- Triggers para updated_at
+CREATE TRIGGER update_user_two_factor_updated_at BEFORE UPDATE ON user_two_factor
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE user_two_factor IS 'Secretos TOTP de usuarios con autenticación de dos factores';
+COMMENT ON TABLE user_recovery_codes IS 'Códigos de recuperación de 2FA (hash SHA256, un solo uso)';
+COMMENT ON TABLE role_two_factor_requirements IS 'Roles que deben usar autenticación de dos factores';
@@ -0,0 +1,56 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Bloqueo de cuentas y alertas de seguridad
+-- ========================================
+
+-- Los contadores de intentos fallidos y los bloqueos viven en Redis;
+-- aquí se completan las tablas que registran los eventos resultantes.
+
+-- ========================================
+-- AUDITORÍA: COLUMNAS USADAS POR EL BACKEND
+-- ========================================
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS error_msg TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS duration INTEGER;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS session_id VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_audit_session ON audit_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_audit_ip_created ON audit_logs(ip_address, created_at);
+
+-- ========================================
+-- NOTIFICACIONES
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    priority VARCHAR(20) NOT NULL DEFAULT 'medium',
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    related_entity VARCHAR(100),
+    related_entity_id VARCHAR(100),
+    action_url VARCHAR(500),
+    icon_type VARCHAR(50),
+    read_at TIMESTAMP,
+    dismissed_at TIMESTAMP,
+    scheduled_for TIMESTAMP,
+    sent_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    metadata JSONB,
+    is_read BOOLEAN DEFAULT false,
+    related_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, is_read);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications(type);
+CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created_at);
+
+CREATE TRIGGER update_notifications_updated_at BEFORE UPDATE ON notifications
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE notifications IS 'Notificaciones para usuarios (mensajes, alertas de seguridad, sistema)';
+COMMENT ON COLUMN audit_logs.error_msg IS 'Motivo del fallo (intentos fallidos, bloqueos, errores)';
@@ -0,0 +1,54 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Proveedor OAuth2 / OpenID Connect
+-- ========================================
+
+-- Los códigos de autorización viven en Redis (un solo uso, vida corta);
+-- aquí se guardan los clientes registrados y los consentimientos otorgados.
+
+-- ========================================
+-- CLIENTES OAUTH
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR(64) UNIQUE NOT NULL,
+    client_secret_hash VARCHAR(64), -- SHA-256; NULL en clientes públicos (SPA, móviles)
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    redirect_uris JSONB NOT NULL DEFAULT '[]',
+    allowed_scopes JSONB NOT NULL DEFAULT '["openid","profile","email"]',
+    is_confidential BOOLEAN DEFAULT true,
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_active ON oauth_clients(is_active);
+
+CREATE TRIGGER update_oauth_clients_updated_at BEFORE UPDATE ON oauth_clients
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ========================================
+-- CONSENTIMIENTOS
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    granted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, client_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_consents_user ON oauth_consents(user_id);
+
+CREATE TRIGGER update_oauth_consents_updated_at BEFORE UPDATE ON oauth_consents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE oauth_clients IS 'Aplicaciones municipales que usan el inicio de sesión único (OIDC)';
+COMMENT ON TABLE oauth_consents IS 'Permisos otorgados por cada usuario a cada aplicación cliente';
+COMMENT ON COLUMN oauth_clients.client_secret_hash IS 'Hash SHA-256 del secreto; el secreto solo se muestra al crearlo o rotarlo';
@@ -0,0 +1,33 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Historial y expiración de contraseñas
+-- ========================================
+
+-- ========================================
+-- HISTORIAL DE CONTRASEÑAS
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS password_history (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, created_at DESC);
+
+-- La contraseña vigente de cada usuario es la primera entrada de su historial
+INSERT INTO password_history (user_id, password_hash, created_at)
+SELECT u.id, u.password_hash, COALESCE(u.password_changed_at, CURRENT_TIMESTAMP)
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM password_history ph WHERE ph.user_id = u.id);
+
+-- ========================================
+-- AVISO DE EXPIRACIÓN
+-- ========================================
+
+-- Evita repetir el aviso para la misma contraseña
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_expiry_warned_at TIMESTAMP;
+
+COMMENT ON TABLE password_history IS 'Hashes de contraseñas anteriores para impedir su reutilización';
+COMMENT ON COLUMN users.password_expiry_warned_at IS 'Último aviso de expiración enviado; se compara con password_changed_at';
@@ -0,0 +1,43 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Política de seguridad editable
+-- ========================================
+
+-- Fila única (id = 1) que reemplaza las reglas fijas del validador,
+-- el tiempo de sesión y los umbrales de bloqueo.
+
+CREATE TABLE IF NOT EXISTS security_policies (
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+
+    -- Contraseñas
+    min_password_length INTEGER NOT NULL DEFAULT 8,
+    require_uppercase BOOLEAN NOT NULL DEFAULT true,
+    require_lowercase BOOLEAN NOT NULL DEFAULT true,
+    require_numbers BOOLEAN NOT NULL DEFAULT true,
+    require_special_chars BOOLEAN NOT NULL DEFAULT true,
+    block_common_passwords BOOLEAN NOT NULL DEFAULT true,
+    block_sequential_chars BOOLEAN NOT NULL DEFAULT true,
+    password_history_count INTEGER NOT NULL DEFAULT 5,
+    password_expiration_days INTEGER NOT NULL DEFAULT 90,
+    password_expiry_warning_days INTEGER NOT NULL DEFAULT 7,
+
+    -- Login y sesiones
+    max_login_attempts INTEGER NOT NULL DEFAULT 5,
+    lockout_duration_minutes INTEGER NOT NULL DEFAULT 15,
+    session_timeout_minutes INTEGER NOT NULL DEFAULT 480,
+    two_factor_required BOOLEAN NOT NULL DEFAULT false,
+    ip_whitelist JSONB NOT NULL DEFAULT '[]',
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_security_policies_updated_at BEFORE UPDATE ON security_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO security_policies (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
+
+COMMENT ON TABLE security_policies IS 'Política de seguridad vigente (fila única), editable desde /admin/security/policies';
+COMMENT ON COLUMN security_policies.session_timeout_minutes IS 'Inactividad máxima antes de que expire la sesión';
+COMMENT ON COLUMN security_policies.two_factor_required IS 'Exige 2FA a todos los roles, además del requerimiento por rol';
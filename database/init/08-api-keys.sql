@@ -0,0 +1,43 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Cuentas de servicio y API keys
+-- ========================================
+
+-- Las cuentas de servicio son usuarios sin login interactivo: solo se
+-- autentican con API keys (Authorization: ApiKey <key>) y heredan el rol y
+-- la unidad organizacional de la cuenta.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_service_account BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS description TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_users_service_account ON users(is_service_account) WHERE is_service_account;
+
+-- ========================================
+-- API KEYS
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_account_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL,       -- Parte visible de la key (gamc_<prefix>_...)
+    key_hash VARCHAR(64) NOT NULL,            -- SHA-256 de la key completa
+    scopes JSONB NOT NULL DEFAULT '[]',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_account ON api_keys(service_account_id);
+
+CREATE TRIGGER update_api_keys_updated_at BEFORE UPDATE ON api_keys
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE api_keys IS 'API keys de cuentas de servicio; solo se guarda el hash, la key se muestra una vez';
+COMMENT ON COLUMN api_keys.scopes IS 'Permisos de la key con formato recurso:read|write (ej. messages:read)';
+COMMENT ON COLUMN users.is_service_account IS 'Cuenta de servicio para integraciones: sin login con contraseña';
@@ -0,0 +1,21 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Autenticación contra directorio LDAP / Active Directory
+-- ========================================
+
+-- Cada usuario pertenece a una sola fuente de identidad. Los usuarios del
+-- directorio se crean en su primer login (JIT) y su contraseña local es
+-- aleatoria e inutilizable: siempre se autentican con bind LDAP.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auth_source VARCHAR(20) NOT NULL DEFAULT 'local'
+    CHECK (auth_source IN ('local', 'ldap'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS directory_synced_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS directory_deactivated_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_auth_source ON users(auth_source) WHERE auth_source <> 'local';
+
+COMMENT ON COLUMN users.auth_source IS 'Fuente de identidad: local (bcrypt) o ldap (bind contra el directorio)';
+COMMENT ON COLUMN users.external_id IS 'DN del usuario en el directorio';
+COMMENT ON COLUMN users.directory_synced_at IS 'Última vez que el login o la sincronización confirmó al usuario en el directorio';
+COMMENT ON COLUMN users.directory_deactivated_at IS 'Desactivado por la sincronización al desaparecer del directorio o de los grupos autorizados';
@@ -0,0 +1,105 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Permisos granulares y roles editables
+-- ========================================
+
+-- Los permisos son códigos fijos (recurso:acción) que el código verifica.
+-- Los roles agrupan permisos y se editan en tiempo de ejecución. Cada usuario
+-- conserva su rol base (users.role) y puede recibir roles adicionales,
+-- globales o limitados a una unidad organizacional.
+
+CREATE TABLE IF NOT EXISTS permissions (
+    code VARCHAR(50) PRIMARY KEY,
+    category VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,      -- Roles base: no se eliminan ni renombran
+    base_scope VARCHAR(10) NOT NULL DEFAULT 'unit'  -- Alcance cuando es el rol base del usuario
+        CHECK (base_scope IN ('global', 'unit')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_code VARCHAR(50) NOT NULL REFERENCES permissions(code) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_code)
+);
+
+CREATE TABLE IF NOT EXISTS user_role_assignments (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    organizational_unit_id INTEGER REFERENCES organizational_units(id) ON DELETE CASCADE, -- NULL = global
+    expires_at TIMESTAMP,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role_assignments_unique
+    ON user_role_assignments(user_id, role_id, COALESCE(organizational_unit_id, 0));
+CREATE INDEX IF NOT EXISTS idx_user_role_assignments_user ON user_role_assignments(user_id);
+
+CREATE TRIGGER update_roles_updated_at BEFORE UPDATE ON roles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ========================================
+-- CATÁLOGO DE PERMISOS
+-- ========================================
+
+INSERT INTO permissions (code, category, description) VALUES
+('messages:read', 'messages', 'Ver mensajes de la unidad'),
+('messages:send', 'messages', 'Enviar mensajes a otras unidades'),
+('messages:manage', 'messages', 'Cambiar estado, archivar y eliminar mensajes'),
+('files:read', 'files', 'Descargar archivos adjuntos'),
+('files:upload', 'files', 'Subir archivos'),
+('reports:read', 'reports', 'Consultar reportes generados'),
+('reports:generate', 'reports', 'Generar reportes'),
+('dashboard:read', 'dashboard', 'Ver el tablero de indicadores'),
+('audit:read', 'audit', 'Consultar la bitácora de auditoría'),
+('users:read', 'users', 'Consultar usuarios'),
+('users:manage', 'users', 'Crear, editar y desactivar usuarios'),
+('roles:manage', 'security', 'Editar roles y asignarlos a usuarios'),
+('security:manage', 'security', 'Administrar políticas de seguridad, clientes OAuth y cuentas de servicio')
+ON CONFLICT (code) DO NOTHING;
+
+-- ========================================
+-- ROLES INICIALES
+-- ========================================
+
+INSERT INTO roles (code, name, description, is_system, base_scope) VALUES
+('admin', 'Administrador', 'Acceso completo al sistema', true, 'global'),
+('input', 'Operador', 'Envía y gestiona mensajes de su unidad', true, 'unit'),
+('output', 'Consulta', 'Consulta mensajes de su unidad', true, 'unit'),
+('jefe_unidad', 'Jefe de unidad', 'Supervisa su unidad: mensajes, reportes, usuarios y auditoría', false, 'unit')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_code)
+SELECT r.id, p.code FROM roles r CROSS JOIN permissions p WHERE r.code = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_code)
+SELECT r.id, p.code FROM roles r
+JOIN (VALUES
+    ('input', 'messages:read'), ('input', 'messages:send'), ('input', 'messages:manage'),
+    ('input', 'files:read'), ('input', 'files:upload'), ('input', 'reports:read'), ('input', 'dashboard:read'),
+    ('output', 'messages:read'), ('output', 'files:read'), ('output', 'dashboard:read'),
+    ('jefe_unidad', 'messages:read'), ('jefe_unidad', 'messages:send'), ('jefe_unidad', 'messages:manage'),
+    ('jefe_unidad', 'files:read'), ('jefe_unidad', 'files:upload'),
+    ('jefe_unidad', 'reports:read'), ('jefe_unidad', 'reports:generate'), ('jefe_unidad', 'dashboard:read'),
+    ('jefe_unidad', 'audit:read'), ('jefe_unidad', 'users:read')
+) AS rp(role_code, permission_code) ON r.code = rp.role_code
+JOIN permissions p ON p.code = rp.permission_code
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE roles IS 'Roles editables; admin, input y output corresponden a users.role';
+COMMENT ON COLUMN roles.base_scope IS 'global: el rol base aplica a todas las unidades; unit: solo a la unidad del usuario';
+COMMENT ON TABLE user_role_assignments IS 'Roles adicionales por usuario, globales (unidad NULL) o limitados a una unidad';
@@ -0,0 +1,34 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Cola persistente de correo saliente
+-- ========================================
+
+-- Cada correo se renderiza al encolarse y se entrega de forma asíncrona.
+-- Los envíos fallidos se reintentan con espera exponencial hasta
+-- max_attempts; el estado y el último error quedan registrados.
+
+CREATE TABLE IF NOT EXISTS email_queue (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    recipient VARCHAR(255) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body_text TEXT NOT NULL,
+    body_html TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 6,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    message_id VARCHAR(255),                 -- Message-ID del correo entregado
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_queue_pending ON email_queue(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_email_queue_status ON email_queue(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_queue_user ON email_queue(user_id);
+
+COMMENT ON TABLE email_queue IS 'Cola de correo saliente con reintentos y estado de entrega';
@@ -0,0 +1,32 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Verificación de email en el auto-registro
+-- ========================================
+
+-- Las cuentas creadas por auto-registro quedan pendientes (email_verified_at NULL)
+-- hasta que el usuario usa el enlace o el código enviado a su correo. Las cuentas
+-- pendientes no pueden iniciar sesión y se eliminan tras unos días.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Las cuentas existentes (seed, directorio, cuentas de servicio) se consideran verificadas
+UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP) WHERE email_verified_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_pending_verification ON users(created_at) WHERE email_verified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,  -- HMAC-SHA256 del token del enlace
+    code_hash VARCHAR(64) NOT NULL,          -- HMAC-SHA256 del código de 6 dígitos
+    attempts INTEGER NOT NULL DEFAULT 0,     -- Códigos incorrectos
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    request_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user ON email_verifications(user_id, created_at DESC);
+
+COMMENT ON COLUMN users.email_verified_at IS 'NULL: cuenta auto-registrada pendiente de verificar el email';
+COMMENT ON TABLE email_verifications IS 'Enlaces y códigos de verificación de email, de un solo uso';
@@ -0,0 +1,47 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Desafío de preguntas de seguridad en el reset de contraseña
+-- ========================================
+
+-- Cada solicitud de reset elige al azar las preguntas a responder (una o dos, según
+-- la política) y cuenta los intentos fallidos por pregunta. Una nueva solicitud
+-- mientras la anterior sigue vigente conserva las mismas preguntas e intentos.
+
+ALTER TABLE password_reset_tokens
+    ADD COLUMN IF NOT EXISTS challenge_question_ids JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS security_answers_verified INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS question_attempts JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE security_policies
+    ADD COLUMN IF NOT EXISTS reset_security_answers_required INTEGER NOT NULL DEFAULT 1
+        CHECK (reset_security_answers_required BETWEEN 1 AND 2);
+
+COMMENT ON COLUMN password_reset_tokens.challenge_question_ids IS 'Preguntas del desafío, en el orden en que deben responderse';
+COMMENT ON COLUMN password_reset_tokens.question_attempts IS 'Intentos fallidos por pregunta: {"<security_question_id>": n}';
+COMMENT ON COLUMN security_policies.reset_security_answers_required IS 'Respuestas correctas consecutivas exigidas para restablecer la contraseña';
+
+-- Las respuestas nuevas se normalizan en el backend (sin acentos ni mayúsculas) antes
+-- de hashearlas; las guardadas con hash_security_answer() se actualizan al responderse
+-- correctamente por primera vez.
+
+-- El límite de 3 preguntas activas se validaba también en cada UPDATE, contando la
+-- propia fila: actualizar una respuesta fallaba para los usuarios con 3 preguntas.
+CREATE OR REPLACE FUNCTION hash_security_answer_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Hashear la respuesta si no está ya hasheada
+    IF LENGTH(NEW.answer_hash) != 64 THEN
+        NEW.answer_hash := hash_security_answer(NEW.answer_hash);
+    END IF;
+
+    -- Validar que no exceda 3 preguntas activas por usuario (solo al crear o reactivar)
+    IF NEW.is_active AND (TG_OP = 'INSERT' OR NOT OLD.is_active) AND
+       (SELECT COUNT(*) FROM user_security_questions
+        WHERE user_id = NEW.user_id AND is_active = true AND id <> NEW.id) >= 3 THEN
+        RAISE EXCEPTION 'Un usuario no puede tener más de 3 preguntas de seguridad activas'
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,50 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Restricción de acceso por red (rangos IP por rol y por unidad)
+-- ========================================
+
+-- Un usuario solo opera desde IPs incluidas en los rangos de su rol y de su unidad
+-- (si existen reglas para ellos) y en security_policies.ip_whitelist (si no está
+-- vacía). Se verifica al iniciar sesión y en cada petición autenticada.
+
+CREATE TABLE IF NOT EXISTS ip_allowlist_rules (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('role', 'unit')),
+    role VARCHAR(20) CHECK (role IN ('admin', 'input', 'output')),
+    organizational_unit_id INTEGER REFERENCES organizational_units(id) ON DELETE CASCADE,
+    cidr VARCHAR(50) NOT NULL,                -- IPv4 o IPv6 en notación CIDR
+    description VARCHAR(255),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((scope = 'role' AND role IS NOT NULL AND organizational_unit_id IS NULL) OR
+           (scope = 'unit' AND organizational_unit_id IS NOT NULL AND role IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ip_allowlist_rules_unique
+    ON ip_allowlist_rules(scope, COALESCE(role, ''), COALESCE(organizational_unit_id, 0), cidr);
+
+-- Excepciones de emergencia: permiten operar fuera de los rangos hasta expires_at.
+-- user_id NULL cubre a todos los usuarios (por ejemplo, si la intranet queda fuera de servicio).
+CREATE TABLE IF NOT EXISTS ip_allowlist_bypasses (
+    id SERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id),
+    use_count INTEGER NOT NULL DEFAULT 0,     -- Usos registrados (uno por usuario e IP por hora)
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_allowlist_bypasses_active
+    ON ip_allowlist_bypasses(expires_at) WHERE revoked_at IS NULL;
+
+CREATE TRIGGER update_ip_allowlist_rules_updated_at BEFORE UPDATE ON ip_allowlist_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE ip_allowlist_rules IS 'Rangos IP permitidos por rol o por unidad organizacional';
+COMMENT ON TABLE ip_allowlist_bypasses IS 'Excepciones temporales y auditadas a los rangos IP permitidos';
@@ -0,0 +1,47 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Dispositivos conocidos y alertas de inicio de sesión
+-- ========================================
+
+-- Cada navegador o aplicación se identifica con un ID aleatorio de larga duración
+-- (cookie gamc_device o cabecera X-Device-ID) más la familia de navegador y sistema.
+-- Un login desde un dispositivo desconocido genera una alerta con un enlace
+-- "No fui yo" que cierra las sesiones y obliga a restablecer la contraseña.
+
+CREATE TABLE IF NOT EXISTS user_devices (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash VARCHAR(64) NOT NULL,          -- SHA-256 del ID del dispositivo
+    fingerprint VARCHAR(64) NOT NULL,          -- SHA-256 de navegador y sistema operativo
+    description VARCHAR(100) NOT NULL,         -- "Chrome en Windows"
+    user_agent TEXT,
+    first_ip VARCHAR(45),
+    last_ip VARCHAR(45),
+    first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, device_hash)
+);
+
+CREATE TABLE IF NOT EXISTS device_login_alerts (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id INTEGER REFERENCES user_devices(id) ON DELETE SET NULL,
+    session_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,    -- HMAC-SHA256 del token del enlace "No fui yo"
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    reported_at TIMESTAMP,
+    reported_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_login_alerts_user ON device_login_alerts(user_id, created_at DESC);
+
+-- Tras reportar un acceso no reconocido la contraseña deja de servir para iniciar
+-- sesión hasta restablecerla por el proceso de recuperación
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required_at TIMESTAMP;
+
+COMMENT ON TABLE user_devices IS 'Dispositivos desde los que cada usuario ya inició sesión';
+COMMENT ON TABLE device_login_alerts IS 'Alertas de login desde dispositivos nuevos y su enlace "No fui yo"';
+COMMENT ON COLUMN users.password_reset_required_at IS 'Distinto de NULL: el usuario debe restablecer su contraseña antes de iniciar sesión';
@@ -0,0 +1,15 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Sesiones con inactividad por rol y vigencia máxima
+-- ========================================
+
+-- La sesión expira al superar la inactividad de su rol (o session_timeout_minutes
+-- si el rol no la define) o la vigencia máxima desde el login, aunque haya actividad.
+
+ALTER TABLE security_policies
+    ADD COLUMN IF NOT EXISTS role_session_timeouts JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS session_max_lifetime_hours INTEGER NOT NULL DEFAULT 12
+        CHECK (session_max_lifetime_hours BETWEEN 1 AND 168);
+
+COMMENT ON COLUMN security_policies.role_session_timeouts IS 'Inactividad máxima por rol en minutos: {"admin": 15, "output": 60}';
+COMMENT ON COLUMN security_policies.session_max_lifetime_hours IS 'Vigencia máxima de la sesión desde el login, renovando tokens o no';
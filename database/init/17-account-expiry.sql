@@ -0,0 +1,19 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Cambio de contraseña exigido y cuentas temporales
+-- ========================================
+
+-- must_change_password: el administrador exige cambiar la contraseña en el próximo
+-- login; hasta hacerlo solo se emite un token restringido al cambio de contraseña.
+-- expires_at: las cuentas temporales (contratistas, pasantes) no inician sesión
+-- desde esa fecha y una tarea nocturna las desactiva.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_expires_at ON users(expires_at)
+    WHERE expires_at IS NOT NULL AND is_active = true;
+
+COMMENT ON COLUMN users.must_change_password IS 'Cambio de contraseña exigido por un administrador en el próximo login';
+COMMENT ON COLUMN users.expires_at IS 'Fin de vigencia de la cuenta temporal; se desactiva en la tarea nocturna';
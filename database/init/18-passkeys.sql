@@ -0,0 +1,34 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Passkeys (WebAuthn)
+-- ========================================
+
+-- Credenciales WebAuthn residentes: permiten iniciar sesión sin contraseña y cuentan
+-- como segundo factor cuando el rol exige 2FA. El contador de firmas detecta
+-- autenticadores clonados; las passkeys sincronizadas suelen informar siempre 0.
+-- Las revocadas se conservan para auditoría y no vuelven a aceptarse.
+
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id VARCHAR(1400) UNIQUE NOT NULL, -- ID de la credencial en base64url
+    public_key BYTEA NOT NULL,                   -- Clave pública COSE
+    algorithm INTEGER NOT NULL,                  -- -7 ES256, -8 EdDSA, -257 RS256
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    aaguid VARCHAR(36),                          -- Modelo del autenticador
+    transports VARCHAR(100),                     -- internal, hybrid, usb, nfc, ble
+    name VARCHAR(100) NOT NULL,                  -- Nombre elegido por el usuario
+    backup_eligible BOOLEAN NOT NULL DEFAULT false,
+    backed_up BOOLEAN NOT NULL DEFAULT false,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user ON webauthn_credentials(user_id)
+    WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE webauthn_credentials IS 'Passkeys WebAuthn de los usuarios (login sin contraseña y segundo factor)';
+COMMENT ON COLUMN webauthn_credentials.sign_count IS 'Último contador de firmas; si retrocede la credencial pudo ser clonada';
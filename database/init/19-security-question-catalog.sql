@@ -0,0 +1,32 @@
+-- ========================================
+-- GAMC Sistema Web Centralizado
+-- Administración del catálogo de preguntas de seguridad
+-- ========================================
+
+-- Los administradores crean, editan, reordenan y desactivan preguntas. Al desactivar
+-- una pregunta se desactivan también las respuestas de los usuarios que la usaban
+-- (ya no se eligen en el desafío de reset) y se les pide reconfigurarlas.
+
+ALTER TABLE security_questions
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deactivated_by UUID REFERENCES users(id);
+
+DROP TRIGGER IF EXISTS update_security_questions_updated_at ON security_questions;
+CREATE TRIGGER update_security_questions_updated_at BEFORE UPDATE ON security_questions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_security_questions_order ON security_questions(category, sort_order, id);
+CREATE INDEX IF NOT EXISTS idx_user_security_questions_question ON user_security_questions(security_question_id)
+    WHERE is_active = true;
+
+-- Respuestas a preguntas que ya estaban desactivadas antes de esta migración
+UPDATE user_security_questions usq
+SET is_active = false
+FROM security_questions sq
+WHERE sq.id = usq.security_question_id
+  AND sq.is_active = false
+  AND usq.is_active = true;
+
+COMMENT ON COLUMN security_questions.deactivated_at IS 'Fecha en que un administrador retiró la pregunta del catálogo';
+COMMENT ON COLUMN security_questions.deactivated_by IS 'Administrador que retiró la pregunta';
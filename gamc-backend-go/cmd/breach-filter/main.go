@@ -0,0 +1,154 @@
+// cmd/breach-filter/main.go
+//
+// Genera el filtro de contraseñas filtradas (BREACHED_PASSWORDS_FILE) a partir de un
+// corpus descargado, por ejemplo la lista SHA-1 de Have I Been Pwned ("HASH:OCURRENCIAS"),
+// o de una lista de contraseñas en texto plano. Acepta archivos .gz.
+//
+//	go run ./cmd/breach-filter -in pwned-passwords-sha1.txt -out data/breached-passwords.bloom -min-count 10
+//	go run ./cmd/breach-filter -in rockyou.txt.gz -format plain -out data/breached-passwords.bloom
+//	go run ./cmd/breach-filter -check data/breached-passwords.bloom < candidatas.txt
+//
+// El corpus completo de HIBP (~900 millones de hashes) ocupa ~1,6 GB con -fp 0.001;
+// -min-count descarta las contraseñas vistas pocas veces y reduce el filtro.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gamc-backend-go/pkg/breach"
+	"gamc-backend-go/pkg/logger"
+)
+
+func main() {
+	in := flag.String("in", "", "corpus de origen (\"-\" para stdin; .gz se descomprime)")
+	out := flag.String("out", "breached-passwords.bloom", "archivo del filtro a generar")
+	format := flag.String("format", "sha1", "formato del corpus: sha1 (HASH[:OCURRENCIAS]) o plain (texto plano)")
+	fpRate := flag.Float64("fp", 0.001, "tasa de falsos positivos del filtro")
+	minCount := flag.Uint64("min-count", 0, "mínimo de ocurrencias para incluir un hash (solo formato sha1)")
+	expected := flag.Uint64("expected", 0, "cantidad de entradas esperada (0 = contar el corpus antes de construir)")
+	check := flag.String("check", "", "filtro existente: consulta las contraseñas leídas de stdin, una por línea")
+	flag.Parse()
+
+	logger.Init()
+
+	if *check != "" {
+		if err := checkPasswords(*check, os.Stdin, os.Stdout); err != nil {
+			logger.Fatal("❌ %v", err)
+		}
+		return
+	}
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var corpusFormat breach.CorpusFormat
+	switch *format {
+	case "sha1":
+		corpusFormat = breach.FormatSHA1
+	case "plain":
+		corpusFormat = breach.FormatPlain
+	default:
+		logger.Fatal("❌ Formato desconocido %q: use sha1 o plain", *format)
+	}
+
+	if err := build(*in, *out, corpusFormat, *fpRate, *minCount, *expected); err != nil {
+		logger.Fatal("❌ %v", err)
+	}
+}
+
+// build construye el filtro; sin -expected recorre el corpus dos veces para dimensionarlo
+func build(in, out string, format breach.CorpusFormat, fpRate float64, minCount, expected uint64) error {
+	start := time.Now()
+
+	if expected == 0 {
+		if in == "-" {
+			return fmt.Errorf("con stdin indique -expected")
+		}
+		stats, err := readCorpus(in, format, minCount, func(breach.Digest) {})
+		if err != nil {
+			return err
+		}
+		expected = stats.Added
+		logger.Info("📊 Corpus: %d líneas, %d entradas a incluir", stats.Lines, stats.Added)
+	}
+	if expected == 0 {
+		return fmt.Errorf("el corpus no contiene entradas")
+	}
+
+	filter, err := breach.NewFilter(expected, fpRate)
+	if err != nil {
+		return err
+	}
+	logger.Info("🧮 Filtro para %d entradas: %.1f MB", expected, float64(filter.SizeBytes())/(1<<20))
+
+	stats, err := readCorpus(in, format, minCount, filter.Add)
+	if err != nil {
+		return err
+	}
+	if stats.Added > expected {
+		logger.Warn("⚠️ Se agregaron %d entradas, más de las %d esperadas: la tasa de falsos positivos será mayor", stats.Added, expected)
+	}
+
+	if err := filter.SaveFile(out); err != nil {
+		return fmt.Errorf("error al guardar %s: %w", out, err)
+	}
+
+	logger.Info("✅ Filtro generado en %s: %d hashes, falsos positivos ~%.4f%% (%s)",
+		out, filter.Count(), filter.FalsePositiveRate()*100, time.Since(start).Round(time.Second))
+	return nil
+}
+
+// readCorpus abre el corpus (archivo, .gz o stdin) y lo recorre
+func readCorpus(path string, format breach.CorpusFormat, minCount uint64, fn func(breach.Digest)) (breach.CorpusStats, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return breach.CorpusStats{}, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return breach.CorpusStats{}, fmt.Errorf("error al descomprimir %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return breach.ReadCorpus(r, format, minCount, fn)
+}
+
+// checkPasswords indica para cada contraseña de r si aparece en el filtro
+func checkPasswords(filterPath string, r io.Reader, w io.Writer) error {
+	filter, err := breach.LoadFile(filterPath)
+	if err != nil {
+		return fmt.Errorf("error al cargar %s: %w", filterPath, err)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		password := strings.TrimRight(scanner.Text(), "\r")
+		if password == "" {
+			continue
+		}
+		result := "no encontrada"
+		if filter.ContainsPassword(password) {
+			result = "FILTRADA"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", result, password)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,53 @@
+// cmd/fake-ldap/main.go
+//
+// Servidor LDAP en memoria para desarrollo: carga un archivo LDIF y atiende
+// bind y búsquedas, sin necesidad del contenedor OpenLDAP.
+//
+//	go run ./cmd/fake-ldap -ldif docker/ldap/gamc-directory.ldif -addr 127.0.0.1:3389
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gamc-backend-go/pkg/ldap"
+	"gamc-backend-go/pkg/logger"
+)
+
+func main() {
+	ldifPath := flag.String("ldif", "docker/ldap/gamc-directory.ldif", "archivo LDIF con las entradas del directorio")
+	addr := flag.String("addr", "127.0.0.1:3389", "dirección de escucha")
+	bindDN := flag.String("bind-dn", "cn=admin,dc=gamc,dc=gov,dc=bo", "DN administrativo (LDAP_BIND_DN)")
+	bindPassword := flag.String("bind-password", "gamc_ldap_admin_2024", "contraseña del DN administrativo (LDAP_BIND_PASSWORD)")
+	flag.Parse()
+
+	logger.Init()
+
+	file, err := os.Open(*ldifPath)
+	if err != nil {
+		logger.Fatal("❌ Error abriendo LDIF: %v", err)
+	}
+	entries, err := ldap.ParseLDIF(file)
+	file.Close()
+	if err != nil {
+		logger.Fatal("❌ Error leyendo LDIF: %v", err)
+	}
+
+	server := ldap.NewServer(entries)
+	server.SetRootCredentials(*bindDN, *bindPassword)
+
+	listenAddr, err := server.Start(*addr)
+	if err != nil {
+		logger.Fatal("❌ Error iniciando servidor LDAP: %v", err)
+	}
+	logger.Info("📒 Servidor LDAP en memoria escuchando en ldap://%s (%d entradas)", listenAddr, len(entries))
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	server.Close()
+	logger.Info("📒 Servidor LDAP detenido")
+}
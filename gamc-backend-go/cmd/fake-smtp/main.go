@@ -0,0 +1,92 @@
+// cmd/fake-smtp/main.go
+//
+// Servidor SMTP en memoria para desarrollo: acepta los correos de la cola
+// y los muestra en el log (y opcionalmente los guarda como .eml), sin
+// necesidad de un relay real.
+//
+//	go run ./cmd/fake-smtp -addr 127.0.0.1:2525 -dir tmp/mail
+//
+// Con SMTP_HOST=127.0.0.1 SMTP_PORT=2525 SMTP_USE_TLS=false MAIL_ENABLED=true.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"mime"
+	"net/mail"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+
+	"gamc-backend-go/pkg/logger"
+	"gamc-backend-go/pkg/mailer"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:2525", "dirección de escucha")
+	dir := flag.String("dir", "", "directorio donde guardar cada correo como .eml (vacío: solo log)")
+	dkimKey := flag.String("dkim-key", "", "clave privada DKIM (DKIM_PRIVATE_KEY_FILE) para verificar las firmas")
+	flag.Parse()
+
+	logger.Init()
+
+	var verifier *mailer.DKIMSigner
+	if *dkimKey != "" {
+		keyPEM, err := os.ReadFile(*dkimKey)
+		if err != nil {
+			logger.Fatal("❌ Error leyendo clave DKIM: %v", err)
+		}
+		verifier, err = mailer.NewDKIMSigner("verificacion", "verificacion", keyPEM)
+		if err != nil {
+			logger.Fatal("❌ %v", err)
+		}
+	}
+
+	if *dir != "" {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			logger.Fatal("❌ Error creando directorio %s: %v", *dir, err)
+		}
+	}
+
+	server := mailer.NewServer()
+	var received atomic.Int64
+	server.OnMessage(func(msg mailer.ReceivedMessage) {
+		count := received.Add(1)
+		subject := ""
+		if parsed, err := mail.ReadMessage(bytes.NewReader(msg.Data)); err == nil {
+			subject, _ = new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		}
+		logger.Info("📧 #%d de %s para %v: %s (%d bytes)", count, msg.From, msg.To, subject, len(msg.Data))
+
+		if verifier != nil {
+			if err := mailer.VerifyDKIM(msg.Data, verifier.PublicKey()); err != nil {
+				logger.Warn("⚠️ #%d: %v", count, err)
+			} else {
+				logger.Info("✅ #%d: firma DKIM válida", count)
+			}
+		}
+
+		if *dir != "" {
+			path := filepath.Join(*dir, fmt.Sprintf("%s-%03d.eml", msg.ReceivedAt.Format("20060102-150405"), count))
+			if err := os.WriteFile(path, msg.Data, 0o644); err != nil {
+				logger.Error("❌ Error guardando %s: %v", path, err)
+			}
+		}
+	})
+
+	listenAddr, err := server.Start(*addr)
+	if err != nil {
+		logger.Fatal("❌ Error iniciando servidor SMTP: %v", err)
+	}
+	logger.Info("📮 Servidor SMTP en memoria escuchando en %s", listenAddr)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	server.Close()
+	logger.Info("📮 Servidor SMTP detenido (%d correos recibidos)", received.Load())
+}
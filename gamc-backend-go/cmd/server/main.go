@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"gamc-backend-go/internal/api/routes"
+	"gamc-backend-go/internal/auth"
 	"gamc-backend-go/internal/config"
 	"gamc-backend-go/internal/database"
+	"gamc-backend-go/internal/jobs"
 	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/internal/services"
 	"gamc-backend-go/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +36,13 @@ func main() {
 	// Cargar configuración
 	cfg := config.Load()
 
+	// Cargar claves de firma JWT
+	keyStore, err := auth.InitializeKeys(cfg)
+	if err != nil {
+		logger.Fatal("❌ Error cargando claves de firma JWT: %v", err)
+	}
+	logger.Info("🔑 Firma de access tokens: %s", keyStore.Algorithm())
+
 	// Configurar modo Gin
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -59,9 +69,26 @@ func main() {
 		Config: cfg,
 	}
 
+	// Cargar política de seguridad (reglas de contraseña, sesiones, bloqueos)
+	policy := services.InitializeSecurityPolicy(appCtx)
+	logger.Info("🛡️ Política de seguridad: contraseña mínima %d caracteres, sesión %d min, expiración %d días",
+		policy.MinPasswordLength, policy.SessionTimeoutMinutes, policy.PasswordExpirationDays)
+
+	// Cargar el filtro local de contraseñas filtradas
+	if filter, err := services.InitializeBreachedPasswords(cfg); err != nil {
+		logger.Warn("⚠️ Filtro de contraseñas filtradas no disponible, solo se usa la lista de contraseñas comunes: %v", err)
+	} else if filter != nil {
+		logger.Info("🔎 Filtro de contraseñas filtradas: %d hashes, %.1f MB, falsos positivos ~%.4f%%",
+			filter.Count(), float64(filter.SizeBytes())/(1<<20), filter.FalsePositiveRate()*100)
+	}
+
 	// Configurar rutas
 	router := routes.SetupRoutes(appCtx)
 
+	// Iniciar tareas programadas
+	scheduler := jobs.Setup(appCtx)
+	scheduler.Start()
+
 	// Configurar servidor HTTP
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Port),
@@ -104,6 +131,9 @@ func main() {
 		logger.Error("❌ Error en shutdown del servidor: %v", err)
 	}
 
+	// Detener tareas programadas
+	scheduler.Stop()
+
 	// Cerrar conexiones de base de datos
 	if sqlDB, err := db.DB(); err == nil {
 		sqlDB.Close()
@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"gamc-backend-go/internal/auth"
 	"gamc-backend-go/internal/config"
 	"gamc-backend-go/internal/database/models"
 	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/logger"
 	"gamc-backend-go/pkg/response"
 	"gamc-backend-go/pkg/validator"
 
@@ -17,15 +21,21 @@ import (
 
 // AuthHandler maneja las operaciones de autenticación
 type AuthHandler struct {
-	authService *services.AuthService
-	config      *config.Config
+	authService         *services.AuthService
+	verificationService *services.EmailVerificationService
+	deviceService       *services.DeviceService
+	passkeyService      *services.PasskeyService
+	config              *config.Config
 }
 
 // NewAuthHandler crea una nueva instancia del handler de autenticación
 func NewAuthHandler(appCtx *config.AppContext) *AuthHandler {
 	return &AuthHandler{
-		authService: services.NewAuthService(appCtx),
-		config:      appCtx.Config,
+		authService:         services.NewAuthService(appCtx),
+		verificationService: services.NewEmailVerificationService(appCtx),
+		deviceService:       services.NewDeviceService(appCtx),
+		passkeyService:      services.NewPasskeyService(appCtx),
+		config:              appCtx.Config,
 	}
 }
 
@@ -50,7 +60,244 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Ejecutar login
 	result, err := h.authService.Login(c.Request.Context(), &req, ipAddress, userAgent)
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, "Error de autenticación", err.Error())
+		respondLoginError(c, err)
+		return
+	}
+
+	// Primer paso completado: se requiere el código de dos factores o una passkey
+	if result.RequiresTwoFactor {
+		response.Success(c, "Se requiere verificación de dos factores", gin.H{
+			"requiresTwoFactor": true,
+			"challengeToken":    result.ChallengeToken,
+			"methods":           result.TwoFactorMethods,
+			"expiresIn":         result.ExpiresIn,
+		})
+		return
+	}
+
+	h.respondWithSession(c, "Login exitoso", result)
+}
+
+// respondLoginError traduce los errores del login (con contraseña o passkey) a la respuesta HTTP
+func respondLoginError(c *gin.Context, err error) {
+	var lockoutErr *services.LockoutError
+	if errors.As(err, &lockoutErr) {
+		c.Header("Retry-After", strconv.Itoa(int(lockoutErr.RetryAfter.Seconds())))
+		response.Error(c, http.StatusTooManyRequests, "Acceso bloqueado temporalmente",
+			fmt.Sprintf("%s. Intente nuevamente en %d minutos", lockoutErr.Error(), lockoutErr.RetryAfterMinutes()))
+		return
+	}
+	if errors.Is(err, services.ErrDirectoryUnavailable) {
+		response.Error(c, http.StatusServiceUnavailable, "Error de autenticación",
+			"El directorio institucional no está disponible. Intente nuevamente en unos minutos")
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		response.Error(c, http.StatusForbidden, err.Error(), "EMAIL_NOT_VERIFIED")
+		return
+	}
+	if errors.Is(err, services.ErrAccountExpired) {
+		response.Error(c, http.StatusForbidden, err.Error(), "ACCOUNT_EXPIRED")
+		return
+	}
+	if errors.Is(err, services.ErrPasswordResetRequired) {
+		response.Error(c, http.StatusForbidden, err.Error(), "PASSWORD_RESET_REQUIRED")
+		return
+	}
+	if errors.Is(err, services.ErrIPNotAllowed) {
+		response.Error(c, http.StatusForbidden, err.Error(), "IP_NOT_ALLOWED")
+		return
+	}
+	response.Error(c, http.StatusUnauthorized, "Error de autenticación", err.Error())
+}
+
+// VerifyTwoFactorLogin maneja POST /api/v1/auth/login/2fa
+func (h *AuthHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	// Validar datos de entrada
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	// Obtener información del cliente
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	result, err := h.authService.VerifyTwoFactorLogin(c.Request.Context(), &req, ipAddress, userAgent)
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	h.respondWithSession(c, "Login exitoso", result)
+}
+
+// ========================================
+// PASSKEYS (WEBAUTHN)
+// ========================================
+
+// BeginPasskeyLogin maneja POST /api/v1/auth/login/passkey/options
+func (h *AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	var req models.PasskeyLoginOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	options, err := h.authService.BeginPasskeyLogin(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		switch err.Error() {
+		case "desafío 2FA inválido o expirado":
+			response.Error(c, http.StatusUnauthorized, "Error de autenticación", err.Error())
+		case "no tiene passkeys registradas":
+			response.Error(c, http.StatusBadRequest, "Error de autenticación", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al iniciar login con passkey", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Opciones de passkey generadas", options)
+}
+
+// PasskeyLogin maneja POST /api/v1/auth/login/passkey
+func (h *AuthHandler) PasskeyLogin(c *gin.Context) {
+	var req models.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.authService.LoginWithPasskey(c.Request.Context(), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	h.respondWithSession(c, "Login exitoso", result)
+}
+
+// BeginPasskeyRegistration maneja POST /api/v1/auth/passkeys/options
+func (h *AuthHandler) BeginPasskeyRegistration(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	options, err := h.passkeyService.BeginRegistration(c.Request.Context(), userProfile.ID.String())
+	if err != nil {
+		switch {
+		case err.Error() == "usuario no encontrado":
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+		case strings.HasPrefix(err.Error(), "alcanzó el máximo"):
+			response.Error(c, http.StatusConflict, "No se puede registrar la passkey", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al iniciar registro de passkey", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Opciones de registro generadas", options)
+}
+
+// FinishPasskeyRegistration maneja POST /api/v1/auth/passkeys
+func (h *AuthHandler) FinishPasskeyRegistration(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.PasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	credential, err := h.passkeyService.FinishRegistration(c.Request.Context(), userProfile.ID.String(), &req,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "la passkey ya está registrada":
+			response.Error(c, http.StatusConflict, "No se pudo registrar la passkey", err.Error())
+		case err.Error() == "registro de passkey inválido o expirado",
+			strings.HasPrefix(err.Error(), "passkey inválida"):
+			response.Error(c, http.StatusBadRequest, "No se pudo registrar la passkey", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al registrar passkey", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Passkey registrada", credential)
+}
+
+// respondWithSession configura la cookie del refresh token y responde con el access token
+func (h *AuthHandler) respondWithSession(c *gin.Context, message string, result *services.AuthResponse) {
+	// Registrar el dispositivo y alertar si es nuevo (no bloquea el login)
+	deviceID, err := h.deviceService.RecordLogin(c.Request.Context(), result.User, result.SessionID,
+		deviceIDFromRequest(c), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		logger.Warn("⚠️ Error al registrar dispositivo de %s: %v", result.User.Email, err)
+	}
+	if deviceID != "" {
+		c.SetCookie(
+			services.DeviceCookieName,
+			deviceID,
+			int(services.DeviceCookieTTL.Seconds()),
+			"/",
+			"",
+			h.config.Environment == "production", // Secure
+			true,                                 // HttpOnly
+		)
+	}
+
+	// Contraseña expirada o cambio exigido: sin refresh token, solo el token restringido al cambio de contraseña
+	if result.MustChangePassword {
+		message := "Su contraseña expiró, debe cambiarla para continuar"
+		if result.PasswordChangeReason == services.PasswordChangeReasonAdmin {
+			message = "El administrador requiere que cambie su contraseña para continuar"
+		}
+		response.Success(c, message, gin.H{
+			"user":                 result.User,
+			"accessToken":          result.AccessToken,
+			"expiresIn":            result.ExpiresIn,
+			"mustChangePassword":   true,
+			"passwordChangeReason": result.PasswordChangeReason,
+			"deviceId":             deviceID,
+		})
+		return
+	}
+
+	// El rol exige 2FA y no está configurado: sin refresh token, solo el token restringido al enrolamiento
+	if result.TwoFactorSetupRequired && result.RefreshToken == "" {
+		response.Success(c, "Su rol requiere autenticación de dos factores; configúrela para continuar", gin.H{
+			"user":                   result.User,
+			"accessToken":            result.AccessToken,
+			"expiresIn":              result.ExpiresIn,
+			"twoFactorSetupRequired": true,
+			"deviceId":               deviceID,
+		})
 		return
 	}
 
@@ -66,10 +313,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	)
 
 	// Respuesta exitosa (sin incluir refresh token en JSON)
-	response.Success(c, "Login exitoso", gin.H{
+	response.Success(c, message, gin.H{
 		"user":        result.User,
 		"accessToken": result.AccessToken,
 		"expiresIn":   result.ExpiresIn,
+		"deviceId":    deviceID,
 	})
 }
 
@@ -88,13 +336,77 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Ejecutar registro
-	userProfile, err := h.authService.Register(c.Request.Context(), &req)
+	userProfile, err := h.authService.Register(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Error en registro", err.Error())
 		return
 	}
 
-	response.Success(c, "Usuario registrado exitosamente", userProfile)
+	response.Success(c, "Usuario registrado. Revise su email institucional para verificar la cuenta", userProfile)
+}
+
+// VerifyEmail maneja POST /api/v1/auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.EmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	// Validar datos de entrada
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	userProfile, err := h.verificationService.Verify(c.Request.Context(), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "demasiados intentos, solicite un nuevo código":
+			response.Error(c, http.StatusTooManyRequests, "Error en verificación", err.Error())
+		case "el email ya fue verificado":
+			response.Error(c, http.StatusConflict, "Error en verificación", err.Error())
+		case "debe indicar el enlace de verificación o el email y el código",
+			"enlace de verificación inválido o expirado",
+			"código de verificación inválido",
+			"código de verificación inválido o expirado":
+			response.Error(c, http.StatusBadRequest, "Error en verificación", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error en verificación", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Email verificado. Ya puede iniciar sesión", userProfile)
+}
+
+// ResendVerification maneja POST /api/v1/auth/verify-email/resend
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	// Validar datos de entrada
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := h.verificationService.Resend(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		switch err.Error() {
+		case "debe esperar un minuto antes de solicitar otro código",
+			"alcanzó el límite de reenvíos por hoy, intente mañana":
+			response.Error(c, http.StatusTooManyRequests, "Reenvío no disponible", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al reenviar verificación", err.Error())
+		}
+		return
+	}
+
+	// Respuesta genérica: no revela si el email está registrado
+	response.Success(c, "Si la cuenta está pendiente de verificación, recibirá un nuevo código", nil)
 }
 
 // RefreshToken maneja POST /api/v1/auth/refresh
@@ -114,8 +426,16 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	// Ejecutar refresh
-	result, err := h.authService.RefreshToken(c.Request.Context(), refreshToken)
+	result, err := h.authService.RefreshToken(c.Request.Context(), refreshToken, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
+		if err.Error() == "refresh token reutilizado, sesión revocada" {
+			c.SetCookie("refreshToken", "", -1, "/", "", false, true)
+		}
+		if errors.Is(err, services.ErrAccountExpired) {
+			c.SetCookie("refreshToken", "", -1, "/", "", false, true)
+			response.Error(c, http.StatusUnauthorized, err.Error(), "ACCOUNT_EXPIRED")
+			return
+		}
 		response.Error(c, http.StatusUnauthorized, "Error al renovar token", err.Error())
 		return
 	}
@@ -192,6 +512,11 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
+	// Bajo suplantación el perfil indica quién está actuando
+	if current, ok := c.Get("user"); ok {
+		profile.Impersonation = current.(*models.UserProfile).Impersonation
+	}
+
 	response.Success(c, "Perfil obtenido exitosamente", profile)
 }
 
@@ -222,6 +547,15 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// La sesión restringida por contraseña expirada termina aquí: el usuario inicia sesión de nuevo
+	if claims, ok := c.Get("claims"); ok && claims.(*auth.JWTClaims).Restriction != "" {
+		if err := h.authService.Logout(c.Request.Context(), userID.(string), c.GetString("sessionID"), false); err != nil {
+			logger.Warn("Error al cerrar sesión restringida: %v", err)
+		}
+		response.Success(c, "Contraseña cambiada exitosamente. Inicie sesión con su nueva contraseña", gin.H{"reloginRequired": true})
+		return
+	}
+
 	response.Success(c, "Contraseña cambiada exitosamente", nil)
 }
 
@@ -429,6 +763,10 @@ func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
 			response.Error(c, http.StatusTooManyRequests, "Demasiadas solicitudes", "Debe esperar 5 minutos entre solicitudes de reset")
 			return
 		}
+		if strings.HasPrefix(err.Error(), "demasiados intentos fallidos en las preguntas de seguridad") {
+			response.Error(c, http.StatusTooManyRequests, "Demasiadas solicitudes", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Error interno", "Error al procesar solicitud de reset")
 		return
 	}
@@ -517,6 +855,8 @@ func (h *AuthHandler) ConfirmPasswordReset(c *gin.Context) {
 			response.Error(c, http.StatusBadRequest, "Token usado", "Este token ya fue utilizado. Solicite un nuevo reset si es necesario")
 		case "debe verificar la pregunta de seguridad primero":
 			response.Error(c, http.StatusBadRequest, "Verificación requerida", "Debe verificar la pregunta de seguridad antes de cambiar la contraseña")
+		case "debe responder también la siguiente pregunta de seguridad":
+			response.Error(c, http.StatusBadRequest, "Verificación requerida", "Responda la siguiente pregunta de seguridad con POST /auth/verify-security-question")
 		case "demasiados intentos fallidos - token invalidado":
 			response.Error(c, http.StatusBadRequest, "Token invalidado", "Demasiados intentos fallidos. Solicite un nuevo reset")
 		default:
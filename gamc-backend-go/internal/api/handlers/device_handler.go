@@ -0,0 +1,113 @@
+// internal/api/handlers/device_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceHandler maneja los dispositivos conocidos del usuario y el reporte "No fui yo"
+type DeviceHandler struct {
+	deviceService *services.DeviceService
+}
+
+// NewDeviceHandler crea una nueva instancia del handler de dispositivos
+func NewDeviceHandler(appCtx *config.AppContext) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: services.NewDeviceService(appCtx),
+	}
+}
+
+// ListDevices maneja GET /api/v1/auth/devices
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	devices, err := h.deviceService.ListDevices(c.Request.Context(), userProfile.ID, deviceIDFromRequest(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener dispositivos", err.Error())
+		return
+	}
+
+	response.Success(c, "Dispositivos conocidos obtenidos", gin.H{
+		"devices": devices,
+		"count":   len(devices),
+	})
+}
+
+// RemoveDevice maneja DELETE /api/v1/auth/devices/:id
+func (h *DeviceHandler) RemoveDevice(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	deviceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de dispositivo inválido", "")
+		return
+	}
+
+	if err := h.deviceService.RemoveDevice(c.Request.Context(), userProfile.ID, deviceID); err != nil {
+		if err.Error() == "dispositivo no encontrado" {
+			response.Error(c, http.StatusNotFound, "Dispositivo no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al eliminar dispositivo", err.Error())
+		return
+	}
+
+	response.Success(c, "Dispositivo eliminado", nil)
+}
+
+// ReportDevice maneja POST /api/v1/auth/devices/report (público, enlace "No fui yo")
+func (h *DeviceHandler) ReportDevice(c *gin.Context) {
+	var req models.DeviceReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.deviceService.ReportUnrecognized(c.Request.Context(), req.Token, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "enlace inválido o expirado":
+			response.Error(c, http.StatusBadRequest, "No se pudo procesar el reporte", err.Error())
+		case "este acceso ya fue reportado":
+			response.Error(c, http.StatusConflict, "No se pudo procesar el reporte", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al procesar el reporte", err.Error())
+		}
+		return
+	}
+
+	message := "Cerramos todas sus sesiones. Restablezca su contraseña para volver a ingresar"
+	if result.DirectoryUser {
+		message = "Cerramos todas sus sesiones. Cambie su contraseña en el directorio institucional"
+	}
+	response.Success(c, message, result)
+}
+
+// deviceIDFromRequest obtiene el ID del dispositivo de la cabecera o de la cookie
+func deviceIDFromRequest(c *gin.Context) string {
+	if deviceID := c.GetHeader(services.DeviceHeaderName); deviceID != "" {
+		return deviceID
+	}
+	deviceID, _ := c.Cookie(services.DeviceCookieName)
+	return deviceID
+}
@@ -0,0 +1,80 @@
+// internal/api/handlers/email_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler expone el estado de la cola de correo saliente
+type EmailHandler struct {
+	mailService *services.MailService
+}
+
+// NewEmailHandler crea una nueva instancia del handler de correo
+func NewEmailHandler(appCtx *config.AppContext) *EmailHandler {
+	return &EmailHandler{
+		mailService: services.NewMailService(appCtx),
+	}
+}
+
+// ListEmails maneja GET /api/v1/admin/emails?status=failed&page=1&limit=20
+func (h *EmailHandler) ListEmails(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.EmailStatusPending, models.EmailStatusSending, models.EmailStatusSent, models.EmailStatusFailed:
+	default:
+		response.Error(c, http.StatusBadRequest, "Estado inválido", "use pending, sending, sent o failed")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	emails, total, err := h.mailService.ListQueue(c.Request.Context(), status, page, limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener correos", err.Error())
+		return
+	}
+
+	stats, err := h.mailService.Stats(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener correos", err.Error())
+		return
+	}
+
+	response.Success(c, "Cola de correo obtenida", gin.H{
+		"emails":  emails,
+		"total":   total,
+		"page":    page,
+		"stats":   stats,
+		"enabled": h.mailService.Enabled(),
+	})
+}
+
+// RetryEmail maneja POST /api/v1/admin/emails/:id/retry
+func (h *EmailHandler) RetryEmail(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de correo inválido", "")
+		return
+	}
+
+	if err := h.mailService.Retry(c.Request.Context(), id); err != nil {
+		if err.Error() == "correo no encontrado o no fallido" {
+			response.Error(c, http.StatusNotFound, "Correo no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al reintentar correo", err.Error())
+		return
+	}
+
+	response.Success(c, "Correo encolado nuevamente", nil)
+}
@@ -0,0 +1,72 @@
+// internal/api/handlers/impersonation_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHandler maneja la suplantación de usuarios por soporte
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+}
+
+// NewImpersonationHandler crea una nueva instancia del handler de suplantación
+func NewImpersonationHandler(appCtx *config.AppContext) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: services.NewImpersonationService(appCtx),
+	}
+}
+
+// Impersonate maneja POST /api/v1/admin/users/:id/impersonate
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	var adminClaims *auth.JWTClaims
+	if claims, exists := c.Get("claims"); exists {
+		adminClaims = claims.(*auth.JWTClaims)
+	}
+
+	result, err := h.impersonationService.Start(c.Request.Context(), adminProfile, adminClaims, c.Param("id"),
+		req.Reason, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "usuario no encontrado":
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+		case "no se puede suplantar desde una sesión de suplantación",
+			"no puede suplantarse a sí mismo",
+			"el usuario está inactivo",
+			"no se pueden suplantar cuentas de servicio",
+			"no se puede suplantar a otro administrador",
+			"el usuario no tiene unidad organizacional":
+			response.Error(c, http.StatusBadRequest, "Suplantación no permitida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al iniciar suplantación", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Suplantación iniciada", result)
+}
@@ -9,6 +9,7 @@ import (
 
 	"gamc-backend-go/internal/database/models"
 	"gamc-backend-go/internal/services"
+	"gamc-backend-go/internal/types/requests"
 	"gamc-backend-go/pkg/logger"
 	"gamc-backend-go/pkg/response"
 
@@ -245,16 +246,8 @@ func (h *MessageHandler) GetMessageByID(c *gin.Context) {
 		return
 	}
 
-	// Verificar permisos - el usuario debe poder acceder al mensaje
-	userUnitID := 1
-	if userProfile.OrganizationalUnitID != nil {
-		userUnitID = *userProfile.OrganizationalUnitID
-	}
-
-	// Admin puede ver todo, otros solo mensajes de su unidad
-	if userProfile.Role != "admin" &&
-		messageResponse.SenderUnitID != userUnitID &&
-		messageResponse.ReceiverUnitID != userUnitID {
+	// Verificar permisos - messages:read en la unidad emisora o receptora
+	if err := h.messageService.CheckReadAccess(c.Request.Context(), messageResponse.SenderUnitID, messageResponse.ReceiverUnitID, userProfile.ID); err != nil {
 		response.Error(c, http.StatusForbidden, "No tiene permisos para acceder a este mensaje", "")
 		return
 	}
@@ -579,6 +572,70 @@ func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 	})
 }
 
+// GetReplies maneja GET /api/v1/messages/:id/replies (hilo completo al que pertenece el mensaje)
+func (h *MessageHandler) GetReplies(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de mensaje inválido", "")
+		return
+	}
+
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	thread, err := h.messageService.GetMessageThread(c.Request.Context(), messageID, userProfile)
+	if err != nil {
+		respondMessageReplyError(c, "Error al obtener hilo de conversación", err)
+		return
+	}
+
+	response.Success(c, "Hilo de conversación obtenido", thread)
+}
+
+// ReplyToMessage maneja POST /api/v1/messages/:id/replies
+func (h *MessageHandler) ReplyToMessage(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de mensaje inválido", "")
+		return
+	}
+
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req requests.MessageResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos inválidos", err.Error())
+		return
+	}
+
+	reply, err := h.messageService.ReplyToMessage(c.Request.Context(), messageID, &req, userProfile)
+	if err != nil {
+		respondMessageReplyError(c, "Error al responder mensaje", err)
+		return
+	}
+
+	response.Created(c, "Respuesta enviada exitosamente", reply)
+}
+
+// respondMessageReplyError traduce los errores de hilos y respuestas a la respuesta HTTP
+func respondMessageReplyError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "mensaje no encontrado":
+		response.Error(c, http.StatusNotFound, "Mensaje no encontrado", "")
+	case "no tiene permisos para acceder a este mensaje":
+		response.Error(c, http.StatusForbidden, "No tiene permisos para acceder a este mensaje", "")
+	case "tipo de mensaje no encontrado", "el usuario no pertenece a una unidad organizacional":
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
 // GetSimpleMessageStats maneja GET /api/v1/messages/stats-simple
 func (h *MessageHandler) GetSimpleMessageStats(c *gin.Context) {
 	logger.Info("📊 GET /api/v1/messages/stats-simple - Obtener estadísticas simples")
@@ -0,0 +1,210 @@
+// internal/api/handlers/network_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NetworkHandler maneja los rangos IP permitidos por rol y unidad y sus excepciones
+type NetworkHandler struct {
+	networkService *services.NetworkPolicyService
+}
+
+// NewNetworkHandler crea una nueva instancia del handler de política de red
+func NewNetworkHandler(appCtx *config.AppContext) *NetworkHandler {
+	return &NetworkHandler{
+		networkService: services.NewNetworkPolicyService(appCtx),
+	}
+}
+
+// ListRules maneja GET /api/v1/admin/security/network-rules
+func (h *NetworkHandler) ListRules(c *gin.Context) {
+	rules, err := h.networkService.ListRules(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener reglas de red", err.Error())
+		return
+	}
+
+	response.Success(c, "Reglas de red obtenidas", gin.H{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule maneja POST /api/v1/admin/security/network-rules
+func (h *NetworkHandler) CreateRule(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.NetworkRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	rule, err := h.networkService.CreateRule(c.Request.Context(), &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "unidad organizacional no encontrada":
+			response.Error(c, http.StatusNotFound, "Unidad organizacional no encontrada", err.Error())
+		case err.Error() == "el rango ya está registrado para ese alcance":
+			response.Error(c, http.StatusConflict, "Regla duplicada", err.Error())
+		case err.Error() == "rango IP inválido",
+			err.Error() == "una regla de rol debe indicar solo el rol",
+			err.Error() == "una regla de unidad debe indicar solo la unidad organizacional",
+			strings.HasPrefix(err.Error(), "el cambio le impediría acceder"):
+			response.Error(c, http.StatusBadRequest, "Regla de red inválida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al crear regla de red", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Regla de red creada", rule)
+}
+
+// DeleteRule maneja DELETE /api/v1/admin/security/network-rules/:id
+func (h *NetworkHandler) DeleteRule(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de regla inválido", "")
+		return
+	}
+
+	err = h.networkService.DeleteRule(c.Request.Context(), ruleID, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "regla de red no encontrada":
+			response.Error(c, http.StatusNotFound, "Regla de red no encontrada", err.Error())
+		case strings.HasPrefix(err.Error(), "el cambio le impediría acceder"):
+			response.Error(c, http.StatusBadRequest, "No se puede eliminar la regla", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al eliminar regla de red", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Regla de red eliminada", nil)
+}
+
+// CheckAccess maneja POST /api/v1/admin/security/network-rules/check
+func (h *NetworkHandler) CheckAccess(c *gin.Context) {
+	var req models.NetworkCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	decision, err := h.networkService.Check(c.Request.Context(), &req)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al evaluar reglas de red", err.Error())
+		return
+	}
+
+	response.Success(c, "Reglas de red evaluadas", decision)
+}
+
+// ListBypasses maneja GET /api/v1/admin/security/ip-bypasses?all=true
+func (h *NetworkHandler) ListBypasses(c *gin.Context) {
+	bypasses, err := h.networkService.ListBypasses(c.Request.Context(), c.Query("all") == "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener excepciones de red", err.Error())
+		return
+	}
+
+	response.Success(c, "Excepciones de red obtenidas", gin.H{
+		"bypasses": bypasses,
+		"count":    len(bypasses),
+	})
+}
+
+// CreateBypass maneja POST /api/v1/admin/security/ip-bypasses
+func (h *NetworkHandler) CreateBypass(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.IPAllowlistBypassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	bypass, err := h.networkService.CreateBypass(c.Request.Context(), &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al crear excepción de red", err.Error())
+		return
+	}
+
+	response.Created(c, "Excepción de red creada", bypass)
+}
+
+// RevokeBypass maneja DELETE /api/v1/admin/security/ip-bypasses/:id
+func (h *NetworkHandler) RevokeBypass(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	bypassID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de excepción inválido", "")
+		return
+	}
+
+	err = h.networkService.RevokeBypass(c.Request.Context(), bypassID, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "excepción de red no encontrada":
+			response.Error(c, http.StatusNotFound, "Excepción de red no encontrada", err.Error())
+		case "la excepción ya no está vigente":
+			response.Error(c, http.StatusBadRequest, "No se puede revocar la excepción", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al revocar excepción de red", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Excepción de red revocada", nil)
+}
@@ -0,0 +1,301 @@
+// internal/api/handlers/oauth_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler maneja el proveedor OAuth2 / OpenID Connect
+type OAuthHandler struct {
+	oauthService *services.OAuthService
+}
+
+// NewOAuthHandler crea una nueva instancia del handler OAuth
+func NewOAuthHandler(appCtx *config.AppContext) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: services.NewOAuthService(appCtx),
+	}
+}
+
+// ========================================
+// ENDPOINTS ESTÁNDAR (consumidos por los clientes)
+// ========================================
+
+// Discovery maneja GET /.well-known/openid-configuration
+func (h *OAuthHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.oauthService.Discovery())
+}
+
+// Token maneja POST /api/v1/oauth/token
+// Responde en el formato de RFC 6749 (sin envoltorio) porque lo consumen librerías OAuth.
+func (h *OAuthHandler) Token(c *gin.Context) {
+	var req models.OAuthTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	// client_secret_basic tiene prioridad sobre client_secret_post
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	result, err := h.oauthService.ExchangeCode(c.Request.Context(), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			if oauthErr.Status == http.StatusUnauthorized {
+				c.Header("WWW-Authenticate", `Basic realm="gamc"`)
+			}
+			c.JSON(oauthErr.Status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Introspect maneja POST /api/v1/auth/introspect (RFC 7662)
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	var req models.OAuthIntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	// client_secret_basic tiene prioridad sobre client_secret_post
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	result, err := h.oauthService.Introspect(c.Request.Context(), &req)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			if oauthErr.Status == http.StatusUnauthorized {
+				c.Header("WWW-Authenticate", `Basic realm="gamc"`)
+			}
+			c.JSON(oauthErr.Status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UserInfo maneja GET/POST /api/v1/oauth/userinfo
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	claims, err := h.oauthService.UserInfo(c.Request.Context(), c.GetString("userID"), c.GetString("sessionID"))
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient_scope", "error_description": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// ========================================
+// PANTALLA DE CONSENTIMIENTO (consumida por el frontend)
+// ========================================
+
+// GetAuthorization maneja GET /api/v1/oauth/authorize
+func (h *OAuthHandler) GetAuthorization(c *gin.Context) {
+	var req models.OAuthAuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Parámetros de autorización inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Parámetros de autorización inválidos", err.Error())
+		return
+	}
+
+	result, err := h.oauthService.ValidateAuthorization(c.Request.Context(), c.GetString("userID"), &req)
+	if err != nil {
+		respondOAuthAPIError(c, "Solicitud de autorización inválida", err)
+		return
+	}
+
+	response.Success(c, "Solicitud de autorización válida", result)
+}
+
+// Authorize maneja POST /api/v1/oauth/authorize
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req models.OAuthConsentDecision
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.oauthService.Authorize(c.Request.Context(), c.GetString("userID"), c.GetString("sessionID"), &req)
+	if err != nil {
+		respondOAuthAPIError(c, "Error al autorizar aplicación", err)
+		return
+	}
+
+	message := "Aplicación autorizada"
+	if !req.Approve {
+		message = "Acceso denegado a la aplicación"
+	}
+	response.Success(c, message, result)
+}
+
+// ListConsents maneja GET /api/v1/oauth/consents
+func (h *OAuthHandler) ListConsents(c *gin.Context) {
+	consents, err := h.oauthService.ListConsents(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener aplicaciones autorizadas", err.Error())
+		return
+	}
+
+	response.Success(c, "Aplicaciones autorizadas obtenidas", consents)
+}
+
+// RevokeConsent maneja DELETE /api/v1/oauth/consents/:clientId
+func (h *OAuthHandler) RevokeConsent(c *gin.Context) {
+	clientID := c.Param("clientId")
+
+	if err := h.oauthService.RevokeConsent(c.Request.Context(), c.GetString("userID"), clientID); err != nil {
+		if err.Error() == "autorización no encontrada" {
+			response.Error(c, http.StatusNotFound, "Autorización no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al revocar autorización", err.Error())
+		return
+	}
+
+	response.Success(c, "Acceso de la aplicación revocado", gin.H{"clientId": clientID})
+}
+
+// ========================================
+// HANDLERS ADMINISTRATIVOS
+// ========================================
+
+// ListClients maneja GET /api/v1/admin/oauth/clients
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener clientes", err.Error())
+		return
+	}
+
+	response.Success(c, "Clientes OAuth obtenidos", clients)
+}
+
+// CreateClient maneja POST /api/v1/admin/oauth/clients
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.OAuthClientCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.oauthService.CreateClient(c.Request.Context(), &req, adminProfile.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al registrar cliente", err.Error())
+		return
+	}
+
+	response.Created(c, "Cliente registrado. Guarde el secreto: no se volverá a mostrar", result)
+}
+
+// UpdateClient maneja PUT /api/v1/admin/oauth/clients/:clientId
+func (h *OAuthHandler) UpdateClient(c *gin.Context) {
+	var req models.OAuthClientUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	client, err := h.oauthService.UpdateClient(c.Request.Context(), c.Param("clientId"), &req)
+	if err != nil {
+		if err.Error() == "cliente no encontrado" {
+			response.Error(c, http.StatusNotFound, "Cliente no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al actualizar cliente", err.Error())
+		return
+	}
+
+	response.Success(c, "Cliente actualizado", client)
+}
+
+// RotateClientSecret maneja POST /api/v1/admin/oauth/clients/:clientId/secret
+func (h *OAuthHandler) RotateClientSecret(c *gin.Context) {
+	result, err := h.oauthService.RotateClientSecret(c.Request.Context(), c.Param("clientId"))
+	if err != nil {
+		switch err.Error() {
+		case "cliente no encontrado":
+			response.Error(c, http.StatusNotFound, "Cliente no encontrado", err.Error())
+		case "los clientes públicos no tienen secreto":
+			response.Error(c, http.StatusBadRequest, "Operación no válida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al rotar secreto", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Secreto rotado. Guarde el nuevo secreto: no se volverá a mostrar", result)
+}
+
+// DeleteClient maneja DELETE /api/v1/admin/oauth/clients/:clientId
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	if err := h.oauthService.DeleteClient(c.Request.Context(), c.Param("clientId")); err != nil {
+		if err.Error() == "cliente no encontrado" {
+			response.Error(c, http.StatusNotFound, "Cliente no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al eliminar cliente", err.Error())
+		return
+	}
+
+	response.Success(c, "Cliente eliminado", nil)
+}
+
+// respondOAuthAPIError traduce errores OAuth al formato de respuesta del API
+func respondOAuthAPIError(c *gin.Context, message string, err error) {
+	var oauthErr *services.OAuthError
+	if errors.As(err, &oauthErr) {
+		response.Error(c, oauthErr.Status, message, oauthErr.Code+": "+oauthErr.Description)
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, message, err.Error())
+}
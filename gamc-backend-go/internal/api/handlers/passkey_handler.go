@@ -0,0 +1,210 @@
+// internal/api/handlers/passkey_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PasskeyHandler maneja la gestión de passkeys del usuario y su revocación por administradores.
+// Las ceremonias de registro y login están en AuthHandler junto al login con contraseña.
+type PasskeyHandler struct {
+	passkeyService *services.PasskeyService
+}
+
+// NewPasskeyHandler crea una nueva instancia del handler de passkeys
+func NewPasskeyHandler(appCtx *config.AppContext) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: services.NewPasskeyService(appCtx),
+	}
+}
+
+// ListPasskeys maneja GET /api/v1/auth/passkeys
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	passkeys, err := h.passkeyService.ListCredentials(c.Request.Context(), userProfile.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener passkeys", err.Error())
+		return
+	}
+
+	response.Success(c, "Passkeys obtenidas", gin.H{
+		"passkeys": passkeys,
+		"count":    len(passkeys),
+	})
+}
+
+// RenamePasskey maneja PUT /api/v1/auth/passkeys/:id
+func (h *PasskeyHandler) RenamePasskey(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	credentialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de passkey inválido", "")
+		return
+	}
+
+	var req models.PasskeyRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	passkey, err := h.passkeyService.RenameCredential(c.Request.Context(), userProfile.ID, credentialID, req.Name)
+	if err != nil {
+		if err.Error() == "passkey no encontrada" {
+			response.Error(c, http.StatusNotFound, "Passkey no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al renombrar passkey", err.Error())
+		return
+	}
+
+	response.Success(c, "Passkey renombrada", passkey)
+}
+
+// RemovePasskey maneja DELETE /api/v1/auth/passkeys/:id
+func (h *PasskeyHandler) RemovePasskey(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	credentialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de passkey inválido", "")
+		return
+	}
+
+	err = h.passkeyService.RemoveCredential(c.Request.Context(), userProfile, credentialID,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "passkey no encontrada":
+			response.Error(c, http.StatusNotFound, "Passkey no encontrada", err.Error())
+		case "su rol requiere autenticación de dos factores":
+			response.Error(c, http.StatusForbidden, "No se puede eliminar la última passkey",
+				"Su rol requiere autenticación de dos factores. Active TOTP o registre otra passkey antes de eliminarla")
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al eliminar passkey", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Passkey eliminada", nil)
+}
+
+// ========================================
+// ADMINISTRACIÓN
+// ========================================
+
+// AdminListPasskeys maneja GET /api/v1/admin/users/:id/passkeys (?all=true incluye revocadas)
+func (h *PasskeyHandler) AdminListPasskeys(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	includeRevoked := c.Query("all") == "true"
+	passkeys, err := h.passkeyService.AdminListCredentials(c.Request.Context(), userID, includeRevoked)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al obtener passkeys", err.Error())
+		return
+	}
+
+	response.Success(c, "Passkeys del usuario obtenidas", gin.H{
+		"passkeys": passkeys,
+		"count":    len(passkeys),
+	})
+}
+
+// AdminRevokePasskey maneja DELETE /api/v1/admin/users/:id/passkeys/:credentialId
+func (h *PasskeyHandler) AdminRevokePasskey(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	credentialID, err := strconv.Atoi(c.Param("credentialId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de passkey inválido", "")
+		return
+	}
+
+	err = h.passkeyService.RevokeCredential(c.Request.Context(), userID, credentialID, adminProfile,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "usuario no encontrado":
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+		case "passkey no encontrada":
+			response.Error(c, http.StatusNotFound, "Passkey no encontrada", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al revocar passkey", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Passkey revocada", nil)
+}
+
+// AdminRevokeAllPasskeys maneja DELETE /api/v1/admin/users/:id/passkeys
+func (h *PasskeyHandler) AdminRevokeAllPasskeys(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	revoked, err := h.passkeyService.RevokeAllCredentials(c.Request.Context(), userID, adminProfile,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al revocar passkeys", err.Error())
+		return
+	}
+
+	response.Success(c, "Passkeys revocadas", gin.H{
+		"revoked": revoked,
+	})
+}
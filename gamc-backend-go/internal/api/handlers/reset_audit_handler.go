@@ -0,0 +1,168 @@
+// internal/api/handlers/reset_audit_handler.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ResetAuditHandler maneja el tablero de auditoría de resets de contraseña
+type ResetAuditHandler struct {
+	resetAuditService *services.ResetAuditService
+}
+
+// NewResetAuditHandler crea una nueva instancia del handler de auditoría de resets
+func NewResetAuditHandler(appCtx *config.AppContext) *ResetAuditHandler {
+	return &ResetAuditHandler{
+		resetAuditService: services.NewResetAuditService(appCtx),
+	}
+}
+
+// GetReport maneja GET /api/v1/auth/admin/reset-audit
+// (?from=2024-01-01&to=2024-01-31&userId=&ip=&unitId=&outcome=&search=)
+func (h *ResetAuditHandler) GetReport(c *gin.Context) {
+	filter, ok := parseResetAuditFilter(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.resetAuditService.GetReport(c.Request.Context(), filter)
+	if err != nil {
+		respondResetAuditError(c, "Error al obtener auditoría de resets", err)
+		return
+	}
+
+	response.Success(c, "Auditoría de resets obtenida", report)
+}
+
+// ListResets maneja GET /api/v1/auth/admin/reset-audit/resets (mismos filtros, ?page=1&limit=20)
+func (h *ResetAuditHandler) ListResets(c *gin.Context) {
+	filter, ok := parseResetAuditFilter(c)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.resetAuditService.ListResets(c.Request.Context(), filter)
+	if err != nil {
+		respondResetAuditError(c, "Error al obtener solicitudes de reset", err)
+		return
+	}
+
+	response.Success(c, "Solicitudes de reset obtenidas", gin.H{
+		"resets": entries,
+		"total":  total,
+		"page":   filter.Page,
+	})
+}
+
+// ExportCSV maneja GET /api/v1/auth/admin/reset-audit/export (mismos filtros)
+func (h *ResetAuditHandler) ExportCSV(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseResetAuditFilter(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.resetAuditService.ExportCSV(c.Request.Context(), filter, adminProfile,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondResetAuditError(c, "Error al exportar auditoría de resets", err)
+		return
+	}
+
+	filename := fmt.Sprintf("reset-audit-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// parseResetAuditFilter lee los filtros de la query; responde 400 si alguno es inválido
+func parseResetAuditFilter(c *gin.Context) (*services.ResetAuditFilter, bool) {
+	filter := &services.ResetAuditFilter{
+		IPAddress: strings.TrimSpace(c.Query("ip")),
+		Outcome:   c.Query("outcome"),
+		Search:    strings.TrimSpace(c.Query("search")),
+	}
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := parseResetAuditDate(raw, false)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Fecha inválida", "from debe tener formato YYYY-MM-DD o RFC3339")
+			return nil, false
+		}
+		filter.DateFrom = &from
+	}
+	if raw := c.Query("to"); raw != "" {
+		to, err := parseResetAuditDate(raw, true)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Fecha inválida", "to debe tener formato YYYY-MM-DD o RFC3339")
+			return nil, false
+		}
+		filter.DateTo = &to
+	}
+
+	if raw := c.Query("userId"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+			return nil, false
+		}
+		filter.UserID = &userID
+	}
+
+	if raw := c.Query("unitId"); raw != "" {
+		unitID, err := strconv.Atoi(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "ID de unidad inválido", "")
+			return nil, false
+		}
+		filter.OrganizationalUnitID = &unitID
+	}
+
+	return filter, true
+}
+
+// parseResetAuditDate acepta YYYY-MM-DD (el fin del período incluye el día completo) o RFC3339
+func parseResetAuditDate(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// respondResetAuditError traduce los errores del tablero a la respuesta HTTP
+func respondResetAuditError(c *gin.Context, message string, err error) {
+	switch {
+	case err.Error() == "rango de fechas inválido",
+		strings.HasPrefix(err.Error(), "el período no puede superar"),
+		strings.HasPrefix(err.Error(), "resultado inválido"):
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,268 @@
+// internal/api/handlers/role_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RoleHandler maneja roles, permisos y asignaciones por unidad
+type RoleHandler struct {
+	permissionService *services.PermissionService
+}
+
+// NewRoleHandler crea una nueva instancia del handler de roles
+func NewRoleHandler(appCtx *config.AppContext) *RoleHandler {
+	return &RoleHandler{
+		permissionService: services.NewPermissionService(appCtx.DB),
+	}
+}
+
+// GetMyPermissions maneja GET /api/v1/auth/permissions
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	effective, err := h.permissionService.Effective(c.Request.Context(), userProfile.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener permisos", err.Error())
+		return
+	}
+
+	response.Success(c, "Permisos obtenidos", effective.ToResponse())
+}
+
+// ListPermissions maneja GET /api/v1/admin/permissions
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.ListPermissions(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener permisos", err.Error())
+		return
+	}
+
+	response.Success(c, "Catálogo de permisos obtenido", gin.H{
+		"permissions": permissions,
+		"count":       len(permissions),
+	})
+}
+
+// ListRoles maneja GET /api/v1/admin/roles
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.permissionService.ListRoles(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener roles", err.Error())
+		return
+	}
+
+	response.Success(c, "Roles obtenidos", gin.H{
+		"roles": roles,
+		"count": len(roles),
+	})
+}
+
+// CreateRole maneja POST /api/v1/admin/roles
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	role, err := h.permissionService.CreateRole(c.Request.Context(), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "ya existe un rol con ese código":
+			response.Error(c, http.StatusConflict, "Rol duplicado", err.Error())
+		case strings.HasPrefix(err.Error(), "código de rol inválido"), strings.HasPrefix(err.Error(), "permiso desconocido"):
+			response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al crear rol", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Rol creado", role)
+}
+
+// UpdateRole maneja PUT /api/v1/admin/roles/:code
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	role, err := h.permissionService.UpdateRole(c.Request.Context(), c.Param("code"), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "rol no encontrado":
+			response.Error(c, http.StatusNotFound, "Rol no encontrado", err.Error())
+		case strings.HasPrefix(err.Error(), "permiso desconocido"), strings.HasPrefix(err.Error(), "el rol admin debe conservar"):
+			response.Error(c, http.StatusBadRequest, "Operación no válida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al actualizar rol", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Rol actualizado", role)
+}
+
+// DeleteRole maneja DELETE /api/v1/admin/roles/:code
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	err := h.permissionService.DeleteRole(c.Request.Context(), c.Param("code"), adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "rol no encontrado":
+			response.Error(c, http.StatusNotFound, "Rol no encontrado", err.Error())
+		case "los roles de sistema no se pueden eliminar":
+			response.Error(c, http.StatusBadRequest, "Operación no válida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al eliminar rol", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Rol eliminado junto con sus asignaciones", nil)
+}
+
+// ListUserRoles maneja GET /api/v1/admin/users/:id/roles
+func (h *RoleHandler) ListUserRoles(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	assignments, err := h.permissionService.ListAssignments(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener roles asignados", err.Error())
+		return
+	}
+
+	effective, err := h.permissionService.Effective(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al obtener permisos", err.Error())
+		return
+	}
+
+	response.Success(c, "Roles del usuario obtenidos", gin.H{
+		"assignments": assignments,
+		"permissions": effective.ToResponse(),
+	})
+}
+
+// AssignRole maneja POST /api/v1/admin/users/:id/roles
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	var req models.RoleAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	assignment, err := h.permissionService.Assign(c.Request.Context(), userID, &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "usuario no encontrado", "rol no encontrado":
+			response.Error(c, http.StatusNotFound, "Recurso no encontrado", err.Error())
+		case "el usuario ya tiene ese rol en ese alcance":
+			response.Error(c, http.StatusConflict, "Asignación duplicada", err.Error())
+		case "unidad organizacional no encontrada", "la fecha de expiración debe ser futura":
+			response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al asignar rol", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Rol asignado", assignment)
+}
+
+// RemoveRole maneja DELETE /api/v1/admin/users/:id/roles/:assignmentId
+func (h *RoleHandler) RemoveRole(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	assignmentID, err := strconv.Atoi(c.Param("assignmentId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de asignación inválido", "")
+		return
+	}
+
+	err = h.permissionService.RemoveAssignment(c.Request.Context(), userID, assignmentID, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "asignación no encontrada" {
+			response.Error(c, http.StatusNotFound, "Asignación no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al retirar rol", err.Error())
+		return
+	}
+
+	response.Success(c, "Rol retirado", nil)
+}
@@ -0,0 +1,112 @@
+// internal/api/handlers/security_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/internal/types/requests"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHandler maneja la administración de seguridad (bloqueos, políticas)
+type SecurityHandler struct {
+	lockoutService *services.LockoutService
+	policyService  *services.SecurityPolicyService
+}
+
+// NewSecurityHandler crea una nueva instancia del handler de seguridad
+func NewSecurityHandler(appCtx *config.AppContext) *SecurityHandler {
+	return &SecurityHandler{
+		lockoutService: services.NewLockoutService(appCtx),
+		policyService:  services.NewSecurityPolicyService(appCtx),
+	}
+}
+
+// GetLockouts maneja GET /api/v1/admin/security/lockouts
+func (h *SecurityHandler) GetLockouts(c *gin.Context) {
+	locks, err := h.lockoutService.GetActiveLocks(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener bloqueos", err.Error())
+		return
+	}
+
+	response.Success(c, "Bloqueos activos obtenidos", gin.H{
+		"lockouts": locks,
+		"count":    len(locks),
+	})
+}
+
+// Unlock maneja POST /api/v1/admin/security/lockouts/unlock
+func (h *SecurityHandler) Unlock(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req services.UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	err := h.lockoutService.Unlock(c.Request.Context(), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Error al desbloquear", err.Error())
+		return
+	}
+
+	response.Success(c, "Desbloqueo realizado exitosamente", gin.H{
+		"email":     req.Email,
+		"ipAddress": req.IPAddress,
+	})
+}
+
+// GetPolicy maneja GET /api/v1/admin/security/policies
+func (h *SecurityHandler) GetPolicy(c *gin.Context) {
+	response.Success(c, "Política de seguridad obtenida", h.policyService.Current(c.Request.Context()))
+}
+
+// UpdatePolicy maneja PUT /api/v1/admin/security/policies
+func (h *SecurityHandler) UpdatePolicy(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req requests.SecurityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	policy, err := h.policyService.Update(c.Request.Context(), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "el aviso de expiración debe ser menor a la vigencia de la contraseña" ||
+			strings.HasPrefix(err.Error(), "la lista de IPs permitidas debe incluir su IP actual") ||
+			strings.HasPrefix(err.Error(), "la inactividad") {
+			response.Error(c, http.StatusBadRequest, "Política inválida", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al actualizar política de seguridad", err.Error())
+		return
+	}
+
+	response.Success(c, "Política de seguridad actualizada", policy)
+}
+
+// GetPasswordPolicy maneja GET /api/v1/auth/password-policy (público)
+func (h *SecurityHandler) GetPasswordPolicy(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=60")
+	response.Success(c, "Política de contraseñas obtenida", h.policyService.Current(c.Request.Context()).ToPublic())
+}
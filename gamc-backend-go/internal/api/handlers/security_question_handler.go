@@ -0,0 +1,216 @@
+// internal/api/handlers/security_question_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityQuestionHandler maneja la administración del catálogo de preguntas de seguridad
+type SecurityQuestionHandler struct {
+	questionService *services.SecurityQuestionService
+}
+
+// NewSecurityQuestionHandler crea una nueva instancia del handler del catálogo
+func NewSecurityQuestionHandler(appCtx *config.AppContext) *SecurityQuestionHandler {
+	return &SecurityQuestionHandler{
+		questionService: services.NewSecurityQuestionService(appCtx),
+	}
+}
+
+// ListQuestions maneja GET /api/v1/admin/security/questions (?category=personal, ?all=true incluye desactivadas)
+func (h *SecurityQuestionHandler) ListQuestions(c *gin.Context) {
+	category := c.Query("category")
+	if category != "" && !isSecurityQuestionCategory(category) {
+		response.Error(c, http.StatusBadRequest, "Categoría inválida", category)
+		return
+	}
+
+	questions, err := h.questionService.ListQuestions(c.Request.Context(), category, c.Query("all") == "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener preguntas de seguridad", err.Error())
+		return
+	}
+
+	response.Success(c, "Preguntas de seguridad obtenidas", gin.H{
+		"questions":  questions,
+		"count":      len(questions),
+		"categories": models.GetSecurityQuestionCategories(),
+	})
+}
+
+// CreateQuestion maneja POST /api/v1/admin/security/questions
+func (h *SecurityQuestionHandler) CreateQuestion(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.SecurityQuestionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	question, err := h.questionService.CreateQuestion(c.Request.Context(), &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondSecurityQuestionError(c, "Error al crear pregunta de seguridad", err)
+		return
+	}
+
+	response.Created(c, "Pregunta de seguridad creada", question)
+}
+
+// UpdateQuestion maneja PUT /api/v1/admin/security/questions/:id
+func (h *SecurityQuestionHandler) UpdateQuestion(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	questionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de pregunta inválido", "")
+		return
+	}
+
+	var req models.SecurityQuestionEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	question, affected, err := h.questionService.UpdateQuestion(c.Request.Context(), questionID, &req, adminProfile,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondSecurityQuestionError(c, "Error al actualizar pregunta de seguridad", err)
+		return
+	}
+
+	response.Success(c, "Pregunta de seguridad actualizada", gin.H{
+		"question":      question,
+		"affectedUsers": affected,
+	})
+}
+
+// DeactivateQuestion maneja DELETE /api/v1/admin/security/questions/:id
+func (h *SecurityQuestionHandler) DeactivateQuestion(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	questionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de pregunta inválido", "")
+		return
+	}
+
+	affected, err := h.questionService.DeactivateQuestion(c.Request.Context(), questionID, adminProfile,
+		c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondSecurityQuestionError(c, "Error al desactivar pregunta de seguridad", err)
+		return
+	}
+
+	response.Success(c, "Pregunta de seguridad desactivada", gin.H{
+		"affectedUsers": affected,
+	})
+}
+
+// ReorderQuestions maneja PUT /api/v1/admin/security/questions/order
+func (h *SecurityQuestionHandler) ReorderQuestions(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.SecurityQuestionReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	questions, err := h.questionService.ReorderQuestions(c.Request.Context(), &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		respondSecurityQuestionError(c, "Error al reordenar preguntas de seguridad", err)
+		return
+	}
+
+	response.Success(c, "Preguntas de seguridad reordenadas", gin.H{
+		"questions": questions,
+		"count":     len(questions),
+	})
+}
+
+// GetStats maneja GET /api/v1/auth/admin/security-questions/stats (?days=30)
+func (h *SecurityQuestionHandler) GetStats(c *gin.Context) {
+	days := services.SecurityQuestionStatsDefaultDays
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > services.SecurityQuestionStatsMaxDays {
+			response.Error(c, http.StatusBadRequest, "Período inválido",
+				"days debe estar entre 1 y "+strconv.Itoa(services.SecurityQuestionStatsMaxDays))
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := h.questionService.GetStats(c.Request.Context(), days)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener estadísticas", err.Error())
+		return
+	}
+
+	response.Success(c, "Estadísticas de preguntas de seguridad obtenidas", stats)
+}
+
+// respondSecurityQuestionError traduce los errores del catálogo a la respuesta HTTP
+func respondSecurityQuestionError(c *gin.Context, message string, err error) {
+	switch {
+	case err.Error() == "pregunta de seguridad no encontrada":
+		response.Error(c, http.StatusNotFound, "Pregunta de seguridad no encontrada", err.Error())
+	case err.Error() == "ya existe una pregunta con ese texto",
+		err.Error() == "la pregunta de seguridad ya está desactivada":
+		response.Error(c, http.StatusConflict, message, err.Error())
+	case strings.HasPrefix(err.Error(), "el catálogo debe conservar"),
+		strings.HasPrefix(err.Error(), "pregunta de seguridad ID"),
+		strings.HasPrefix(err.Error(), "la pregunta ID"):
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
+func isSecurityQuestionCategory(category string) bool {
+	for _, c := range models.GetSecurityQuestionCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
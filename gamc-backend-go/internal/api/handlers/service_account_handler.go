@@ -0,0 +1,171 @@
+// internal/api/handlers/service_account_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccountHandler maneja la administración de cuentas de servicio y API keys
+type ServiceAccountHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewServiceAccountHandler crea una nueva instancia del handler de cuentas de servicio
+func NewServiceAccountHandler(appCtx *config.AppContext) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		apiKeyService: services.NewAPIKeyService(appCtx),
+	}
+}
+
+// ListServiceAccounts maneja GET /api/v1/admin/service-accounts
+func (h *ServiceAccountHandler) ListServiceAccounts(c *gin.Context) {
+	accounts, err := h.apiKeyService.ListServiceAccounts(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener cuentas de servicio", err.Error())
+		return
+	}
+
+	response.Success(c, "Cuentas de servicio obtenidas", gin.H{
+		"serviceAccounts": accounts,
+		"count":           len(accounts),
+		"availableScopes": models.GetAPIKeyScopes(),
+	})
+}
+
+// CreateServiceAccount maneja POST /api/v1/admin/service-accounts
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.ServiceAccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	account, err := h.apiKeyService.CreateServiceAccount(c.Request.Context(), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "unidad organizacional no encontrada":
+			response.Error(c, http.StatusBadRequest, "Unidad organizacional no válida", err.Error())
+		case "ya existe una cuenta de servicio con ese nombre":
+			response.Error(c, http.StatusConflict, "Cuenta de servicio duplicada", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al crear cuenta de servicio", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Cuenta de servicio creada", account)
+}
+
+// DeactivateServiceAccount maneja DELETE /api/v1/admin/service-accounts/:id
+func (h *ServiceAccountHandler) DeactivateServiceAccount(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	err := h.apiKeyService.DeactivateServiceAccount(c.Request.Context(), c.Param("id"), adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "cuenta de servicio no encontrada" {
+			response.Error(c, http.StatusNotFound, "Cuenta de servicio no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al desactivar cuenta de servicio", err.Error())
+		return
+	}
+
+	response.Success(c, "Cuenta de servicio desactivada y sus API keys revocadas", nil)
+}
+
+// ListKeys maneja GET /api/v1/admin/service-accounts/:id/keys
+func (h *ServiceAccountHandler) ListKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListKeys(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err.Error() == "cuenta de servicio no encontrada" {
+			response.Error(c, http.StatusNotFound, "Cuenta de servicio no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al obtener API keys", err.Error())
+		return
+	}
+
+	response.Success(c, "API keys obtenidas", gin.H{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// CreateKey maneja POST /api/v1/admin/service-accounts/:id/keys
+func (h *ServiceAccountHandler) CreateKey(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.apiKeyService.CreateKey(c.Request.Context(), c.Param("id"), &req, adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "cuenta de servicio no encontrada":
+			response.Error(c, http.StatusNotFound, "Cuenta de servicio no encontrada", err.Error())
+		case err.Error() == "cuenta de servicio inactiva", strings.HasPrefix(err.Error(), "scope no válido"):
+			response.Error(c, http.StatusBadRequest, "Operación no válida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al crear API key", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "API key creada. Guarde la key: no se volverá a mostrar", result)
+}
+
+// RevokeKey maneja DELETE /api/v1/admin/service-accounts/:id/keys/:keyId
+func (h *ServiceAccountHandler) RevokeKey(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	err := h.apiKeyService.RevokeKey(c.Request.Context(), c.Param("id"), c.Param("keyId"), adminProfile.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "cuenta de servicio no encontrada", "API key no encontrada":
+			response.Error(c, http.StatusNotFound, "API key no encontrada", err.Error())
+		case "API key ya revocada":
+			response.Error(c, http.StatusBadRequest, "Operación no válida", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al revocar API key", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "API key revocada", nil)
+}
@@ -0,0 +1,96 @@
+// internal/api/handlers/session_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler maneja la consulta y revocación de sesiones del usuario
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionHandler crea una nueva instancia del handler de sesiones
+func NewSessionHandler(appCtx *config.AppContext) *SessionHandler {
+	return &SessionHandler{
+		sessionService: services.NewSessionService(appCtx),
+	}
+}
+
+// ListSessions maneja GET /api/v1/auth/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), userProfile.ID.String(), c.GetString("sessionID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener sesiones", err.Error())
+		return
+	}
+
+	response.Success(c, "Sesiones activas obtenidas", gin.H{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeSession maneja DELETE /api/v1/auth/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		response.Error(c, http.StatusBadRequest, "ID de sesión requerido", "")
+		return
+	}
+
+	err := h.sessionService.RevokeSession(c.Request.Context(), userProfile.ID.String(), sessionID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if err.Error() == "sesión no encontrada" {
+			response.Error(c, http.StatusNotFound, "Sesión no encontrada", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al cerrar sesión", err.Error())
+		return
+	}
+
+	// Si se cerró la sesión actual, limpiar también la cookie del refresh token
+	isCurrent := sessionID == c.GetString("sessionID")
+	if isCurrent {
+		c.SetCookie("refreshToken", "", -1, "/", "", false, true)
+	}
+
+	response.Success(c, "Sesión cerrada exitosamente", gin.H{
+		"sessionId": sessionID,
+		"isCurrent": isCurrent,
+	})
+}
+
+// RevokeOtherSessions maneja POST /api/v1/auth/sessions/revoke-others
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), userProfile.ID.String(), c.GetString("sessionID"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al cerrar sesiones", err.Error())
+		return
+	}
+
+	response.Success(c, "Se cerraron todas las demás sesiones", gin.H{
+		"revokedSessions": count,
+	})
+}
@@ -0,0 +1,224 @@
+// internal/api/handlers/two_factor_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/logger"
+	"gamc-backend-go/pkg/response"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler maneja el enrolamiento y la administración de 2FA
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+	sessionService   *services.SessionService
+}
+
+// NewTwoFactorHandler crea una nueva instancia del handler de 2FA
+func NewTwoFactorHandler(appCtx *config.AppContext) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: services.NewTwoFactorService(appCtx),
+		sessionService:   services.NewSessionService(appCtx),
+	}
+}
+
+// GetStatus maneja GET /api/v1/auth/2fa/status
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactorService.GetStatus(c.Request.Context(), userProfile.ID.String(), userProfile.Role)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener estado 2FA", err.Error())
+		return
+	}
+
+	response.Success(c, "Estado 2FA obtenido", status)
+}
+
+// BeginSetup maneja POST /api/v1/auth/2fa/setup
+func (h *TwoFactorHandler) BeginSetup(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.twoFactorService.BeginSetup(c.Request.Context(), userProfile.ID.String())
+	if err != nil {
+		if err.Error() == "la autenticación de dos factores ya está activada" {
+			response.Error(c, http.StatusConflict, "2FA ya activado", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Error al iniciar configuración 2FA", err.Error())
+		return
+	}
+
+	response.Success(c, "Escanee el código QR con su aplicación autenticadora y confirme con un código", setup)
+}
+
+// ConfirmSetup maneja POST /api/v1/auth/2fa/confirm
+func (h *TwoFactorHandler) ConfirmSetup(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	result, err := h.twoFactorService.ConfirmSetup(c.Request.Context(), userProfile.ID.String(), req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Error al confirmar 2FA", err.Error())
+		return
+	}
+
+	// La sesión restringida al enrolamiento termina aquí: el usuario inicia sesión de nuevo con 2FA
+	if claims, ok := c.Get("claims"); ok && claims.(*auth.JWTClaims).Restriction == auth.RestrictionTwoFactorSetup {
+		if err := h.sessionService.EndSession(c.Request.Context(), userProfile.ID.String(), c.GetString("sessionID")); err != nil {
+			logger.Warn("Error al cerrar sesión restringida: %v", err)
+		}
+		response.Success(c, "Autenticación de dos factores activada. Guarde sus códigos de recuperación e inicie sesión nuevamente", gin.H{
+			"enabled":         result.Enabled,
+			"recoveryCodes":   result.RecoveryCodes,
+			"reloginRequired": true,
+		})
+		return
+	}
+
+	response.Success(c, "Autenticación de dos factores activada. Guarde sus códigos de recuperación en un lugar seguro", result)
+}
+
+// Disable maneja POST /api/v1/auth/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userProfile.ID.String(), &req); err != nil {
+		if err.Error() == "su rol requiere autenticación de dos factores" {
+			response.Error(c, http.StatusForbidden, "No permitido", err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Error al desactivar 2FA", err.Error())
+		return
+	}
+
+	response.Success(c, "Autenticación de dos factores desactivada", nil)
+}
+
+// RegenerateRecoveryCodes maneja POST /api/v1/auth/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userProfile.ID.String(), req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Error al regenerar códigos", err.Error())
+		return
+	}
+
+	response.Success(c, "Códigos de recuperación regenerados", gin.H{
+		"recoveryCodes": codes,
+	})
+}
+
+// ========================================
+// HANDLERS ADMINISTRATIVOS
+// ========================================
+
+// GetRoleRequirements maneja GET /api/v1/admin/security/two-factor/roles
+func (h *TwoFactorHandler) GetRoleRequirements(c *gin.Context) {
+	requirements, err := h.twoFactorService.GetRoleRequirements(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener requerimientos 2FA", err.Error())
+		return
+	}
+
+	response.Success(c, "Requerimientos 2FA por rol obtenidos", requirements)
+}
+
+// SetRoleRequirement maneja PUT /api/v1/admin/security/two-factor/roles
+func (h *TwoFactorHandler) SetRoleRequirement(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoleTwoFactorUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	requirement, err := h.twoFactorService.SetRoleRequirement(c.Request.Context(), &req, adminProfile.ID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Error al actualizar requerimiento 2FA", err.Error())
+		return
+	}
+
+	response.Success(c, "Requerimiento 2FA actualizado", requirement)
+}
+
+// getUserProfile obtiene el perfil del usuario autenticado desde el contexto
+func getUserProfile(c *gin.Context) (*models.UserProfile, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Usuario no autenticado", "")
+		return nil, false
+	}
+
+	userProfile, ok := user.(*models.UserProfile)
+	if !ok {
+		response.Error(c, http.StatusInternalServerError, "Error interno del servidor", "")
+		return nil, false
+	}
+
+	return userProfile, true
+}
@@ -0,0 +1,98 @@
+// internal/api/handlers/user_admin_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/services"
+	"gamc-backend-go/internal/types/requests"
+	"gamc-backend-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UserAdminHandler maneja el alta y la edición de cuentas por administradores
+type UserAdminHandler struct {
+	userAdminService *services.UserAdminService
+}
+
+// NewUserAdminHandler crea una nueva instancia del handler de administración de usuarios
+func NewUserAdminHandler(appCtx *config.AppContext) *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminService: services.NewUserAdminService(appCtx),
+	}
+}
+
+// CreateUser maneja POST /api/v1/admin/users
+func (h *UserAdminHandler) CreateUser(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var req requests.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	user, err := h.userAdminService.CreateUser(c.Request.Context(), &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "ya existe un usuario con ese email o username":
+			response.Error(c, http.StatusConflict, "Usuario duplicado", err.Error())
+		case err.Error() == "unidad organizacional no encontrada":
+			response.Error(c, http.StatusNotFound, "Unidad organizacional no encontrada", err.Error())
+		case err.Error() == "la fecha de expiración debe ser futura",
+			strings.HasPrefix(err.Error(), "contraseña inválida"):
+			response.Error(c, http.StatusBadRequest, "Datos de usuario inválidos", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Error al crear usuario", err.Error())
+		}
+		return
+	}
+
+	response.Created(c, "Usuario creado", user)
+}
+
+// UpdateUser maneja PUT /api/v1/admin/users/:id
+func (h *UserAdminHandler) UpdateUser(c *gin.Context) {
+	adminProfile, ok := getUserProfile(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "ID de usuario inválido", "")
+		return
+	}
+
+	var req requests.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Datos de entrada inválidos", err.Error())
+		return
+	}
+
+	user, err := h.userAdminService.UpdateUser(c.Request.Context(), userID, &req, adminProfile, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "usuario no encontrado":
+			response.Error(c, http.StatusNotFound, "Usuario no encontrado", err.Error())
+		case err.Error() == "unidad organizacional no encontrada":
+			response.Error(c, http.StatusNotFound, "Unidad organizacional no encontrada", err.Error())
+		case err.Error() == "ya existe un usuario con ese email o username":
+			response.Error(c, http.StatusConflict, "Usuario duplicado", err.Error())
+		case strings.HasPrefix(err.Error(), "error al"):
+			response.Error(c, http.StatusInternalServerError, "Error al actualizar usuario", err.Error())
+		default:
+			response.Error(c, http.StatusBadRequest, "Datos de usuario inválidos", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, "Usuario actualizado", user)
+}
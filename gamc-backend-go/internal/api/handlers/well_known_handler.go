@@ -0,0 +1,38 @@
+// internal/api/handlers/well_known_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WellKnownHandler expone metadatos públicos para que otros sistemas municipales
+// verifiquen nuestros tokens sin compartir secretos
+type WellKnownHandler struct {
+	jwtService *auth.JWTService
+}
+
+// NewWellKnownHandler crea una nueva instancia del handler de metadatos públicos
+func NewWellKnownHandler(appCtx *config.AppContext) *WellKnownHandler {
+	return &WellKnownHandler{
+		jwtService: auth.NewJWTService(appCtx.Config),
+	}
+}
+
+// JWKS maneja GET /.well-known/jwks.json
+// Responde el JWK Set estándar (sin envoltorio) para que lo consuman librerías JWT.
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	jwks, err := h.jwtService.JWKS()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Error al obtener claves públicas", err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
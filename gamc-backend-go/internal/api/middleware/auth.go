@@ -2,7 +2,9 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"gamc-backend-go/internal/auth"
@@ -22,13 +24,25 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 	sessionManager := redis.NewSessionManager(appCtx.Redis)
 	blacklistManager := redis.NewJWTBlacklistManager(appCtx.Redis)
 	authService := services.NewAuthService(appCtx)
+	sessionService := services.NewSessionService(appCtx)
+	apiKeyService := services.NewAPIKeyService(appCtx)
+	impersonationService := services.NewImpersonationService(appCtx)
+	networkPolicyService := services.NewNetworkPolicyService(appCtx)
 
 	return func(c *gin.Context) {
 		logger.Info("🔍 AUTH DEBUG: Iniciando validación para %s %s", c.Request.Method, c.Request.URL.Path)
 
 		// Extraer token del header Authorization
 		authHeader := c.GetHeader("Authorization")
-		logger.Info("🔍 AUTH DEBUG: Header Authorization = '%s'", authHeader)
+
+		// Integraciones: Authorization: ApiKey gamc_<prefix>_<secreto>. Se atienden antes
+		// del log de depuración para que el secreto de larga duración nunca llegue a los logs.
+		if apiKey := auth.ExtractAPIKeyFromHeader(authHeader); apiKey != "" {
+			authenticateAPIKey(c, apiKeyService, networkPolicyService, apiKey)
+			return
+		}
+
+		logger.Info("🔍 AUTH DEBUG: Header Authorization = '%s'", redactAuthorization(authHeader))
 
 		if authHeader == "" {
 			logger.Error("🚨 AUTH DEBUG: Header Authorization vacío")
@@ -49,7 +63,11 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 
 		// Verificar y parsear token
 		logger.Info("🔍 AUTH DEBUG: Verificando token JWT...")
+		// Los tokens de clientes OAuth (aud = client_id) solo se aceptan en /oauth/userinfo
 		claims, err := jwtService.VerifyAccessToken(token)
+		if err != nil && isOAuthClientRoute(c.FullPath()) {
+			claims, err = jwtService.VerifyClientAccessToken(token)
+		}
 		if err != nil {
 			logger.Error("🚨 AUTH DEBUG: Error al verificar token: %v", err)
 			response.Error(c, http.StatusUnauthorized, "Token inválido o expirado", "")
@@ -99,7 +117,7 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 		logger.Info("✅ AUTH DEBUG: Sesión encontrada - UserID: %s", sessionData.UserID)
 
 		// Verificar que los datos del token coincidan con la sesión
-		if sessionData.UserID != claims.UserID || sessionData.Email != claims.Email {
+		if sessionData.UserID != claims.UserID || sessionData.Email != claims.Email || sessionData.ClientID != claims.ClientID {
 			logger.Error("🚨 AUTH DEBUG: Datos inconsistentes - Token: %s/%s vs Sesión: %s/%s",
 				claims.UserID, claims.Email, sessionData.UserID, sessionData.Email)
 			response.Error(c, http.StatusUnauthorized, "Datos de sesión inconsistentes", "")
@@ -109,6 +127,56 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 
 		logger.Info("✅ AUTH DEBUG: Datos de sesión consistentes")
 
+		// Inactividad del rol y vigencia máxima desde el login
+		if services.IsInteractiveSession(sessionData) {
+			if reason := sessionService.Expiry(c.Request.Context(), sessionData); reason != "" {
+				logger.Error("🚨 AUTH DEBUG: Sesión %s expirada (%s)", claims.SessionID, reason)
+				sessionService.ExpireSession(c.Request.Context(), sessionData, reason)
+				response.Error(c, http.StatusUnauthorized, "Sesión expirada", "SESSION_EXPIRED")
+				c.Abort()
+				return
+			}
+		}
+
+		// Los tokens emitidos a clientes OAuth solo sirven para /oauth/userinfo
+		if sessionData.ClientID != "" && !isOAuthClientRoute(c.FullPath()) {
+			logger.Error("🚨 AUTH DEBUG: Token de cliente OAuth %s usado fuera de userinfo", sessionData.ClientID)
+			response.Error(c, http.StatusForbidden, "Token de aplicación externa no autorizado para este recurso", "")
+			c.Abort()
+			return
+		}
+
+		// Los tokens restringidos (contraseña expirada) solo permiten cambiarla o cerrar sesión
+		if claims.Restriction == auth.RestrictionPasswordChange && !isPasswordChangeRoute(c.FullPath()) {
+			logger.Error("🚨 AUTH DEBUG: Token restringido usado en %s", c.FullPath())
+			response.Error(c, http.StatusForbidden, "Debe cambiar su contraseña antes de continuar", "PASSWORD_CHANGE_REQUIRED")
+			c.Abort()
+			return
+		}
+		if claims.Restriction == auth.RestrictionTwoFactorSetup && !isTwoFactorSetupRoute(c.FullPath()) {
+			logger.Error("🚨 AUTH DEBUG: Token restringido a enrolamiento 2FA usado en %s", c.FullPath())
+			response.Error(c, http.StatusForbidden, "Debe configurar la autenticación de dos factores antes de continuar", "TWO_FACTOR_SETUP_REQUIRED")
+			c.Abort()
+			return
+		}
+
+		// Suplantación: la sesión debe corresponder al mismo administrador y solo permite consultar
+		if claims.IsImpersonation() {
+			if sessionData.ImpersonatorID == "" || sessionData.ImpersonatorID != claims.Actor.Subject {
+				logger.Error("🚨 AUTH DEBUG: Token de suplantación sin sesión de suplantación válida")
+				response.Error(c, http.StatusUnauthorized, "Datos de sesión inconsistentes", "")
+				c.Abort()
+				return
+			}
+			if !isImpersonationAllowed(c.Request.Method, c.FullPath()) {
+				response.Error(c, http.StatusForbidden, "Acción no permitida durante la suplantación", "IMPERSONATION_READ_ONLY")
+				c.Abort()
+				impersonationService.LogRequest(c.Request.Context(), claims, c.Request.Method, c.Request.URL.Path,
+					http.StatusForbidden, 0, c.ClientIP(), c.GetHeader("User-Agent"))
+				return
+			}
+		}
+
 		// Obtener perfil actualizado del usuario
 		logger.Info("🔍 AUTH DEBUG: Obteniendo perfil de usuario...")
 		userProfile, err := authService.GetUserProfile(c.Request.Context(), claims.UserID)
@@ -121,9 +189,51 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 
 		logger.Info("✅ AUTH DEBUG: Perfil obtenido - Email: %s, Role: %s", userProfile.Email, userProfile.Role)
 
-		// Actualizar última actividad en la sesión
-		sessionData.LastActivity = time.Now()
-		sessionManager.SaveSession(c.Request.Context(), claims.SessionID, sessionData, 7*24*time.Hour)
+		// Cuentas temporales vencidas antes de que la tarea nocturna las desactive
+		if userProfile.IsAccountExpired() {
+			logger.Error("🚨 AUTH DEBUG: Cuenta temporal de %s expirada", userProfile.Email)
+			sessionService.EndSession(c.Request.Context(), claims.UserID, claims.SessionID)
+			response.Error(c, http.StatusUnauthorized, services.ErrAccountExpired.Error(), "ACCOUNT_EXPIRED")
+			c.Abort()
+			return
+		}
+
+		// Rangos IP del rol y de la unidad; los clientes OAuth consultan desde sus propios servidores
+		if sessionData.ClientID == "" {
+			err := networkPolicyService.Authorize(c.Request.Context(), services.NetworkSubjectFromProfile(userProfile),
+				c.ClientIP(), c.GetHeader("User-Agent"), c.Request.Method+" "+c.FullPath())
+			if err != nil {
+				if errors.Is(err, services.ErrIPNotAllowed) {
+					logger.Error("🚨 AUTH DEBUG: IP %s fuera de los rangos permitidos para %s", c.ClientIP(), userProfile.Email)
+					response.Error(c, http.StatusForbidden, err.Error(), "IP_NOT_ALLOWED")
+				} else {
+					logger.Error("🚨 AUTH DEBUG: Error al verificar la política de red: %v", err)
+					response.Error(c, http.StatusInternalServerError, "Error interno del servidor", "")
+				}
+				c.Abort()
+				return
+			}
+		}
+
+		// Actualizar última actividad en la sesión; la inactividad máxima la define la política por rol.
+		// Las sesiones de clientes OAuth y las restringidas conservan la vigencia del access token.
+		sessionTTL := sessionService.SessionTTL(c.Request.Context(), sessionData)
+		if sessionData.ClientID != "" || claims.Restriction != "" {
+			sessionTTL = appCtx.Config.JWTExpiresIn
+		}
+		if claims.IsImpersonation() {
+			// La suplantación no se extiende más allá de su token
+			sessionTTL = time.Until(claims.ExpiresAt.Time)
+			userProfile.Impersonation = &models.ImpersonationInfo{
+				ImpersonatorID:    sessionData.ImpersonatorID,
+				ImpersonatorEmail: sessionData.ImpersonatorEmail,
+				Reason:            sessionData.ImpersonationReason,
+				ExpiresAt:         claims.ExpiresAt.Time,
+			}
+		}
+		if err := sessionService.Touch(c.Request.Context(), claims.SessionID, sessionData, sessionTTL); err != nil {
+			logger.Warn("Error al registrar actividad de la sesión %s: %v", claims.SessionID, err)
+		}
 
 		// Agregar datos al contexto
 		c.Set("userID", claims.UserID)
@@ -132,10 +242,156 @@ func AuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 		c.Set("claims", claims)
 
 		logger.Info("✅ AUTH DEBUG: Autenticación completada exitosamente para %s", userProfile.Email)
+
+		if !claims.IsImpersonation() {
+			c.Next()
+			return
+		}
+
+		// Cada petición bajo suplantación queda auditada con ambas identidades
+		c.Set("impersonatorID", claims.Actor.Subject)
+		start := time.Now()
 		c.Next()
+		impersonationService.LogRequest(c.Request.Context(), claims, c.Request.Method, c.Request.URL.Path,
+			c.Writer.Status(), time.Since(start), c.ClientIP(), c.GetHeader("User-Agent"))
 	}
 }
 
+// isImpersonationAllowed durante la suplantación solo se consulta; cerrar sesión termina la suplantación
+func isImpersonationAllowed(method, path string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	return method == http.MethodPost && strings.HasSuffix(path, "/auth/logout")
+}
+
+// redactAuthorization oculta el secreto de una API key con esquema mal escrito
+// (ej. "apikey gamc_ab12cd34_..."): solo se conservan el esquema y el prefijo
+func redactAuthorization(header string) string {
+	scheme, credential, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "ApiKey") {
+		return header
+	}
+	parts := strings.SplitN(strings.TrimSpace(credential), "_", 3)
+	if len(parts) < 3 {
+		return scheme + " ***"
+	}
+	return scheme + " " + parts[0] + "_" + parts[1] + "_***"
+}
+
+// authenticateAPIKey autentica una cuenta de servicio con API key, verifica el scope
+// requerido por la ruta y los rangos IP del rol y la unidad de la cuenta, y audita la
+// petición con el ID de la key
+func authenticateAPIKey(c *gin.Context, apiKeyService *services.APIKeyService, networkPolicyService *services.NetworkPolicyService, rawKey string) {
+	key, account, err := apiKeyService.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+	if err != nil {
+		logger.Warn("🔑 API key rechazada desde %s: %v", c.ClientIP(), err)
+		response.Error(c, http.StatusUnauthorized, "API key inválida o expirada", "")
+		c.Abort()
+		return
+	}
+
+	scope, allowed := apiKeyService.RequiredScope(c.Request.Method, c.FullPath())
+	if !allowed || !key.HasScope(scope) {
+		logger.Warn("🔑 API key %s sin permiso para %s %s", key.Prefix, c.Request.Method, c.FullPath())
+		apiKeyService.LogUse(c.Request.Context(), key, &services.LogRequest{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.GetHeader("User-Agent"),
+			NewValues: map[string]interface{}{
+				"method":        c.Request.Method,
+				"path":          c.Request.URL.Path,
+				"requiredScope": scope,
+			},
+			Result:   models.AuditResultFailure,
+			ErrorMsg: "scope insuficiente",
+		})
+		response.Error(c, http.StatusForbidden, "La API key no tiene permiso para este recurso", "INSUFFICIENT_SCOPE")
+		c.Abort()
+		return
+	}
+
+	// Una key filtrada no debe servir desde fuera de los rangos de su rol o unidad
+	userProfile := account.ToProfile()
+	err = networkPolicyService.Authorize(c.Request.Context(), services.NetworkSubjectFromProfile(userProfile),
+		c.ClientIP(), c.GetHeader("User-Agent"), c.Request.Method+" "+c.FullPath())
+	if err != nil {
+		if errors.Is(err, services.ErrIPNotAllowed) {
+			logger.Warn("🔑 API key %s usada desde IP %s fuera de los rangos permitidos", key.Prefix, c.ClientIP())
+			apiKeyService.LogUse(c.Request.Context(), key, &services.LogRequest{
+				IPAddress: c.ClientIP(),
+				UserAgent: c.GetHeader("User-Agent"),
+				NewValues: map[string]interface{}{
+					"method": c.Request.Method,
+					"path":   c.Request.URL.Path,
+				},
+				Result:   models.AuditResultFailure,
+				ErrorMsg: "IP no permitida",
+			})
+			response.Error(c, http.StatusForbidden, err.Error(), "IP_NOT_ALLOWED")
+		} else {
+			logger.Error("❌ Error al verificar la política de red para la API key %s: %v", key.Prefix, err)
+			response.Error(c, http.StatusInternalServerError, "Error interno del servidor", "")
+		}
+		c.Abort()
+		return
+	}
+
+	orgUnitID := 0
+	if account.OrganizationalUnitID != nil {
+		orgUnitID = *account.OrganizationalUnitID
+	}
+	sessionID := "apikey:" + key.ID.String()
+
+	// Mismas claves de contexto que la autenticación JWT
+	c.Set("userID", account.ID.String())
+	c.Set("sessionID", sessionID)
+	c.Set("user", userProfile)
+	c.Set("claims", &auth.JWTClaims{
+		UserID:               account.ID.String(),
+		Email:                account.Email,
+		Role:                 account.Role,
+		OrganizationalUnitID: orgUnitID,
+		SessionID:            sessionID,
+	})
+	c.Set("apiKeyID", key.ID.String())
+
+	start := time.Now()
+	c.Next()
+
+	result := models.AuditResultSuccess
+	if c.Writer.Status() >= http.StatusBadRequest {
+		result = models.AuditResultFailure
+	}
+	apiKeyService.LogUse(c.Request.Context(), key, &services.LogRequest{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+		NewValues: map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": c.Writer.Status(),
+		},
+		Duration: int(time.Since(start).Milliseconds()),
+		Result:   result,
+	})
+}
+
+// isPasswordChangeRoute indica si la ruta está permitida para un token restringido
+func isPasswordChangeRoute(path string) bool {
+	return strings.HasSuffix(path, "/auth/change-password") || strings.HasSuffix(path, "/auth/logout")
+}
+
+// isOAuthClientRoute indica si la ruta acepta tokens emitidos a clientes OAuth
+func isOAuthClientRoute(path string) bool {
+	return strings.HasSuffix(path, "/oauth/userinfo")
+}
+
+// isTwoFactorSetupRoute indica si la ruta está permitida para un token restringido al enrolamiento 2FA
+func isTwoFactorSetupRoute(path string) bool {
+	return strings.HasSuffix(path, "/auth/2fa/setup") ||
+		strings.HasSuffix(path, "/auth/2fa/confirm") ||
+		strings.HasSuffix(path, "/auth/logout")
+}
+
 // Helper function para obtener el mínimo entre dos enteros
 func min(a, b int) int {
 	if a < b {
@@ -163,9 +419,9 @@ func OptionalAuthMiddleware(appCtx *config.AppContext) gin.HandlerFunc {
 			return
 		}
 
-		// Intentar verificar token (sin fallar si es inválido)
+		// Intentar verificar token (sin fallar si es inválido); los tokens restringidos se ignoran
 		claims, err := jwtService.VerifyAccessToken(token)
-		if err != nil {
+		if err != nil || claims.Restriction != "" {
 			c.Next()
 			return
 		}
@@ -275,6 +531,46 @@ func RequireOutputRole() gin.HandlerFunc {
 	return RequireRole("admin", "input", "output")
 }
 
+// RequirePermission middleware para verificar un permiso granular. Basta con tenerlo
+// en alguna unidad; la verificación por unidad la hace el servicio sobre el recurso.
+// Deja los permisos resueltos en el contexto ("permissions").
+func RequirePermission(appCtx *config.AppContext, permission string) gin.HandlerFunc {
+	permissionService := services.NewPermissionService(appCtx.DB)
+
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			response.Error(c, http.StatusUnauthorized, "Autenticación requerida", "")
+			c.Abort()
+			return
+		}
+
+		userProfile, ok := user.(*models.UserProfile)
+		if !ok {
+			response.Error(c, http.StatusInternalServerError, "Error interno del servidor", "")
+			c.Abort()
+			return
+		}
+
+		effective, err := permissionService.Effective(c.Request.Context(), userProfile.ID)
+		if err != nil {
+			logger.Error("❌ Error al resolver permisos de %s: %v", userProfile.Email, err)
+			response.Error(c, http.StatusInternalServerError, "Error al verificar permisos", "")
+			c.Abort()
+			return
+		}
+
+		if !effective.HasAnywhere(permission) {
+			response.Error(c, http.StatusForbidden, "Permisos insuficientes", "Se requiere el permiso "+permission)
+			c.Abort()
+			return
+		}
+
+		c.Set("permissions", effective)
+		c.Next()
+	}
+}
+
 // RequireOwnership middleware para verificar propiedad del recurso
 func RequireOwnership(getUserIDFromResource func(*gin.Context) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -33,6 +33,7 @@ func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 				"X-CSRF-Token",
 				"X-Forwarded-For",
 				"X-Real-IP",
+				"X-Device-ID",
 			},
 			ExposeHeaders: []string{
 				"X-Total-Count",
@@ -87,6 +88,7 @@ func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 			"X-CSRF-Token",
 			"X-Forwarded-For",
 			"X-Real-IP",
+			"X-Device-ID",
 		},
 		ExposeHeaders: []string{
 			"X-Total-Count",
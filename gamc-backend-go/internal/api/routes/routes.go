@@ -7,7 +7,9 @@ import (
 	"gamc-backend-go/internal/api/handlers"
 	"gamc-backend-go/internal/api/middleware"
 	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
 	"gamc-backend-go/internal/services"
+	"gamc-backend-go/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,6 +23,13 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 	router.RedirectTrailingSlash = false
 	router.RedirectFixedPath = false
 
+	// c.ClientIP() solo acepta X-Forwarded-For de los proxies configurados; sin ellos
+	// usa la IP de la conexión, de modo que el cliente no puede elegir su IP
+	if err := router.SetTrustedProxies(appCtx.Config.GetTrustedProxies()); err != nil {
+		logger.Warn("⚠️ TRUSTED_PROXIES inválido, no se confiará en ningún proxy: %v", err)
+		router.SetTrustedProxies(nil)
+	}
+
 	// Middlewares globales
 	router.Use(gin.Recovery())                           // Recuperación de panics
 	router.Use(middleware.SecurityHeaders())             // Headers de seguridad
@@ -36,6 +45,21 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 	// Crear handlers
 	healthHandler := handlers.NewHealthHandler(appCtx)
 	authHandler := handlers.NewAuthHandler(appCtx)
+	twoFactorHandler := handlers.NewTwoFactorHandler(appCtx)
+	securityHandler := handlers.NewSecurityHandler(appCtx)
+	sessionHandler := handlers.NewSessionHandler(appCtx)
+	wellKnownHandler := handlers.NewWellKnownHandler(appCtx)
+	oauthHandler := handlers.NewOAuthHandler(appCtx)
+	serviceAccountHandler := handlers.NewServiceAccountHandler(appCtx)
+	roleHandler := handlers.NewRoleHandler(appCtx)
+	impersonationHandler := handlers.NewImpersonationHandler(appCtx)
+	userAdminHandler := handlers.NewUserAdminHandler(appCtx)
+	emailHandler := handlers.NewEmailHandler(appCtx)
+	networkHandler := handlers.NewNetworkHandler(appCtx)
+	deviceHandler := handlers.NewDeviceHandler(appCtx)
+	passkeyHandler := handlers.NewPasskeyHandler(appCtx)
+	securityQuestionHandler := handlers.NewSecurityQuestionHandler(appCtx)
+	resetAuditHandler := handlers.NewResetAuditHandler(appCtx)
 
 	// ========================================
 	// RUTAS PÚBLICAS
@@ -47,6 +71,12 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 	// Health check
 	router.GET("/health", healthHandler.HealthCheck)
 
+	// Claves públicas para verificar access tokens (JWKS)
+	router.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
+
+	// Documento de descubrimiento OpenID Connect
+	router.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+
 	// ========================================
 	// RUTAS DE AUTENTICACIÓN (API v1)
 	// ========================================
@@ -67,15 +97,59 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				middleware.UserActivityLogger("LOGIN_ATTEMPT"),
 				authHandler.Login)
 
+			// Segundo paso del login: canjear desafío + código 2FA por tokens
+			auth.POST("/login/2fa",
+				authRateLimit,
+				middleware.NoCache(),
+				middleware.UserActivityLogger("LOGIN_2FA_ATTEMPT"),
+				authHandler.VerifyTwoFactorLogin)
+
+			// Login con passkey: sin contraseña o como segundo factor (con challengeToken)
+			auth.POST("/login/passkey/options",
+				authRateLimit,
+				middleware.NoCache(),
+				authHandler.BeginPasskeyLogin)
+
+			auth.POST("/login/passkey",
+				authRateLimit,
+				middleware.NoCache(),
+				middleware.UserActivityLogger("LOGIN_PASSKEY_ATTEMPT"),
+				authHandler.PasskeyLogin)
+
 			auth.POST("/register",
 				authRateLimit,
 				middleware.UserActivityLogger("REGISTER_ATTEMPT"),
 				authHandler.Register)
 
+			// Verificación del email de cuentas auto-registradas (enlace o código)
+			auth.POST("/verify-email",
+				authRateLimit,
+				middleware.NoCache(),
+				middleware.UserActivityLogger("EMAIL_VERIFY"),
+				authHandler.VerifyEmail)
+
+			auth.POST("/verify-email/resend",
+				authRateLimit,
+				middleware.UserActivityLogger("EMAIL_VERIFICATION_RESEND"),
+				authHandler.ResendVerification)
+
+			// Enlace "No fui yo" de la alerta de dispositivo nuevo
+			auth.POST("/devices/report",
+				authRateLimit,
+				middleware.UserActivityLogger("DEVICE_REPORT"),
+				deviceHandler.ReportDevice)
+
 			auth.POST("/refresh",
 				authRateLimit,
 				authHandler.RefreshToken)
 
+			// Introspección de tokens para clientes registrados (RFC 7662); la autenticación
+			// es del cliente, y los servicios la consultan en cada petición
+			auth.POST("/introspect",
+				middleware.UserRateLimitMiddleware(1000, time.Minute),
+				middleware.NoCache(),
+				oauthHandler.Introspect)
+
 			// ========================================
 			// RUTAS PÚBLICAS DE PREGUNTAS DE SEGURIDAD
 			// ========================================
@@ -84,6 +158,10 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 			auth.GET("/security-questions",
 				authHandler.GetSecurityQuestions)
 
+			// Reglas de contraseña vigentes (público, para validar en el frontend)
+			auth.GET("/password-policy",
+				securityHandler.GetPasswordPolicy)
+
 			// ========================================
 			// RUTAS PÚBLICAS DE RESET DE CONTRASEÑA
 			// ========================================
@@ -139,6 +217,10 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				protected.GET("/verify",
 					authHandler.VerifyToken)
 
+				// Permisos efectivos del usuario (globales y por unidad)
+				protected.GET("/permissions",
+					roleHandler.GetMyPermissions)
+
 				// ========================================
 				// RUTAS PROTEGIDAS DE PREGUNTAS DE SEGURIDAD
 				// ========================================
@@ -177,6 +259,92 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				protected.GET("/reset-history",
 					middleware.UserActivityLogger("GET_RESET_HISTORY"),
 					authHandler.GetPasswordResetHistory)
+
+				// ========================================
+				// RUTAS PROTEGIDAS DE AUTENTICACIÓN DE DOS FACTORES
+				// ========================================
+
+				protected.GET("/2fa/status",
+					twoFactorHandler.GetStatus)
+
+				protected.POST("/2fa/setup",
+					authRateLimit,
+					middleware.NoCache(),
+					middleware.UserActivityLogger("2FA_SETUP"),
+					twoFactorHandler.BeginSetup)
+
+				protected.POST("/2fa/confirm",
+					authRateLimit,
+					middleware.NoCache(),
+					middleware.UserActivityLogger("2FA_CONFIRM"),
+					twoFactorHandler.ConfirmSetup)
+
+				protected.POST("/2fa/disable",
+					authRateLimit,
+					middleware.NoCache(),
+					middleware.UserActivityLogger("2FA_DISABLE"),
+					twoFactorHandler.Disable)
+
+				protected.POST("/2fa/recovery-codes",
+					authRateLimit,
+					middleware.NoCache(),
+					middleware.UserActivityLogger("2FA_REGENERATE_RECOVERY_CODES"),
+					twoFactorHandler.RegenerateRecoveryCodes)
+
+				// ========================================
+				// RUTAS PROTEGIDAS DE SESIONES ACTIVAS
+				// ========================================
+
+				protected.GET("/sessions",
+					middleware.NoCache(),
+					sessionHandler.ListSessions)
+
+				protected.POST("/sessions/revoke-others",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("REVOKE_OTHER_SESSIONS"),
+					sessionHandler.RevokeOtherSessions)
+
+				protected.DELETE("/sessions/:id",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("REVOKE_SESSION"),
+					sessionHandler.RevokeSession)
+
+				// Dispositivos conocidos
+				protected.GET("/devices",
+					middleware.NoCache(),
+					deviceHandler.ListDevices)
+
+				protected.DELETE("/devices/:id",
+					middleware.UserActivityLogger("REMOVE_DEVICE"),
+					deviceHandler.RemoveDevice)
+
+				// ========================================
+				// RUTAS PROTEGIDAS DE PASSKEYS (WEBAUTHN)
+				// ========================================
+
+				protected.GET("/passkeys",
+					middleware.NoCache(),
+					passkeyHandler.ListPasskeys)
+
+				protected.POST("/passkeys/options",
+					authRateLimit,
+					middleware.NoCache(),
+					authHandler.BeginPasskeyRegistration)
+
+				protected.POST("/passkeys",
+					authRateLimit,
+					middleware.NoCache(),
+					middleware.UserActivityLogger("PASSKEY_REGISTER"),
+					authHandler.FinishPasskeyRegistration)
+
+				protected.PUT("/passkeys/:id",
+					middleware.UserActivityLogger("PASSKEY_RENAME"),
+					passkeyHandler.RenamePasskey)
+
+				protected.DELETE("/passkeys/:id",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("PASSKEY_REMOVE"),
+					passkeyHandler.RemovePasskey)
 			}
 
 			// ========================================
@@ -194,21 +362,55 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 					middleware.UserActivityLogger("CLEANUP_RESET_TOKENS"),
 					authHandler.CleanupExpiredTokens)
 
-				// Gestión de preguntas de seguridad (futuro)
-				adminAuth.GET("/security-questions/stats", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Security questions stats - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				// Adopción y tasa de fallos de las preguntas de seguridad
+				adminAuth.GET("/security-questions/stats",
+					securityQuestionHandler.GetStats)
 
-				// Auditoría de resets de contraseña (futuro)
-				adminAuth.GET("/reset-audit", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Password reset audit - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				// Auditoría de resets de contraseña: tablero, listado y exportación CSV
+				adminAuth.GET("/reset-audit", middleware.NoCache(), resetAuditHandler.GetReport)
+				adminAuth.GET("/reset-audit/resets", middleware.NoCache(), resetAuditHandler.ListResets)
+				adminAuth.GET("/reset-audit/export",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("EXPORT_RESET_AUDIT"),
+					resetAuditHandler.ExportCSV)
+			}
+		}
+
+		// ========================================
+		// PROVEEDOR OAUTH2 / OPENID CONNECT
+		// ========================================
+
+		oauth := apiV1.Group("/oauth")
+		{
+			// Canje de código por tokens (autenticación del cliente, no del usuario)
+			oauth.POST("/token",
+				middleware.UserRateLimitMiddleware(30, 15*time.Minute),
+				middleware.NoCache(),
+				oauthHandler.Token)
+
+			oauthProtected := oauth.Group("/")
+			oauthProtected.Use(middleware.AuthMiddleware(appCtx))
+			{
+				oauthProtected.GET("/userinfo", oauthHandler.UserInfo)
+				oauthProtected.POST("/userinfo", oauthHandler.UserInfo)
+
+				// Pantalla de consentimiento del frontend
+				oauthProtected.GET("/authorize",
+					middleware.NoCache(),
+					oauthHandler.GetAuthorization)
+
+				oauthProtected.POST("/authorize",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("OAUTH_AUTHORIZE"),
+					oauthHandler.Authorize)
+
+				// Aplicaciones autorizadas por el usuario
+				oauthProtected.GET("/consents",
+					oauthHandler.ListConsents)
+
+				oauthProtected.DELETE("/consents/:clientId",
+					middleware.UserActivityLogger("OAUTH_REVOKE_CONSENT"),
+					oauthHandler.RevokeConsent)
 			}
 		}
 
@@ -217,7 +419,7 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 		// ========================================
 
 		// Crear handler de mensajes
-		messageService := services.NewMessageService(appCtx.DB)
+		messageService := services.NewMessageService(appCtx)
 		messageHandler := handlers.NewMessageHandler(messageService)
 
 		messages := apiV1.Group("/messages")
@@ -229,16 +431,25 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 
 			// Rutas básicas de mensajes
 			// IMPORTANTE: Usar rutas sin trailing slash
-			messages.GET("", messageHandler.GetMessages)
-			messages.POST("", messageHandler.CreateMessage)
+			// Permisos granulares: el alcance por unidad se verifica en el servicio
+			canRead := middleware.RequirePermission(appCtx, models.PermissionMessagesRead)
+			canSend := middleware.RequirePermission(appCtx, models.PermissionMessagesSend)
+			canManage := middleware.RequirePermission(appCtx, models.PermissionMessagesManage)
 
-			messages.GET("/stats", messageHandler.GetMessageStats)
-			messages.GET("/stats-simple", messageHandler.GetSimpleMessageStats)
+			messages.GET("", canRead, messageHandler.GetMessages)
+			messages.POST("", canSend, messageHandler.CreateMessage)
 
-			messages.GET("/:id", messageHandler.GetMessageByID)
-			messages.PUT("/:id/read", messageHandler.MarkAsRead)
-			messages.PUT("/:id/status", messageHandler.UpdateMessageStatus)
-			messages.DELETE("/:id", messageHandler.DeleteMessage)
+			messages.GET("/stats", canRead, messageHandler.GetMessageStats)
+			messages.GET("/stats-simple", canRead, messageHandler.GetSimpleMessageStats)
+
+			messages.GET("/:id", canRead, messageHandler.GetMessageByID)
+			messages.PUT("/:id/read", canRead, messageHandler.MarkAsRead)
+			messages.PUT("/:id/status", canManage, messageHandler.UpdateMessageStatus)
+			messages.DELETE("/:id", canManage, messageHandler.DeleteMessage)
+
+			// Hilos de conversación: respuestas enlazadas en message_responses
+			messages.GET("/:id/replies", canRead, messageHandler.GetReplies)
+			messages.POST("/:id/replies", canSend, messageHandler.ReplyToMessage)
 
 			// Estadísticas (solo admin - se valida internamente)
 		}
@@ -281,6 +492,15 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				})
 			})
 
+			admin.POST("/users",
+				middleware.NoCache(),
+				middleware.UserActivityLogger("USER_CREATE"),
+				userAdminHandler.CreateUser)
+
+			admin.PUT("/users/:id",
+				middleware.UserActivityLogger("USER_UPDATE"),
+				userAdminHandler.UpdateUser)
+
 			// Estadísticas del sistema
 			admin.GET("/stats", func(c *gin.Context) {
 				c.JSON(200, gin.H{
@@ -297,33 +517,162 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				})
 			})
 
+			// ========================================
+			// CLIENTES OAUTH / OIDC
+			// ========================================
+
+			oauthAdmin := admin.Group("/oauth/clients")
+			{
+				oauthAdmin.GET("", oauthHandler.ListClients)
+
+				oauthAdmin.POST("",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("OAUTH_CLIENT_CREATE"),
+					oauthHandler.CreateClient)
+
+				oauthAdmin.PUT("/:clientId",
+					middleware.UserActivityLogger("OAUTH_CLIENT_UPDATE"),
+					oauthHandler.UpdateClient)
+
+				oauthAdmin.POST("/:clientId/secret",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("OAUTH_CLIENT_ROTATE_SECRET"),
+					oauthHandler.RotateClientSecret)
+
+				oauthAdmin.DELETE("/:clientId",
+					middleware.UserActivityLogger("OAUTH_CLIENT_DELETE"),
+					oauthHandler.DeleteClient)
+			}
+
+			// ========================================
+			// ROLES Y PERMISOS
+			// ========================================
+
+			admin.GET("/permissions", roleHandler.ListPermissions)
+
+			roles := admin.Group("/roles")
+			roles.Use(middleware.RequirePermission(appCtx, models.PermissionRolesManage))
+			{
+				roles.GET("", roleHandler.ListRoles)
+
+				roles.POST("",
+					middleware.UserActivityLogger("ROLE_CREATE"),
+					roleHandler.CreateRole)
+
+				roles.PUT("/:code",
+					middleware.UserActivityLogger("ROLE_UPDATE"),
+					roleHandler.UpdateRole)
+
+				roles.DELETE("/:code",
+					middleware.UserActivityLogger("ROLE_DELETE"),
+					roleHandler.DeleteRole)
+			}
+
+			userRoles := admin.Group("/users/:id/roles")
+			userRoles.Use(middleware.RequirePermission(appCtx, models.PermissionRolesManage))
+			{
+				userRoles.GET("", roleHandler.ListUserRoles)
+
+				userRoles.POST("",
+					middleware.UserActivityLogger("ROLE_ASSIGN"),
+					roleHandler.AssignRole)
+
+				userRoles.DELETE("/:assignmentId",
+					middleware.UserActivityLogger("ROLE_UNASSIGN"),
+					roleHandler.RemoveRole)
+			}
+
+			// ========================================
+			// SUPLANTACIÓN (SOPORTE)
+			// ========================================
+
+			admin.POST("/users/:id/impersonate",
+				middleware.NoCache(),
+				middleware.UserActivityLogger("IMPERSONATION_START"),
+				impersonationHandler.Impersonate)
+
+			// ========================================
+			// PASSKEYS DE USUARIOS (dispositivo perdido o robado)
+			// ========================================
+
+			userPasskeys := admin.Group("/users/:id/passkeys")
+			{
+				userPasskeys.GET("", passkeyHandler.AdminListPasskeys)
+
+				userPasskeys.DELETE("",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("PASSKEY_REVOKE_ALL"),
+					passkeyHandler.AdminRevokeAllPasskeys)
+
+				userPasskeys.DELETE("/:credentialId",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("PASSKEY_REVOKE"),
+					passkeyHandler.AdminRevokePasskey)
+			}
+
+			// ========================================
+			// COLA DE CORREO
+			// ========================================
+
+			admin.GET("/emails", emailHandler.ListEmails)
+
+			admin.POST("/emails/:id/retry",
+				middleware.UserActivityLogger("EMAIL_RETRY"),
+				emailHandler.RetryEmail)
+
+			// ========================================
+			// CUENTAS DE SERVICIO Y API KEYS
+			// ========================================
+
+			serviceAccounts := admin.Group("/service-accounts")
+			{
+				serviceAccounts.GET("", serviceAccountHandler.ListServiceAccounts)
+
+				serviceAccounts.POST("",
+					middleware.UserActivityLogger("SERVICE_ACCOUNT_CREATE"),
+					serviceAccountHandler.CreateServiceAccount)
+
+				serviceAccounts.DELETE("/:id",
+					middleware.UserActivityLogger("SERVICE_ACCOUNT_DEACTIVATE"),
+					serviceAccountHandler.DeactivateServiceAccount)
+
+				serviceAccounts.GET("/:id/keys", serviceAccountHandler.ListKeys)
+
+				serviceAccounts.POST("/:id/keys",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("API_KEY_CREATE"),
+					serviceAccountHandler.CreateKey)
+
+				serviceAccounts.DELETE("/:id/keys/:keyId",
+					middleware.UserActivityLogger("API_KEY_REVOKE"),
+					serviceAccountHandler.RevokeKey)
+			}
+
 			// ========================================
 			// ADMINISTRACIÓN DE SEGURIDAD
 			// ========================================
 
 			security := admin.Group("/security")
 			{
-				// Gestión de preguntas de seguridad del sistema
-				security.GET("/questions", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Admin security questions management - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				// Catálogo de preguntas de seguridad del sistema
+				security.GET("/questions",
+					securityQuestionHandler.ListQuestions)
 
-				security.POST("/questions", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Create security question - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				security.POST("/questions",
+					middleware.UserActivityLogger("CREATE_SECURITY_QUESTION"),
+					securityQuestionHandler.CreateQuestion)
 
-				security.PUT("/questions/:id", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Update security question - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				security.PUT("/questions/order",
+					middleware.UserActivityLogger("REORDER_SECURITY_QUESTIONS"),
+					securityQuestionHandler.ReorderQuestions)
+
+				security.PUT("/questions/:id",
+					middleware.UserActivityLogger("UPDATE_SECURITY_QUESTION"),
+					securityQuestionHandler.UpdateQuestion)
+
+				security.DELETE("/questions/:id",
+					middleware.UserActivityLogger("DEACTIVATE_SECURITY_QUESTION"),
+					securityQuestionHandler.DeactivateQuestion)
 
 				// Reportes de seguridad
 				security.GET("/reports", func(c *gin.Context) {
@@ -333,13 +682,63 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 					})
 				})
 
+				// Bloqueos por intentos fallidos de login
+				security.GET("/lockouts",
+					securityHandler.GetLockouts)
+
+				security.POST("/lockouts/unlock",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("UNLOCK_LOGIN"),
+					securityHandler.Unlock)
+
+				// Requerimiento de 2FA por rol
+				security.GET("/two-factor/roles",
+					twoFactorHandler.GetRoleRequirements)
+
+				security.PUT("/two-factor/roles",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("UPDATE_2FA_ROLE_REQUIREMENT"),
+					twoFactorHandler.SetRoleRequirement)
+
 				// Configuración de políticas de seguridad
-				security.GET("/policies", func(c *gin.Context) {
-					c.JSON(200, gin.H{
-						"message": "Security policies management - futuro",
-						"status":  "coming_soon",
-					})
-				})
+				security.GET("/policies",
+					securityHandler.GetPolicy)
+
+				security.PUT("/policies",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("UPDATE_SECURITY_POLICY"),
+					securityHandler.UpdatePolicy)
+
+				// Rangos IP permitidos por rol y por unidad
+				security.GET("/network-rules",
+					networkHandler.ListRules)
+
+				security.POST("/network-rules",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("CREATE_NETWORK_RULE"),
+					networkHandler.CreateRule)
+
+				security.POST("/network-rules/check",
+					networkHandler.CheckAccess)
+
+				security.DELETE("/network-rules/:id",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("DELETE_NETWORK_RULE"),
+					networkHandler.DeleteRule)
+
+				// Excepciones de emergencia a los rangos IP
+				security.GET("/ip-bypasses",
+					networkHandler.ListBypasses)
+
+				security.POST("/ip-bypasses",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("CREATE_IP_BYPASS"),
+					networkHandler.CreateBypass)
+
+				security.DELETE("/ip-bypasses/:id",
+					middleware.NoCache(),
+					middleware.UserActivityLogger("REVOKE_IP_BYPASS"),
+					networkHandler.RevokeBypass)
 			}
 		}
 
@@ -382,9 +781,17 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				"auth": gin.H{
 					"public": []string{
 						"POST /api/v1/auth/login",
+						"POST /api/v1/auth/login/2fa",
+						"POST /api/v1/auth/login/passkey/options",
+						"POST /api/v1/auth/login/passkey",
 						"POST /api/v1/auth/register",
+						"POST /api/v1/auth/verify-email",
+						"POST /api/v1/auth/verify-email/resend",
+						"POST /api/v1/auth/devices/report",
 						"POST /api/v1/auth/refresh",
+						"POST /api/v1/auth/introspect",
 						"GET  /api/v1/auth/security-questions",
+						"GET  /api/v1/auth/password-policy",
 						"POST /api/v1/auth/forgot-password",
 						"GET  /api/v1/auth/reset-status/:token",
 						"POST /api/v1/auth/verify-security-question",
@@ -395,16 +802,34 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 						"GET  /api/v1/auth/profile",
 						"PUT  /api/v1/auth/change-password",
 						"GET  /api/v1/auth/verify",
+						"GET  /api/v1/auth/permissions",
 						"GET  /api/v1/auth/security-status",
 						"POST /api/v1/auth/security-questions",
 						"PUT  /api/v1/auth/security-questions/:questionId",
 						"DELETE /api/v1/auth/security-questions/:questionId",
 						"GET  /api/v1/auth/reset-history",
+						"GET  /api/v1/auth/2fa/status",
+						"POST /api/v1/auth/2fa/setup",
+						"POST /api/v1/auth/2fa/confirm",
+						"POST /api/v1/auth/2fa/disable",
+						"POST /api/v1/auth/2fa/recovery-codes",
+						"GET  /api/v1/auth/sessions",
+						"POST /api/v1/auth/sessions/revoke-others",
+						"DELETE /api/v1/auth/sessions/:id",
+						"GET  /api/v1/auth/devices",
+						"DELETE /api/v1/auth/devices/:id",
+						"GET  /api/v1/auth/passkeys",
+						"POST /api/v1/auth/passkeys/options",
+						"POST /api/v1/auth/passkeys",
+						"PUT  /api/v1/auth/passkeys/:id",
+						"DELETE /api/v1/auth/passkeys/:id",
 					},
 					"admin": []string{
 						"POST /api/v1/auth/admin/cleanup-tokens",
 						"GET  /api/v1/auth/admin/security-questions/stats",
 						"GET  /api/v1/auth/admin/reset-audit",
+						"GET  /api/v1/auth/admin/reset-audit/resets",
+						"GET  /api/v1/auth/admin/reset-audit/export",
 					},
 				},
 				"messages":      "/api/v1/messages/*",
@@ -412,6 +837,9 @@ func SetupRoutes(appCtx *config.AppContext) *gin.Engine {
 				"admin":         "/api/v1/admin/*",
 				"notifications": "/api/v1/notifications/*",
 				"health":        "/health",
+				"jwks":          "/.well-known/jwks.json",
+				"oidc":          "/.well-known/openid-configuration",
+				"oauth":         "/api/v1/oauth/*",
 			},
 		})
 	})
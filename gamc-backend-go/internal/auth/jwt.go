@@ -19,9 +19,36 @@ type JWTClaims struct {
 	OrganizationalUnitID int    `json:"organizationalUnitId"`
 	SessionID            string `json:"sessionId"`
 	JTI                  string `json:"jti,omitempty"` // JWT ID para blacklist
+	Restriction          string `json:"restriction,omitempty"`
+	Actor                *Actor `json:"act,omitempty"`       // Suplantación: quién actúa en nombre del usuario
+	ClientID             string `json:"client_id,omitempty"` // Cliente OAuth al que se emitió (RFC 9068)
 	jwt.RegisteredClaims
 }
 
+// Actor identidad que actúa en nombre del sujeto del token (claim "act", RFC 8693 §4.1)
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// IsImpersonation indica si el token fue emitido para suplantar al usuario
+func (c *JWTClaims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// IsClientToken indica si el token fue emitido a un cliente OAuth externo
+func (c *JWTClaims) IsClientToken() bool {
+	return c.ClientID != ""
+}
+
+// Restricciones de un access token emitido antes de completar un requisito de la cuenta
+const (
+	// RestrictionPasswordChange limita el token a cambiar la contraseña o cerrar sesión
+	RestrictionPasswordChange = "password_change"
+	// RestrictionTwoFactorSetup limita el token a enrolar 2FA o cerrar sesión
+	RestrictionTwoFactorSetup = "two_factor_setup"
+)
+
 // RefreshTokenClaims representa los claims del refresh token
 type RefreshTokenClaims struct {
 	UserID       string `json:"userId"`
@@ -30,22 +57,61 @@ type RefreshTokenClaims struct {
 	jwt.RegisteredClaims
 }
 
-// JWTService maneja la generación y validación de tokens JWT
+// JWTService maneja la generación y validación de tokens JWT.
+// Los access tokens se firman con la clave activa del KeyStore (RS256/EdDSA o HS256 legado);
+// los refresh tokens solo los verifica este servicio y siguen firmándose con HMAC.
 type JWTService struct {
 	config *config.Config
+	keys   *KeyStore
+	keyErr error
 }
 
 // NewJWTService crea una nueva instancia del servicio JWT
 func NewJWTService(cfg *config.Config) *JWTService {
-	return &JWTService{config: cfg}
+	keys, err := InitializeKeys(cfg)
+	return &JWTService{config: cfg, keys: keys, keyErr: err}
 }
 
 // GenerateAccessToken genera un access token
 func (j *JWTService) GenerateAccessToken(userID, email, role string, orgUnitID int, sessionID string) (string, error) {
+	return j.generateAccessToken(userID, email, role, orgUnitID, sessionID, "")
+}
+
+// GenerateRestrictedAccessToken genera un access token que solo sirve para la acción indicada
+func (j *JWTService) GenerateRestrictedAccessToken(userID, email, role string, orgUnitID int, sessionID, restriction string) (string, error) {
+	return j.generateAccessToken(userID, email, role, orgUnitID, sessionID, restriction)
+}
+
+// GenerateImpersonationToken genera un access token para actuar como el usuario indicado.
+// Lleva el claim "act" con la identidad real y su propia vigencia; no tiene refresh token.
+func (j *JWTService) GenerateImpersonationToken(userID, email, role string, orgUnitID int, sessionID string, actor Actor, ttl time.Duration) (string, error) {
+	claims := j.newAccessClaims(userID, email, role, orgUnitID, sessionID, ttl)
+	claims.Actor = &actor
+	return j.signAccessToken(claims)
+}
+
+// GenerateClientAccessToken genera el access token de un cliente OAuth. Su audiencia es el
+// client_id y no la del sistema, para que ningún verificador propio lo acepte como token de usuario.
+func (j *JWTService) GenerateClientAccessToken(userID, email, role string, orgUnitID int, sessionID, clientID string) (string, error) {
+	claims := j.newAccessClaims(userID, email, role, orgUnitID, sessionID, j.config.JWTExpiresIn)
+	claims.ClientID = clientID
+	claims.Audience = []string{clientID}
+	return j.signAccessToken(claims)
+}
+
+// generateAccessToken construye y firma el access token
+func (j *JWTService) generateAccessToken(userID, email, role string, orgUnitID int, sessionID, restriction string) (string, error) {
+	claims := j.newAccessClaims(userID, email, role, orgUnitID, sessionID, j.config.JWTExpiresIn)
+	claims.Restriction = restriction
+	return j.signAccessToken(claims)
+}
+
+// newAccessClaims arma los claims comunes de un access token
+func (j *JWTService) newAccessClaims(userID, email, role string, orgUnitID int, sessionID string, ttl time.Duration) *JWTClaims {
 	now := time.Now()
 	jti := uuid.New().String()
 
-	claims := &JWTClaims{
+	return &JWTClaims{
 		UserID:               userID,
 		Email:                email,
 		Role:                 role,
@@ -56,15 +122,28 @@ func (j *JWTService) GenerateAccessToken(userID, email, role string, orgUnitID i
 			Issuer:    j.config.JWTIssuer,
 			Audience:  []string{j.config.JWTAudience},
 			Subject:   userID,
-			ExpiresAt: jwt.NewNumericDate(now.Add(j.config.JWTExpiresIn)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			NotBefore: jwt.NewNumericDate(now),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ID:        jti,
 		},
 	}
+}
+
+// signAccessToken firma los claims con la clave activa
+func (j *JWTService) signAccessToken(claims *JWTClaims) (string, error) {
+	if j.keys == nil {
+		return "", fmt.Errorf("signing keys not available: %v", j.keyErr)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.config.JWTSecret))
+	if j.keys.IsLegacy() {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.config.JWTSecret))
+	}
+
+	token := jwt.NewWithClaims(j.keys.signing, claims)
+	token.Header["kid"] = j.keys.signingKeyID
+	return token.SignedString(j.keys.signingKey)
 }
 
 // GenerateRefreshToken genera un refresh token
@@ -82,6 +161,7 @@ func (j *JWTService) GenerateRefreshToken(userID, sessionID string) (string, err
 			ExpiresAt: jwt.NewNumericDate(now.Add(j.config.JWTRefreshExpiresIn)),
 			NotBefore: jwt.NewNumericDate(now),
 			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(), // Cada rotación produce un token distinto
 		},
 	}
 
@@ -104,15 +184,35 @@ func (j *JWTService) GenerateTokens(userID, email, role string, orgUnitID int, s
 	return accessToken, refreshToken, nil
 }
 
-// VerifyAccessToken verifica y parsea un access token
+// VerifyAccessToken verifica y parsea un access token del sistema.
+// Rechaza los tokens emitidos a clientes OAuth: esos solo los acepta VerifyClientAccessToken.
 func (j *JWTService) VerifyAccessToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verificar método de signing
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.config.JWTSecret), nil
-	})
+	claims, err := j.ParseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IsClientToken() {
+		return nil, fmt.Errorf("invalid audience")
+	}
+	return claims, nil
+}
+
+// VerifyClientAccessToken verifica y parsea un access token emitido a un cliente OAuth
+func (j *JWTService) VerifyClientAccessToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.ParseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.IsClientToken() {
+		return nil, fmt.Errorf("invalid audience")
+	}
+	return claims, nil
+}
+
+// ParseAccessToken verifica firma, issuer y audience de cualquier access token emitido por
+// este servicio: la audiencia es la del sistema o, en tokens de clientes OAuth, su client_id
+func (j *JWTService) ParseAccessToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.accessTokenKey)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -124,9 +224,14 @@ func (j *JWTService) VerifyAccessToken(tokenString string) (*JWTClaims, error) {
 			return nil, fmt.Errorf("invalid issuer")
 		}
 
+		expectedAudience := j.config.JWTAudience
+		if claims.IsClientToken() {
+			expectedAudience = claims.ClientID
+		}
+
 		validAudience := false
 		for _, aud := range claims.Audience {
-			if aud == j.config.JWTAudience {
+			if aud == expectedAudience {
 				validAudience = true
 				break
 			}
@@ -177,6 +282,44 @@ func (j *JWTService) VerifyRefreshToken(tokenString string) (*RefreshTokenClaims
 	return nil, fmt.Errorf("invalid refresh token")
 }
 
+// accessTokenKey selecciona la clave de verificación según el algoritmo y el kid del token
+func (j *JWTService) accessTokenKey(token *jwt.Token) (interface{}, error) {
+	if j.keys == nil {
+		return nil, fmt.Errorf("signing keys not available: %v", j.keyErr)
+	}
+
+	// Tokens HS256: solo en modo legado o durante la migración
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if j.keys.IsLegacy() || j.config.JWTAcceptLegacyHS256 {
+			return []byte(j.config.JWTSecret), nil
+		}
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	if j.keys.IsLegacy() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys.VerificationKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+// JWKS retorna las claves públicas de verificación (vacío en modo HS256)
+func (j *JWTService) JWKS() (*JWKSet, error) {
+	if j.keys == nil {
+		return nil, fmt.Errorf("signing keys not available: %v", j.keyErr)
+	}
+	return j.keys.JWKS(), nil
+}
+
 // ExtractTokenFromHeader extrae el token del header Authorization
 func ExtractTokenFromHeader(authHeader string) string {
 	const bearerPrefix = "Bearer "
@@ -186,6 +329,15 @@ func ExtractTokenFromHeader(authHeader string) string {
 	return ""
 }
 
+// ExtractAPIKeyFromHeader extrae la API key del header Authorization (esquema ApiKey)
+func ExtractAPIKeyFromHeader(authHeader string) string {
+	const apiKeyPrefix = "ApiKey "
+	if len(authHeader) > len(apiKeyPrefix) && authHeader[:len(apiKeyPrefix)] == apiKeyPrefix {
+		return authHeader[len(apiKeyPrefix):]
+	}
+	return ""
+}
+
 // GetTokenExpiration obtiene la fecha de expiración de un token
 func (j *JWTService) GetTokenExpiration(tokenString string) (*time.Time, error) {
 	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &JWTClaims{})
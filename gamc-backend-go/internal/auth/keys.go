@@ -0,0 +1,366 @@
+// internal/auth/keys.go
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gamc-backend-go/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritmos de firma soportados para access tokens
+const (
+	AlgorithmHS256 = "HS256" // Legado: secreto compartido
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// VerificationKey clave pública aceptada para verificar tokens
+type VerificationKey struct {
+	ID        string
+	Algorithm string
+	Method    jwt.SigningMethod
+	Public    crypto.PublicKey
+}
+
+// KeyStore contiene la clave de firma activa y las claves de verificación vigentes.
+// Durante una rotación, las claves anteriores se mantienen solo para verificación.
+type KeyStore struct {
+	algorithm    string
+	signingKeyID string
+	signingKey   crypto.Signer
+	signing      jwt.SigningMethod
+	verification map[string]*VerificationKey
+}
+
+// JWK representa una clave pública en formato JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA
+	E         string `json:"e,omitempty"`   // RSA
+	Curve     string `json:"crv,omitempty"` // OKP
+	X         string `json:"x,omitempty"`   // OKP
+}
+
+// JWKSet conjunto de claves públicas expuesto en /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	defaultKeyStore *KeyStore
+	keyStoreErr     error
+	keyStoreOnce    sync.Once
+)
+
+// InitializeKeys carga las claves de firma una sola vez por proceso.
+// Debe llamarse al iniciar el servidor para detectar errores de configuración temprano.
+func InitializeKeys(cfg *config.Config) (*KeyStore, error) {
+	keyStoreOnce.Do(func() {
+		defaultKeyStore, keyStoreErr = LoadKeyStore(cfg)
+	})
+	return defaultKeyStore, keyStoreErr
+}
+
+// LoadKeyStore carga la clave privada (archivo o PEM en variable de entorno)
+// y las claves públicas adicionales del directorio de verificación
+func LoadKeyStore(cfg *config.Config) (*KeyStore, error) {
+	algorithm := cfg.JWTAlgorithm
+	if algorithm == "" {
+		algorithm = AlgorithmHS256
+	}
+
+	store := &KeyStore{
+		algorithm:    algorithm,
+		verification: make(map[string]*VerificationKey),
+	}
+
+	switch algorithm {
+	case AlgorithmHS256:
+		// Modo legado: se firma con JWTSecret y no hay claves públicas que publicar
+		store.signing = jwt.SigningMethodHS256
+		return store, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", algorithm)
+	}
+
+	pemData, err := readPrivateKeyPEM(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := parsePrivateKey(pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := signingMethodFor(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if method.Alg() != algorithm {
+		return nil, fmt.Errorf("private key type does not match JWT algorithm %s", algorithm)
+	}
+
+	keyID := cfg.JWTKeyID
+	if keyID == "" {
+		keyID, err = thumbprint(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	store.signingKeyID = keyID
+	store.signingKey = signer
+	store.signing = method
+	store.verification[keyID] = &VerificationKey{
+		ID:        keyID,
+		Algorithm: method.Alg(),
+		Method:    method,
+		Public:    signer.Public(),
+	}
+
+	if cfg.JWTVerificationKeysDir != "" {
+		if err := store.loadVerificationKeys(cfg.JWTVerificationKeysDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Algorithm retorna el algoritmo de firma activo
+func (ks *KeyStore) Algorithm() string {
+	return ks.algorithm
+}
+
+// IsLegacy indica si se usa el modo HS256 con secreto compartido
+func (ks *KeyStore) IsLegacy() bool {
+	return ks.algorithm == AlgorithmHS256
+}
+
+// VerificationKey busca una clave de verificación por kid
+func (ks *KeyStore) VerificationKey(keyID string) (*VerificationKey, bool) {
+	key, ok := ks.verification[keyID]
+	return key, ok
+}
+
+// JWKS retorna las claves públicas de verificación en formato JWK
+func (ks *KeyStore) JWKS() *JWKSet {
+	set := &JWKSet{Keys: make([]JWK, 0, len(ks.verification))}
+
+	for _, key := range ks.verification {
+		jwk, err := toJWK(key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, *jwk)
+	}
+
+	// Orden estable: clave activa primero
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if (set.Keys[i].KeyID == ks.signingKeyID) != (set.Keys[j].KeyID == ks.signingKeyID) {
+			return set.Keys[i].KeyID == ks.signingKeyID
+		}
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// loadVerificationKeys carga claves públicas (o privadas, de las que se usa la parte pública)
+// desde archivos <kid>.pem; el nombre del archivo es el kid
+func (ks *KeyStore) loadVerificationKeys(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list verification keys: %w", err)
+	}
+
+	for _, file := range files {
+		keyID := strings.TrimSuffix(filepath.Base(file), ".pem")
+		if _, exists := ks.verification[keyID]; exists {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read verification key %s: %w", file, err)
+		}
+
+		public, err := parsePublicKey(data)
+		if err != nil {
+			return fmt.Errorf("invalid verification key %s: %w", file, err)
+		}
+
+		method, err := signingMethodFor(public)
+		if err != nil {
+			return fmt.Errorf("invalid verification key %s: %w", file, err)
+		}
+
+		ks.verification[keyID] = &VerificationKey{
+			ID:        keyID,
+			Algorithm: method.Alg(),
+			Method:    method,
+			Public:    public,
+		}
+	}
+
+	return nil
+}
+
+// readPrivateKeyPEM lee la clave privada desde JWT_PRIVATE_KEY (PEM) o JWT_PRIVATE_KEY_FILE
+func readPrivateKeyPEM(cfg *config.Config) ([]byte, error) {
+	if cfg.JWTPrivateKey != "" {
+		// Permite PEM en una sola línea con saltos escapados
+		return []byte(strings.ReplaceAll(cfg.JWTPrivateKey, `\n`, "\n")), nil
+	}
+
+	if cfg.JWTPrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE is required for %s", cfg.JWTAlgorithm)
+}
+
+// parsePrivateKey interpreta una clave privada PEM (PKCS#8 o PKCS#1)
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key PEM")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type")
+		}
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key format")
+}
+
+// parsePublicKey interpreta una clave pública PEM; acepta también claves privadas
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	signer, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported public key format")
+	}
+	return signer.Public(), nil
+}
+
+// signingMethodFor determina el método de firma según el tipo de clave
+func signingMethodFor(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", public)
+	}
+}
+
+// toJWK convierte una clave de verificación a JWK
+func toJWK(key *VerificationKey) (*JWK, error) {
+	jwk := &JWK{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Algorithm,
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.Public)
+	}
+
+	return jwk, nil
+}
+
+// thumbprint calcula el JWK thumbprint (RFC 7638), usado como kid por defecto
+func thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := toJWK(&VerificationKey{Public: public})
+	if err != nil {
+		return "", err
+	}
+
+	// Miembros requeridos en orden lexicográfico
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,51 @@
+// internal/auth/oidc.go
+package auth
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenClaims representa los claims del ID token OpenID Connect
+type IDTokenClaims struct {
+	Nonce                string `json:"nonce,omitempty"`
+	AuthTime             int64  `json:"auth_time,omitempty"`
+	Email                string `json:"email,omitempty"`
+	Name                 string `json:"name,omitempty"`
+	GivenName            string `json:"given_name,omitempty"`
+	FamilyName           string `json:"family_name,omitempty"`
+	PreferredUsername    string `json:"preferred_username,omitempty"`
+	Role                 string `json:"role"`
+	OrganizationalUnitID int    `json:"organizationalUnitId"`
+	jwt.RegisteredClaims
+}
+
+// GenerateIDToken firma un ID token con la clave activa.
+// En modo HS256 legado los clientes no tienen nuestro secreto, por lo que se firma
+// con el client_secret del cliente confidencial (OIDC Core 10.1).
+func (j *JWTService) GenerateIDToken(claims *IDTokenClaims, clientSecret string) (string, error) {
+	if j.keys == nil {
+		return "", fmt.Errorf("signing keys not available: %v", j.keyErr)
+	}
+
+	if j.keys.IsLegacy() {
+		if clientSecret == "" {
+			return "", fmt.Errorf("public clients require asymmetric signing keys for ID tokens")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(clientSecret))
+	}
+
+	token := jwt.NewWithClaims(j.keys.signing, claims)
+	token.Header["kid"] = j.keys.signingKeyID
+	return token.SignedString(j.keys.signingKey)
+}
+
+// SigningAlgorithm retorna el algoritmo con el que se firman los tokens
+func (j *JWTService) SigningAlgorithm() string {
+	if j.keys == nil {
+		return AlgorithmHS256
+	}
+	return j.keys.Algorithm()
+}
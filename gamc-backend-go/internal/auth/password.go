@@ -4,6 +4,8 @@ package auth
 import (
 	"fmt"
 
+	"gamc-backend-go/pkg/validator"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -41,68 +43,7 @@ func (p *PasswordService) ComparePassword(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-// IsValidPassword verifica si una contraseña cumple con los requisitos
+// IsValidPassword verifica si una contraseña cumple la política de contraseñas vigente
 func (p *PasswordService) IsValidPassword(password string) (bool, []string) {
-	var errors []string
-
-	// Longitud mínima
-	if len(password) < 8 {
-		errors = append(errors, "La contraseña debe tener al menos 8 caracteres")
-	}
-
-	// Verificar al menos una minúscula
-	hasLower := false
-	for _, char := range password {
-		if char >= 'a' && char <= 'z' {
-			hasLower = true
-			break
-		}
-	}
-	if !hasLower {
-		errors = append(errors, "Debe contener al menos una letra minúscula")
-	}
-
-	// Verificar al menos una mayúscula
-	hasUpper := false
-	for _, char := range password {
-		if char >= 'A' && char <= 'Z' {
-			hasUpper = true
-			break
-		}
-	}
-	if !hasUpper {
-		errors = append(errors, "Debe contener al menos una letra mayúscula")
-	}
-
-	// Verificar al menos un número
-	hasDigit := false
-	for _, char := range password {
-		if char >= '0' && char <= '9' {
-			hasDigit = true
-			break
-		}
-	}
-	if !hasDigit {
-		errors = append(errors, "Debe contener al menos un número")
-	}
-
-	// Verificar al menos un carácter especial
-	specialChars := "@$!%*?&"
-	hasSpecial := false
-	for _, char := range password {
-		for _, special := range specialChars {
-			if char == special {
-				hasSpecial = true
-				break
-			}
-		}
-		if hasSpecial {
-			break
-		}
-	}
-	if !hasSpecial {
-		errors = append(errors, "Debe contener al menos un carácter especial (@$!%*?&)")
-	}
-
-	return len(errors) == 0, errors
+	return validator.IsValidPassword(password)
 }
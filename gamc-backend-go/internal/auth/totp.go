@@ -0,0 +1,141 @@
+// internal/auth/totp.go
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPDigits número de dígitos del código
+	TOTPDigits = 6
+	// TOTPPeriod duración de cada paso en segundos
+	TOTPPeriod = 30
+	// TOTPSkew pasos de tolerancia hacia atrás y adelante (desfase de reloj)
+	TOTPSkew = 1
+	// RecoveryCodesCount cantidad de códigos de recuperación generados
+	RecoveryCodesCount = 10
+)
+
+// TOTPService implementa códigos de un solo uso basados en tiempo (RFC 6238)
+type TOTPService struct {
+	issuer string
+}
+
+// NewTOTPService crea una nueva instancia del servicio TOTP
+func NewTOTPService(issuer string) *TOTPService {
+	return &TOTPService{issuer: issuer}
+}
+
+// GenerateSecret genera un secreto aleatorio de 160 bits codificado en base32
+func (s *TOTPService) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error al generar secreto TOTP: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// ProvisioningURI construye la URI otpauth:// que se codifica en el QR
+func (s *TOTPService) ProvisioningURI(accountName, secret string) string {
+	label := url.PathEscape(s.issuer + ":" + accountName)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", s.issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", TOTPDigits))
+	params.Set("period", fmt.Sprintf("%d", TOTPPeriod))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// GenerateCode genera el código TOTP para un instante dado
+func (s *TOTPService) GenerateCode(secret string, t time.Time) (string, error) {
+	return generateHOTP(secret, uint64(t.Unix()/TOTPPeriod))
+}
+
+// ValidateCode valida un código y retorna el paso de tiempo que coincidió.
+// Los pasos menores o iguales a lastUsedStep se rechazan para evitar reutilización.
+func (s *TOTPService) ValidateCode(secret, code string, t time.Time, lastUsedStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	currentStep := t.Unix() / TOTPPeriod
+	for offset := -TOTPSkew; offset <= TOTPSkew; offset++ {
+		step := currentStep + int64(offset)
+		if step <= lastUsedStep {
+			continue
+		}
+
+		expected, err := generateHOTP(secret, uint64(step))
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes genera códigos de recuperación legibles (formato xxxxx-xxxxx)
+func (s *TOTPService) GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodesCount)
+	for i := 0; i < RecoveryCodesCount; i++ {
+		bytes := make([]byte, 5)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, fmt.Errorf("error al generar código de recuperación: %w", err)
+		}
+		raw := hex.EncodeToString(bytes)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode hashea un código de recuperación normalizado
+func (s *TOTPService) HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+// generateHOTP implementa HOTP (RFC 4226) con HMAC-SHA1
+func generateHOTP(secret string, counter uint64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("secreto TOTP inválido: %w", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Truncamiento dinámico
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod), nil
+}
@@ -0,0 +1,183 @@
+// internal/auth/totp_test.go
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret es la clave ASCII "12345678901234567890" de los apéndices de RFC 4226 y RFC 6238, en base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateHOTPVectors(t *testing.T) {
+	// RFC 4226, apéndice D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, expected := range want {
+		got, err := generateHOTP(rfcSecret, uint64(counter))
+		if err != nil {
+			t.Fatalf("generateHOTP(%d): %v", counter, err)
+		}
+		if got != expected {
+			t.Errorf("generateHOTP(%d) = %s, se esperaba %s", counter, got, expected)
+		}
+	}
+
+	if _, err := generateHOTP("no-es-base32!", 0); err == nil {
+		t.Error("generateHOTP aceptó un secreto que no es base32")
+	}
+}
+
+func TestGenerateCodeVectors(t *testing.T) {
+	// RFC 6238, apéndice B (SHA-1): los vectores tienen 8 dígitos, se comparan los últimos 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	s := NewTOTPService("GAMC")
+	for _, tt := range tests {
+		got, err := s.GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode(%d) = %s, se esperaba %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCodeSkew(t *testing.T) {
+	s := NewTOTPService("GAMC")
+	now := time.Unix(1234567890, 0)
+	currentStep := now.Unix() / TOTPPeriod
+
+	tests := []struct {
+		name   string
+		offset int64
+		valid  bool
+	}{
+		{name: "paso actual", offset: 0, valid: true},
+		{name: "un paso atrás", offset: -1, valid: true},
+		{name: "un paso adelante", offset: 1, valid: true},
+		{name: "dos pasos atrás", offset: -2, valid: false},
+		{name: "dos pasos adelante", offset: 2, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := currentStep + tt.offset
+			code, err := generateHOTP(rfcSecret, uint64(step))
+			if err != nil {
+				t.Fatalf("generateHOTP: %v", err)
+			}
+
+			matched, ok := s.ValidateCode(rfcSecret, code, now, 0)
+			if ok != tt.valid {
+				t.Fatalf("ValidateCode(desfase %d) = %v, se esperaba %v", tt.offset, ok, tt.valid)
+			}
+			if ok && matched != step {
+				t.Errorf("paso = %d, se esperaba %d", matched, step)
+			}
+		})
+	}
+}
+
+func TestValidateCodeRejectsReplay(t *testing.T) {
+	s := NewTOTPService("GAMC")
+	now := time.Unix(1234567890, 0)
+
+	code, _ := s.GenerateCode(rfcSecret, now)
+	step, ok := s.ValidateCode(rfcSecret, code, now, 0)
+	if !ok {
+		t.Fatal("ValidateCode rechazó el código vigente")
+	}
+
+	// El mismo código dentro de la ventana de tolerancia ya no se acepta
+	if _, ok := s.ValidateCode(rfcSecret, code, now, step); ok {
+		t.Error("ValidateCode aceptó un código ya usado")
+	}
+	if _, ok := s.ValidateCode(rfcSecret, code, now.Add(TOTPPeriod*time.Second), step); ok {
+		t.Error("ValidateCode aceptó un código ya usado en el paso siguiente")
+	}
+
+	// Un paso anterior al último usado tampoco, aunque esté dentro del desfase
+	previous, _ := generateHOTP(rfcSecret, uint64(step-1))
+	if _, ok := s.ValidateCode(rfcSecret, previous, now, step); ok {
+		t.Error("ValidateCode aceptó un paso anterior al último usado")
+	}
+
+	// El paso siguiente sí
+	next, _ := generateHOTP(rfcSecret, uint64(step+1))
+	if matched, ok := s.ValidateCode(rfcSecret, next, now, step); !ok || matched != step+1 {
+		t.Errorf("ValidateCode(paso siguiente) = %d, %v, se esperaba %d, true", matched, ok, step+1)
+	}
+}
+
+func TestValidateCodeRejectsMalformed(t *testing.T) {
+	s := NewTOTPService("GAMC")
+	now := time.Unix(1234567890, 0)
+	code, _ := s.GenerateCode(rfcSecret, now)
+
+	if _, ok := s.ValidateCode(rfcSecret, " "+code+"\n", now, 0); !ok {
+		t.Error("ValidateCode rechazó el código con espacios alrededor")
+	}
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{name: "vacío", code: ""},
+		{name: "muy corto", code: code[:5]},
+		{name: "muy largo", code: code + "0"},
+		{name: "formato de 8 dígitos", code: "89005924"},
+		{name: "con letras", code: code[:5] + "a"},
+		{name: "con símbolos", code: "00-592"},
+		{name: "espacio interior", code: code[:3] + " " + code[3:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := s.ValidateCode(rfcSecret, tt.code, now, 0); ok {
+				t.Errorf("ValidateCode(%q) aceptó un código inválido", tt.code)
+			}
+		})
+	}
+
+	if _, ok := s.ValidateCode("no-es-base32!", code, now, 0); ok {
+		t.Error("ValidateCode aceptó un secreto inválido")
+	}
+}
+
+func TestHashRecoveryCodeNormalization(t *testing.T) {
+	s := NewTOTPService("GAMC")
+	want := s.HashRecoveryCode("a1b2c-3d4e5")
+
+	for _, code := range []string{"A1B2C-3D4E5", "a1b2c3d4e5", " a1b2c-3d4e5 ", "A1B2C3D4E5", "a1-b2c-3d4e5"} {
+		if got := s.HashRecoveryCode(code); got != want {
+			t.Errorf("HashRecoveryCode(%q) = %s, se esperaba el mismo hash que a1b2c-3d4e5", code, got)
+		}
+	}
+	if s.HashRecoveryCode("a1b2c-3d4e6") == want {
+		t.Error("HashRecoveryCode produjo el mismo hash para otro código")
+	}
+
+	codes, err := s.GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodesCount {
+		t.Fatalf("códigos generados = %d, se esperaba %d", len(codes), RecoveryCodesCount)
+	}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("código %q sin formato xxxxx-xxxxx", code)
+		}
+	}
+}
@@ -31,6 +31,81 @@ type Config struct {
 	JWTIssuer           string
 	JWTAudience         string
 
+	// Firma asimétrica de access tokens (RS256/EdDSA); HS256 queda como modo legado
+	JWTAlgorithm           string
+	JWTKeyID               string // kid de la clave activa; por defecto su thumbprint
+	JWTPrivateKey          string // PEM en la variable de entorno
+	JWTPrivateKeyFile      string
+	JWTVerificationKeysDir string // Claves públicas anteriores (<kid>.pem) aún válidas para verificar
+	JWTAcceptLegacyHS256   bool   // Acepta tokens HS256 durante la migración
+
+	// Autenticación de dos factores
+	TwoFactorIssuer string
+
+	// Proveedor OpenID Connect
+	OIDCIssuer       string // URL pública del backend; se usa como "iss" de los ID tokens
+	OIDCAuthorizeURL string // Pantalla de consentimiento del frontend
+
+	// Introspección de tokens (RFC 7662)
+	IntrospectionNegativeCacheTTL time.Duration // Tiempo que se recuerda un token inactivo
+
+	// Passkeys (WebAuthn)
+	WebAuthnRPID    string // Dominio del frontend al que quedan ligadas las passkeys
+	WebAuthnRPName  string
+	WebAuthnOrigins string // Orígenes permitidos separados por coma (vacío = CORS_ORIGIN)
+
+	// Bloqueo por intentos fallidos de login
+	MaxLoginAttempts      int
+	MaxLoginAttemptsPerIP int
+	LockoutDuration       time.Duration // Primer bloqueo; se duplica en cada bloqueo siguiente
+	MaxLockoutDuration    time.Duration
+
+	// Historial y expiración de contraseñas (valores por defecto de la política de seguridad)
+	PasswordHistoryCount      int // Contraseñas anteriores que no se pueden reutilizar
+	PasswordExpirationDays    int // 0 desactiva la expiración
+	PasswordExpiryWarningDays int // Días de anticipación del aviso de expiración
+
+	// Filtro local de contraseñas filtradas (vacío = solo la lista de contraseñas comunes)
+	BreachedPasswordsFile string
+
+	// Auditoría de resets: cuentas distintas desde una IP, o IPs distintas para una cuenta,
+	// a partir de las cuales el reporte marca la anomalía
+	ResetAuditIPAccountThreshold int
+	ResetAuditAccountIPThreshold int
+
+	// Directorio LDAP / Active Directory (cadena de autenticación: local y luego LDAP)
+	LDAPEnabled            bool
+	LDAPURL                string // ldap://host:389 o ldaps://host:636
+	LDAPStartTLS           bool
+	LDAPInsecureSkipVerify bool
+	LDAPTimeout            time.Duration
+	LDAPBindDN             string // Cuenta de búsqueda
+	LDAPBindPassword       string
+	LDAPBaseDN             string
+	LDAPUserFilter         string // %s se reemplaza por el email escapado
+	LDAPEmailAttribute     string
+	LDAPFirstNameAttribute string
+	LDAPLastNameAttribute  string
+	LDAPGroupAttribute     string // Atributo de pertenencia en la entrada del usuario (memberOf en AD)
+	LDAPGroupBaseDN        string
+	LDAPGroupFilter        string        // Alternativa a LDAPGroupAttribute; %s se reemplaza por el DN del usuario
+	LDAPRoleGroups         string        // rol=grupo separados por ';' (grupo: CN o DN completo)
+	LDAPUnitGroups         string        // CODIGO_UNIDAD=grupo separados por ';'
+	LDAPDefaultRole        string        // Vacío: se rechaza a quien no pertenece a ningún grupo de rol
+	LDAPDefaultUnitCode    string        // Vacío: se rechaza a quien no pertenece a ningún grupo de unidad
+	LDAPSyncInterval       time.Duration // 0 desactiva la sincronización
+
+	// Verificación de email en el auto-registro
+	EmailVerificationTTL       time.Duration // Vigencia del enlace y del código
+	EmailVerificationPurgeDays int           // Días antes de eliminar cuentas no verificadas
+
+	// Suplantación de usuarios por soporte (admin)
+	ImpersonationTTL time.Duration // Vigencia del token de suplantación; no se renueva
+
+	// Restricción de acceso por red (listas de rangos IP por rol y unidad)
+	IPAllowlistEnabled bool   // false desactiva la verificación en emergencias
+	TrustedProxies     string // Proxies cuyo X-Forwarded-For se acepta (IPs o CIDR separados por coma)
+
 	// CORS
 	CORSOrigin string
 
@@ -65,7 +140,19 @@ type Config struct {
 	SMTPUser     string
 	SMTPPassword string
 	SMTPFrom     string
-	SMTPUseTLS   bool
+	SMTPUseTLS   bool // STARTTLS obligatorio; el puerto 465 usa TLS implícito
+
+	// Cola de correo saliente
+	MailEnabled       bool          // Desactivado: los correos no se encolan
+	MailFromName      string        // Nombre mostrado en el remitente
+	MailBaseURL       string        // URL del frontend para los enlaces de los correos
+	MailQueueInterval time.Duration // Frecuencia de procesamiento de la cola
+	MailMaxAttempts   int           // Intentos antes de marcar un correo como fallido
+
+	// Firma DKIM de los correos salientes (vacío = sin firma)
+	DKIMDomain         string
+	DKIMSelector       string
+	DKIMPrivateKeyFile string
 }
 
 // AppContext contiene las dependencias de la aplicación
@@ -97,6 +184,78 @@ func Load() *Config {
 		JWTIssuer:           getEnv("JWT_ISSUER", "gamc-auth"),
 		JWTAudience:         getEnv("JWT_AUDIENCE", "gamc-system"),
 
+		// Firma asimétrica de JWT
+		JWTAlgorithm:           getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyID:               getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKey:          getEnv("JWT_PRIVATE_KEY", ""),
+		JWTPrivateKeyFile:      getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTVerificationKeysDir: getEnv("JWT_VERIFICATION_KEYS_DIR", ""),
+		JWTAcceptLegacyHS256:   getEnvBool("JWT_ACCEPT_LEGACY_HS256", false),
+
+		// Autenticación de dos factores
+		TwoFactorIssuer: getEnv("TWO_FACTOR_ISSUER", "GAMC"),
+
+		// Proveedor OpenID Connect
+		OIDCIssuer:       getEnv("OIDC_ISSUER", "http://localhost:3000"),
+		OIDCAuthorizeURL: getEnv("OIDC_AUTHORIZE_URL", "http://localhost:5173/oauth/authorize"),
+
+		// Introspección de tokens
+		IntrospectionNegativeCacheTTL: parseDuration(getEnv("INTROSPECTION_NEGATIVE_CACHE_TTL", "30s")),
+
+		// Passkeys
+		WebAuthnRPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:  getEnv("WEBAUTHN_RP_NAME", "GAMC Sistema Web Centralizado"),
+		WebAuthnOrigins: getEnv("WEBAUTHN_ORIGINS", ""),
+
+		// Bloqueo por intentos fallidos
+		MaxLoginAttempts:      parseInt(getEnv("MAX_LOGIN_ATTEMPTS", "5")),
+		MaxLoginAttemptsPerIP: parseInt(getEnv("MAX_LOGIN_ATTEMPTS_PER_IP", "20")),
+		LockoutDuration:       parseDuration(getEnv("LOCKOUT_DURATION", "15m")),
+		MaxLockoutDuration:    parseDuration(getEnv("MAX_LOCKOUT_DURATION", "24h")),
+
+		// Historial y expiración de contraseñas
+		PasswordHistoryCount:      parseInt(getEnv("PASSWORD_HISTORY_COUNT", "5")),
+		PasswordExpirationDays:    parseInt(getEnv("PASSWORD_EXPIRATION_DAYS", "90")),
+		PasswordExpiryWarningDays: parseInt(getEnv("PASSWORD_EXPIRY_WARNING_DAYS", "7")),
+		BreachedPasswordsFile:     getEnv("BREACHED_PASSWORDS_FILE", ""),
+
+		// Auditoría de resets de contraseña
+		ResetAuditIPAccountThreshold: parseInt(getEnv("RESET_AUDIT_IP_ACCOUNT_THRESHOLD", "5")),
+		ResetAuditAccountIPThreshold: parseInt(getEnv("RESET_AUDIT_ACCOUNT_IP_THRESHOLD", "4")),
+
+		// Directorio LDAP
+		LDAPEnabled:            getEnvBool("LDAP_ENABLED", false),
+		LDAPURL:                getEnv("LDAP_URL", "ldap://localhost:389"),
+		LDAPStartTLS:           getEnvBool("LDAP_START_TLS", false),
+		LDAPInsecureSkipVerify: getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		LDAPTimeout:            parseDuration(getEnv("LDAP_TIMEOUT", "5s")),
+		LDAPBindDN:             getEnv("LDAP_BIND_DN", ""),
+		LDAPBindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:             getEnv("LDAP_BASE_DN", "dc=gamc,dc=gov,dc=bo"),
+		LDAPUserFilter:         getEnv("LDAP_USER_FILTER", "(&(objectClass=person)(mail=%s))"),
+		LDAPEmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		LDAPFirstNameAttribute: getEnv("LDAP_FIRST_NAME_ATTRIBUTE", "givenName"),
+		LDAPLastNameAttribute:  getEnv("LDAP_LAST_NAME_ATTRIBUTE", "sn"),
+		LDAPGroupAttribute:     getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		LDAPGroupBaseDN:        getEnv("LDAP_GROUP_BASE_DN", ""),
+		LDAPGroupFilter:        getEnv("LDAP_GROUP_FILTER", ""),
+		LDAPRoleGroups:         getEnv("LDAP_ROLE_GROUPS", ""),
+		LDAPUnitGroups:         getEnv("LDAP_UNIT_GROUPS", ""),
+		LDAPDefaultRole:        getEnv("LDAP_DEFAULT_ROLE", ""),
+		LDAPDefaultUnitCode:    getEnv("LDAP_DEFAULT_UNIT_CODE", ""),
+		LDAPSyncInterval:       parseDuration(getEnv("LDAP_SYNC_INTERVAL", "1h")),
+
+		// Verificación de email
+		EmailVerificationTTL:       parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "24h")),
+		EmailVerificationPurgeDays: parseInt(getEnv("EMAIL_VERIFICATION_PURGE_DAYS", "7")),
+
+		// Suplantación
+		ImpersonationTTL: parseDuration(getEnv("IMPERSONATION_TTL", "15m")),
+
+		// Restricción de acceso por red
+		IPAllowlistEnabled: getEnvBool("IP_ALLOWLIST_ENABLED", true),
+		TrustedProxies:     getEnv("TRUSTED_PROXIES", ""),
+
 		// CORS
 		CORSOrigin: getEnv("CORS_ORIGIN", "http://localhost:5173"),
 
@@ -132,6 +291,18 @@ func Load() *Config {
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 		SMTPFrom:     getEnv("SMTP_FROM", "noreply@gamc.gov.bo"),
 		SMTPUseTLS:   getEnvBool("SMTP_USE_TLS", true),
+
+		// Cola de correo
+		MailEnabled:       getEnvBool("MAIL_ENABLED", false),
+		MailFromName:      getEnv("MAIL_FROM_NAME", "Sistema Web Centralizado GAMC"),
+		MailBaseURL:       getEnv("MAIL_BASE_URL", "http://localhost:5173"),
+		MailQueueInterval: parseDuration(getEnv("MAIL_QUEUE_INTERVAL", "30s")),
+		MailMaxAttempts:   parseInt(getEnv("MAIL_MAX_ATTEMPTS", "6")),
+
+		// DKIM
+		DKIMDomain:         getEnv("DKIM_DOMAIN", ""),
+		DKIMSelector:       getEnv("DKIM_SELECTOR", "gamc"),
+		DKIMPrivateKeyFile: getEnv("DKIM_PRIVATE_KEY_FILE", ""),
 	}
 }
 
@@ -249,6 +420,33 @@ func (c *Config) GetAllowedFileExtensions() []string {
 	return strings.Split(c.MinIOAllowedTypes, ",")
 }
 
+// GetTrustedProxies retorna los proxies de confianza como slice (vacío = ninguno)
+func (c *Config) GetTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// GetWebAuthnOrigins retorna los orígenes desde los que se aceptan ceremonias WebAuthn
+func (c *Config) GetWebAuthnOrigins() []string {
+	source := c.WebAuthnOrigins
+	if source == "" {
+		source = c.CORSOrigin
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(source, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
 // IsFileTypeAllowed verifica si un tipo de archivo está permitido
 func (c *Config) IsFileTypeAllowed(extension string) bool {
 	allowed := c.GetAllowedFileExtensions()
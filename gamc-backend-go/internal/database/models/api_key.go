@@ -0,0 +1,143 @@
+// internal/database/models/api_key.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix prefijo fijo que identifica las keys del sistema (gamc_<prefix>_<secreto>)
+const APIKeyPrefix = "gamc"
+
+// Scopes de API keys: <recurso>:read (GET) o <recurso>:write (resto de métodos)
+const (
+	APIKeyScopeProfileRead        = "profile:read"
+	APIKeyScopeMessagesRead       = "messages:read"
+	APIKeyScopeMessagesWrite      = "messages:write"
+	APIKeyScopeFilesRead          = "files:read"
+	APIKeyScopeFilesWrite         = "files:write"
+	APIKeyScopeNotificationsRead  = "notifications:read"
+	APIKeyScopeNotificationsWrite = "notifications:write"
+)
+
+// GetAPIKeyScopes retorna el catálogo de scopes asignables
+func GetAPIKeyScopes() []string {
+	return []string{
+		APIKeyScopeProfileRead,
+		APIKeyScopeMessagesRead,
+		APIKeyScopeMessagesWrite,
+		APIKeyScopeFilesRead,
+		APIKeyScopeFilesWrite,
+		APIKeyScopeNotificationsRead,
+		APIKeyScopeNotificationsWrite,
+	}
+}
+
+// IsValidAPIKeyScope verifica si un scope existe en el catálogo
+func IsValidAPIKeyScope(scope string) bool {
+	for _, valid := range GetAPIKeyScopes() {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey representa una credencial de una cuenta de servicio
+type APIKey struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ServiceAccountID uuid.UUID  `json:"serviceAccountId" gorm:"type:uuid;not null;index"`
+	Name             string     `json:"name" gorm:"size:100;not null"`
+	Prefix           string     `json:"prefix" gorm:"uniqueIndex;size:16;not null"`
+	KeyHash          string     `json:"-" gorm:"size:64;not null"` // Nunca exponer en JSON
+	Scopes           []string   `json:"scopes" gorm:"type:jsonb;serializer:json"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+	LastUsedAt       *time.Time `json:"lastUsedAt"`
+	LastUsedIP       string     `json:"lastUsedIp,omitempty" gorm:"column:last_used_ip;size:45"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy        *uuid.UUID `json:"revokedBy,omitempty" gorm:"type:uuid"`
+	CreatedBy        *uuid.UUID `json:"createdBy,omitempty" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+
+	// Relaciones
+	ServiceAccount *User `json:"-" gorm:"foreignKey:ServiceAccountID"`
+}
+
+// TableName especifica el nombre de la tabla
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// BeforeCreate hook de GORM para generar UUID
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired verifica si la key expiró
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// IsRevoked verifica si la key fue revocada
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// HasScope verifica si la key tiene el scope indicado
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+// ServiceAccountCreateRequest solicitud de creación de cuenta de servicio
+type ServiceAccountCreateRequest struct {
+	Name                 string `json:"name" validate:"required,min=3,max=50"`
+	Description          string `json:"description,omitempty" validate:"max=500"`
+	Role                 string `json:"role" validate:"required,oneof=input output"`
+	OrganizationalUnitID int    `json:"organizationalUnitId" validate:"required,min=1"`
+}
+
+// APIKeyCreateRequest solicitud de emisión de API key
+type APIKeyCreateRequest struct {
+	Name          string   `json:"name" validate:"required,min=3,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"min=0,max=730"` // 0 = sin expiración
+}
+
+// ========================================
+// RESPONSES
+// ========================================
+
+// ServiceAccountResponse cuenta de servicio con el resumen de sus keys
+type ServiceAccountResponse struct {
+	ID                   uuid.UUID  `json:"id"`
+	Username             string     `json:"username"`
+	Name                 string     `json:"name"`
+	Description          string     `json:"description,omitempty"`
+	Role                 string     `json:"role"`
+	OrganizationalUnitID *int       `json:"organizationalUnitId"`
+	IsActive             bool       `json:"isActive"`
+	ActiveKeys           int64      `json:"activeKeys"`
+	LastUsedAt           *time.Time `json:"lastUsedAt"`
+	CreatedAt            time.Time  `json:"createdAt"`
+}
+
+// APIKeySecretResponse respuesta de emisión: la key completa solo se muestra aquí
+type APIKeySecretResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"apiKey"`
+}
@@ -26,6 +26,44 @@ const (
 	AuditActionReceive AuditAction = "RECEIVE"
 	AuditActionArchive AuditAction = "ARCHIVE"
 	AuditActionRestore AuditAction = "RESTORE"
+
+	// Eventos de seguridad de autenticación
+	AuditActionLoginFailed     AuditAction = "LOGIN_FAILED"
+	AuditActionAccountLocked   AuditAction = "ACCOUNT_LOCKED"
+	AuditActionAccountUnlocked AuditAction = "ACCOUNT_UNLOCKED"
+	AuditActionAccountExpired  AuditAction = "ACCOUNT_EXPIRED"
+	AuditActionIPLocked        AuditAction = "IP_LOCKED"
+	AuditActionIPUnlocked      AuditAction = "IP_UNLOCKED"
+	AuditActionSessionRevoked  AuditAction = "SESSION_REVOKED"
+	AuditActionSessionExpired  AuditAction = "SESSION_EXPIRED"
+	AuditActionTokenReuse      AuditAction = "REFRESH_TOKEN_REUSE"
+
+	// Integraciones con API keys
+	AuditActionAPIKeyUsed    AuditAction = "API_KEY_USED"
+	AuditActionAPIKeyRevoked AuditAction = "API_KEY_REVOKED"
+
+	// Suplantación de usuarios por soporte
+	AuditActionImpersonationStart   AuditAction = "IMPERSONATION_START"
+	AuditActionImpersonationRequest AuditAction = "IMPERSONATION_REQUEST"
+
+	// Verificación de email del auto-registro
+	AuditActionEmailVerified    AuditAction = "EMAIL_VERIFIED"
+	AuditActionUnverifiedPurged AuditAction = "UNVERIFIED_USER_PURGED"
+
+	// Restricción de acceso por red
+	AuditActionIPNotAllowed    AuditAction = "IP_NOT_ALLOWED"
+	AuditActionIPBypassCreated AuditAction = "IP_BYPASS_CREATED"
+	AuditActionIPBypassUsed    AuditAction = "IP_BYPASS_USED"
+	AuditActionIPBypassRevoked AuditAction = "IP_BYPASS_REVOKED"
+
+	// Dispositivos conocidos
+	AuditActionNewDeviceLogin AuditAction = "NEW_DEVICE_LOGIN"
+	AuditActionDeviceReported AuditAction = "DEVICE_REPORTED"
+
+	// Passkeys (WebAuthn)
+	AuditActionPasskeyRegistered AuditAction = "PASSKEY_REGISTERED"
+	AuditActionPasskeyRevoked    AuditAction = "PASSKEY_REVOKED"
+	AuditActionPasskeyCloned     AuditAction = "PASSKEY_SIGN_COUNT_REGRESSION"
 )
 
 // AuditResult define los resultados de una acción auditada
@@ -0,0 +1,67 @@
+// internal/database/models/device.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDevice dispositivo desde el que el usuario ya inició sesión
+type UserDevice struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	UserID      uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	DeviceHash  string    `json:"-" gorm:"size:64;not null"`
+	Fingerprint string    `json:"-" gorm:"size:64;not null"`
+	Description string    `json:"description" gorm:"size:100;not null"`
+	UserAgent   string    `json:"userAgent,omitempty"`
+	FirstIP     string    `json:"firstIp,omitempty" gorm:"column:first_ip;size:45"`
+	LastIP      string    `json:"lastIp,omitempty" gorm:"column:last_ip;size:45"`
+	FirstSeenAt time.Time `json:"firstSeenAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+
+	// Calculado: dispositivo de la petición actual
+	IsCurrent bool `json:"isCurrent" gorm:"-"`
+}
+
+// TableName especifica el nombre de la tabla
+func (UserDevice) TableName() string {
+	return "user_devices"
+}
+
+// DeviceLoginAlert alerta de login desde un dispositivo nuevo con su enlace "No fui yo"
+type DeviceLoginAlert struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     uuid.UUID  `json:"userId" gorm:"type:uuid;not null"`
+	DeviceID   *int       `json:"deviceId,omitempty"`
+	SessionID  string     `json:"sessionId" gorm:"size:64;not null"`
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	IPAddress  string     `json:"ipAddress" gorm:"size:45"`
+	UserAgent  string     `json:"userAgent"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"not null"`
+	ReportedAt *time.Time `json:"reportedAt,omitempty"`
+	ReportedIP string     `json:"reportedIp,omitempty" gorm:"column:reported_ip;size:45"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (DeviceLoginAlert) TableName() string {
+	return "device_login_alerts"
+}
+
+// ===== ESTRUCTURAS PARA REQUESTS =====
+
+// DeviceReportRequest token del enlace "No fui yo"
+type DeviceReportRequest struct {
+	Token string `json:"token" validate:"required,len=64,token_hex"`
+}
+
+// ===== ESTRUCTURAS PARA RESPONSES =====
+
+// DeviceReportResponse resultado de reportar un acceso no reconocido
+type DeviceReportResponse struct {
+	SessionsRevoked       int  `json:"sessionsRevoked"`
+	PasswordResetRequired bool `json:"passwordResetRequired"`
+	// Los usuarios del directorio cambian la contraseña en el directorio institucional
+	DirectoryUser bool `json:"directoryUser,omitempty"`
+}
@@ -0,0 +1,58 @@
+// internal/database/models/email.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de entrega de un correo
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSending = "sending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// Plantillas de correo disponibles (internal/services/templates/email)
+const (
+	EmailTemplatePasswordReset = "password_reset"
+	EmailTemplateSecurityAlert = "security_alert"
+	EmailTemplateNewMessage    = "new_message"
+	EmailTemplateVerification  = "email_verification"
+	EmailTemplateNewDevice     = "new_device"
+)
+
+// EmailMessage correo en la cola de salida con su estado de entrega
+type EmailMessage struct {
+	ID            int64      `json:"id" gorm:"primaryKey"`
+	UserID        *uuid.UUID `json:"userId,omitempty" gorm:"type:uuid;index"`
+	Recipient     string     `json:"recipient" gorm:"size:255;not null"`
+	Template      string     `json:"template" gorm:"size:50;not null"`
+	Subject       string     `json:"subject" gorm:"size:255;not null"`
+	BodyText      string     `json:"-" gorm:"type:text;not null"`
+	BodyHTML      string     `json:"-" gorm:"column:body_html;type:text;not null"`
+	Status        string     `json:"status" gorm:"size:20;not null;default:pending"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts   int        `json:"maxAttempts" gorm:"not null;default:6"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"not null"`
+	LastError     *string    `json:"lastError,omitempty"`
+	MessageID     *string    `json:"messageId,omitempty" gorm:"size:255"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (EmailMessage) TableName() string {
+	return "email_queue"
+}
+
+// EmailQueueStats conteo de correos por estado
+type EmailQueueStats struct {
+	Pending int64 `json:"pending"`
+	Sending int64 `json:"sending"`
+	Sent    int64 `json:"sent"`
+	Failed  int64 `json:"failed"`
+}
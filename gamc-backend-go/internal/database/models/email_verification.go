@@ -0,0 +1,46 @@
+// internal/database/models/email_verification.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxEmailVerificationAttempts códigos incorrectos permitidos por solicitud
+const MaxEmailVerificationAttempts = 5
+
+// EmailVerification enlace y código de verificación de email, de un solo uso
+type EmailVerification struct {
+	ID        int        `json:"id" gorm:"primaryKey"`
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	UsedAt    *time.Time `json:"usedAt"`
+	RequestIP string     `json:"requestIp" gorm:"size:45"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (EmailVerification) TableName() string {
+	return "email_verifications"
+}
+
+// IsUsable indica si la verificación no se usó, no venció y no agotó sus intentos
+func (v *EmailVerification) IsUsable() bool {
+	return v.UsedAt == nil && time.Now().Before(v.ExpiresAt) && v.Attempts < MaxEmailVerificationAttempts
+}
+
+// EmailVerificationRequest verifica con el token del enlace o con email + código
+type EmailVerificationRequest struct {
+	Token string `json:"token" validate:"omitempty,token_hex"`
+	Email string `json:"email" validate:"omitempty,email"`
+	Code  string `json:"code" validate:"omitempty,len=6,numeric"`
+}
+
+// ResendVerificationRequest solicita un nuevo enlace y código
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
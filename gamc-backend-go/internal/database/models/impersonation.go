@@ -0,0 +1,29 @@
+// internal/database/models/impersonation.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationInfo identifica al administrador que actúa como el usuario
+type ImpersonationInfo struct {
+	ImpersonatorID    string    `json:"impersonatorId"`
+	ImpersonatorEmail string    `json:"impersonatorEmail"`
+	Reason            string    `json:"reason,omitempty"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+// ImpersonationRequest solicitud de suplantación; el motivo queda en la auditoría
+type ImpersonationRequest struct {
+	Reason string `json:"reason" validate:"required,min=10,max=500"`
+}
+
+// ImpersonationResponse token de suplantación (sin refresh token)
+type ImpersonationResponse struct {
+	AccessToken    string       `json:"accessToken"`
+	ExpiresIn      int64        `json:"expiresIn"`
+	User           *UserProfile `json:"user"`
+	ImpersonatorID uuid.UUID    `json:"impersonatorId"`
+}
@@ -73,6 +73,19 @@ func (Message) TableName() string {
 	return "messages"
 }
 
+// MessageReply enlaza una respuesta con el mensaje al que responde (hilos de conversación)
+type MessageReply struct {
+	ID                int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	OriginalMessageID int64     `json:"originalMessageId" gorm:"not null;index"`
+	ResponseMessageID int64     `json:"responseMessageId" gorm:"not null;index"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MessageReply) TableName() string {
+	return "message_responses"
+}
+
 // IsRead verifica si el mensaje ha sido leído
 func (m *Message) IsRead() bool {
 	return m.ReadAt != nil
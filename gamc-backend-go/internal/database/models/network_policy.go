@@ -0,0 +1,105 @@
+// internal/database/models/network_policy.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alcances de las reglas de red
+const (
+	NetworkRuleScopeRole = "role"
+	NetworkRuleScopeUnit = "unit"
+)
+
+// NetworkRule rango IP desde el que pueden operar los usuarios de un rol o de una unidad.
+// Con varias reglas del mismo alcance basta con que la IP esté en una de ellas.
+type NetworkRule struct {
+	ID                   int        `json:"id" gorm:"primaryKey"`
+	Scope                string     `json:"scope" gorm:"size:10;not null"`
+	Role                 *string    `json:"role,omitempty" gorm:"size:20"`
+	OrganizationalUnitID *int       `json:"organizationalUnitId,omitempty"`
+	CIDR                 string     `json:"cidr" gorm:"column:cidr;size:50;not null"`
+	Description          string     `json:"description,omitempty"`
+	CreatedBy            *uuid.UUID `json:"createdBy,omitempty" gorm:"type:uuid"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	UpdatedAt            time.Time  `json:"updatedAt"`
+
+	// Relaciones
+	OrganizationalUnit *OrganizationalUnit `json:"organizationalUnit,omitempty" gorm:"foreignKey:OrganizationalUnitID"`
+}
+
+// TableName especifica el nombre de la tabla
+func (NetworkRule) TableName() string {
+	return "ip_allowlist_rules"
+}
+
+// IPAllowlistBypass excepción temporal a las reglas de red, para un usuario o para
+// todos (UserID nil), por ejemplo si la intranet queda fuera de servicio
+type IPAllowlistBypass struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     *uuid.UUID `json:"userId,omitempty" gorm:"type:uuid"`
+	Reason     string     `json:"reason" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"not null"`
+	CreatedBy  uuid.UUID  `json:"createdBy" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy  *uuid.UUID `json:"revokedBy,omitempty" gorm:"type:uuid"`
+	UseCount   int        `json:"useCount" gorm:"not null;default:0"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty" gorm:"column:last_used_ip;size:45"`
+
+	// Relaciones
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName especifica el nombre de la tabla
+func (IPAllowlistBypass) TableName() string {
+	return "ip_allowlist_bypasses"
+}
+
+// IsActive indica si la excepción sigue vigente
+func (b *IPAllowlistBypass) IsActive() bool {
+	return b.RevokedAt == nil && time.Now().Before(b.ExpiresAt)
+}
+
+// AppliesTo indica si la excepción cubre al usuario
+func (b *IPAllowlistBypass) AppliesTo(userID uuid.UUID) bool {
+	return b.UserID == nil || *b.UserID == userID
+}
+
+// ===== ESTRUCTURAS PARA REQUESTS =====
+
+// NetworkRuleRequest alta de un rango para un rol (Role) o una unidad (OrganizationalUnitID)
+type NetworkRuleRequest struct {
+	Scope                string `json:"scope" validate:"required,oneof=role unit"`
+	Role                 string `json:"role,omitempty" validate:"omitempty,oneof=admin input output"`
+	OrganizationalUnitID int    `json:"organizationalUnitId,omitempty" validate:"omitempty,min=1"`
+	CIDR                 string `json:"cidr" validate:"required,cidr|ip"`
+	Description          string `json:"description,omitempty" validate:"max=255"`
+}
+
+// IPAllowlistBypassRequest excepción de emergencia; el motivo queda en la auditoría
+type IPAllowlistBypassRequest struct {
+	UserID          string `json:"userId,omitempty" validate:"omitempty,uuid"`
+	Reason          string `json:"reason" validate:"required,min=10,max=500"`
+	DurationMinutes int    `json:"durationMinutes" validate:"required,min=5,max=1440"`
+}
+
+// NetworkCheckRequest consulta qué reglas aplican a un usuario desde una IP
+type NetworkCheckRequest struct {
+	Email     string `json:"email" validate:"required,email"`
+	IPAddress string `json:"ipAddress" validate:"required,ip"`
+}
+
+// ===== ESTRUCTURAS PARA RESPONSES =====
+
+// NetworkDecision resultado de evaluar las reglas de red para un usuario y una IP
+type NetworkDecision struct {
+	Allowed   bool     `json:"allowed"`
+	IPAddress string   `json:"ipAddress"`
+	Enforced  []string `json:"enforced"`           // Alcances con reglas para el usuario: global, role, unit
+	Failed    []string `json:"failed,omitempty"`   // Alcances cuyos rangos no incluyen la IP
+	BypassID  *int     `json:"bypassId,omitempty"` // Excepción que permitió el acceso
+}
@@ -0,0 +1,195 @@
+// internal/database/models/oauth.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes OIDC soportados
+const (
+	OAuthScopeOpenID  = "openid"
+	OAuthScopeProfile = "profile"
+	OAuthScopeEmail   = "email"
+)
+
+// OAuthClient representa una aplicación registrada para el inicio de sesión único
+type OAuthClient struct {
+	ID               int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ClientID         string     `json:"clientId" gorm:"uniqueIndex;size:64;not null"`
+	ClientSecretHash *string    `json:"-" gorm:"size:64"` // Nunca exponer en JSON
+	Name             string     `json:"name" gorm:"size:150;not null"`
+	Description      string     `json:"description,omitempty"`
+	RedirectURIs     []string   `json:"redirectUris" gorm:"column:redirect_uris;type:jsonb;serializer:json"`
+	AllowedScopes    []string   `json:"allowedScopes" gorm:"type:jsonb;serializer:json"`
+	IsConfidential   bool       `json:"isConfidential" gorm:"default:true"`
+	IsActive         bool       `json:"isActive" gorm:"default:true"`
+	CreatedBy        *uuid.UUID `json:"createdBy,omitempty" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// HasRedirectURI verifica si la URI está registrada (comparación exacta)
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScope verifica si el cliente puede solicitar el scope
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	for _, allowed := range c.AllowedScopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthConsent representa los scopes que un usuario otorgó a un cliente
+type OAuthConsent struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uuid.UUID `json:"userId" gorm:"type:uuid;not null;index"`
+	ClientID  string    `json:"clientId" gorm:"size:64;not null"`
+	Scopes    []string  `json:"scopes" gorm:"type:jsonb;serializer:json"`
+	GrantedAt time.Time `json:"grantedAt" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relaciones
+	Client *OAuthClient `json:"client,omitempty" gorm:"foreignKey:ClientID;references:ClientID"`
+}
+
+// TableName especifica el nombre de la tabla
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+// OAuthClientCreateRequest solicitud de registro de cliente
+type OAuthClientCreateRequest struct {
+	Name           string   `json:"name" validate:"required,min=3,max=150"`
+	Description    string   `json:"description,omitempty" validate:"max=500"`
+	RedirectURIs   []string `json:"redirectUris" validate:"required,min=1,dive,url"`
+	AllowedScopes  []string `json:"allowedScopes,omitempty" validate:"omitempty,dive,oneof=openid profile email"`
+	IsConfidential *bool    `json:"isConfidential,omitempty"`
+}
+
+// OAuthClientUpdateRequest solicitud de actualización de cliente
+type OAuthClientUpdateRequest struct {
+	Name          *string  `json:"name,omitempty" validate:"omitempty,min=3,max=150"`
+	Description   *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	RedirectURIs  []string `json:"redirectUris,omitempty" validate:"omitempty,min=1,dive,url"`
+	AllowedScopes []string `json:"allowedScopes,omitempty" validate:"omitempty,dive,oneof=openid profile email"`
+	IsActive      *bool    `json:"isActive,omitempty"`
+}
+
+// OAuthAuthorizeRequest parámetros de autorización (RFC 6749 + PKCE RFC 7636)
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type" validate:"required"`
+	ClientID            string `json:"client_id" form:"client_id" validate:"required,max=64"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri" validate:"required,url"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state" validate:"max=500"`
+	Nonce               string `json:"nonce" form:"nonce" validate:"max=500"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+}
+
+// OAuthConsentDecision decisión del usuario en la pantalla de consentimiento
+type OAuthConsentDecision struct {
+	OAuthAuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+// OAuthTokenRequest solicitud al token endpoint (application/x-www-form-urlencoded)
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+// OAuthIntrospectionRequest solicitud al endpoint de introspección (RFC 7662)
+type OAuthIntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// ========================================
+// RESPONSES
+// ========================================
+
+// OAuthClientSecretResponse cliente con su secreto (solo al crear o rotar)
+type OAuthClientSecretResponse struct {
+	Client       *OAuthClient `json:"client"`
+	ClientSecret string       `json:"clientSecret,omitempty"`
+}
+
+// OAuthClientInfo datos públicos del cliente mostrados en la pantalla de consentimiento
+type OAuthClientInfo struct {
+	ClientID    string `json:"clientId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// OAuthAuthorizeResponse información para la pantalla de consentimiento
+type OAuthAuthorizeResponse struct {
+	Client          OAuthClientInfo `json:"client"`
+	Scopes          []string        `json:"scopes"`
+	ConsentRequired bool            `json:"consentRequired"`
+}
+
+// OAuthRedirectResponse URL a la que el frontend debe redirigir al navegador
+type OAuthRedirectResponse struct {
+	RedirectTo string `json:"redirectTo"`
+}
+
+// OAuthTokenResponse respuesta del token endpoint
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token,omitempty"`
+	Scope       string `json:"scope"`
+}
+
+// OAuthIntrospectionResponse respuesta del endpoint de introspección (RFC 7662).
+// Un token inactivo solo informa "active": false.
+type OAuthIntrospectionResponse struct {
+	Active               bool                   `json:"active"`
+	Scope                string                 `json:"scope,omitempty"`
+	ClientID             string                 `json:"client_id,omitempty"`
+	Username             string                 `json:"username,omitempty"`
+	TokenType            string                 `json:"token_type,omitempty"`
+	Exp                  int64                  `json:"exp,omitempty"`
+	Iat                  int64                  `json:"iat,omitempty"`
+	Sub                  string                 `json:"sub,omitempty"`
+	Iss                  string                 `json:"iss,omitempty"`
+	Jti                  string                 `json:"jti,omitempty"`
+	SessionID            string                 `json:"sid,omitempty"`
+	Role                 string                 `json:"role,omitempty"`
+	OrganizationalUnitID int                    `json:"organizationalUnitId,omitempty"`
+	Act                  *OAuthIntrospectionAct `json:"act,omitempty"`
+}
+
+// OAuthIntrospectionAct administrador que actúa en nombre del sujeto (suplantación)
+type OAuthIntrospectionAct struct {
+	Sub   string `json:"sub"`
+	Email string `json:"email,omitempty"`
+}
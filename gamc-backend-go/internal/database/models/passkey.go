@@ -0,0 +1,91 @@
+// internal/database/models/passkey.go
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gamc-backend-go/pkg/webauthn"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential passkey registrada por un usuario para iniciar sesión sin contraseña
+type WebAuthnCredential struct {
+	ID             int        `json:"id" gorm:"primaryKey"`
+	UserID         uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	CredentialID   string     `json:"-" gorm:"size:1400;uniqueIndex;not null"` // base64url
+	PublicKey      []byte     `json:"-" gorm:"not null"`                       // Clave COSE
+	Algorithm      int        `json:"algorithm"`
+	SignCount      int64      `json:"-" gorm:"not null;default:0"`
+	AAGUID         string     `json:"aaguid,omitempty" gorm:"column:aaguid;size:36"`
+	Transports     string     `json:"-" gorm:"size:100"` // Separados por coma
+	Name           string     `json:"name" gorm:"size:100;not null"`
+	BackupEligible bool       `json:"backupEligible"`
+	BackedUp       bool       `json:"backedUp"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP     string     `json:"lastUsedIp,omitempty" gorm:"column:last_used_ip;size:45"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy      *uuid.UUID `json:"revokedBy,omitempty" gorm:"type:uuid"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// IsRevoked indica si la passkey fue revocada
+func (c *WebAuthnCredential) IsRevoked() bool {
+	return c.RevokedAt != nil
+}
+
+// Descriptor credencial en el formato de allowCredentials / excludeCredentials
+func (c *WebAuthnCredential) Descriptor() webauthn.CredentialDescriptor {
+	descriptor := webauthn.CredentialDescriptor{Type: "public-key", ID: c.CredentialID}
+	if c.Transports != "" {
+		descriptor.Transports = strings.Split(c.Transports, ",")
+	}
+	return descriptor
+}
+
+// ===== ESTRUCTURAS PARA REQUESTS =====
+
+// PasskeyRegistrationRequest respuesta de navigator.credentials.create()
+type PasskeyRegistrationRequest struct {
+	CeremonyID string                        `json:"ceremonyId" validate:"required,len=64,token_hex"`
+	Name       string                        `json:"name" validate:"omitempty,max=100"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// PasskeyLoginOptionsRequest inicio del login con passkey. Sin email el navegador ofrece
+// las passkeys guardadas; con challengeToken la passkey es el segundo factor del login.
+type PasskeyLoginOptionsRequest struct {
+	Email          string `json:"email" validate:"omitempty,email"`
+	ChallengeToken string `json:"challengeToken" validate:"omitempty,len=64"`
+}
+
+// PasskeyLoginRequest respuesta de navigator.credentials.get()
+type PasskeyLoginRequest struct {
+	CeremonyID string                          `json:"ceremonyId" validate:"required,len=64,token_hex"`
+	Credential webauthn.AuthenticationResponse `json:"credential"`
+}
+
+// PasskeyRenameRequest nombre descriptivo de la passkey ("Tablet de despacho")
+type PasskeyRenameRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// ===== ESTRUCTURAS PARA RESPONSES =====
+
+// PasskeyRegistrationOptions opciones para navigator.credentials.create()
+type PasskeyRegistrationOptions struct {
+	CeremonyID string                    `json:"ceremonyId"`
+	PublicKey  *webauthn.CreationOptions `json:"publicKey"`
+}
+
+// PasskeyLoginOptions opciones para navigator.credentials.get()
+type PasskeyLoginOptions struct {
+	CeremonyID string                   `json:"ceremonyId"`
+	PublicKey  *webauthn.RequestOptions `json:"publicKey"`
+}
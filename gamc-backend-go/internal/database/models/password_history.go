@@ -0,0 +1,21 @@
+// internal/database/models/password_history.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistory representa una contraseña usada anteriormente por un usuario
+type PasswordHistory struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uuid.UUID `json:"userId" gorm:"type:uuid;not null;index"`
+	PasswordHash string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
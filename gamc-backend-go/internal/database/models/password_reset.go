@@ -25,6 +25,11 @@ type PasswordResetToken struct {
 	SecurityQuestionVerified bool `json:"securityQuestionVerified" gorm:"default:false"`
 	SecurityQuestionAttempts int  `json:"securityQuestionAttempts" gorm:"default:0"`
 
+	// Desafío: preguntas elegidas al azar, respondidas en orden, con intentos por pregunta
+	ChallengeQuestionIDs    []int       `json:"-" gorm:"column:challenge_question_ids;type:jsonb;serializer:json"`
+	SecurityAnswersVerified int         `json:"securityAnswersVerified" gorm:"default:0"`
+	QuestionAttempts        map[int]int `json:"-" gorm:"column:question_attempts;type:jsonb;serializer:json"`
+
 	// Metadatos para auditoría
 	EmailSentAt   *time.Time `json:"emailSentAt,omitempty"`
 	EmailOpenedAt *time.Time `json:"emailOpenedAt,omitempty"`
@@ -42,7 +47,7 @@ type PasswordResetRequest struct {
 // PasswordResetConfirm representa la confirmación del reset
 type PasswordResetConfirm struct {
 	Token              string  `json:"token" validate:"required,min=64,max=64"`
-	NewPassword        string  `json:"newPassword" validate:"required,min=8"`
+	NewPassword        string  `json:"newPassword" validate:"required"`
 	SecurityQuestionID *int    `json:"securityQuestionId,omitempty"`
 	SecurityAnswer     *string `json:"securityAnswer,omitempty"`
 }
@@ -84,6 +89,87 @@ type PasswordResetVerifySecurityResponse struct {
 	CanProceedToReset bool   `json:"canProceedToReset"`
 	AttemptsRemaining int    `json:"attemptsRemaining"`
 	ResetToken        string `json:"resetToken,omitempty"` // NUEVO: Solo se devuelve si la verificación es exitosa
+
+	// Siguiente pregunta cuando la política exige más de una respuesta correcta
+	NextQuestion *SecurityQuestionForResetResponse `json:"nextQuestion,omitempty"`
+}
+
+// ===== AUDITORÍA DE RESETS =====
+
+// Resultados de una solicitud de reset en la auditoría
+const (
+	ResetOutcomeCompleted              = "completed"                // Contraseña restablecida
+	ResetOutcomeFailedSecurityQuestion = "failed_security_question" // Agotó los intentos de las preguntas
+	ResetOutcomeExpired                = "expired"                  // Venció sin usarse
+	ResetOutcomePending                = "pending"                  // Aún vigente
+)
+
+// ResetOutcomes resultados válidos para filtrar la auditoría
+var ResetOutcomes = []string{ResetOutcomeCompleted, ResetOutcomeFailedSecurityQuestion, ResetOutcomeExpired, ResetOutcomePending}
+
+// Tipos de anomalía del reporte de resets
+const (
+	ResetAnomalyIPManyAccounts = "ip_many_accounts" // Una IP pidió resets para muchas cuentas
+	ResetAnomalyAccountManyIPs = "account_many_ips" // Una cuenta recibió resets desde muchas IPs
+)
+
+// PasswordResetAuditEntry solicitud de reset con el usuario, su unidad y el resultado
+type PasswordResetAuditEntry struct {
+	ID                       int64      `json:"id"`
+	UserID                   uuid.UUID  `json:"userId"`
+	Email                    string     `json:"email"`
+	FirstName                string     `json:"firstName"`
+	LastName                 string     `json:"lastName"`
+	OrganizationalUnitID     *int       `json:"organizationalUnitId,omitempty"`
+	UnitName                 string     `json:"unitName,omitempty"`
+	RequestIP                string     `json:"requestIp" gorm:"column:ip_address"`
+	UserAgent                string     `json:"userAgent,omitempty"`
+	RequiresSecurityQuestion bool       `json:"requiresSecurityQuestion"`
+	SecurityQuestionAttempts int        `json:"securityQuestionAttempts"`
+	Outcome                  string     `json:"outcome"`
+	CreatedAt                time.Time  `json:"createdAt"`
+	ExpiresAt                time.Time  `json:"expiresAt"`
+	UsedAt                   *time.Time `json:"usedAt,omitempty"`
+}
+
+// PasswordResetAuditBucket solicitudes de un día, unidad, resultado o IP
+type PasswordResetAuditBucket struct {
+	Key                    string `json:"key"`
+	Label                  string `json:"label"`
+	Total                  int64  `json:"total"`
+	Accounts               int64  `json:"accounts"` // Cuentas distintas
+	Completed              int64  `json:"completed"`
+	FailedSecurityQuestion int64  `json:"failedSecurityQuestion"`
+	Expired                int64  `json:"expired"`
+	Pending                int64  `json:"pending"`
+}
+
+// PasswordResetAuditAnomaly patrón de abuso detectado en el período
+type PasswordResetAuditAnomaly struct {
+	Type                   string     `json:"type"`
+	IPAddress              string     `json:"ipAddress,omitempty"`
+	UserID                 *uuid.UUID `json:"userId,omitempty"`
+	Email                  string     `json:"email,omitempty"`
+	Requests               int64      `json:"requests"`
+	Accounts               int64      `json:"accounts"`
+	IPs                    int64      `json:"ips"`
+	FailedSecurityQuestion int64      `json:"failedSecurityQuestion"`
+	FirstSeen              time.Time  `json:"firstSeen"`
+	LastSeen               time.Time  `json:"lastSeen"`
+	Description            string     `json:"description"`
+}
+
+// PasswordResetAuditReport tablero de auditoría de resets de contraseña
+type PasswordResetAuditReport struct {
+	From        time.Time                   `json:"from"`
+	To          time.Time                   `json:"to"`
+	Totals      PasswordResetAuditBucket    `json:"totals"`
+	ByDay       []PasswordResetAuditBucket  `json:"byDay"`
+	ByUnit      []PasswordResetAuditBucket  `json:"byUnit"`
+	ByOutcome   []PasswordResetAuditBucket  `json:"byOutcome"`
+	ByIP        []PasswordResetAuditBucket  `json:"byIp"` // IPs con más solicitudes
+	Anomalies   []PasswordResetAuditAnomaly `json:"anomalies"`
+	GeneratedAt time.Time                   `json:"generatedAt"`
 }
 
 // TableName especifica el nombre de la tabla
@@ -137,20 +223,54 @@ func (prt *PasswordResetToken) CanProceedToPasswordReset() bool {
 	return true
 }
 
-// HasExceededSecurityAttempts verifica si excedió intentos de seguridad
+// HasExceededSecurityAttempts verifica si se agotaron los intentos de alguna pregunta.
+// Los tokens sin desafío (anteriores a las preguntas aleatorias) usan el contador total.
 func (prt *PasswordResetToken) HasExceededSecurityAttempts() bool {
-	return prt.SecurityQuestionAttempts >= MaxSecurityQuestionAttempts
+	if len(prt.ChallengeQuestionIDs) == 0 {
+		return prt.SecurityQuestionAttempts >= MaxSecurityQuestionAttempts
+	}
+	for _, attempts := range prt.QuestionAttempts {
+		if attempts >= MaxSecurityQuestionAttempts {
+			return true
+		}
+	}
+	return false
 }
 
-// GetSecurityAttemptsRemaining obtiene intentos restantes de pregunta de seguridad
+// GetSecurityAttemptsRemaining obtiene intentos restantes de la pregunta en curso
 func (prt *PasswordResetToken) GetSecurityAttemptsRemaining() int {
-	remaining := MaxSecurityQuestionAttempts - prt.SecurityQuestionAttempts
+	attempts := prt.SecurityQuestionAttempts
+	if questionID, ok := prt.CurrentChallengeQuestionID(); ok {
+		attempts = prt.QuestionAttempts[questionID]
+	}
+	remaining := MaxSecurityQuestionAttempts - attempts
 	if remaining < 0 {
 		return 0
 	}
 	return remaining
 }
 
+// CurrentChallengeQuestionID pregunta que debe responderse a continuación
+func (prt *PasswordResetToken) CurrentChallengeQuestionID() (int, bool) {
+	if prt.SecurityAnswersVerified >= len(prt.ChallengeQuestionIDs) {
+		return 0, false
+	}
+	return prt.ChallengeQuestionIDs[prt.SecurityAnswersVerified], true
+}
+
+// ChallengeStep posición (desde 1) de la pregunta en curso y total de preguntas del desafío
+func (prt *PasswordResetToken) ChallengeStep() (int, int) {
+	total := len(prt.ChallengeQuestionIDs)
+	if total == 0 {
+		return 1, 1
+	}
+	step := prt.SecurityAnswersVerified + 1
+	if step > total {
+		step = total
+	}
+	return step, total
+}
+
 // ===== MÉTODOS DE ACTUALIZACIÓN =====
 
 // MarkAsUsed marca el token como utilizado
@@ -180,6 +300,23 @@ func (prt *PasswordResetToken) IncrementSecurityAttempts() {
 	}
 }
 
+// RecordWrongAnswer registra una respuesta incorrecta para la pregunta indicada
+func (prt *PasswordResetToken) RecordWrongAnswer(questionID int) {
+	if prt.QuestionAttempts == nil {
+		prt.QuestionAttempts = make(map[int]int)
+	}
+	prt.QuestionAttempts[questionID]++
+	prt.IncrementSecurityAttempts()
+}
+
+// RecordCorrectAnswer avanza el desafío; queda verificado al responder todas las preguntas
+func (prt *PasswordResetToken) RecordCorrectAnswer() {
+	prt.SecurityAnswersVerified++
+	if prt.SecurityAnswersVerified >= len(prt.ChallengeQuestionIDs) {
+		prt.MarkSecurityQuestionVerified()
+	}
+}
+
 // MarkSecurityQuestionVerified marca la pregunta de seguridad como verificada
 func (prt *PasswordResetToken) MarkSecurityQuestionVerified() {
 	prt.SecurityQuestionVerified = true
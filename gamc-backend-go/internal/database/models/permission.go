@@ -0,0 +1,218 @@
+// internal/database/models/permission.go
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permisos verificados por el código (<recurso>:<acción>); el catálogo vive en la tabla permissions
+const (
+	PermissionMessagesRead    = "messages:read"
+	PermissionMessagesSend    = "messages:send"
+	PermissionMessagesManage  = "messages:manage"
+	PermissionFilesRead       = "files:read"
+	PermissionFilesUpload     = "files:upload"
+	PermissionReportsRead     = "reports:read"
+	PermissionReportsGenerate = "reports:generate"
+	PermissionDashboardRead   = "dashboard:read"
+	PermissionAuditRead       = "audit:read"
+	PermissionUsersRead       = "users:read"
+	PermissionUsersManage     = "users:manage"
+	PermissionRolesManage     = "roles:manage"
+	PermissionSecurityManage  = "security:manage"
+)
+
+// Alcance del rol cuando es el rol base del usuario (users.role)
+const (
+	RoleScopeGlobal = "global"
+	RoleScopeUnit   = "unit"
+)
+
+// Permission entrada del catálogo de permisos
+type Permission struct {
+	Code        string    `json:"code" gorm:"primaryKey;size:50"`
+	Category    string    `json:"category" gorm:"size:50;not null"`
+	Description string    `json:"description" gorm:"not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role agrupa permisos; los roles de sistema corresponden a users.role
+type Role struct {
+	ID          int          `json:"id" gorm:"primaryKey"`
+	Code        string       `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name        string       `json:"name" gorm:"size:100;not null"`
+	Description string       `json:"description"`
+	IsSystem    bool         `json:"isSystem" gorm:"not null"`
+	BaseScope   string       `json:"baseScope" gorm:"size:10;not null;default:unit"`
+	CreatedBy   *uuid.UUID   `json:"createdBy,omitempty" gorm:"type:uuid"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;joinForeignKey:RoleID;joinReferences:PermissionCode"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionCodes retorna los códigos de permiso del rol
+func (r *Role) PermissionCodes() []string {
+	codes := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		codes = append(codes, permission.Code)
+	}
+	return codes
+}
+
+// UserRoleAssignment rol adicional de un usuario, global o limitado a una unidad
+type UserRoleAssignment struct {
+	ID                   int        `json:"id" gorm:"primaryKey"`
+	UserID               uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	RoleID               int        `json:"roleId" gorm:"not null"`
+	OrganizationalUnitID *int       `json:"organizationalUnitId"` // nil = todas las unidades
+	ExpiresAt            *time.Time `json:"expiresAt"`
+	GrantedBy            *uuid.UUID `json:"grantedBy,omitempty" gorm:"type:uuid"`
+	CreatedAt            time.Time  `json:"createdAt"`
+
+	// Relaciones
+	Role               *Role               `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	OrganizationalUnit *OrganizationalUnit `json:"organizationalUnit,omitempty" gorm:"foreignKey:OrganizationalUnitID"`
+}
+
+// TableName especifica el nombre de la tabla
+func (UserRoleAssignment) TableName() string {
+	return "user_role_assignments"
+}
+
+// IsExpired verifica si la asignación ya venció
+func (a *UserRoleAssignment) IsExpired() bool {
+	return a.ExpiresAt != nil && time.Now().After(*a.ExpiresAt)
+}
+
+// ========================================
+// PERMISOS EFECTIVOS
+// ========================================
+
+// EffectivePermissions permisos resueltos de un usuario: globales y por unidad
+type EffectivePermissions struct {
+	Global map[string]bool         `json:"-"`
+	Units  map[int]map[string]bool `json:"-"`
+}
+
+// NewEffectivePermissions crea un conjunto vacío
+func NewEffectivePermissions() *EffectivePermissions {
+	return &EffectivePermissions{
+		Global: make(map[string]bool),
+		Units:  make(map[int]map[string]bool),
+	}
+}
+
+// Grant agrega permisos globales (unitID nil) o para una unidad
+func (p *EffectivePermissions) Grant(unitID *int, codes ...string) {
+	target := p.Global
+	if unitID != nil {
+		if p.Units[*unitID] == nil {
+			p.Units[*unitID] = make(map[string]bool)
+		}
+		target = p.Units[*unitID]
+	}
+	for _, code := range codes {
+		target[code] = true
+	}
+}
+
+// Can verifica el permiso sobre una unidad (los permisos globales aplican a todas)
+func (p *EffectivePermissions) Can(permission string, unitID int) bool {
+	return p.Global[permission] || p.Units[unitID][permission]
+}
+
+// HasAnywhere verifica si el usuario tiene el permiso en alguna unidad
+func (p *EffectivePermissions) HasAnywhere(permission string) bool {
+	if p.Global[permission] {
+		return true
+	}
+	for _, codes := range p.Units {
+		if codes[permission] {
+			return true
+		}
+	}
+	return false
+}
+
+// UnitsWith retorna las unidades donde el usuario tiene el permiso (nil si lo tiene globalmente)
+func (p *EffectivePermissions) UnitsWith(permission string) []int {
+	if p.Global[permission] {
+		return nil
+	}
+	units := []int{}
+	for unitID, codes := range p.Units {
+		if codes[permission] {
+			units = append(units, unitID)
+		}
+	}
+	sort.Ints(units)
+	return units
+}
+
+// ToResponse convierte el conjunto a su representación JSON
+func (p *EffectivePermissions) ToResponse() *EffectivePermissionsResponse {
+	resp := &EffectivePermissionsResponse{
+		Global: sortedCodes(p.Global),
+		Units:  make(map[int][]string, len(p.Units)),
+	}
+	for unitID, codes := range p.Units {
+		resp.Units[unitID] = sortedCodes(codes)
+	}
+	return resp
+}
+
+// sortedCodes retorna las claves del conjunto ordenadas
+func sortedCodes(set map[string]bool) []string {
+	codes := make([]string, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ========================================
+// REQUESTS Y RESPONSES
+// ========================================
+
+// RoleCreateRequest datos para crear un rol
+type RoleCreateRequest struct {
+	Code        string   `json:"code" validate:"required,min=3,max=50"`
+	Name        string   `json:"name" validate:"required,min=3,max=100"`
+	Description string   `json:"description" validate:"max=500"`
+	BaseScope   string   `json:"baseScope" validate:"omitempty,oneof=global unit"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+// RoleUpdateRequest datos para editar un rol (el código no cambia)
+type RoleUpdateRequest struct {
+	Name        string   `json:"name" validate:"required,min=3,max=100"`
+	Description string   `json:"description" validate:"max=500"`
+	Permissions []string `json:"permissions" validate:"required,dive,required"`
+}
+
+// RoleAssignmentRequest asigna un rol a un usuario, opcionalmente limitado a una unidad
+type RoleAssignmentRequest struct {
+	RoleCode             string     `json:"roleCode" validate:"required"`
+	OrganizationalUnitID *int       `json:"organizationalUnitId" validate:"omitempty,gt=0"`
+	ExpiresAt            *time.Time `json:"expiresAt"`
+}
+
+// EffectivePermissionsResponse permisos efectivos de un usuario
+type EffectivePermissionsResponse struct {
+	Global []string         `json:"global"`
+	Units  map[int][]string `json:"units"`
+}
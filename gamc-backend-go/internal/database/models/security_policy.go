@@ -0,0 +1,124 @@
+// internal/database/models/security_policy.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityPolicyID identificador de la única fila de política
+const SecurityPolicyID = 1
+
+// SecurityPolicy representa la política de seguridad vigente (fila única)
+type SecurityPolicy struct {
+	ID int `json:"id" gorm:"primaryKey"`
+
+	// Contraseñas
+	MinPasswordLength         int  `json:"minPasswordLength" gorm:"not null"`
+	RequireUppercase          bool `json:"requireUppercase" gorm:"not null"`
+	RequireLowercase          bool `json:"requireLowercase" gorm:"not null"`
+	RequireNumbers            bool `json:"requireNumbers" gorm:"not null"`
+	RequireSpecialChars       bool `json:"requireSpecialChars" gorm:"not null"`
+	BlockCommonPasswords      bool `json:"blockCommonPasswords" gorm:"not null"`
+	BlockSequentialChars      bool `json:"blockSequentialChars" gorm:"not null"`
+	PasswordHistoryCount      int  `json:"passwordHistoryCount" gorm:"not null"`
+	PasswordExpirationDays    int  `json:"passwordExpirationDays" gorm:"not null"`
+	PasswordExpiryWarningDays int  `json:"passwordExpiryWarningDays" gorm:"not null"`
+
+	// Reset por preguntas de seguridad: respuestas correctas exigidas (1 o 2)
+	ResetSecurityAnswersRequired int `json:"resetSecurityAnswersRequired" gorm:"not null;default:1"`
+
+	// Login y sesiones
+	MaxLoginAttempts       int  `json:"maxLoginAttempts" gorm:"not null"`
+	LockoutDurationMinutes int  `json:"lockoutDurationMinutes" gorm:"not null"`
+	SessionTimeoutMinutes  int  `json:"sessionTimeoutMinutes" gorm:"not null"`
+	TwoFactorRequired      bool `json:"twoFactorRequired" gorm:"not null"`
+	// Inactividad máxima por rol en minutos; los roles ausentes usan SessionTimeoutMinutes
+	RoleSessionTimeouts map[string]int `json:"roleSessionTimeouts" gorm:"column:role_session_timeouts;type:jsonb;serializer:json"`
+	// Vigencia máxima de la sesión desde el login, aunque haya actividad
+	SessionMaxLifetimeHours int      `json:"sessionMaxLifetimeHours" gorm:"not null;default:12"`
+	IPWhitelist             []string `json:"ipWhitelist" gorm:"column:ip_whitelist;type:jsonb;serializer:json"`
+
+	UpdatedBy *uuid.UUID `json:"updatedBy,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (SecurityPolicy) TableName() string {
+	return "security_policies"
+}
+
+// SessionTimeout tiempo de inactividad tras el cual expira la sesión
+func (p *SecurityPolicy) SessionTimeout() time.Duration {
+	return time.Duration(p.SessionTimeoutMinutes) * time.Minute
+}
+
+// IdleTimeoutFor tiempo de inactividad tras el cual expiran las sesiones del rol
+func (p *SecurityPolicy) IdleTimeoutFor(role string) time.Duration {
+	if minutes, ok := p.RoleSessionTimeouts[role]; ok && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return p.SessionTimeout()
+}
+
+// SessionMaxLifetime vigencia máxima de una sesión desde el login
+func (p *SecurityPolicy) SessionMaxLifetime() time.Duration {
+	return time.Duration(p.SessionMaxLifetimeHours) * time.Hour
+}
+
+// RequiredSecurityAnswers respuestas exigidas en el desafío de reset, entre 1 y MaxResetSecurityAnswers
+func (p *SecurityPolicy) RequiredSecurityAnswers() int {
+	required := p.ResetSecurityAnswersRequired
+	if required < 1 {
+		required = 1
+	}
+	if required > MaxResetSecurityAnswers {
+		required = MaxResetSecurityAnswers
+	}
+	return required
+}
+
+// LockoutDuration duración del primer bloqueo por intentos fallidos
+func (p *SecurityPolicy) LockoutDuration() time.Duration {
+	return time.Duration(p.LockoutDurationMinutes) * time.Minute
+}
+
+// ToPublic retorna solo las reglas de contraseña, seguras de exponer sin autenticación
+func (p *SecurityPolicy) ToPublic() *PasswordPolicyResponse {
+	return &PasswordPolicyResponse{
+		MinLength:              p.MinPasswordLength,
+		MaxLength:              PasswordMaxLength,
+		RequireUppercase:       p.RequireUppercase,
+		RequireLowercase:       p.RequireLowercase,
+		RequireNumbers:         p.RequireNumbers,
+		RequireSpecialChars:    p.RequireSpecialChars,
+		AllowedSpecialChars:    PasswordSpecialChars,
+		BlockCommonPasswords:   p.BlockCommonPasswords,
+		BlockSequentialChars:   p.BlockSequentialChars,
+		PasswordHistoryCount:   p.PasswordHistoryCount,
+		PasswordExpirationDays: p.PasswordExpirationDays,
+	}
+}
+
+// Límites fijos de contraseñas (no editables)
+const (
+	PasswordMaxLength    = 128
+	PasswordSpecialChars = "@$!%*?&"
+)
+
+// PasswordPolicyResponse reglas de contraseña publicadas para el frontend
+type PasswordPolicyResponse struct {
+	MinLength              int    `json:"minLength"`
+	MaxLength              int    `json:"maxLength"`
+	RequireUppercase       bool   `json:"requireUppercase"`
+	RequireLowercase       bool   `json:"requireLowercase"`
+	RequireNumbers         bool   `json:"requireNumbers"`
+	RequireSpecialChars    bool   `json:"requireSpecialChars"`
+	AllowedSpecialChars    string `json:"allowedSpecialChars"`
+	BlockCommonPasswords   bool   `json:"blockCommonPasswords"`
+	BlockSequentialChars   bool   `json:"blockSequentialChars"`
+	PasswordHistoryCount   int    `json:"passwordHistoryCount"`
+	PasswordExpirationDays int    `json:"passwordExpirationDays"`
+}
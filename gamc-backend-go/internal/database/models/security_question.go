@@ -4,21 +4,28 @@ package models
 // ===== IMPORTS NECESARIOS =====
 import (
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 // SecurityQuestion representa una pregunta de seguridad predefinida
 type SecurityQuestion struct {
-	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	QuestionText string    `json:"questionText" gorm:"type:text;not null"`
-	Category     string    `json:"category" gorm:"size:50;default:'general'"`
-	IsActive     bool      `json:"isActive" gorm:"default:true;index"`
-	SortOrder    int       `json:"sortOrder" gorm:"default:0"`
-	CreatedAt    time.Time `json:"createdAt"`
+	ID            int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	QuestionText  string     `json:"questionText" gorm:"type:text;not null"`
+	Category      string     `json:"category" gorm:"size:50;default:'general'"`
+	IsActive      bool       `json:"isActive" gorm:"default:true;index"`
+	SortOrder     int        `json:"sortOrder" gorm:"default:0"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	DeactivatedBy *uuid.UUID `json:"deactivatedBy,omitempty" gorm:"type:uuid"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 // UserSecurityQuestion representa la respuesta de seguridad de un usuario
@@ -62,6 +69,27 @@ type SecurityQuestionVerifyRequest struct {
 	Answer     string `json:"answer" validate:"required,min=1,max=100"`
 }
 
+// SecurityQuestionCreateRequest alta de una pregunta en el catálogo (administradores)
+type SecurityQuestionCreateRequest struct {
+	QuestionText string `json:"questionText" validate:"required,min=10,max=255"`
+	Category     string `json:"category" validate:"required,oneof=personal education professional preferences general"`
+	SortOrder    *int   `json:"sortOrder,omitempty" validate:"omitempty,min=0"`
+}
+
+// SecurityQuestionEditRequest edición de una pregunta del catálogo; isActive=false la retira
+type SecurityQuestionEditRequest struct {
+	QuestionText *string `json:"questionText,omitempty" validate:"omitempty,min=10,max=255"`
+	Category     *string `json:"category,omitempty" validate:"omitempty,oneof=personal education professional preferences general"`
+	SortOrder    *int    `json:"sortOrder,omitempty" validate:"omitempty,min=0"`
+	IsActive     *bool   `json:"isActive,omitempty"`
+}
+
+// SecurityQuestionReorderRequest nuevo orden de las preguntas (todas o las de una categoría)
+type SecurityQuestionReorderRequest struct {
+	Category    string `json:"category,omitempty" validate:"omitempty,oneof=personal education professional preferences general"`
+	QuestionIDs []int  `json:"questionIds" validate:"required,min=1,dive,min=1"`
+}
+
 // ===== ESTRUCTURAS PARA RESPONSES =====
 
 // SecurityQuestionResponse para respuestas de API
@@ -86,6 +114,9 @@ type UserSecurityStatusResponse struct {
 	HasSecurityQuestions bool                           `json:"hasSecurityQuestions"`
 	QuestionsCount       int                            `json:"questionsCount"`
 	MaxQuestions         int                            `json:"maxQuestions"`
+	RequiredQuestions    int                            `json:"requiredQuestions"`
+	RetiredQuestions     []SecurityQuestionResponse     `json:"retiredQuestions,omitempty"` // Desactivadas del catálogo: debe reconfigurarlas
+	NeedsReconfiguration bool                           `json:"needsReconfiguration"`
 	Questions            []UserSecurityQuestionResponse `json:"questions"`
 	AvailableQuestions   []SecurityQuestionResponse     `json:"availableQuestions,omitempty"`
 }
@@ -96,6 +127,45 @@ type SecurityQuestionForResetResponse struct {
 	QuestionText string `json:"questionText"`
 	Attempts     int    `json:"attempts"`
 	MaxAttempts  int    `json:"maxAttempts"`
+	Step         int    `json:"step"`       // Pregunta en curso (desde 1)
+	TotalSteps   int    `json:"totalSteps"` // Preguntas a responder en el desafío
+}
+
+// SecurityQuestionAdoption uso de una pregunta del catálogo
+type SecurityQuestionAdoption struct {
+	QuestionID     int     `json:"questionId"`
+	QuestionText   string  `json:"questionText"`
+	Category       string  `json:"category"`
+	IsActive       bool    `json:"isActive"`
+	Users          int64   `json:"users"`          // Usuarios con respuesta vigente
+	AdoptionRate   float64 `json:"adoptionRate"`   // Porcentaje de los usuarios elegibles
+	Challenges     int64   `json:"challenges"`     // Veces que se preguntó en un reset del período
+	FailedAttempts int64   `json:"failedAttempts"` // Respuestas incorrectas en el período
+	FailureRate    float64 `json:"failureRate"`    // Respuestas incorrectas por cada vez que se preguntó (%)
+}
+
+// SecurityQuestionResetStats resultado de las preguntas en los resets del período
+type SecurityQuestionResetStats struct {
+	PeriodDays            int     `json:"periodDays"`
+	Challenges            int64   `json:"challenges"`            // Resets que exigieron preguntas
+	Verified              int64   `json:"verified"`              // Desafíos completados
+	WithFailures          int64   `json:"withFailures"`          // Desafíos con al menos una respuesta incorrecta
+	LockedOut             int64   `json:"lockedOut"`             // Desafíos que agotaron los intentos
+	FailedAttempts        int64   `json:"failedAttempts"`        // Suma de security_question_attempts
+	AverageFailedAttempts float64 `json:"averageFailedAttempts"` // Por desafío
+	FailureRate           float64 `json:"failureRate"`           // Desafíos con fallos (%)
+	LockoutRate           float64 `json:"lockoutRate"`           // Desafíos bloqueados (%)
+}
+
+// SecurityQuestionStatsResponse estadísticas del catálogo de preguntas de seguridad
+type SecurityQuestionStatsResponse struct {
+	EligibleUsers         int64                      `json:"eligibleUsers"` // Usuarios activos, sin cuentas de servicio
+	RequiredQuestions     int                        `json:"requiredQuestions"`
+	UsersBelowRequired    int64                      `json:"usersBelowRequired"`    // Con menos preguntas que las exigidas en el reset
+	UsersWithoutQuestions int64                      `json:"usersWithoutQuestions"` // Incluidos en usersBelowRequired
+	Questions             []SecurityQuestionAdoption `json:"questions"`
+	Resets                SecurityQuestionResetStats `json:"resets"`
+	GeneratedAt           time.Time                  `json:"generatedAt"`
 }
 
 // ===== MÉTODOS DE LA TABLA =====
@@ -166,8 +236,11 @@ const (
 	// MaxSecurityQuestionAnswer longitud máxima de respuesta
 	MaxSecurityQuestionAnswer = 100
 
-	// MaxSecurityQuestionAttempts intentos máximos para verificación
+	// MaxSecurityQuestionAttempts intentos máximos por pregunta durante un reset
 	MaxSecurityQuestionAttempts = 3
+
+	// MaxResetSecurityAnswers preguntas que la política puede exigir responder en un reset
+	MaxResetSecurityAnswers = 2
 )
 
 // ===== FUNCIONES DE VALIDACIÓN =====
@@ -252,18 +325,21 @@ func isRepeatedCharacters(s string) bool {
 
 // ===== FUNCIONES DE UTILIDAD =====
 
-// NormalizeSecurityAnswer normaliza una respuesta de seguridad
+// NormalizeSecurityAnswer normaliza una respuesta de seguridad para compararla:
+// sin acentos ni diacríticos (NFD y eliminación de marcas), sin distinción de
+// mayúsculas (case folding) y con los espacios colapsados. "  José  MARÍA" y
+// "jose maria" producen el mismo resultado.
 func NormalizeSecurityAnswer(answer string) string {
-	// Convertir a minúsculas, eliminar espacios extra
-	normalized := strings.ToLower(strings.TrimSpace(answer))
-
-	// Reemplazar múltiples espacios por uno solo
-	normalized = regexp.MustCompile(`\s+`).ReplaceAllString(normalized, " ")
+	// Los transformadores mantienen estado: se crean en cada llamada
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(stripMarks, answer)
+	if err != nil {
+		normalized = answer
+	}
 
-	// Remover acentos básicos (opcional - implementar si es necesario)
-	// normalized = removeAccents(normalized)
+	normalized = cases.Fold().String(normalized)
 
-	return normalized
+	return strings.Join(strings.Fields(normalized), " ")
 }
 
 // GetSecurityQuestionCategories retorna las categorías disponibles
@@ -0,0 +1,15 @@
+// internal/database/models/session.go
+package models
+
+import "time"
+
+// SessionInfo representa una sesión activa del usuario (almacenada en Redis)
+type SessionInfo struct {
+	SessionID    string    `json:"sessionId"`
+	Device       string    `json:"device"`
+	IPAddress    string    `json:"ipAddress,omitempty"`
+	UserAgent    string    `json:"userAgent,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActivity time.Time `json:"lastActivity"`
+	IsCurrent    bool      `json:"isCurrent"`
+}
@@ -0,0 +1,110 @@
+// internal/database/models/two_factor.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor representa la configuración TOTP de un usuario
+type UserTwoFactor struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uuid.UUID  `json:"userId" gorm:"type:uuid;uniqueIndex;not null"`
+	Secret       string     `json:"-" gorm:"size:64;not null"` // Nunca exponer en JSON
+	IsEnabled    bool       `json:"isEnabled" gorm:"default:false"`
+	ConfirmedAt  *time.Time `json:"confirmedAt,omitempty"`
+	LastUsedStep int64      `json:"-" gorm:"default:0"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+
+	// Relaciones
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica el nombre de la tabla
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// UserRecoveryCode representa un código de recuperación de 2FA (hash, un solo uso)
+type UserRecoveryCode struct {
+	ID        int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// IsUsed verifica si el código ya fue utilizado
+func (c *UserRecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// RoleTwoFactorRequirement indica si un rol debe usar 2FA
+type RoleTwoFactorRequirement struct {
+	Role      string     `json:"role" gorm:"primaryKey;size:20"`
+	Required  bool       `json:"required" gorm:"default:false"`
+	UpdatedBy *uuid.UUID `json:"updatedBy,omitempty" gorm:"type:uuid"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla
+func (RoleTwoFactorRequirement) TableName() string {
+	return "role_two_factor_requirements"
+}
+
+// ===== ESTRUCTURAS PARA REQUESTS =====
+
+// TwoFactorCodeRequest código TOTP o de recuperación
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=20"`
+}
+
+// TwoFactorLoginRequest segundo paso del login con 2FA
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required,len=64"`
+	Code           string `json:"code" validate:"required,min=6,max=20"`
+}
+
+// TwoFactorDisableRequest para desactivar 2FA (requiere contraseña y código)
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=20"`
+}
+
+// RoleTwoFactorUpdateRequest para cambiar el requerimiento de un rol
+type RoleTwoFactorUpdateRequest struct {
+	Role     string `json:"role" validate:"required,oneof=admin input output"`
+	Required bool   `json:"required"`
+}
+
+// ===== ESTRUCTURAS PARA RESPONSES =====
+
+// TwoFactorSetupResponse datos para enrolar la app autenticadora
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+	Issuer          string `json:"issuer"`
+	AccountName     string `json:"accountName"`
+}
+
+// TwoFactorConfirmResponse respuesta al confirmar el enrolamiento
+type TwoFactorConfirmResponse struct {
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorStatusResponse estado de 2FA del usuario
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	ConfirmedAt            *time.Time `json:"confirmedAt,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+	Passkeys               int64      `json:"passkeys"`
+}
@@ -10,19 +10,30 @@ import (
 
 // User representa un usuario del sistema
 type User struct {
-	ID                   uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Username             string     `json:"username" gorm:"uniqueIndex;size:50;not null"`
-	Email                string     `json:"email" gorm:"uniqueIndex;size:100;not null"`
-	PasswordHash         string     `json:"-" gorm:"size:255;not null"`
-	FirstName            string     `json:"firstName" gorm:"size:50;not null"`
-	LastName             string     `json:"lastName" gorm:"size:50;not null"`
-	Role                 string     `json:"role" gorm:"size:20;not null;check:role IN ('admin','input','output')"`
-	OrganizationalUnitID *int       `json:"organizationalUnitId" gorm:"index"`
-	IsActive             bool       `json:"isActive" gorm:"default:true"`
-	LastLogin            *time.Time `json:"lastLogin"`
-	PasswordChangedAt    time.Time  `json:"passwordChangedAt" gorm:"default:CURRENT_TIMESTAMP"`
-	CreatedAt            time.Time  `json:"createdAt"`
-	UpdatedAt            time.Time  `json:"updatedAt"`
+	ID                      uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Username                string     `json:"username" gorm:"uniqueIndex;size:50;not null"`
+	Email                   string     `json:"email" gorm:"uniqueIndex;size:100;not null"`
+	PasswordHash            string     `json:"-" gorm:"size:255;not null"`
+	FirstName               string     `json:"firstName" gorm:"size:50;not null"`
+	LastName                string     `json:"lastName" gorm:"size:50;not null"`
+	Role                    string     `json:"role" gorm:"size:20;not null;check:role IN ('admin','input','output')"`
+	OrganizationalUnitID    *int       `json:"organizationalUnitId" gorm:"index"`
+	IsActive                bool       `json:"isActive" gorm:"default:true"`
+	LastLogin               *time.Time `json:"lastLogin"`
+	PasswordChangedAt       time.Time  `json:"passwordChangedAt" gorm:"default:CURRENT_TIMESTAMP"`
+	PasswordExpiryWarnedAt  *time.Time `json:"-"`
+	IsServiceAccount        bool       `json:"isServiceAccount" gorm:"default:false"`
+	Description             string     `json:"description,omitempty"`
+	AuthSource              string     `json:"authSource" gorm:"size:20;not null;default:local"`
+	ExternalID              string     `json:"-" gorm:"size:255"`
+	DirectorySyncedAt       *time.Time `json:"-"`
+	DirectoryDeactivatedAt  *time.Time `json:"-"`
+	EmailVerifiedAt         *time.Time `json:"emailVerifiedAt"`
+	PasswordResetRequiredAt *time.Time `json:"-"`
+	MustChangePassword      bool       `json:"mustChangePassword" gorm:"not null;default:false"`
+	ExpiresAt               *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt               time.Time  `json:"createdAt"`
+	UpdatedAt               time.Time  `json:"updatedAt"`
 
 	// Relaciones
 	OrganizationalUnit  *OrganizationalUnit    `json:"organizationalUnit,omitempty" gorm:"foreignKey:OrganizationalUnitID"`
@@ -30,6 +41,27 @@ type User struct {
 	PasswordResetTokens []PasswordResetToken   `json:"-" gorm:"foreignKey:UserID"`
 }
 
+// Fuentes de identidad de los usuarios
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// IsDirectoryUser indica si la contraseña del usuario la administra el directorio LDAP
+func (u *User) IsDirectoryUser() bool {
+	return u.AuthSource == AuthSourceLDAP
+}
+
+// IsPendingVerification indica si la cuenta auto-registrada aún no verificó su email
+func (u *User) IsPendingVerification() bool {
+	return u.EmailVerifiedAt == nil && u.AuthSource == AuthSourceLocal && !u.IsServiceAccount
+}
+
+// IsAccountExpired indica si venció la cuenta temporal (contratistas, pasantes)
+func (u *User) IsAccountExpired() bool {
+	return u.ExpiresAt != nil && !time.Now().Before(*u.ExpiresAt)
+}
+
 // BeforeCreate hook de GORM para generar UUID
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
@@ -51,11 +83,34 @@ type UserProfile struct {
 	LastLogin            *time.Time          `json:"lastLogin"`
 	CreatedAt            time.Time           `json:"createdAt"`
 
+	// Cuenta de servicio autenticada con API key
+	IsServiceAccount bool `json:"isServiceAccount,omitempty"`
+
+	// Fuente de identidad (local o ldap); en ldap la contraseña se cambia en el directorio
+	AuthSource string `json:"authSource,omitempty"`
+
+	// Falso mientras la cuenta auto-registrada no verifique su email
+	EmailVerified bool `json:"emailVerified"`
+
+	// Cuentas temporales: fecha en que dejan de poder iniciar sesión
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// El administrador exige cambiar la contraseña en el próximo login
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
+
+	// Presente solo cuando un administrador actúa como este usuario
+	Impersonation *ImpersonationInfo `json:"impersonation,omitempty"`
+
 	// NUEVO: Estado de preguntas de seguridad
 	HasSecurityQuestions   bool `json:"hasSecurityQuestions"`
 	SecurityQuestionsCount int  `json:"securityQuestionsCount"`
 }
 
+// IsAccountExpired indica si venció la cuenta temporal
+func (p *UserProfile) IsAccountExpired() bool {
+	return p.ExpiresAt != nil && !time.Now().Before(*p.ExpiresAt)
+}
+
 // UserProfileExtended perfil extendido con información de seguridad
 type UserProfileExtended struct {
 	*UserProfile
@@ -75,6 +130,11 @@ func (u *User) ToProfile() *UserProfile {
 		IsActive:             u.IsActive,
 		LastLogin:            u.LastLogin,
 		CreatedAt:            u.CreatedAt,
+		IsServiceAccount:     u.IsServiceAccount,
+		AuthSource:           u.AuthSource,
+		EmailVerified:        !u.IsPendingVerification(),
+		ExpiresAt:            u.ExpiresAt,
+		MustChangePassword:   u.MustChangePassword,
 	}
 
 	// Contar preguntas de seguridad activas
@@ -251,7 +311,7 @@ func IsValidRole(role string) bool {
 type UserCreateRequest struct {
 	Username             string                        `json:"username" validate:"required,min=3,max=50"`
 	Email                string                        `json:"email" validate:"required,email"`
-	Password             string                        `json:"password" validate:"required,min=8"`
+	Password             string                        `json:"password" validate:"required"`
 	FirstName            string                        `json:"firstName" validate:"required,min=2,max=50"`
 	LastName             string                        `json:"lastName" validate:"required,min=2,max=50"`
 	Role                 string                        `json:"role" validate:"required,oneof=admin input output"`
@@ -271,7 +331,7 @@ type UserUpdateRequest struct {
 // UserChangePasswordRequest para cambiar contraseña
 type UserChangePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword" validate:"required"`
-	NewPassword     string `json:"newPassword" validate:"required,min=8"`
+	NewPassword     string `json:"newPassword" validate:"required"`
 }
 
 // ===== MÉTODOS DE CONVERSIÓN =====
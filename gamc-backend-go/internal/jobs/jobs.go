@@ -0,0 +1,98 @@
+// internal/jobs/jobs.go
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/services"
+)
+
+// Setup crea el scheduler con las tareas periódicas de la aplicación
+func Setup(appCtx *config.AppContext) *Scheduler {
+	scheduler := NewScheduler(appCtx.Config.Timezone)
+
+	// ========================================
+	// CONTRASEÑAS
+	// ========================================
+
+	passwordPolicy := services.NewPasswordPolicyService(appCtx)
+	scheduler.Daily("avisos de expiración de contraseña", 8, func(ctx context.Context) error {
+		_, err := passwordPolicy.SendExpiryWarnings(ctx)
+		return err
+	})
+
+	// ========================================
+	// SESIONES
+	// ========================================
+
+	// Cierra las sesiones inactivas o vencidas y avisa al cliente (session:expired)
+	sessionService := services.NewSessionService(appCtx)
+	scheduler.Every("expiración de sesiones inactivas", time.Minute, func(ctx context.Context) error {
+		_, err := sessionService.ExpireInactiveSessions(ctx)
+		return err
+	})
+
+	// ========================================
+	// CUENTAS TEMPORALES
+	// ========================================
+
+	userAdminService := services.NewUserAdminService(appCtx)
+	scheduler.Daily("desactivación de cuentas expiradas", 1, func(ctx context.Context) error {
+		_, err := userAdminService.DeactivateExpiredAccounts(ctx)
+		return err
+	})
+
+	// ========================================
+	// CORREO SALIENTE
+	// ========================================
+
+	if appCtx.Config.MailEnabled && appCtx.Config.MailQueueInterval > 0 {
+		mailService := services.NewMailService(appCtx)
+		scheduler.Every("envío de la cola de correo", appCtx.Config.MailQueueInterval, func(ctx context.Context) error {
+			_, err := mailService.ProcessQueue(ctx)
+			return err
+		})
+		scheduler.Daily("limpieza de correos enviados", 3, func(ctx context.Context) error {
+			_, err := mailService.PurgeSent(ctx)
+			return err
+		})
+	}
+
+	// ========================================
+	// VERIFICACIÓN DE EMAIL
+	// ========================================
+
+	if appCtx.Config.EmailVerificationPurgeDays > 0 {
+		verificationService := services.NewEmailVerificationService(appCtx)
+		scheduler.Daily("limpieza de cuentas no verificadas", 4, func(ctx context.Context) error {
+			_, err := verificationService.PurgeUnverified(ctx)
+			return err
+		})
+	}
+
+	// ========================================
+	// DISPOSITIVOS CONOCIDOS
+	// ========================================
+
+	deviceService := services.NewDeviceService(appCtx)
+	scheduler.Daily("limpieza de alertas de dispositivos", 4, func(ctx context.Context) error {
+		_, err := deviceService.CleanupExpiredAlerts(ctx)
+		return err
+	})
+
+	// ========================================
+	// DIRECTORIO LDAP
+	// ========================================
+
+	if appCtx.Config.LDAPEnabled && appCtx.Config.LDAPSyncInterval > 0 {
+		ldapService := services.NewLDAPService(appCtx)
+		scheduler.Every("sincronización de usuarios LDAP", appCtx.Config.LDAPSyncInterval, func(ctx context.Context) error {
+			_, err := ldapService.Sync(ctx)
+			return err
+		})
+	}
+
+	return scheduler
+}
@@ -0,0 +1,119 @@
+// internal/jobs/scheduler.go
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gamc-backend-go/pkg/logger"
+)
+
+// JobFunc función ejecutada por el scheduler
+type JobFunc func(ctx context.Context) error
+
+// job tarea registrada en el scheduler
+type job struct {
+	name     string
+	interval time.Duration
+	hour     int // -1 para tareas por intervalo
+	run      JobFunc
+}
+
+// Scheduler ejecuta tareas periódicas en segundo plano (limpiezas, avisos, sincronizaciones)
+type Scheduler struct {
+	jobs     []job
+	location *time.Location
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewScheduler crea un scheduler que usa la zona horaria indicada para las tareas diarias
+func NewScheduler(timezone string) *Scheduler {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		logger.Warn("Zona horaria %s inválida para el scheduler, usando hora local: %v", timezone, err)
+		location = time.Local
+	}
+	return &Scheduler{location: location}
+}
+
+// Every registra una tarea que se ejecuta cada intervalo
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, hour: -1, run: run})
+}
+
+// Daily registra una tarea que se ejecuta una vez al día a la hora indicada (0-23)
+func (s *Scheduler) Daily(name string, hour int, run JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, hour: hour, run: run})
+}
+
+// Start inicia todas las tareas registradas
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	logger.Info("⏰ Scheduler iniciado con %d tareas", len(s.jobs))
+}
+
+// Stop detiene las tareas y espera a que terminen las ejecuciones en curso
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	logger.Info("✅ Scheduler detenido")
+}
+
+// loop espera hasta la próxima ejecución de la tarea y la ejecuta
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	for {
+		timer := time.NewTimer(s.nextDelay(j, time.Now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.execute(ctx, j)
+		}
+	}
+}
+
+// execute ejecuta la tarea protegiendo al scheduler de panics
+func (s *Scheduler) execute(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ Panic en tarea programada %s: %v", j.name, r)
+		}
+	}()
+
+	start := time.Now()
+	if err := j.run(ctx); err != nil {
+		logger.Error("❌ Error en tarea programada %s: %v", j.name, err)
+		return
+	}
+	logger.Info("⏰ Tarea %s completada en %v", j.name, time.Since(start).Round(time.Millisecond))
+}
+
+// nextDelay calcula el tiempo hasta la próxima ejecución
+func (s *Scheduler) nextDelay(j job, now time.Time) time.Duration {
+	if j.hour < 0 {
+		return j.interval
+	}
+
+	local := now.In(s.location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), j.hour, 0, 0, 0, s.location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.Sub(local)
+}
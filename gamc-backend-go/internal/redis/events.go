@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WebSocketEventsChannel canal Pub/Sub por el que el backend entrega eventos al servidor WebSocket
+const WebSocketEventsChannel = "websocket:events"
+
+// EventPublisher publica eventos para los clientes WebSocket. Cualquier instancia
+// del backend puede publicar; el servidor WebSocket los reenvía a las conexiones.
+type EventPublisher struct {
+	client *redis.Client
+}
+
+// NewEventPublisher crea un nuevo publicador de eventos
+func NewEventPublisher(client *redis.Client) *EventPublisher {
+	return &EventPublisher{client: client}
+}
+
+// Publish serializa el evento y lo publica en el canal de eventos WebSocket
+func (ep *EventPublisher) Publish(ctx context.Context, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return ep.client.Publish(ctx, WebSocketEventsChannel, payload).Err()
+}
+
+// Subscribe se suscribe al canal de eventos WebSocket
+func (ep *EventPublisher) Subscribe(ctx context.Context) *redis.PubSub {
+	return ep.client.Subscribe(ctx, WebSocketEventsChannel)
+}
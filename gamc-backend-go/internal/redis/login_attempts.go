@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Ámbitos de los contadores de intentos fallidos
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+// LoginLock describe un bloqueo activo
+type LoginLock struct {
+	Scope             string `json:"scope"`
+	Identifier        string `json:"identifier"`
+	Lockouts          int64  `json:"lockouts"`
+	RetryAfterSeconds int64  `json:"retryAfterSeconds"`
+}
+
+// LoginAttemptManager maneja contadores de intentos fallidos y bloqueos temporales (DB 0)
+type LoginAttemptManager struct {
+	client *redis.Client
+}
+
+// NewLoginAttemptManager crea un nuevo manejador de intentos de login
+func NewLoginAttemptManager(client *redis.Client) *LoginAttemptManager {
+	return &LoginAttemptManager{client: client}
+}
+
+// RegisterFailure incrementa el contador de fallos dentro de la ventana indicada.
+// El incremento y la expiración van en una misma transacción: el contador nunca queda sin TTL.
+func (m *LoginAttemptManager) RegisterFailure(ctx context.Context, scope, identifier string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("login_failures:%s:%s", scope, identifier)
+
+	pipe := m.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	// La ventana comienza con el primer fallo (NX: no se extiende con los siguientes)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment login failures: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+// ResetFailures reinicia el contador de fallos
+func (m *LoginAttemptManager) ResetFailures(ctx context.Context, scope, identifier string) error {
+	key := fmt.Sprintf("login_failures:%s:%s", scope, identifier)
+	return m.client.Del(ctx, key).Err()
+}
+
+// Lock bloquea el identificador con backoff exponencial: base * 2^(n-1), con tope maxDuration.
+// El número de bloqueos se recuerda durante 24 horas.
+func (m *LoginAttemptManager) Lock(ctx context.Context, scope, identifier string, base, maxDuration time.Duration) (time.Duration, int64, error) {
+	countKey := fmt.Sprintf("login_lock_count:%s:%s", scope, identifier)
+
+	pipe := m.client.TxPipeline()
+	incr := pipe.Incr(ctx, countKey)
+	pipe.Expire(ctx, countKey, 24*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to increment lockout count: %w", err)
+	}
+	lockouts := incr.Val()
+
+	duration := base
+	for i := int64(1); i < lockouts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+
+	// Los fallos se cuentan de nuevo al terminar el bloqueo
+	if err := m.ResetFailures(ctx, scope, identifier); err != nil {
+		return 0, 0, fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	lockKey := fmt.Sprintf("login_lock:%s:%s", scope, identifier)
+	if err := m.client.SetEx(ctx, lockKey, lockouts, duration).Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to save lock: %w", err)
+	}
+
+	return duration, lockouts, nil
+}
+
+// GetLockTTL retorna el tiempo restante de bloqueo (0 si no está bloqueado)
+func (m *LoginAttemptManager) GetLockTTL(ctx context.Context, scope, identifier string) (time.Duration, error) {
+	key := fmt.Sprintf("login_lock:%s:%s", scope, identifier)
+
+	ttl, err := m.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lock ttl: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil // -2 no existe, -1 sin expiración (no debería ocurrir)
+	}
+
+	return ttl, nil
+}
+
+// Unlock elimina el bloqueo, los fallos y el historial de backoff
+func (m *LoginAttemptManager) Unlock(ctx context.Context, scope, identifier string) error {
+	return m.client.Del(ctx,
+		fmt.Sprintf("login_lock:%s:%s", scope, identifier),
+		fmt.Sprintf("login_lock_count:%s:%s", scope, identifier),
+		fmt.Sprintf("login_failures:%s:%s", scope, identifier),
+	).Err()
+}
+
+// GetActiveLocks lista los bloqueos activos. Recorre las claves con SCAN por lotes
+// para no bloquear Redis como KEYS; SCAN puede repetir claves, por eso se deduplican.
+func (m *LoginAttemptManager) GetActiveLocks(ctx context.Context) ([]LoginLock, error) {
+	locks := make([]LoginLock, 0)
+	seen := make(map[string]struct{})
+
+	iter := m.client.Scan(ctx, 0, "login_lock:*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		parts := strings.SplitN(strings.TrimPrefix(key, "login_lock:"), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		ttl, err := m.client.TTL(ctx, key).Result()
+		if err != nil || ttl <= 0 {
+			continue
+		}
+
+		value, _ := m.client.Get(ctx, key).Result()
+		lockouts, _ := strconv.ParseInt(value, 10, 64)
+
+		locks = append(locks, LoginLock{
+			Scope:             parts[0],
+			Identifier:        parts[1],
+			Lockouts:          lockouts,
+			RetryAfterSeconds: int64(ttl.Seconds()),
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan lock keys: %w", err)
+	}
+
+	return locks, nil
+}
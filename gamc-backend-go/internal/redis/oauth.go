@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// OAuthAuthorizationCode datos asociados a un código de autorización
+type OAuthAuthorizationCode struct {
+	ClientID      string    `json:"clientId"`
+	UserID        string    `json:"userId"`
+	SessionID     string    `json:"sessionId"` // Sesión del usuario que otorgó el consentimiento
+	RedirectURI   string    `json:"redirectUri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"codeChallenge"`
+	Nonce         string    `json:"nonce,omitempty"`
+	AuthTime      time.Time `json:"authTime"`
+	IPAddress     string    `json:"ipAddress,omitempty"` // Red del usuario al otorgar el consentimiento
+	UserAgent     string    `json:"userAgent,omitempty"`
+}
+
+// OAuthCodeManager maneja los códigos de autorización OAuth (DB 0)
+type OAuthCodeManager struct {
+	client *redis.Client
+}
+
+// NewOAuthCodeManager crea un nuevo manejador de códigos de autorización
+func NewOAuthCodeManager(client *redis.Client) *OAuthCodeManager {
+	return &OAuthCodeManager{client: client}
+}
+
+// SaveCode guarda un código de autorización
+func (m *OAuthCodeManager) SaveCode(ctx context.Context, code string, data *OAuthAuthorizationCode, ttl time.Duration) error {
+	key := fmt.Sprintf("oauth_code:%s", code)
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorization code: %w", err)
+	}
+
+	return m.client.SetEx(ctx, key, jsonData, ttl).Err()
+}
+
+// ConsumeCode obtiene y elimina el código en una sola operación (un solo uso).
+// Retorna nil si el código no existe o ya fue usado.
+func (m *OAuthCodeManager) ConsumeCode(ctx context.Context, code string) (*OAuthAuthorizationCode, error) {
+	key := fmt.Sprintf("oauth_code:%s", code)
+
+	result, err := m.client.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	var data OAuthAuthorizationCode
+	if err := json.Unmarshal([]byte(result), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorization code: %w", err)
+	}
+
+	return &data, nil
+}
@@ -2,6 +2,8 @@ package redis
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -52,6 +54,11 @@ type SessionData struct {
 	LastActivity         time.Time `json:"lastActivity"`
 	IPAddress            string    `json:"ipAddress,omitempty"`
 	UserAgent            string    `json:"userAgent,omitempty"`
+	ClientID             string    `json:"clientId,omitempty"` // Sesiones emitidas a clientes OAuth
+	Scopes               []string  `json:"scopes,omitempty"`
+	ImpersonatorID       string    `json:"impersonatorId,omitempty"` // Sesiones de suplantación por soporte
+	ImpersonatorEmail    string    `json:"impersonatorEmail,omitempty"`
+	ImpersonationReason  string    `json:"impersonationReason,omitempty"`
 }
 
 // SessionManager maneja las operaciones de sesión
@@ -102,27 +109,116 @@ func (sm *SessionManager) DeleteSession(ctx context.Context, sessionID string) e
 	return sm.client.Del(ctx, key).Err()
 }
 
-// ExtendSession extiende el TTL de una sesión
-func (sm *SessionManager) ExtendSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+// ExtendSession registra la última actividad de la sesión y renueva su TTL.
+// Solo escribe si la sesión aún existe, para no revivir una sesión recién cerrada.
+// Retorna false si la sesión ya no existía.
+func (sm *SessionManager) ExtendSession(ctx context.Context, sessionID string, data *SessionData, ttl time.Duration) (bool, error) {
 	key := fmt.Sprintf("session:%s", sessionID)
-	return sm.client.Expire(ctx, key, ttl).Err()
+
+	data.LastActivity = time.Now()
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal session data: %w", err)
+	}
+
+	extended, err := sm.client.SetXX(ctx, key, jsonData, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to extend session: %w", err)
+	}
+
+	return extended, nil
+}
+
+// sessionScanBatch claves pedidas a Redis en cada iteración de SCAN
+const sessionScanBatch = 200
+
+// GetAllSessions obtiene los IDs de todas las sesiones activas
+func (sm *SessionManager) GetAllSessions(ctx context.Context) ([]string, error) {
+	var sessionIDs []string
+	err := sm.scanSessionKeys(ctx, func(keys []string) error {
+		for _, key := range keys {
+			sessionIDs = append(sessionIDs, strings.TrimPrefix(key, "session:"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionIDs, nil
+}
+
+// ForEachSession recorre las sesiones activas leyéndolas por lotes con MGET, en lugar de
+// un GET por sesión. Las que expiran durante el recorrido se omiten.
+func (sm *SessionManager) ForEachSession(ctx context.Context, fn func(*SessionData)) error {
+	return sm.scanSessionKeys(ctx, func(keys []string) error {
+		values, err := sm.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get sessions: %w", err)
+		}
+
+		for _, value := range values {
+			raw, ok := value.(string)
+			if !ok {
+				continue
+			}
+			var data SessionData
+			if err := json.Unmarshal([]byte(raw), &data); err != nil {
+				continue
+			}
+			fn(&data)
+		}
+		return nil
+	})
+}
+
+// scanSessionKeys recorre las claves session:* con SCAN por lotes para no bloquear Redis
+// como KEYS; SCAN puede repetir claves, por eso cada lote se entrega sin repetidos
+func (sm *SessionManager) scanSessionKeys(ctx context.Context, fn func(keys []string) error) error {
+	seen := make(map[string]struct{})
+	var cursor uint64
+	for {
+		keys, next, err := sm.client.Scan(ctx, cursor, "session:*", sessionScanBatch).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan session keys: %w", err)
+		}
+
+		batch := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			batch = append(batch, key)
+		}
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
 }
 
 // GetUserSessions obtiene todas las sesiones de un usuario
 func (sm *SessionManager) GetUserSessions(ctx context.Context, userID string) ([]string, error) {
-	keys, err := sm.client.Keys(ctx, "session:*").Result()
+	sessionIDs, err := sm.GetAllSessions(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session keys: %w", err)
+		return nil, err
 	}
 
 	var userSessions []string
-	for _, key := range keys {
-		sessionData, err := sm.GetSession(ctx, strings.TrimPrefix(key, "session:"))
+	for _, sessionID := range sessionIDs {
+		sessionData, err := sm.GetSession(ctx, sessionID)
 		if err != nil {
 			continue
 		}
 		if sessionData != nil && sessionData.UserID == userID {
-			userSessions = append(userSessions, strings.TrimPrefix(key, "session:"))
+			userSessions = append(userSessions, sessionID)
 		}
 	}
 
@@ -181,6 +277,59 @@ func (rtm *RefreshTokenManager) DeleteAllUserRefreshTokens(ctx context.Context,
 	return nil
 }
 
+// rotateRefreshScript reemplaza el refresh token vigente solo si coincide con el presentado
+// y agrega el anterior a la familia de tokens rotados, en una sola operación atómica.
+var rotateRefreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "EX", ARGV[4])
+redis.call("SADD", KEYS[2], ARGV[3])
+redis.call("EXPIRE", KEYS[2], ARGV[4])
+return 1
+`)
+
+// RotateRefreshToken reemplaza oldToken por newToken y guarda oldToken en la familia de la sesión.
+// Retorna false si oldToken ya no es el token vigente (otra petición lo rotó primero o fue revocado).
+func (rtm *RefreshTokenManager) RotateRefreshToken(ctx context.Context, userID, sessionID, oldToken, newToken string, ttl time.Duration) (bool, error) {
+	keys := []string{
+		fmt.Sprintf("refresh:%s:%s", userID, sessionID),
+		fmt.Sprintf("refresh_family:%s", sessionID),
+	}
+
+	rotated, err := rotateRefreshScript.Run(ctx, rtm.client, keys,
+		oldToken, newToken, hashRefreshToken(oldToken), int64(ttl.Seconds())).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	return rotated == 1, nil
+}
+
+// IsRotatedToken verifica si el token ya fue rotado dentro de la familia de la sesión (reutilización)
+func (rtm *RefreshTokenManager) IsRotatedToken(ctx context.Context, sessionID, token string) (bool, error) {
+	key := fmt.Sprintf("refresh_family:%s", sessionID)
+
+	used, err := rtm.client.SIsMember(ctx, key, hashRefreshToken(token)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check refresh token family: %w", err)
+	}
+
+	return used, nil
+}
+
+// DeleteTokenFamily elimina el historial de tokens rotados de la sesión
+func (rtm *RefreshTokenManager) DeleteTokenFamily(ctx context.Context, sessionID string) error {
+	key := fmt.Sprintf("refresh_family:%s", sessionID)
+	return rtm.client.Del(ctx, key).Err()
+}
+
+// hashRefreshToken evita guardar en claro los tokens ya rotados
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 // JWTBlacklistManager maneja la blacklist de JWT (DB 5)
 type JWTBlacklistManager struct {
 	client *redis.Client
@@ -268,6 +417,11 @@ func (cm *CacheManager) Delete(ctx context.Context, key string) error {
 	return cm.client.Del(ctx, key).Err()
 }
 
+// SetIfAbsent crea la clave solo si no existe; retorna true si la creó
+func (cm *CacheManager) SetIfAbsent(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return cm.client.SetNX(ctx, key, 1, ttl).Result()
+}
+
 // RedisStats representa estadísticas de Redis
 type RedisStats struct {
 	Sessions          int64  `json:"sessions"`
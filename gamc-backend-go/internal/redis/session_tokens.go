@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionTokenManager registra los access tokens (JTI) emitidos para cada sesión (DB 0).
+// Permite revocar en la blacklist los tokens aún vigentes al cerrar una sesión.
+type SessionTokenManager struct {
+	client *redis.Client
+}
+
+// NewSessionTokenManager crea un nuevo manejador de tokens por sesión
+func NewSessionTokenManager(client *redis.Client) *SessionTokenManager {
+	return &SessionTokenManager{client: client}
+}
+
+// TrackToken asocia un JTI (con su expiración unix) a la sesión
+func (m *SessionTokenManager) TrackToken(ctx context.Context, sessionID, jti string, exp int64, ttl time.Duration) error {
+	key := fmt.Sprintf("session_tokens:%s", sessionID)
+
+	pipe := m.client.TxPipeline()
+	pipe.HSet(ctx, key, jti, exp)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to track session token: %w", err)
+	}
+
+	return nil
+}
+
+// GetTokens retorna los JTI de la sesión con su expiración unix
+func (m *SessionTokenManager) GetTokens(ctx context.Context, sessionID string) (map[string]int64, error) {
+	key := fmt.Sprintf("session_tokens:%s", sessionID)
+
+	result, err := m.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session tokens: %w", err)
+	}
+
+	tokens := make(map[string]int64, len(result))
+	for jti, value := range result {
+		exp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		tokens[jti] = exp
+	}
+
+	return tokens, nil
+}
+
+// DeleteTokens elimina el registro de tokens de la sesión
+func (m *SessionTokenManager) DeleteTokens(ctx context.Context, sessionID string) error {
+	key := fmt.Sprintf("session_tokens:%s", sessionID)
+	return m.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TwoFactorChallenge datos del desafío emitido tras validar la contraseña
+type TwoFactorChallenge struct {
+	UserID    string    `json:"userId"`
+	IPAddress string    `json:"ipAddress,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TwoFactorChallengeManager maneja los desafíos de login con 2FA (DB 0)
+type TwoFactorChallengeManager struct {
+	client *redis.Client
+}
+
+// NewTwoFactorChallengeManager crea un nuevo manejador de desafíos 2FA
+func NewTwoFactorChallengeManager(client *redis.Client) *TwoFactorChallengeManager {
+	return &TwoFactorChallengeManager{client: client}
+}
+
+// SaveChallenge guarda un desafío con su TTL
+func (m *TwoFactorChallengeManager) SaveChallenge(ctx context.Context, token string, data *TwoFactorChallenge, ttl time.Duration) error {
+	key := fmt.Sprintf("2fa_challenge:%s", token)
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal challenge data: %w", err)
+	}
+
+	return m.client.SetEx(ctx, key, jsonData, ttl).Err()
+}
+
+// GetChallenge obtiene un desafío (nil si no existe o expiró)
+func (m *TwoFactorChallengeManager) GetChallenge(ctx context.Context, token string) (*TwoFactorChallenge, error) {
+	key := fmt.Sprintf("2fa_challenge:%s", token)
+
+	result, err := m.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	var data TwoFactorChallenge
+	if err := json.Unmarshal([]byte(result), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal challenge data: %w", err)
+	}
+
+	return &data, nil
+}
+
+// reserveChallengeAttemptScript incrementa el contador de intentos del desafío solo si
+// el desafío sigue vigente; el contador expira junto con el desafío.
+var reserveChallengeAttemptScript = redis.NewScript(`
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+local attempts = redis.call("INCR", KEYS[2])
+redis.call("PEXPIRE", KEYS[2], ttl)
+return attempts
+`)
+
+// ReserveAttempt registra un intento sobre el desafío de forma atómica y retorna su número
+// (0 si el desafío no existe o expiró). Las peticiones concurrentes reciben números distintos.
+func (m *TwoFactorChallengeManager) ReserveAttempt(ctx context.Context, token string) (int64, error) {
+	keys := []string{
+		fmt.Sprintf("2fa_challenge:%s", token),
+		fmt.Sprintf("2fa_challenge_attempts:%s", token),
+	}
+
+	attempts, err := reserveChallengeAttemptScript.Run(ctx, m.client, keys).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve challenge attempt: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ConsumeChallenge elimina el desafío y retorna false si otra petición ya lo había consumido
+func (m *TwoFactorChallengeManager) ConsumeChallenge(ctx context.Context, token string) (bool, error) {
+	// Solo la petición que elimina la clave del desafío lo consume
+	deleted, err := m.client.Del(ctx, fmt.Sprintf("2fa_challenge:%s", token)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	m.client.Del(ctx, fmt.Sprintf("2fa_challenge_attempts:%s", token))
+
+	return deleted == 1, nil
+}
+
+// DeleteChallenge elimina un desafío (consumido o invalidado) y su contador de intentos
+func (m *TwoFactorChallengeManager) DeleteChallenge(ctx context.Context, token string) error {
+	return m.client.Del(ctx,
+		fmt.Sprintf("2fa_challenge:%s", token),
+		fmt.Sprintf("2fa_challenge_attempts:%s", token),
+	).Err()
+}
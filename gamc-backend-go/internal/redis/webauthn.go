@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tipos de ceremonia WebAuthn
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+// WebAuthnCeremony desafío emitido al navegador entre las opciones y la respuesta
+type WebAuthnCeremony struct {
+	Type             string    `json:"type"`
+	Challenge        string    `json:"challenge"`
+	UserID           string    `json:"userId,omitempty"`           // Vacío en el login sin email (passkey residente)
+	TwoFactorToken   string    `json:"twoFactorToken,omitempty"`   // Desafío 2FA que completa la passkey
+	UserVerification string    `json:"userVerification,omitempty"` // Exigida en el login sin contraseña
+	IPAddress        string    `json:"ipAddress,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// WebAuthnCeremonyManager maneja los desafíos de registro y login con passkeys (DB 0)
+type WebAuthnCeremonyManager struct {
+	client *redis.Client
+}
+
+// NewWebAuthnCeremonyManager crea un nuevo manejador de ceremonias WebAuthn
+func NewWebAuthnCeremonyManager(client *redis.Client) *WebAuthnCeremonyManager {
+	return &WebAuthnCeremonyManager{client: client}
+}
+
+// SaveCeremony guarda una ceremonia con su TTL
+func (m *WebAuthnCeremonyManager) SaveCeremony(ctx context.Context, ceremonyID string, data *WebAuthnCeremony, ttl time.Duration) error {
+	key := fmt.Sprintf("webauthn_ceremony:%s", ceremonyID)
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ceremony data: %w", err)
+	}
+
+	return m.client.SetEx(ctx, key, jsonData, ttl).Err()
+}
+
+// ConsumeCeremony obtiene y elimina la ceremonia en una sola operación (un solo uso).
+// Retorna nil si no existe o expiró.
+func (m *WebAuthnCeremonyManager) ConsumeCeremony(ctx context.Context, ceremonyID string) (*WebAuthnCeremony, error) {
+	key := fmt.Sprintf("webauthn_ceremony:%s", ceremonyID)
+
+	result, err := m.client.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ceremony: %w", err)
+	}
+
+	var data WebAuthnCeremony
+	if err := json.Unmarshal([]byte(result), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ceremony data: %w", err)
+	}
+
+	return &data, nil
+}
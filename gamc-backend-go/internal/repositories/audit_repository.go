@@ -429,3 +429,233 @@ type UserActivityReport struct {
 	LastLogin    *time.Time
 	IPAddresses  []string
 }
+
+// ========================================
+// AUDITORÍA DE RESETS DE CONTRASEÑA
+// ========================================
+
+// ResetAuditFilter filtros del tablero de resets. Del AuditFilter se usan UserID,
+// IPAddress (IP de la solicitud), DateFrom/DateTo, SearchTerm, orden y paginación
+type ResetAuditFilter struct {
+	AuditFilter
+	OrganizationalUnitID *int
+	Outcome              string
+}
+
+// resetOutcomeSQL clasifica cada solicitud con las mismas reglas que
+// PasswordResetToken.HasExceededSecurityAttempts
+var resetOutcomeSQL = fmt.Sprintf(`CASE
+	WHEN prt.used_at IS NOT NULL THEN '%s'
+	WHEN prt.requires_security_question IS TRUE AND prt.security_question_verified IS NOT TRUE AND (
+		CASE WHEN (CASE WHEN jsonb_typeof(prt.challenge_question_ids) = 'array'
+				THEN jsonb_array_length(prt.challenge_question_ids) ELSE 0 END) = 0
+			THEN COALESCE(prt.security_question_attempts, 0) >= %d
+			ELSE (CASE WHEN jsonb_typeof(prt.question_attempts) = 'object'
+				THEN EXISTS (SELECT 1 FROM jsonb_each_text(prt.question_attempts) qa WHERE qa.value::int >= %d)
+				ELSE false END)
+		END) THEN '%s'
+	WHEN prt.expires_at <= NOW() OR prt.is_active IS NOT TRUE THEN '%s'
+	ELSE '%s'
+END`,
+	models.ResetOutcomeCompleted,
+	models.MaxSecurityQuestionAttempts, models.MaxSecurityQuestionAttempts,
+	models.ResetOutcomeFailedSecurityQuestion,
+	models.ResetOutcomeExpired,
+	models.ResetOutcomePending)
+
+// resetBucketColumns agregados comunes a todas las dimensiones del tablero
+var resetBucketColumns = fmt.Sprintf(`COUNT(*) AS total,
+	COUNT(DISTINCT user_id) AS accounts,
+	SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS completed,
+	SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS failed_security_question,
+	SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS expired,
+	SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS pending`,
+	models.ResetOutcomeCompleted,
+	models.ResetOutcomeFailedSecurityQuestion,
+	models.ResetOutcomeExpired,
+	models.ResetOutcomePending)
+
+// resetBreakdownDimensions clave y etiqueta de cada dimensión del tablero
+var resetBreakdownDimensions = map[string][2]string{
+	"day":     {"to_char(date_trunc('day', created_at), 'YYYY-MM-DD')", "to_char(date_trunc('day', created_at), 'YYYY-MM-DD')"},
+	"unit":    {"COALESCE(organizational_unit_id::text, '')", "COALESCE(NULLIF(unit_name, ''), 'Sin unidad')"},
+	"outcome": {"outcome", "outcome"},
+	"ip":      {"ip_address", "COALESCE(NULLIF(ip_address, ''), 'Sin IP')"},
+}
+
+// resetAuditSortColumns columnas por las que se puede ordenar el listado
+var resetAuditSortColumns = map[string]bool{
+	"created_at": true,
+	"expires_at": true,
+	"email":      true,
+	"ip_address": true,
+	"unit_name":  true,
+	"outcome":    true,
+}
+
+// GetResetsByFilter obtiene solicitudes de reset con filtros y paginación
+func (r *AuditRepository) GetResetsByFilter(ctx context.Context, filter *ResetAuditFilter) ([]*models.PasswordResetAuditEntry, int64, error) {
+	var entries []*models.PasswordResetAuditEntry
+	var total int64
+
+	query := r.applyResetAuditFilters(r.resetAuditQuery(ctx), filter)
+
+	// Contar total antes de paginar
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Aplicar ordenamiento (solo columnas conocidas)
+	if filter.SortBy != "" && resetAuditSortColumns[filter.SortBy] {
+		order := filter.SortBy
+		if filter.SortDesc {
+			order += " DESC"
+		}
+		query = query.Order(order)
+	} else {
+		query = query.Order("created_at DESC")
+	}
+
+	// Aplicar paginación
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// GetResetTotals obtiene los agregados de todas las solicitudes del filtro
+func (r *AuditRepository) GetResetTotals(ctx context.Context, filter *ResetAuditFilter) (*models.PasswordResetAuditBucket, error) {
+	var totals models.PasswordResetAuditBucket
+	err := r.applyResetAuditFilters(r.resetAuditQuery(ctx), filter).
+		Select(resetBucketColumns).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// GetResetBreakdown agrupa las solicitudes por día, unidad, resultado o IP.
+// Los días se ordenan cronológicamente; el resto por volumen. limit <= 0 = sin límite
+func (r *AuditRepository) GetResetBreakdown(ctx context.Context, filter *ResetAuditFilter, dimension string, limit int) ([]models.PasswordResetAuditBucket, error) {
+	columns, ok := resetBreakdownDimensions[dimension]
+	if !ok {
+		return nil, fmt.Errorf("dimensión de auditoría desconocida: %s", dimension)
+	}
+
+	var buckets []models.PasswordResetAuditBucket
+	query := r.applyResetAuditFilters(r.resetAuditQuery(ctx), filter).
+		Select(fmt.Sprintf("%s AS key, %s AS label, %s", columns[0], columns[1], resetBucketColumns)).
+		Group("1, 2")
+
+	if dimension == "day" {
+		query = query.Order("1")
+	} else {
+		query = query.Order("total DESC, 1")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Scan(&buckets).Error; err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// GetResetIPAnomalies obtiene las IPs que pidieron resets para al menos minAccounts cuentas distintas
+func (r *AuditRepository) GetResetIPAnomalies(ctx context.Context, filter *ResetAuditFilter, minAccounts int) ([]models.PasswordResetAuditAnomaly, error) {
+	var anomalies []models.PasswordResetAuditAnomaly
+	err := r.applyResetAuditFilters(r.resetAuditQuery(ctx), filter).
+		Select(fmt.Sprintf(`ip_address,
+			COUNT(*) AS requests,
+			COUNT(DISTINCT user_id) AS accounts,
+			1 AS ips,
+			SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS failed_security_question,
+			MIN(created_at) AS first_seen,
+			MAX(created_at) AS last_seen`, models.ResetOutcomeFailedSecurityQuestion)).
+		Where("ip_address <> ''").
+		Group("ip_address").
+		Having("COUNT(DISTINCT user_id) >= ?", minAccounts).
+		Order("accounts DESC, requests DESC").
+		Scan(&anomalies).Error
+	return anomalies, err
+}
+
+// GetResetAccountAnomalies obtiene las cuentas que recibieron resets desde al menos minIPs IPs distintas
+func (r *AuditRepository) GetResetAccountAnomalies(ctx context.Context, filter *ResetAuditFilter, minIPs int) ([]models.PasswordResetAuditAnomaly, error) {
+	var anomalies []models.PasswordResetAuditAnomaly
+	err := r.applyResetAuditFilters(r.resetAuditQuery(ctx), filter).
+		Select(fmt.Sprintf(`user_id,
+			email,
+			COUNT(*) AS requests,
+			1 AS accounts,
+			COUNT(DISTINCT NULLIF(ip_address, '')) AS ips,
+			SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END) AS failed_security_question,
+			MIN(created_at) AS first_seen,
+			MAX(created_at) AS last_seen`, models.ResetOutcomeFailedSecurityQuestion)).
+		Group("user_id, email").
+		Having("COUNT(DISTINCT NULLIF(ip_address, '')) >= ?", minIPs).
+		Order("ips DESC, requests DESC").
+		Scan(&anomalies).Error
+	return anomalies, err
+}
+
+// resetAuditQuery consulta base: cada solicitud con su usuario, unidad y resultado.
+// La IP de la solicitud se expone como ip_address para reutilizar applyAuditFilters
+func (r *AuditRepository) resetAuditQuery(ctx context.Context) *gorm.DB {
+	inner := r.db.Table("password_reset_tokens prt").
+		Select(`prt.id, prt.user_id, u.email, u.first_name, u.last_name,
+			u.organizational_unit_id, COALESCE(ou.name, '') AS unit_name,
+			COALESCE(prt.request_ip, '') AS ip_address, COALESCE(prt.user_agent, '') AS user_agent,
+			COALESCE(prt.requires_security_question, false) AS requires_security_question,
+			COALESCE(prt.security_question_attempts, 0) AS security_question_attempts,
+			` + resetOutcomeSQL + ` AS outcome,
+			prt.created_at, prt.expires_at, prt.used_at`).
+		Joins("JOIN users u ON u.id = prt.user_id").
+		Joins("LEFT JOIN organizational_units ou ON ou.id = u.organizational_unit_id")
+
+	return r.db.WithContext(ctx).Table("(?) AS r", inner)
+}
+
+// applyResetAuditFilters aplica los filtros del tablero sobre resetAuditQuery
+func (r *AuditRepository) applyResetAuditFilters(query *gorm.DB, filter *ResetAuditFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	// Usuario, IP y rango de fechas comparten columnas con audit_logs
+	query = r.applyAuditFilters(query, &AuditFilter{
+		UserID:    filter.UserID,
+		IPAddress: filter.IPAddress,
+		DateFrom:  filter.DateFrom,
+		DateTo:    filter.DateTo,
+	})
+
+	// Filtro por unidad organizacional
+	if filter.OrganizationalUnitID != nil {
+		query = query.Where("organizational_unit_id = ?", *filter.OrganizationalUnitID)
+	}
+
+	// Filtro por resultado
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+
+	// Búsqueda por email, nombre o IP
+	if filter.SearchTerm != "" {
+		searchPattern := fmt.Sprintf("%%%s%%", filter.SearchTerm)
+		query = query.Where("email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR ip_address ILIKE ?",
+			searchPattern, searchPattern, searchPattern, searchPattern)
+	}
+
+	return query
+}
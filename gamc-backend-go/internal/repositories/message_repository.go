@@ -322,19 +322,71 @@ func (r *MessageRepository) SearchMessages(ctx context.Context, searchTerm strin
 	return r.GetByFilter(ctx, filter)
 }
 
-// GetMessageThread obtiene un hilo de conversación
-func (r *MessageRepository) GetMessageThread(ctx context.Context, originalMessageID int64) ([]*models.Message, error) {
-	var messages []*models.Message
+// MaxThreadDepth profundidad máxima que se recorre en un hilo de respuestas
+const MaxThreadDepth = 100
+
+// GetThreadRootID obtiene el mensaje que inició el hilo al que pertenece un mensaje
+func (r *MessageRepository) GetThreadRootID(ctx context.Context, messageID int64) (int64, error) {
+	var rootID int64
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT CAST(? AS BIGINT) AS id, 0 AS depth
+			UNION ALL
+			SELECT mr.original_message_id, a.depth + 1
+			FROM message_responses mr
+			JOIN ancestors a ON mr.response_message_id = a.id
+			WHERE a.depth < ?
+		)
+		SELECT id FROM ancestors ORDER BY depth DESC LIMIT 1`, messageID, MaxThreadDepth).
+		Scan(&rootID).Error
+	return rootID, err
+}
 
-	// Aquí implementarías la lógica para obtener respuestas a un mensaje
-	// Por ahora, retornamos solo el mensaje original
-	message, err := r.GetByID(ctx, originalMessageID)
+// GetMessageThread obtiene un hilo de conversación completo: el mensaje raíz, todas
+// sus respuestas (en orden cronológico) y los enlaces respuesta → mensaje original
+func (r *MessageRepository) GetMessageThread(ctx context.Context, rootMessageID int64) ([]*models.Message, []models.MessageReply, error) {
+	var links []models.MessageReply
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE thread AS (
+			SELECT CAST(? AS BIGINT) AS id, 0 AS depth
+			UNION
+			SELECT mr.response_message_id, t.depth + 1
+			FROM message_responses mr
+			JOIN thread t ON mr.original_message_id = t.id
+			WHERE t.depth < ?
+		)
+		SELECT mr.* FROM message_responses mr
+		WHERE mr.original_message_id IN (SELECT id FROM thread)
+		ORDER BY mr.created_at, mr.id`, rootMessageID, MaxThreadDepth).
+		Scan(&links).Error
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	ids := []int64{rootMessageID}
+	for _, link := range links {
+		ids = append(ids, link.ResponseMessageID)
+	}
+
+	var messages []*models.Message
+	err = r.db.WithContext(ctx).
+		Preload("Sender").
+		Preload("SenderUnit").
+		Preload("ReceiverUnit").
+		Preload("MessageType").
+		Preload("Status").
+		Preload("Attachments").
+		Where("id IN ?", ids).
+		Order("created_at ASC, id ASC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
 	}
 
-	messages = append(messages, message)
-	return messages, nil
+	return messages, links, nil
 }
 
 // BatchUpdateStatus actualiza el estado de múltiples mensajes
@@ -0,0 +1,488 @@
+// internal/services/api_key_service.go
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyLastUsedInterval frecuencia máxima con la que se persiste el último uso de una key
+const apiKeyLastUsedInterval = time.Minute
+
+// nonUsernameChars caracteres no permitidos al derivar el username de una cuenta de servicio
+var nonUsernameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// APIKeyService administra cuentas de servicio y sus API keys
+type APIKeyService struct {
+	db              *gorm.DB
+	passwordService *auth.PasswordService
+	auditService    *AuditService
+	apiPrefix       string
+}
+
+// NewAPIKeyService crea una nueva instancia del servicio de API keys
+func NewAPIKeyService(appCtx *config.AppContext) *APIKeyService {
+	return &APIKeyService{
+		db:              appCtx.DB,
+		passwordService: auth.NewPasswordService(),
+		auditService:    NewAuditService(appCtx.DB),
+		apiPrefix:       appCtx.Config.APIPrefix,
+	}
+}
+
+// ========================================
+// AUTENTICACIÓN
+// ========================================
+
+// Authenticate valida una key con formato gamc_<prefix>_<secreto> y retorna la key
+// junto con la cuenta de servicio propietaria
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey, ipAddress string) (*models.APIKey, *models.User, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("API key inválida")
+	}
+
+	var key models.APIKey
+	err := s.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("API key inválida")
+		}
+		return nil, nil, fmt.Errorf("error al buscar API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashClientSecret(rawKey)), []byte(key.KeyHash)) != 1 {
+		return nil, nil, fmt.Errorf("API key inválida")
+	}
+	if key.IsRevoked() {
+		return nil, nil, fmt.Errorf("API key revocada")
+	}
+	if key.IsExpired() {
+		return nil, nil, fmt.Errorf("API key expirada")
+	}
+
+	var account models.User
+	err = s.db.WithContext(ctx).
+		Preload("OrganizationalUnit").
+		Where("id = ? AND is_active = ? AND is_service_account = ?", key.ServiceAccountID, true, true).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("cuenta de servicio inactiva")
+		}
+		return nil, nil, fmt.Errorf("error al obtener cuenta de servicio: %w", err)
+	}
+
+	s.touch(ctx, &key, ipAddress)
+
+	return &key, &account, nil
+}
+
+// RequiredScope determina el scope que exige una ruta del API. Retorna false si la
+// ruta no está disponible para API keys (administración, OAuth y el resto de /auth).
+func (s *APIKeyService) RequiredScope(method, path string) (string, bool) {
+	path = strings.TrimPrefix(path, s.apiPrefix)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resource := segments[0]
+
+	read := method == http.MethodGet || method == http.MethodHead
+
+	switch resource {
+	case "auth":
+		// Solo la identidad de la cuenta: perfil y verificación del token
+		if read && len(segments) == 2 && (segments[1] == "profile" || segments[1] == "verify") {
+			return models.APIKeyScopeProfileRead, true
+		}
+		return "", false
+	case "messages", "files", "notifications":
+		if read {
+			return resource + ":read", true
+		}
+		return resource + ":write", true
+	default:
+		return "", false
+	}
+}
+
+// LogUse registra en auditoría una petición autenticada con API key
+func (s *APIKeyService) LogUse(ctx context.Context, key *models.APIKey, req *LogRequest) {
+	req.UserID = &key.ServiceAccountID
+	req.Action = models.AuditActionAPIKeyUsed
+	req.Resource = "api_key"
+	req.ResourceID = key.ID.String()
+	req.SessionID = "apikey:" + key.ID.String()
+	s.auditService.Log(ctx, req)
+}
+
+// touch actualiza la fecha e IP de último uso, como máximo una vez por minuto
+func (s *APIKeyService) touch(ctx context.Context, key *models.APIKey, ipAddress string) {
+	now := time.Now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < apiKeyLastUsedInterval && key.LastUsedIP == ipAddress {
+		return
+	}
+
+	err := s.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ?", key.ID).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ipAddress,
+		}).Error
+	if err != nil {
+		logger.Warn("Error al registrar uso de API key %s: %v", key.Prefix, err)
+		return
+	}
+
+	key.LastUsedAt = &now
+	key.LastUsedIP = ipAddress
+}
+
+// ========================================
+// CUENTAS DE SERVICIO
+// ========================================
+
+// ListServiceAccounts lista las cuentas de servicio con el resumen de sus keys
+func (s *APIKeyService) ListServiceAccounts(ctx context.Context) ([]models.ServiceAccountResponse, error) {
+	var accounts []models.User
+	err := s.db.WithContext(ctx).
+		Where("is_service_account = ?", true).
+		Order("username").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener cuentas de servicio: %w", err)
+	}
+
+	result := make([]models.ServiceAccountResponse, 0, len(accounts))
+	for i := range accounts {
+		summary, err := s.toServiceAccountResponse(ctx, &accounts[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *summary)
+	}
+
+	return result, nil
+}
+
+// CreateServiceAccount crea una cuenta de servicio. La cuenta no puede iniciar sesión
+// con contraseña y nunca tiene rol de administrador.
+func (s *APIKeyService) CreateServiceAccount(ctx context.Context, req *models.ServiceAccountCreateRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.ServiceAccountResponse, error) {
+	var orgUnit models.OrganizationalUnit
+	err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", req.OrganizationalUnitID, true).First(&orgUnit).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("unidad organizacional no encontrada")
+		}
+		return nil, fmt.Errorf("error al verificar unidad organizacional: %w", err)
+	}
+
+	username := "svc_" + strings.Trim(nonUsernameChars.ReplaceAllString(strings.ToLower(req.Name), "_"), "_")
+	if len(username) > 50 {
+		username = username[:50]
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("error al verificar cuenta de servicio: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("ya existe una cuenta de servicio con ese nombre")
+	}
+
+	// Contraseña aleatoria descartada: la cuenta solo se autentica con API keys
+	unusable, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar credenciales: %w", err)
+	}
+	passwordHash, err := s.passwordService.HashPassword(unusable)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar credenciales: %w", err)
+	}
+
+	firstName := req.Name
+	if len(firstName) > 50 {
+		firstName = firstName[:50]
+	}
+
+	account := &models.User{
+		Username:             username,
+		Email:                username + "@service.gamc.local",
+		PasswordHash:         passwordHash,
+		FirstName:            firstName,
+		LastName:             "Cuenta de servicio",
+		Role:                 req.Role,
+		OrganizationalUnitID: &req.OrganizationalUnitID,
+		IsActive:             true,
+		IsServiceAccount:     true,
+		Description:          req.Description,
+	}
+
+	if err := s.db.WithContext(ctx).Create(account).Error; err != nil {
+		return nil, fmt.Errorf("error al crear cuenta de servicio: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionCreate,
+		Resource:   "service_account",
+		ResourceID: account.ID.String(),
+		NewValues: map[string]interface{}{
+			"username":             account.Username,
+			"role":                 account.Role,
+			"organizationalUnitId": req.OrganizationalUnitID,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("🤖 Cuenta de servicio %s creada por %s", account.Username, adminID)
+
+	return s.toServiceAccountResponse(ctx, account)
+}
+
+// DeactivateServiceAccount desactiva la cuenta y revoca todas sus keys
+func (s *APIKeyService) DeactivateServiceAccount(ctx context.Context, accountID string, adminID uuid.UUID, ipAddress, userAgent string) error {
+	account, err := s.getServiceAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(account).UpdateColumn("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", account.ID).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_by": adminID}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error al desactivar cuenta de servicio: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionDelete,
+		Resource:   "service_account",
+		ResourceID: account.ID.String(),
+		OldValues:  map[string]interface{}{"username": account.Username, "isActive": true},
+		NewValues:  map[string]interface{}{"isActive": false},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🤖 Cuenta de servicio %s desactivada por %s", account.Username, adminID)
+
+	return nil
+}
+
+// ========================================
+// API KEYS
+// ========================================
+
+// ListKeys lista las keys de una cuenta de servicio (sin el secreto)
+func (s *APIKeyService) ListKeys(ctx context.Context, accountID string) ([]models.APIKey, error) {
+	account, err := s.getServiceAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.APIKey
+	err = s.db.WithContext(ctx).
+		Where("service_account_id = ?", account.ID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// CreateKey emite una nueva key. La key completa solo se retorna en esta respuesta.
+func (s *APIKeyService) CreateKey(ctx context.Context, accountID string, req *models.APIKeyCreateRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.APIKeySecretResponse, error) {
+	account, err := s.getServiceAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, fmt.Errorf("cuenta de servicio inactiva")
+	}
+
+	for _, scope := range req.Scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("scope no válido: %s", scope)
+		}
+	}
+
+	prefix, err := randomToken(6)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar API key: %w", err)
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar API key: %w", err)
+	}
+	rawKey := fmt.Sprintf("%s_%s_%s", models.APIKeyPrefix, prefix, secret)
+
+	key := &models.APIKey{
+		ServiceAccountID: account.ID,
+		Name:             req.Name,
+		Prefix:           prefix,
+		KeyHash:          hashClientSecret(rawKey),
+		Scopes:           req.Scopes,
+		CreatedBy:        &adminID,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, fmt.Errorf("error al crear API key: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionCreate,
+		Resource:   "api_key",
+		ResourceID: key.ID.String(),
+		NewValues: map[string]interface{}{
+			"serviceAccountId": account.ID.String(),
+			"prefix":           key.Prefix,
+			"scopes":           key.Scopes,
+			"expiresAt":        key.ExpiresAt,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("🔑 API key %s emitida para %s por %s", key.Prefix, account.Username, adminID)
+
+	return &models.APIKeySecretResponse{Key: rawKey, APIKey: key}, nil
+}
+
+// RevokeKey revoca una key de forma inmediata y permanente
+func (s *APIKeyService) RevokeKey(ctx context.Context, accountID, keyID string, adminID uuid.UUID, ipAddress, userAgent string) error {
+	account, err := s.getServiceAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return fmt.Errorf("API key no encontrada")
+	}
+
+	var key models.APIKey
+	err = s.db.WithContext(ctx).Where("id = ? AND service_account_id = ?", id, account.ID).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("API key no encontrada")
+		}
+		return fmt.Errorf("error al obtener API key: %w", err)
+	}
+	if key.IsRevoked() {
+		return fmt.Errorf("API key ya revocada")
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&key).Updates(map[string]interface{}{
+		"revoked_at": now,
+		"revoked_by": adminID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("error al revocar API key: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionAPIKeyRevoked,
+		Resource:   "api_key",
+		ResourceID: key.ID.String(),
+		OldValues:  map[string]interface{}{"serviceAccountId": account.ID.String(), "prefix": key.Prefix},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🔑 API key %s de %s revocada por %s", key.Prefix, account.Username, adminID)
+
+	return nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// getServiceAccount obtiene una cuenta de servicio por ID
+func (s *APIKeyService) getServiceAccount(ctx context.Context, accountID string) (*models.User, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("cuenta de servicio no encontrada")
+	}
+
+	var account models.User
+	err = s.db.WithContext(ctx).Where("id = ? AND is_service_account = ?", id, true).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("cuenta de servicio no encontrada")
+		}
+		return nil, fmt.Errorf("error al obtener cuenta de servicio: %w", err)
+	}
+
+	return &account, nil
+}
+
+// toServiceAccountResponse arma el resumen de una cuenta de servicio
+func (s *APIKeyService) toServiceAccountResponse(ctx context.Context, account *models.User) (*models.ServiceAccountResponse, error) {
+	var summary struct {
+		ActiveKeys int64
+		LastUsedAt *time.Time
+	}
+	err := s.db.WithContext(ctx).Model(&models.APIKey{}).
+		Select("COUNT(*) FILTER (WHERE revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)) AS active_keys, MAX(last_used_at) AS last_used_at", time.Now()).
+		Where("service_account_id = ?", account.ID).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener resumen de API keys: %w", err)
+	}
+
+	return &models.ServiceAccountResponse{
+		ID:                   account.ID,
+		Username:             account.Username,
+		Name:                 account.FirstName,
+		Description:          account.Description,
+		Role:                 account.Role,
+		OrganizationalUnitID: account.OrganizationalUnitID,
+		IsActive:             account.IsActive,
+		ActiveKeys:           summary.ActiveKeys,
+		LastUsedAt:           summary.LastUsedAt,
+		CreatedAt:            account.CreatedAt,
+	}, nil
+}
+
+// parseAPIKeyPrefix extrae el prefijo público de una key gamc_<prefix>_<secreto>
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != models.APIKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
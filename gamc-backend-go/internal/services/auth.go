@@ -19,6 +19,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AuthService maneja la lógica de negocio de autenticación
@@ -29,6 +30,16 @@ type AuthService struct {
 	blacklistManager *redis.JWTBlacklistManager
 	jwtService       *auth.JWTService
 	passwordService  *auth.PasswordService
+	twoFactorService *TwoFactorService
+	passkeyService   *PasskeyService
+	lockoutService   *LockoutService
+	sessionService   *SessionService
+	passwordPolicy   *PasswordPolicyService
+	policyService    *SecurityPolicyService
+	authenticators   *AuthenticatorChain
+	mailService      *MailService
+	verification     *EmailVerificationService
+	networkPolicy    *NetworkPolicyService
 	config           *config.Config
 }
 
@@ -41,6 +52,16 @@ func NewAuthService(appCtx *config.AppContext) *AuthService {
 		blacklistManager: redis.NewJWTBlacklistManager(appCtx.Redis),
 		jwtService:       auth.NewJWTService(appCtx.Config),
 		passwordService:  auth.NewPasswordService(),
+		twoFactorService: NewTwoFactorService(appCtx),
+		passkeyService:   NewPasskeyService(appCtx),
+		lockoutService:   NewLockoutService(appCtx),
+		sessionService:   NewSessionService(appCtx),
+		passwordPolicy:   NewPasswordPolicyService(appCtx),
+		policyService:    NewSecurityPolicyService(appCtx),
+		authenticators:   NewAuthenticatorChain(appCtx),
+		mailService:      NewMailService(appCtx),
+		verification:     NewEmailVerificationService(appCtx),
+		networkPolicy:    NewNetworkPolicyService(appCtx),
 		config:           appCtx.Config,
 	}
 }
@@ -54,7 +75,7 @@ type LoginRequest struct {
 // RegisterRequest representa una solicitud de registro
 type RegisterRequest struct {
 	Email                string                               `json:"email" validate:"required,email"`
-	Password             string                               `json:"password" validate:"required,min=8"`
+	Password             string                               `json:"password" validate:"required"`
 	FirstName            string                               `json:"firstName" validate:"required"`
 	LastName             string                               `json:"lastName" validate:"required"`
 	OrganizationalUnitID int                                  `json:"organizationalUnitId" validate:"required"`
@@ -65,7 +86,7 @@ type RegisterRequest struct {
 // ChangePasswordRequest representa una solicitud de cambio de contraseña
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword" validate:"required"`
-	NewPassword     string `json:"newPassword" validate:"required,min=8"`
+	NewPassword     string `json:"newPassword" validate:"required"`
 }
 
 // AuthResponse representa la respuesta de autenticación
@@ -74,16 +95,192 @@ type AuthResponse struct {
 	AccessToken  string              `json:"accessToken"`
 	RefreshToken string              `json:"refreshToken,omitempty"`
 	ExpiresIn    int64               `json:"expiresIn"`
+
+	// Login en dos pasos: si RequiresTwoFactor es true no se emiten tokens,
+	// solo un ChallengeToken que se canjea en /auth/login/2fa
+	RequiresTwoFactor      bool     `json:"requiresTwoFactor,omitempty"`
+	ChallengeToken         string   `json:"challengeToken,omitempty"`
+	TwoFactorMethods       []string `json:"twoFactorMethods,omitempty"` // totp y/o passkey
+	TwoFactorSetupRequired bool     `json:"twoFactorSetupRequired,omitempty"`
+
+	// Contraseña expirada o cambio exigido por un administrador: el access token
+	// solo permite cambiar la contraseña o cerrar sesión
+	MustChangePassword   bool   `json:"mustChangePassword,omitempty"`
+	PasswordChangeReason string `json:"passwordChangeReason,omitempty"`
+
+	// Sesión creada, para registrar el dispositivo del login
+	SessionID string `json:"-"`
 }
 
+// Métodos con los que se completa el segundo paso del login
+const (
+	TwoFactorMethodTOTP    = "totp"
+	TwoFactorMethodPasskey = "passkey"
+)
+
 // Login autentica un usuario y genera tokens
 func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, userAgent string) (*AuthResponse, error) {
-	// Buscar usuario por email
+	// Rechazar de inmediato si la cuenta o la IP están bloqueadas
+	if err := s.lockoutService.CheckLogin(ctx, req.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// Verificar credenciales en la cadena de autenticadores (local, luego directorio)
+	user, err := s.authenticators.Authenticate(ctx, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, ErrIdentityNotFound) || errors.Is(err, ErrInvalidCredentials) {
+			s.lockoutService.RecordFailure(ctx, req.Email, user, ipAddress, userAgent, failureReason(err))
+			return nil, fmt.Errorf("credenciales inválidas")
+		}
+		return nil, err
+	}
+
+	if err := checkLoginAllowed(ctx, s.networkPolicy, user, ipAddress, userAgent, "login"); err != nil {
+		return nil, err
+	}
+
+	// Verificar si el usuario debe completar el segundo factor
+	twoFactorEnabled, err := s.twoFactorService.IsEnabled(ctx, user.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar 2FA: %w", err)
+	}
+	hasPasskey, err := s.twoFactorService.HasPasskey(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	requiredForRole := s.twoFactorService.IsRequiredForRole(ctx, user.Role)
+
+	// Una passkey satisface el 2FA del rol aunque el usuario no tenga TOTP
+	if twoFactorEnabled || (hasPasskey && requiredForRole) {
+		challengeToken, err := s.twoFactorService.CreateChallenge(ctx, user.ID.String(), ipAddress, userAgent)
+		if err != nil {
+			return nil, err
+		}
+
+		methods := []string{}
+		if twoFactorEnabled {
+			methods = append(methods, TwoFactorMethodTOTP)
+		}
+		if hasPasskey {
+			methods = append(methods, TwoFactorMethodPasskey)
+		}
+
+		logger.Info("🔐 Desafío 2FA emitido para usuario %s (%s)", user.Email, strings.Join(methods, ", "))
+
+		return &AuthResponse{
+			RequiresTwoFactor: true,
+			ChallengeToken:    challengeToken,
+			TwoFactorMethods:  methods,
+			ExpiresIn:         int64(TwoFactorChallengeTTL.Seconds()),
+		}, nil
+	}
+
+	// Sin segundo factor pendiente el login está completo: recién ahora se reinicia el contador
+	s.lockoutService.RecordSuccess(ctx, req.Email)
+
+	// El rol exige 2FA pero el usuario aún no lo configuró: solo se emite un token
+	// restringido al enrolamiento. El cambio de contraseña pendiente va primero.
+	if requiredForRole && s.passwordChangeReason(ctx, user) == "" {
+		return s.createRestrictedSession(ctx, user, auth.RestrictionTwoFactorSetup, "", ipAddress, userAgent)
+	}
+
+	return s.createAuthenticatedSession(ctx, user, ipAddress, userAgent)
+}
+
+// BeginPasskeyLogin emite las opciones para autenticar con una passkey, sin contraseña
+// o como segundo factor del desafío de /auth/login
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context, req *models.PasskeyLoginOptionsRequest, ipAddress string) (*models.PasskeyLoginOptions, error) {
+	return s.passkeyService.BeginLogin(ctx, req, ipAddress)
+}
+
+// LoginWithPasskey completa el login con la firma de la passkey. Sin desafío 2FA es un
+// login sin contraseña: la verificación del usuario en el dispositivo (PIN o biometría)
+// más la posesión de la passkey ya son dos factores.
+func (s *AuthService) LoginWithPasskey(ctx context.Context, req *models.PasskeyLoginRequest, ipAddress, userAgent string) (*AuthResponse, error) {
+	assertion, err := s.passkeyService.VerifyLogin(ctx, req, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
 	var user models.User
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
 		Preload("OrganizationalUnit").
 		Preload("SecurityQuestions", "is_active = ?", true).
-		Where("email = ? AND is_active = ?", req.Email, true).
+		Where("id = ? AND is_active = ? AND is_service_account = ?", assertion.UserID, true, false).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("passkey no reconocida")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	// Segundo factor del login con contraseña: la cuenta ya se verificó en el primer paso
+	if assertion.TwoFactorToken != "" {
+		if err := s.lockoutService.CheckLogin(ctx, user.Email, ipAddress); err != nil {
+			return nil, err
+		}
+		if err := s.twoFactorService.CompleteChallenge(ctx, assertion.TwoFactorToken, user.ID.String()); err != nil {
+			return nil, err
+		}
+		s.lockoutService.RecordSuccess(ctx, user.Email)
+
+		logger.Info("✅ Segundo factor verificado con passkey para usuario %s", user.Email)
+		return s.createAuthenticatedSession(ctx, &user, ipAddress, userAgent)
+	}
+
+	if err := s.lockoutService.CheckLogin(ctx, user.Email, ipAddress); err != nil {
+		return nil, err
+	}
+	if err := checkLoginAllowed(ctx, s.networkPolicy, &user, ipAddress, userAgent, "login"); err != nil {
+		return nil, err
+	}
+	s.lockoutService.RecordSuccess(ctx, user.Email)
+
+	logger.Info("🔑 Login sin contraseña con passkey %d para usuario %s", assertion.CredentialID, user.Email)
+
+	return s.createAuthenticatedSession(ctx, &user, ipAddress, userAgent)
+}
+
+// checkLoginAllowed verifica el estado de la cuenta y la red antes de emitir cualquier
+// token, sea cual sea el método con el que el usuario se identificó (también OAuth)
+func checkLoginAllowed(ctx context.Context, networkPolicy *NetworkPolicyService, user *models.User, ipAddress, userAgent, origin string) error {
+	// Cuentas temporales vencidas (contratistas, pasantes)
+	if user.IsAccountExpired() {
+		return ErrAccountExpired
+	}
+
+	// Las cuentas auto-registradas no inician sesión hasta confirmar su email
+	if user.IsPendingVerification() {
+		return ErrEmailNotVerified
+	}
+
+	// Tras un "No fui yo" la contraseña se considera comprometida
+	if user.PasswordResetRequiredAt != nil && !user.IsDirectoryUser() {
+		return ErrPasswordResetRequired
+	}
+
+	// Los rangos IP del rol y de la unidad se verifican antes de emitir cualquier token
+	return networkPolicy.Authorize(ctx, &NetworkSubject{
+		UserID:               user.ID,
+		Email:                user.Email,
+		Role:                 user.Role,
+		OrganizationalUnitID: user.OrganizationalUnitID,
+	}, ipAddress, userAgent, origin)
+}
+
+// VerifyTwoFactorLogin completa el login canjeando el desafío y el código 2FA por los tokens
+func (s *AuthService) VerifyTwoFactorLogin(ctx context.Context, req *models.TwoFactorLoginRequest, ipAddress, userAgent string) (*AuthResponse, error) {
+	challenge, err := s.twoFactorService.GetChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = s.db.WithContext(ctx).
+		Preload("OrganizationalUnit").
+		Preload("SecurityQuestions", "is_active = ?", true).
+		Where("id = ? AND is_active = ?", challenge.UserID, true).
 		First(&user).Error
 
 	if err != nil {
@@ -93,9 +290,31 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, u
 		return nil, fmt.Errorf("error al buscar usuario: %w", err)
 	}
 
-	// Verificar contraseña
-	if err := s.passwordService.ComparePassword(req.Password, user.PasswordHash); err != nil {
-		return nil, fmt.Errorf("credenciales inválidas")
+	// Los códigos fallidos cuentan para el bloqueo de la cuenta y de la IP igual que
+	// las contraseñas: pedir desafíos nuevos no permite seguir probando códigos
+	if err := s.lockoutService.CheckLogin(ctx, user.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.twoFactorService.VerifyChallenge(ctx, req.ChallengeToken, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrTooManyTwoFactorAttempts) {
+			s.lockoutService.RecordFailure(ctx, user.Email, &user, ipAddress, userAgent, "segundo factor: "+err.Error())
+		}
+		return nil, err
+	}
+
+	s.lockoutService.RecordSuccess(ctx, user.Email)
+	logger.Info("✅ Segundo factor verificado para usuario %s", user.Email)
+
+	return s.createAuthenticatedSession(ctx, &user, ipAddress, userAgent)
+}
+
+// createAuthenticatedSession crea la sesión en Redis y emite el par de tokens JWT
+func (s *AuthService) createAuthenticatedSession(ctx context.Context, user *models.User, ipAddress, userAgent string) (*AuthResponse, error) {
+	// Con la contraseña expirada o el cambio exigido por un administrador solo se emite
+	// un token restringido al cambio de contraseña
+	if reason := s.passwordChangeReason(ctx, user); reason != "" {
+		return s.createRestrictedSession(ctx, user, auth.RestrictionPasswordChange, reason, ipAddress, userAgent)
 	}
 
 	// Generar ID de sesión
@@ -114,8 +333,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, u
 		UserAgent:            userAgent,
 	}
 
-	// Guardar sesión en Redis
-	sessionTTL := 7 * 24 * time.Hour // 7 días
+	// Guardar sesión en Redis; expira tras la inactividad del rol o la vigencia máxima de la política
+	sessionTTL := s.sessionService.SessionTTL(ctx, sessionData)
 	if err := s.sessionManager.SaveSession(ctx, sessionID, sessionData, sessionTTL); err != nil {
 		return nil, fmt.Errorf("error al guardar sesión: %w", err)
 	}
@@ -138,10 +357,15 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, u
 		return nil, fmt.Errorf("error al guardar refresh token: %w", err)
 	}
 
+	// Registrar el access token para poder revocarlo junto con la sesión
+	if err := s.sessionService.TrackAccessToken(ctx, sessionID, accessToken); err != nil {
+		logger.Warn("Error al registrar access token de la sesión %s: %v", sessionID, err)
+	}
+
 	// Actualizar último login
 	now := time.Now()
 	user.LastLogin = &now
-	s.db.WithContext(ctx).Save(&user)
+	s.db.WithContext(ctx).Save(user)
 
 	// Crear perfil de usuario para respuesta
 	userProfile := user.ToProfile()
@@ -151,11 +375,86 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, u
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresIn:    int64(s.config.JWTExpiresIn.Seconds()),
+		SessionID:    sessionID,
 	}, nil
 }
 
-// Register registra un nuevo usuario
-func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*models.UserProfile, error) {
+// Motivos por los que el login solo emite un token restringido al cambio de contraseña
+const (
+	PasswordChangeReasonExpired = "expired"
+	PasswordChangeReasonAdmin   = "admin_required"
+)
+
+// passwordChangeReason motivo por el que el usuario debe cambiar su contraseña ("" si no debe)
+func (s *AuthService) passwordChangeReason(ctx context.Context, user *models.User) string {
+	if user.MustChangePassword && !user.IsDirectoryUser() {
+		return PasswordChangeReasonAdmin
+	}
+	if s.passwordPolicy.IsExpired(ctx, user) {
+		return PasswordChangeReasonExpired
+	}
+	return ""
+}
+
+// createRestrictedSession crea una sesión de corta duración sin refresh token cuyo
+// access token solo permite cambiar la contraseña (RestrictionPasswordChange) o
+// enrolar 2FA (RestrictionTwoFactorSetup), además de cerrar sesión
+func (s *AuthService) createRestrictedSession(ctx context.Context, user *models.User, restriction, reason, ipAddress, userAgent string) (*AuthResponse, error) {
+	sessionID := uuid.New().String()
+
+	sessionData := &redis.SessionData{
+		UserID:               user.ID.String(),
+		Email:                user.Email,
+		Role:                 user.Role,
+		OrganizationalUnitID: *user.OrganizationalUnitID,
+		SessionID:            sessionID,
+		CreatedAt:            time.Now(),
+		LastActivity:         time.Now(),
+		IPAddress:            ipAddress,
+		UserAgent:            userAgent,
+	}
+
+	if err := s.sessionManager.SaveSession(ctx, sessionID, sessionData, s.config.JWTExpiresIn); err != nil {
+		return nil, fmt.Errorf("error al guardar sesión: %w", err)
+	}
+
+	accessToken, err := s.jwtService.GenerateRestrictedAccessToken(
+		user.ID.String(),
+		user.Email,
+		user.Role,
+		*user.OrganizationalUnitID,
+		sessionID,
+		restriction,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar tokens: %w", err)
+	}
+
+	if err := s.sessionService.TrackAccessToken(ctx, sessionID, accessToken); err != nil {
+		logger.Warn("Error al registrar access token de la sesión %s: %v", sessionID, err)
+	}
+
+	result := &AuthResponse{
+		User:        user.ToProfile(),
+		AccessToken: accessToken,
+		ExpiresIn:   int64(s.config.JWTExpiresIn.Seconds()),
+		SessionID:   sessionID,
+	}
+
+	if restriction == auth.RestrictionTwoFactorSetup {
+		logger.Warn("🔐 2FA requerido por el rol sin configurar para usuario %s: sesión restringida al enrolamiento", user.Email)
+		result.TwoFactorSetupRequired = true
+		return result, nil
+	}
+
+	logger.Warn("🔑 Cambio de contraseña pendiente (%s) para usuario %s: sesión restringida al cambio de contraseña", reason, user.Email)
+	result.MustChangePassword = true
+	result.PasswordChangeReason = reason
+	return result, nil
+}
+
+// Register registra un nuevo usuario pendiente de verificar su email
+func (s *AuthService) Register(ctx context.Context, req *RegisterRequest, ipAddress string) (*models.UserProfile, error) {
 	// Verificar si el usuario ya existe
 	var existingUser models.User
 	err := s.db.WithContext(ctx).Where("email = ?", req.Email).First(&existingUser).Error
@@ -235,6 +534,10 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*mode
 			return fmt.Errorf("error al crear usuario: %w", err)
 		}
 
+		if err := s.passwordPolicy.RecordPassword(ctx, tx, user.ID, passwordHash); err != nil {
+			return err
+		}
+
 		// Configurar preguntas de seguridad si se proporcionaron
 		if req.SecurityQuestions != nil {
 			for _, q := range req.SecurityQuestions.Questions {
@@ -269,23 +572,32 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*mode
 		logger.Info("✅ Preguntas de seguridad configuradas: %d", len(req.SecurityQuestions.Questions))
 	}
 
+	// El usuario ya existe: si el envío falla puede pedir otro código con el reenvío
+	if err := s.verification.Issue(ctx, &user, ipAddress); err != nil {
+		logger.Error("Error al enviar verificación de email a %s: %v", user.Email, err)
+	}
+
 	return user.ToProfile(), nil
 }
 
-// RefreshToken renueva un access token usando un refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+// RefreshToken renueva el par de tokens rotando el refresh token: cada refresh token
+// sirve una sola vez. Si se presenta uno ya rotado se revoca la sesión completa.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*AuthResponse, error) {
 	// Verificar refresh token
 	claims, err := s.jwtService.VerifyRefreshToken(refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("refresh token inválido: %w", err)
 	}
 
-	// Verificar que el refresh token existe en Redis
+	// Verificar que el refresh token es el vigente de la sesión
 	storedToken, err := s.refreshManager.GetRefreshToken(ctx, claims.UserID, claims.SessionID)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener refresh token: %w", err)
 	}
 	if storedToken != refreshToken {
+		if err := s.checkRefreshTokenReuse(ctx, claims, refreshToken, ipAddress, userAgent); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("refresh token inválido")
 	}
 
@@ -298,6 +610,14 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 		return nil, fmt.Errorf("sesión expirada")
 	}
 
+	// Renovar tokens no cuenta como actividad: no extiende la inactividad ni la vigencia máxima
+	if IsInteractiveSession(sessionData) {
+		if reason := s.sessionService.Expiry(ctx, sessionData); reason != "" {
+			s.sessionService.ExpireSession(ctx, sessionData, reason)
+			return nil, fmt.Errorf("sesión expirada")
+		}
+	}
+
 	// Generar nuevos tokens
 	newAccessToken, newRefreshToken, err := s.jwtService.GenerateTokens(
 		sessionData.UserID,
@@ -310,17 +630,35 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 		return nil, fmt.Errorf("error al generar nuevos tokens: %w", err)
 	}
 
-	// Actualizar refresh token en Redis
+	// Rotar refresh token: el anterior queda registrado en la familia de la sesión
 	refreshTTL := 7 * 24 * time.Hour
-	if err := s.refreshManager.SaveRefreshToken(ctx, sessionData.UserID, sessionData.SessionID, newRefreshToken, refreshTTL); err != nil {
+	rotated, err := s.refreshManager.RotateRefreshToken(ctx, sessionData.UserID, sessionData.SessionID, refreshToken, newRefreshToken, refreshTTL)
+	if err != nil {
 		return nil, fmt.Errorf("error al actualizar refresh token: %w", err)
 	}
+	if !rotated {
+		// Otra petición rotó el mismo token entre la lectura y la rotación
+		if err := s.checkRefreshTokenReuse(ctx, claims, refreshToken, ipAddress, userAgent); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("refresh token inválido")
+	}
+
+	if err := s.sessionService.TrackAccessToken(ctx, sessionData.SessionID, newAccessToken); err != nil {
+		logger.Warn("Error al registrar access token de la sesión %s: %v", sessionData.SessionID, err)
+	}
 
 	// Obtener perfil actualizado del usuario
 	userProfile, err := s.GetUserProfile(ctx, sessionData.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener perfil de usuario: %w", err)
 	}
+	if userProfile.IsAccountExpired() {
+		if err := s.sessionService.EndSession(ctx, sessionData.UserID, sessionData.SessionID); err != nil {
+			logger.Warn("Error al cerrar sesión de cuenta expirada %s: %v", sessionData.SessionID, err)
+		}
+		return nil, ErrAccountExpired
+	}
 
 	return &AuthResponse{
 		User:         userProfile,
@@ -330,30 +668,32 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 	}, nil
 }
 
+// checkRefreshTokenReuse revoca la sesión si el refresh token presentado ya había sido rotado
+func (s *AuthService) checkRefreshTokenReuse(ctx context.Context, claims *auth.RefreshTokenClaims, refreshToken, ipAddress, userAgent string) error {
+	reused, err := s.refreshManager.IsRotatedToken(ctx, claims.SessionID, refreshToken)
+	if err != nil {
+		return fmt.Errorf("error al verificar refresh token: %w", err)
+	}
+	if !reused {
+		return nil
+	}
+
+	s.sessionService.RevokeCompromisedSession(ctx, claims.UserID, claims.SessionID, ipAddress, userAgent)
+	return fmt.Errorf("refresh token reutilizado, sesión revocada")
+}
+
 // Logout cierra la sesión del usuario
 func (s *AuthService) Logout(ctx context.Context, userID, sessionID string, logoutAll bool) error {
 	if logoutAll {
 		// Logout de todas las sesiones del usuario
-		sessions, err := s.sessionManager.GetUserSessions(ctx, userID)
-		if err != nil {
+		if _, err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
 			return fmt.Errorf("error al obtener sesiones de usuario: %w", err)
 		}
-
-		for _, sid := range sessions {
-			s.sessionManager.DeleteSession(ctx, sid)
-			s.refreshManager.DeleteRefreshToken(ctx, userID, sid)
-		}
-	} else {
-		// Logout de sesión específica
-		if err := s.sessionManager.DeleteSession(ctx, sessionID); err != nil {
-			return fmt.Errorf("error al eliminar sesión: %w", err)
-		}
-		if err := s.refreshManager.DeleteRefreshToken(ctx, userID, sessionID); err != nil {
-			return fmt.Errorf("error al eliminar refresh token: %w", err)
-		}
+		return nil
 	}
 
-	return nil
+	// Logout de sesión específica
+	return s.sessionService.EndSession(ctx, userID, sessionID)
 }
 
 // ChangePassword cambia la contraseña del usuario
@@ -368,6 +708,10 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, req *Ch
 		return fmt.Errorf("error al buscar usuario: %w", err)
 	}
 
+	if user.IsDirectoryUser() {
+		return fmt.Errorf("la contraseña de los usuarios del directorio se cambia en el directorio institucional")
+	}
+
 	// Verificar contraseña actual
 	if err := s.passwordService.ComparePassword(req.CurrentPassword, user.PasswordHash); err != nil {
 		return fmt.Errorf("contraseña actual incorrecta")
@@ -378,21 +722,28 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, req *Ch
 		return fmt.Errorf("nueva contraseña inválida: %v", validationErrors)
 	}
 
+	// Impedir reutilizar contraseñas recientes
+	if err := s.passwordPolicy.CheckReuse(ctx, &user, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hashear nueva contraseña
 	newPasswordHash, err := s.passwordService.HashPassword(req.NewPassword)
 	if err != nil {
 		return fmt.Errorf("error al hashear nueva contraseña: %w", err)
 	}
 
-	// Actualizar contraseña
+	// Actualizar contraseña y registrarla en el historial; cumple el cambio exigido por el administrador
 	user.PasswordHash = newPasswordHash
 	user.PasswordChangedAt = time.Now()
+	user.MustChangePassword = false
 
-	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
-		return fmt.Errorf("error al actualizar contraseña: %w", err)
-	}
-
-	return nil
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return fmt.Errorf("error al actualizar contraseña: %w", err)
+		}
+		return s.passwordPolicy.RecordPassword(ctx, tx, user.ID, newPasswordHash)
+	})
 }
 
 // GetUserProfile obtiene el perfil de un usuario
@@ -454,9 +805,30 @@ func (s *AuthService) GetUserSecurityStatus(ctx context.Context, userID string)
 		HasSecurityQuestions: user.HasSecurityQuestionsConfigured(),
 		QuestionsCount:       user.GetSecurityQuestionsCount(),
 		MaxQuestions:         models.MaxSecurityQuestionsPerUser,
+		RequiredQuestions:    s.policyService.Current(ctx).RequiredSecurityAnswers(),
 		Questions:            make([]models.UserSecurityQuestionResponse, 0),
 	}
 
+	// Preguntas retiradas del catálogo después de la última vez que el usuario configuró
+	// las suyas: se le pide elegir otras
+	var retired []models.SecurityQuestion
+	err = s.db.WithContext(ctx).
+		Table("security_questions sq").
+		Select("sq.*").
+		Joins("JOIN user_security_questions usq ON usq.security_question_id = sq.id").
+		Where("usq.user_id = ? AND usq.is_active = ? AND sq.is_active = ?", user.ID, false, false).
+		Where("sq.deactivated_at IS NOT NULL AND usq.updated_at >= sq.deactivated_at").
+		Where("NOT EXISTS (SELECT 1 FROM user_security_questions a WHERE a.user_id = usq.user_id AND a.is_active = true AND a.updated_at > usq.updated_at)").
+		Order("sq.deactivated_at").
+		Find(&retired).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener preguntas retiradas: %w", err)
+	}
+	for _, q := range retired {
+		status.RetiredQuestions = append(status.RetiredQuestions, *q.ToResponse())
+	}
+	status.NeedsReconfiguration = len(retired) > 0
+
 	// Convertir preguntas a respuestas
 	for _, sq := range user.GetActiveSecurityQuestions() {
 		status.Questions = append(status.Questions, *sq.ToResponse())
@@ -529,6 +901,23 @@ func (s *AuthService) SetupSecurityQuestions(ctx context.Context, userID string,
 				return fmt.Errorf("pregunta de seguridad ID %d no válida", reqQ.QuestionID)
 			}
 
+			// Una respuesta anterior (eliminada o de una pregunta retirada y reactivada) se
+			// reactiva con la nueva respuesta: (user_id, security_question_id) es único
+			var previous models.UserSecurityQuestion
+			err = tx.Where("user_id = ? AND security_question_id = ? AND is_active = ?", user.ID, reqQ.QuestionID, false).
+				First(&previous).Error
+			if err == nil {
+				previous.AnswerHash = s.hashSecurityAnswer(reqQ.Answer)
+				previous.IsActive = true
+				if err := tx.Save(&previous).Error; err != nil {
+					return fmt.Errorf("error al crear pregunta de seguridad: %w", err)
+				}
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("error al buscar pregunta de seguridad: %w", err)
+			}
+
 			// Crear pregunta del usuario
 			userSecQuestion := models.UserSecurityQuestion{
 				UserID:             user.ID,
@@ -622,7 +1011,7 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, req *models.Pass
 	err := s.db.WithContext(ctx).
 		Preload("SecurityQuestions", "is_active = ?", true).
 		Preload("SecurityQuestions.SecurityQuestion").
-		Where("email = ? AND is_active = ?", req.Email, true).
+		Where("email = ? AND is_active = ? AND is_service_account = ? AND auth_source = ?", req.Email, true, false, models.AuthSourceLocal).
 		First(&user).Error
 
 	if err != nil {
@@ -651,8 +1040,16 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, req *models.Pass
 		return nil, fmt.Errorf("error al generar token: %w", err)
 	}
 
-	// Verificar si tiene preguntas de seguridad
+	// Verificar si tiene preguntas de seguridad y elegir las del desafío
 	hasSecurityQuestions := user.HasSecurityQuestionsConfigured()
+	challenge := &resetChallenge{questionIDs: []int{}, attempts: map[int]int{}}
+	if hasSecurityQuestions {
+		challenge, err = s.prepareResetChallenge(ctx, &user)
+		if err != nil {
+			logger.Warn("Reset bloqueado para %s desde IP %s: %v", user.Email, requestIP, err)
+			return nil, err
+		}
+	}
 
 	// Crear registro de reset token
 	resetToken := &models.PasswordResetToken{
@@ -664,6 +1061,9 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, req *models.Pass
 		IsActive:                 true,
 		RequiresSecurityQuestion: hasSecurityQuestions,
 		SecurityQuestionVerified: !hasSecurityQuestions, // Si no tiene preguntas, ya está verificado
+		SecurityQuestionAttempts: challenge.totalAttempts,
+		ChallengeQuestionIDs:     challenge.questionIDs,
+		QuestionAttempts:         challenge.attempts,
 	}
 
 	// Guardar token (el trigger de DB validará automáticamente)
@@ -687,31 +1087,51 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, req *models.Pass
 
 	if hasSecurityQuestions {
 		response.Message = "Responda la pregunta de seguridad para continuar"
-		// Incluir pregunta de seguridad pero NO el token
-		if len(user.SecurityQuestions) > 0 {
-			selectedQuestion := user.SecurityQuestions[0] // Por simplicidad, tomar la primera
-			response.SecurityQuestion = &models.SecurityQuestionForResetResponse{
-				QuestionID:   selectedQuestion.SecurityQuestionID,
-				QuestionText: selectedQuestion.SecurityQuestion.QuestionText,
-				Attempts:     0,
-				MaxAttempts:  models.MaxSecurityQuestionAttempts,
-			}
-		}
+		// Incluir la primera pregunta del desafío pero NO el token
+		response.SecurityQuestion = challengeQuestion(&user, resetToken)
 	} else {
 		response.Message = "Token de reset enviado a su email institucional"
-		// TODO: Aquí enviar email real con el token cuando se implemente el servicio de email
-		logger.Info("Token de reset para usuario sin preguntas de seguridad: %s", token)
+		s.sendResetEmail(ctx, &user, resetToken, requestIP)
 	}
 
 	return response, nil
 }
 
-// VerifySecurityQuestion verifica la respuesta a la pregunta de seguridad
+// sendResetEmail encola el correo con el enlace de restablecimiento
+func (s *AuthService) sendResetEmail(ctx context.Context, user *models.User, resetToken *models.PasswordResetToken, requestIP string) {
+	if !s.mailService.Enabled() {
+		if s.config.Environment == "development" {
+			logger.Info("Token de reset para %s (correo desactivado): %s", user.Email, resetToken.Token)
+		}
+		return
+	}
+
+	_, err := s.mailService.Enqueue(ctx, &EmailRequest{
+		UserID:   &user.ID,
+		To:       user.Email,
+		Name:     user.FirstName,
+		Template: models.EmailTemplatePasswordReset,
+		Data: map[string]interface{}{
+			"Token":          resetToken.Token,
+			"ResetURL":       s.mailService.Link("/reset-password?token=" + resetToken.Token),
+			"ExpiresMinutes": int(time.Until(resetToken.ExpiresAt).Round(time.Minute).Minutes()),
+			"IPAddress":      requestIP,
+		},
+	})
+	if err != nil {
+		logger.Error("Error al encolar correo de reset para %s: %v", user.Email, err)
+	}
+}
+
+// VerifySecurityQuestion verifica la respuesta a la pregunta en curso del desafío.
+// Si la política exige dos respuestas, la primera correcta devuelve la siguiente pregunta;
+// el token solo se entrega al completar el desafío.
 func (s *AuthService) VerifySecurityQuestion(ctx context.Context, req *models.PasswordResetVerifySecurityRequest, requestIP string) (*models.PasswordResetVerifySecurityResponse, error) {
 	// Buscar usuario y token por email y pregunta (sin exponer el token aún)
 	var user models.User
 	err := s.db.WithContext(ctx).
 		Preload("SecurityQuestions", "is_active = ?", true).
+		Preload("SecurityQuestions.SecurityQuestion").
 		Where("email = ? AND is_active = ?", req.Email, true).
 		First(&user).Error
 
@@ -725,87 +1145,125 @@ func (s *AuthService) VerifySecurityQuestion(ctx context.Context, req *models.Pa
 		}, nil
 	}
 
-	// Buscar el token más reciente y activo para este usuario
-	var resetToken models.PasswordResetToken
-	err = s.db.WithContext(ctx).
-		Where("user_id = ? AND is_active = ? AND requires_security_question = ?",
-			user.ID, true, true).
-		Order("created_at DESC").
-		First(&resetToken).Error
+	var response *models.PasswordResetVerifySecurityResponse
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bloquear el token: los intentos concurrentes no pueden perder incrementos
+		var resetToken models.PasswordResetToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND is_active = ? AND requires_security_question = ?",
+				user.ID, true, true).
+			Order("created_at DESC").
+			First(&resetToken).Error
 
-	if err != nil {
-		return &models.PasswordResetVerifySecurityResponse{
-			Success:           false,
-			Message:           "No hay solicitud de reset activa",
-			Verified:          false,
-			CanProceedToReset: false,
-			AttemptsRemaining: 0,
-		}, nil
-	}
+		if err != nil {
+			response = &models.PasswordResetVerifySecurityResponse{
+				Success:           false,
+				Message:           "No hay solicitud de reset activa",
+				Verified:          false,
+				CanProceedToReset: false,
+				AttemptsRemaining: 0,
+			}
+			return nil
+		}
 
-	// Validar token
-	if !resetToken.IsValid() || resetToken.HasExceededSecurityAttempts() {
-		return &models.PasswordResetVerifySecurityResponse{
-			Success:           false,
-			Message:           "Solicitud de reset inválida o expirada",
-			Verified:          false,
-			CanProceedToReset: false,
-			AttemptsRemaining: 0,
-		}, nil
-	}
+		// Validar token
+		if !resetToken.IsValid() || resetToken.HasExceededSecurityAttempts() {
+			response = &models.PasswordResetVerifySecurityResponse{
+				Success:           false,
+				Message:           "Solicitud de reset inválida o expirada",
+				Verified:          false,
+				CanProceedToReset: false,
+				AttemptsRemaining: 0,
+			}
+			return nil
+		}
 
-	// Buscar la pregunta del usuario
-	var userSecQuestion models.UserSecurityQuestion
-	err = s.db.WithContext(ctx).
-		Where("user_id = ? AND security_question_id = ? AND is_active = ?",
-			user.ID, req.QuestionID, true).
-		First(&userSecQuestion).Error
+		// Solo se acepta la pregunta en curso; responder otra cuenta como intento fallido
+		questionID := req.QuestionID
+		if current, ok := resetToken.CurrentChallengeQuestionID(); ok {
+			questionID = current
+		}
 
-	if err != nil {
-		resetToken.IncrementSecurityAttempts()
-		s.db.WithContext(ctx).Save(&resetToken)
+		var userSecQuestion models.UserSecurityQuestion
+		if questionID == req.QuestionID {
+			err = tx.Where("user_id = ? AND security_question_id = ? AND is_active = ?",
+				user.ID, questionID, true).
+				First(&userSecQuestion).Error
+		}
 
-		return &models.PasswordResetVerifySecurityResponse{
-			Success:           false,
-			Message:           "Pregunta de seguridad no válida",
-			Verified:          false,
-			CanProceedToReset: false,
-			AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
-		}, nil
-	}
+		if questionID != req.QuestionID || err != nil {
+			resetToken.RecordWrongAnswer(questionID)
+			if err := tx.Save(&resetToken).Error; err != nil {
+				return fmt.Errorf("error al registrar intento: %w", err)
+			}
 
-	// Verificar respuesta
-	providedAnswerHash := s.hashSecurityAnswer(req.Answer)
-	if providedAnswerHash != userSecQuestion.AnswerHash {
-		resetToken.IncrementSecurityAttempts()
-		s.db.WithContext(ctx).Save(&resetToken)
+			response = &models.PasswordResetVerifySecurityResponse{
+				Success:           false,
+				Message:           "Pregunta de seguridad no válida",
+				Verified:          false,
+				CanProceedToReset: false,
+				AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
+			}
+			return nil
+		}
 
-		return &models.PasswordResetVerifySecurityResponse{
-			Success:           false,
-			Message:           "Respuesta incorrecta",
-			Verified:          false,
-			CanProceedToReset: false,
-			AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
-			ResetToken:        "", // NO devolver token si la respuesta es incorrecta
-		}, nil
-	}
+		// Verificar respuesta
+		if !s.verifySecurityAnswer(ctx, &userSecQuestion, req.Answer) {
+			resetToken.RecordWrongAnswer(questionID)
+			if err := tx.Save(&resetToken).Error; err != nil {
+				return fmt.Errorf("error al registrar intento: %w", err)
+			}
 
-	// Respuesta correcta - marcar como verificada y DEVOLVER EL TOKEN
-	resetToken.MarkSecurityQuestionVerified()
-	if err := s.db.WithContext(ctx).Save(&resetToken).Error; err != nil {
-		return nil, fmt.Errorf("error al marcar pregunta como verificada: %w", err)
-	}
+			logger.Warn("Respuesta de seguridad incorrecta para usuario %s desde IP %s (pregunta %d, %d intentos restantes)",
+				user.Email, requestIP, questionID, resetToken.GetSecurityAttemptsRemaining())
 
-	logger.Info("Pregunta de seguridad verificada para usuario %s desde IP %s - Token proporcionado", user.Email, requestIP)
+			response = &models.PasswordResetVerifySecurityResponse{
+				Success:           false,
+				Message:           "Respuesta incorrecta",
+				Verified:          false,
+				CanProceedToReset: false,
+				AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
+				ResetToken:        "", // NO devolver token si la respuesta es incorrecta
+			}
+			return nil
+		}
 
-	return &models.PasswordResetVerifySecurityResponse{
-		Success:           true,
-		Message:           "Pregunta de seguridad verificada correctamente",
-		Verified:          true,
-		CanProceedToReset: true,
-		AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
-		ResetToken:        resetToken.Token, // AQUÍ DEVOLVEMOS EL TOKEN
-	}, nil
+		// Respuesta correcta: avanzar el desafío
+		resetToken.RecordCorrectAnswer()
+		if err := tx.Save(&resetToken).Error; err != nil {
+			return fmt.Errorf("error al marcar pregunta como verificada: %w", err)
+		}
+
+		if !resetToken.SecurityQuestionVerified {
+			response = &models.PasswordResetVerifySecurityResponse{
+				Success:           true,
+				Message:           "Respuesta correcta. Responda la siguiente pregunta de seguridad",
+				Verified:          false,
+				CanProceedToReset: false,
+				AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
+				NextQuestion:      challengeQuestion(&user, &resetToken),
+			}
+			return nil
+		}
+
+		logger.Info("Pregunta de seguridad verificada para usuario %s desde IP %s - Token proporcionado", user.Email, requestIP)
+
+		// Desafío completo - DEVOLVER EL TOKEN
+		response = &models.PasswordResetVerifySecurityResponse{
+			Success:           true,
+			Message:           "Pregunta de seguridad verificada correctamente",
+			Verified:          true,
+			CanProceedToReset: true,
+			AttemptsRemaining: resetToken.GetSecurityAttemptsRemaining(),
+			ResetToken:        resetToken.Token, // AQUÍ DEVOLVEMOS EL TOKEN
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
 
 // GetPasswordResetStatus obtiene el estado de un usuario durante el proceso de reset
@@ -859,14 +1317,8 @@ func (s *AuthService) GetPasswordResetStatusByEmail(ctx context.Context, email s
 
 	// Agregar información de pregunta de seguridad si es necesaria y no está verificada
 	if resetToken.RequiresSecurityQuestion && !resetToken.SecurityQuestionVerified && len(user.SecurityQuestions) > 0 {
-		// Seleccionar pregunta para mostrar
-		selectedQuestion := user.SecurityQuestions[0]
-		status.SecurityQuestion = &models.SecurityQuestionForResetResponse{
-			QuestionID:   selectedQuestion.SecurityQuestionID,
-			QuestionText: selectedQuestion.SecurityQuestion.QuestionText,
-			Attempts:     resetToken.SecurityQuestionAttempts,
-			MaxAttempts:  models.MaxSecurityQuestionAttempts,
-		}
+		// Pregunta en curso del desafío
+		status.SecurityQuestion = challengeQuestion(&user, &resetToken)
 	}
 
 	return status, nil
@@ -922,6 +1374,9 @@ func (s *AuthService) ConfirmPasswordReset(ctx context.Context, req *models.Pass
 		}
 
 		if !verifyResp.Verified {
+			if verifyResp.NextQuestion != nil {
+				return fmt.Errorf("debe responder también la siguiente pregunta de seguridad")
+			}
 			return fmt.Errorf("respuesta de seguridad incorrecta: %s", verifyResp.Message)
 		}
 
@@ -942,6 +1397,11 @@ func (s *AuthService) ConfirmPasswordReset(ctx context.Context, req *models.Pass
 		return fmt.Errorf("nueva contraseña inválida: %v", validationErrors)
 	}
 
+	// Impedir reutilizar contraseñas recientes
+	if err := s.passwordPolicy.CheckReuse(ctx, resetToken.User, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hashear nueva contraseña
 	newPasswordHash, err := s.passwordService.HashPassword(req.NewPassword)
 	if err != nil {
@@ -952,12 +1412,18 @@ func (s *AuthService) ConfirmPasswordReset(ctx context.Context, req *models.Pass
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Actualizar contraseña del usuario
 		if err := tx.Model(&resetToken.User).Updates(map[string]interface{}{
-			"password_hash":       newPasswordHash,
-			"password_changed_at": time.Now(),
+			"password_hash":              newPasswordHash,
+			"password_changed_at":        time.Now(),
+			"password_reset_required_at": nil,
+			"must_change_password":       false,
 		}).Error; err != nil {
 			return fmt.Errorf("error al actualizar contraseña: %w", err)
 		}
 
+		if err := s.passwordPolicy.RecordPassword(ctx, tx, resetToken.User.ID, newPasswordHash); err != nil {
+			return err
+		}
+
 		// Marcar token como usado
 		resetToken.MarkAsUsed()
 		if err := tx.Save(&resetToken).Error; err != nil {
@@ -1036,23 +1502,13 @@ func (s *AuthService) hashSecurityAnswer(answer string) string {
 
 // invalidateUserSessions invalida todas las sesiones de un usuario
 func (s *AuthService) invalidateUserSessions(ctx context.Context, userID string) error {
-	// Obtener todas las sesiones del usuario
-	sessions, err := s.sessionManager.GetUserSessions(ctx, userID)
+	// Eliminar cada sesión y revocar sus access tokens vigentes
+	count, err := s.sessionService.RevokeAllSessions(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("error al obtener sesiones: %w", err)
-	}
-
-	// Eliminar cada sesión
-	for _, sessionID := range sessions {
-		if err := s.sessionManager.DeleteSession(ctx, sessionID); err != nil {
-			logger.Warn("Error al eliminar sesión %s: %v", sessionID, err)
-		}
-		if err := s.refreshManager.DeleteRefreshToken(ctx, userID, sessionID); err != nil {
-			logger.Warn("Error al eliminar refresh token %s: %v", sessionID, err)
-		}
+		return err
 	}
 
-	logger.Info("Sesiones invalidadas para usuario %s: %d sesiones", userID, len(sessions))
+	logger.Info("Sesiones invalidadas para usuario %s: %d sesiones", userID, count)
 	return nil
 }
 
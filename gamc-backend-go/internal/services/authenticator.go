@@ -0,0 +1,145 @@
+// internal/services/authenticator.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIdentityNotFound la fuente no conoce al usuario: se consulta la siguiente de la cadena
+	ErrIdentityNotFound = errors.New("usuario inexistente o inactivo")
+
+	// ErrInvalidCredentials la fuente conoce al usuario pero rechazó la contraseña; la cadena se detiene
+	ErrInvalidCredentials = errors.New("credenciales inválidas")
+
+	// ErrDirectoryUnavailable el directorio no respondió; no se puede decidir sobre sus usuarios
+	ErrDirectoryUnavailable = errors.New("servicio de directorio no disponible")
+
+	// ErrEmailNotVerified la cuenta auto-registrada aún no confirmó su email institucional
+	ErrEmailNotVerified = errors.New("debe verificar su email antes de iniciar sesión")
+
+	// ErrPasswordResetRequired el usuario reportó un acceso no reconocido: la contraseña
+	// actual ya no sirve para iniciar sesión hasta restablecerla
+	ErrPasswordResetRequired = errors.New("debe restablecer su contraseña antes de iniciar sesión")
+
+	// ErrAccountExpired venció la cuenta temporal; solo se informa tras verificar la contraseña
+	ErrAccountExpired = errors.New("su cuenta expiró, contacte al administrador")
+
+	// ErrIPNotAllowed la IP del cliente no está en los rangos permitidos para el rol o la unidad del usuario
+	ErrIPNotAllowed = errors.New("no tiene permitido acceder desde esta red")
+)
+
+// Authenticator fuente de identidad capaz de verificar email y contraseña.
+// Retorna el usuario local (con OrganizationalUnit y SecurityQuestions precargados).
+// Ante ErrInvalidCredentials puede retornar además el usuario para registrar el intento.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, email, password string) (*models.User, error)
+}
+
+// AuthenticatorChain prueba las fuentes en orden hasta que una reconoce al usuario
+type AuthenticatorChain struct {
+	authenticators []Authenticator
+}
+
+// NewAuthenticatorChain crea la cadena configurada: bcrypt local y luego LDAP si está habilitado
+func NewAuthenticatorChain(appCtx *config.AppContext) *AuthenticatorChain {
+	chain := &AuthenticatorChain{
+		authenticators: []Authenticator{NewLocalAuthenticator(appCtx.DB)},
+	}
+	if appCtx.Config.LDAPEnabled {
+		chain.authenticators = append(chain.authenticators, NewLDAPService(appCtx))
+	}
+	return chain
+}
+
+// Authenticate recorre la cadena. Una fuente que no conoce al usuario cede el turno;
+// cualquier otro error (contraseña incorrecta, directorio caído) detiene la cadena.
+func (c *AuthenticatorChain) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	for _, authenticator := range c.authenticators {
+		user, err := authenticator.Authenticate(ctx, email, password)
+		if err == nil {
+			return user, nil
+		}
+		if errors.Is(err, ErrIdentityNotFound) {
+			continue
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			logger.Error("❌ Error en autenticación %s para %s: %v", authenticator.Name(), email, err)
+		}
+		return user, err
+	}
+	return nil, ErrIdentityNotFound
+}
+
+// ========================================
+// AUTENTICACIÓN LOCAL (bcrypt)
+// ========================================
+
+// LocalAuthenticator verifica la contraseña almacenada en la base de datos
+type LocalAuthenticator struct {
+	db              *gorm.DB
+	passwordService *auth.PasswordService
+}
+
+// NewLocalAuthenticator crea el autenticador local
+func NewLocalAuthenticator(db *gorm.DB) *LocalAuthenticator {
+	return &LocalAuthenticator{
+		db:              db,
+		passwordService: auth.NewPasswordService(),
+	}
+}
+
+// Name identifica la fuente en logs
+func (a *LocalAuthenticator) Name() string {
+	return models.AuthSourceLocal
+}
+
+// Authenticate verifica usuarios locales; los del directorio se ceden al autenticador LDAP
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	// Las cuentas temporales vencidas siguen visibles para informar ErrAccountExpired
+	// aunque la tarea nocturna ya las haya desactivado
+	var user models.User
+	err := a.db.WithContext(ctx).
+		Preload("OrganizationalUnit").
+		Preload("SecurityQuestions", "is_active = ?", true).
+		Where("email = ? AND (is_active = ? OR expires_at <= ?) AND is_service_account = ? AND auth_source = ?",
+			email, true, time.Now(), false, models.AuthSourceLocal).
+		First(&user).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	if err := a.passwordService.ComparePassword(password, user.PasswordHash); err != nil {
+		return &user, fmt.Errorf("%w: contraseña incorrecta", ErrInvalidCredentials)
+	}
+
+	return &user, nil
+}
+
+// failureReason motivo del fallo para el registro de intentos, sin el prefijo genérico
+func failureReason(err error) string {
+	for _, sentinel := range []error{ErrInvalidCredentials, ErrIdentityNotFound} {
+		if errors.Is(err, sentinel) {
+			if reason := strings.TrimPrefix(err.Error(), sentinel.Error()+": "); reason != "" {
+				return reason
+			}
+		}
+	}
+	return err.Error()
+}
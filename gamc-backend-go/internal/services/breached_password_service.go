@@ -0,0 +1,36 @@
+// internal/services/breached_password_service.go
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/pkg/breach"
+	"gamc-backend-go/pkg/validator"
+)
+
+// InitializeBreachedPasswords carga el filtro de contraseñas filtradas y lo registra
+// en el paquete validator, de modo que el registro, el cambio y el reset de contraseña
+// lo consulten junto a la lista de contraseñas comunes. Retorna nil si no está configurado.
+func InitializeBreachedPasswords(cfg *config.Config) (*breach.Filter, error) {
+	if cfg.BreachedPasswordsFile == "" {
+		return nil, nil
+	}
+
+	filter, err := breach.LoadFile(cfg.BreachedPasswordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar %s: %w", cfg.BreachedPasswordsFile, err)
+	}
+
+	validator.SetBreachedPasswordChecker(func(password string) bool {
+		if filter.ContainsPassword(password) {
+			return true
+		}
+		// Las variantes que solo cambian mayúsculas no agregan resistencia a un diccionario
+		lower := strings.ToLower(password)
+		return lower != password && filter.ContainsPassword(lower)
+	})
+
+	return filter, nil
+}
@@ -0,0 +1,368 @@
+// internal/services/device_service.go
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DeviceCookieName cookie de larga duración con el ID del dispositivo
+	DeviceCookieName = "gamc_device"
+	// DeviceHeaderName alternativa a la cookie para aplicaciones que no las conservan
+	DeviceHeaderName = "X-Device-ID"
+	// DeviceCookieTTL vigencia de la cookie del dispositivo
+	DeviceCookieTTL = 365 * 24 * time.Hour
+
+	// deviceAlertTTL vigencia del enlace "No fui yo"
+	deviceAlertTTL = 7 * 24 * time.Hour
+)
+
+// DeviceService mantiene los dispositivos conocidos de cada usuario y alerta
+// cuando se inicia sesión desde uno nuevo
+type DeviceService struct {
+	db                  *gorm.DB
+	sessionService      *SessionService
+	notificationService *NotificationService
+	mailService         *MailService
+	auditService        *AuditService
+	config              *config.Config
+}
+
+// NewDeviceService crea una nueva instancia del servicio de dispositivos
+func NewDeviceService(appCtx *config.AppContext) *DeviceService {
+	return &DeviceService{
+		db:                  appCtx.DB,
+		sessionService:      NewSessionService(appCtx),
+		notificationService: NewNotificationService(appCtx),
+		mailService:         NewMailService(appCtx),
+		auditService:        NewAuditService(appCtx.DB),
+		config:              appCtx.Config,
+	}
+}
+
+// ========================================
+// REGISTRO DE DISPOSITIVOS
+// ========================================
+
+// RecordLogin registra el dispositivo del login y alerta al usuario si es nuevo.
+// Retorna el ID de dispositivo que el cliente debe conservar: el recibido si es
+// válido o uno nuevo. El primer dispositivo de un usuario no genera alerta.
+func (s *DeviceService) RecordLogin(ctx context.Context, user *models.UserProfile, sessionID, deviceID, ipAddress, userAgent string) (string, error) {
+	if !isValidDeviceID(deviceID) {
+		newID, err := randomToken(32)
+		if err != nil {
+			return "", fmt.Errorf("error al generar ID de dispositivo: %w", err)
+		}
+		deviceID = newID
+	}
+
+	deviceHash := hashDeviceID(deviceID)
+	description := describeDevice(userAgent)
+	fingerprint := hashDeviceID(description)
+	now := time.Now()
+
+	var device models.UserDevice
+	err := s.db.WithContext(ctx).Where("user_id = ? AND device_hash = ?", user.ID, deviceHash).First(&device).Error
+	if err == nil && device.Fingerprint == fingerprint {
+		err = s.db.WithContext(ctx).Model(&device).Updates(map[string]interface{}{
+			"last_ip":      ipAddress,
+			"last_seen_at": now,
+			"user_agent":   userAgent,
+		}).Error
+		if err != nil {
+			logger.Warn("Error al actualizar dispositivo %d de %s: %v", device.ID, user.Email, err)
+		}
+		return deviceID, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return deviceID, fmt.Errorf("error al buscar dispositivo: %w", err)
+	}
+
+	var known int64
+	if err := s.db.WithContext(ctx).Model(&models.UserDevice{}).Where("user_id = ?", user.ID).Count(&known).Error; err != nil {
+		return deviceID, fmt.Errorf("error al contar dispositivos: %w", err)
+	}
+
+	if device.ID != 0 {
+		// El mismo ID desde otro navegador o sistema: la cookie pudo copiarse
+		device.Fingerprint = fingerprint
+		device.Description = description
+		device.UserAgent = userAgent
+		device.LastIP = ipAddress
+		device.LastSeenAt = now
+		err = s.db.WithContext(ctx).Save(&device).Error
+	} else {
+		device = models.UserDevice{
+			UserID:      user.ID,
+			DeviceHash:  deviceHash,
+			Fingerprint: fingerprint,
+			Description: description,
+			UserAgent:   userAgent,
+			FirstIP:     ipAddress,
+			LastIP:      ipAddress,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		}
+		err = s.db.WithContext(ctx).Create(&device).Error
+	}
+	if err != nil {
+		return deviceID, fmt.Errorf("error al registrar dispositivo: %w", err)
+	}
+
+	if known == 0 {
+		logger.Info("📱 Primer dispositivo registrado para %s: %s", user.Email, description)
+		return deviceID, nil
+	}
+
+	if err := s.alertNewDevice(ctx, user, &device, sessionID, ipAddress, userAgent); err != nil {
+		logger.Error("Error al alertar nuevo dispositivo de %s: %v", user.Email, err)
+	}
+
+	return deviceID, nil
+}
+
+// alertNewDevice crea el enlace "No fui yo" y lo envía como notificación y por correo
+func (s *DeviceService) alertNewDevice(ctx context.Context, user *models.UserProfile, device *models.UserDevice, sessionID, ipAddress, userAgent string) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return fmt.Errorf("error al generar token: %w", err)
+	}
+
+	alert := &models.DeviceLoginAlert{
+		UserID:    user.ID,
+		DeviceID:  &device.ID,
+		SessionID: sessionID,
+		TokenHash: s.sign(token),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(deviceAlertTTL),
+	}
+	if err := s.db.WithContext(ctx).Create(alert).Error; err != nil {
+		return fmt.Errorf("error al guardar alerta: %w", err)
+	}
+
+	logger.Warn("📱 Login de %s desde dispositivo nuevo: %s (IP %s)", user.Email, device.Description, ipAddress)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &user.ID,
+		Action:     models.AuditActionNewDeviceLogin,
+		Resource:   "user_device",
+		ResourceID: fmt.Sprintf("%d", device.ID),
+		NewValues: map[string]interface{}{
+			"device":  device.Description,
+			"alertId": alert.ID,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: sessionID,
+		Result:    models.AuditResultSuccess,
+	})
+
+	reportPath := "/report-device?token=" + token
+	_, err = s.notificationService.CreateNotification(ctx, &CreateNotificationRequest{
+		UserID:    user.ID,
+		Type:      models.NotificationTypeSecurity,
+		Title:     "Nuevo inicio de sesión desde un dispositivo no reconocido",
+		Content:   fmt.Sprintf("Se inició sesión desde %s (IP %s). Si no fue usted, use el enlace \"No fui yo\".", device.Description, ipAddress),
+		Priority:  models.NotificationPriorityHigh,
+		ActionURL: reportPath,
+		Metadata: map[string]interface{}{
+			"ip_address": ipAddress,
+			"device":     device.Description,
+			"session_id": sessionID,
+		},
+	})
+	if err != nil {
+		logger.Warn("Error al notificar nuevo dispositivo a %s: %v", user.Email, err)
+	}
+
+	if !s.mailService.Enabled() {
+		if s.config.Environment == "development" {
+			logger.Info("Enlace \"No fui yo\" para %s (correo desactivado): %s", user.Email, reportPath)
+		}
+		return nil
+	}
+
+	return s.mailService.SendToUser(ctx, user.ID, models.EmailTemplateNewDevice, map[string]interface{}{
+		"Device":      device.Description,
+		"IPAddress":   ipAddress,
+		"OccurredAt":  time.Now().Format("02/01/2006 15:04"),
+		"ReportURL":   s.mailService.Link(reportPath),
+		"ExpiresDays": int(deviceAlertTTL.Hours() / 24),
+	})
+}
+
+// ========================================
+// REPORTE "NO FUI YO"
+// ========================================
+
+// ReportUnrecognized procesa el enlace "No fui yo": olvida el dispositivo, cierra todas
+// las sesiones del usuario y exige restablecer la contraseña antes del próximo login
+func (s *DeviceService) ReportUnrecognized(ctx context.Context, token, ipAddress, userAgent string) (*models.DeviceReportResponse, error) {
+	var alert models.DeviceLoginAlert
+	err := s.db.WithContext(ctx).Where("token_hash = ?", s.sign(token)).First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("enlace inválido o expirado")
+		}
+		return nil, fmt.Errorf("error al buscar alerta: %w", err)
+	}
+	if alert.ReportedAt != nil {
+		return nil, fmt.Errorf("este acceso ya fue reportado")
+	}
+	if time.Now().After(alert.ExpiresAt) {
+		return nil, fmt.Errorf("enlace inválido o expirado")
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", alert.UserID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&alert).Where("reported_at IS NULL").Updates(map[string]interface{}{
+			"reported_at": now,
+			"reported_ip": ipAddress,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("este acceso ya fue reportado")
+		}
+
+		if alert.DeviceID != nil {
+			if err := tx.Delete(&models.UserDevice{}, *alert.DeviceID).Error; err != nil {
+				return err
+			}
+		}
+
+		// La contraseña de los usuarios del directorio no se administra aquí
+		if !user.IsDirectoryUser() {
+			if err := tx.Model(&user).Update("password_reset_required_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err.Error() == "este acceso ya fue reportado" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error al registrar el reporte: %w", err)
+	}
+
+	revoked, err := s.sessionService.RevokeAllSessions(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Error al revocar sesiones de %s tras reporte de dispositivo: %v", user.Email, err)
+	}
+
+	logger.Warn("🚨 %s reportó como no reconocido el acceso de la alerta %d: %d sesiones revocadas", user.Email, alert.ID, revoked)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &user.ID,
+		Action:     models.AuditActionDeviceReported,
+		Resource:   "device_login_alert",
+		ResourceID: fmt.Sprintf("%d", alert.ID),
+		NewValues: map[string]interface{}{
+			"reportedSessionId":     alert.SessionID,
+			"loginIp":               alert.IPAddress,
+			"sessionsRevoked":       revoked,
+			"passwordResetRequired": !user.IsDirectoryUser(),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: alert.SessionID,
+		Result:    models.AuditResultSuccess,
+	})
+
+	return &models.DeviceReportResponse{
+		SessionsRevoked:       revoked,
+		PasswordResetRequired: !user.IsDirectoryUser(),
+		DirectoryUser:         user.IsDirectoryUser(),
+	}, nil
+}
+
+// ========================================
+// DISPOSITIVOS DEL USUARIO
+// ========================================
+
+// ListDevices retorna los dispositivos conocidos del usuario, marcando el actual
+func (s *DeviceService) ListDevices(ctx context.Context, userID uuid.UUID, currentDeviceID string) ([]models.UserDevice, error) {
+	var devices []models.UserDevice
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener dispositivos: %w", err)
+	}
+
+	if isValidDeviceID(currentDeviceID) {
+		currentHash := hashDeviceID(currentDeviceID)
+		for i := range devices {
+			devices[i].IsCurrent = devices[i].DeviceHash == currentHash
+		}
+	}
+	return devices, nil
+}
+
+// RemoveDevice olvida un dispositivo: el próximo login desde él generará una alerta
+func (s *DeviceService) RemoveDevice(ctx context.Context, userID uuid.UUID, deviceID int) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.UserDevice{})
+	if result.Error != nil {
+		return fmt.Errorf("error al eliminar dispositivo: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("dispositivo no encontrado")
+	}
+	return nil
+}
+
+// CleanupExpiredAlerts elimina las alertas vencidas hace más de 90 días
+func (s *DeviceService) CleanupExpiredAlerts(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now().AddDate(0, 0, -90)).
+		Delete(&models.DeviceLoginAlert{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error al limpiar alertas de dispositivos: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// sign calcula el HMAC del token del enlace; la base de datos nunca guarda el token
+func (s *DeviceService) sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("device-alert:" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hashDeviceID hash con el que se guarda el ID del dispositivo
+func hashDeviceID(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// isValidDeviceID verifica el formato de los IDs emitidos (32 bytes en hexadecimal)
+func isValidDeviceID(deviceID string) bool {
+	if len(deviceID) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(deviceID)
+	return err == nil
+}
@@ -0,0 +1,280 @@
+// internal/services/email_verification_service.go
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	verificationResendInterval = time.Minute // Espera mínima entre envíos
+	verificationMaxPerDay      = 5           // Envíos por usuario en 24 horas
+)
+
+// EmailVerificationService verifica el email de las cuentas auto-registradas
+type EmailVerificationService struct {
+	db           *gorm.DB
+	mailService  *MailService
+	auditService *AuditService
+	config       *config.Config
+}
+
+// NewEmailVerificationService crea una nueva instancia del servicio de verificación
+func NewEmailVerificationService(appCtx *config.AppContext) *EmailVerificationService {
+	return &EmailVerificationService{
+		db:           appCtx.DB,
+		mailService:  NewMailService(appCtx),
+		auditService: NewAuditService(appCtx.DB),
+		config:       appCtx.Config,
+	}
+}
+
+// Issue invalida las verificaciones anteriores del usuario y envía un nuevo enlace y código
+func (s *EmailVerificationService) Issue(ctx context.Context, user *models.User, requestIP string) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return fmt.Errorf("error al generar token: %w", err)
+	}
+	code, err := randomDigits(6)
+	if err != nil {
+		return fmt.Errorf("error al generar código: %w", err)
+	}
+
+	now := time.Now()
+	verification := &models.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: s.sign(token),
+		CodeHash:  s.sign(user.ID.String() + ":" + code),
+		ExpiresAt: now.Add(s.config.EmailVerificationTTL),
+		RequestIP: requestIP,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EmailVerification{}).
+			Where("user_id = ? AND used_at IS NULL AND expires_at > ?", user.ID, now).
+			Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(verification).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error al crear verificación: %w", err)
+	}
+
+	verifyURL := s.mailService.Link("/verify-email?token=" + token)
+	if !s.mailService.Enabled() {
+		if s.config.Environment == "development" {
+			logger.Info("Verificación de %s (correo desactivado): código %s, enlace %s", user.Email, code, verifyURL)
+		}
+		return nil
+	}
+
+	_, err = s.mailService.Enqueue(ctx, &EmailRequest{
+		UserID:   &user.ID,
+		To:       user.Email,
+		Name:     user.FirstName,
+		Template: models.EmailTemplateVerification,
+		Data: map[string]interface{}{
+			"VerifyURL":    verifyURL,
+			"Code":         code,
+			"ExpiresHours": int(s.config.EmailVerificationTTL.Hours()),
+			"PurgeDays":    s.config.EmailVerificationPurgeDays,
+		},
+	})
+	return err
+}
+
+// Verify marca el email como verificado usando el token del enlace o el email y el código
+func (s *EmailVerificationService) Verify(ctx context.Context, req *models.EmailVerificationRequest, ipAddress, userAgent string) (*models.UserProfile, error) {
+	var user models.User
+	var verification models.EmailVerification
+
+	switch {
+	case req.Token != "":
+		err := s.db.WithContext(ctx).Where("token_hash = ?", s.sign(req.Token)).First(&verification).Error
+		if err != nil || !verification.IsUsable() {
+			return nil, fmt.Errorf("enlace de verificación inválido o expirado")
+		}
+		if err := s.db.WithContext(ctx).Where("id = ?", verification.UserID).First(&user).Error; err != nil {
+			return nil, fmt.Errorf("enlace de verificación inválido o expirado")
+		}
+
+	case req.Email != "" && req.Code != "":
+		if err := s.db.WithContext(ctx).Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
+			return nil, fmt.Errorf("código de verificación inválido")
+		}
+		if !user.IsPendingVerification() {
+			return nil, fmt.Errorf("el email ya fue verificado")
+		}
+		err := s.db.WithContext(ctx).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Order("created_at DESC").
+			First(&verification).Error
+		if err != nil || time.Now().After(verification.ExpiresAt) {
+			return nil, fmt.Errorf("código de verificación inválido o expirado")
+		}
+		if verification.Attempts >= models.MaxEmailVerificationAttempts {
+			return nil, fmt.Errorf("demasiados intentos, solicite un nuevo código")
+		}
+		expected := s.sign(user.ID.String() + ":" + req.Code)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(verification.CodeHash)) != 1 {
+			s.db.WithContext(ctx).Model(&verification).UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+			return nil, fmt.Errorf("código de verificación inválido")
+		}
+
+	default:
+		return nil, fmt.Errorf("debe indicar el enlace de verificación o el email y el código")
+	}
+
+	if !user.IsPendingVerification() {
+		return nil, fmt.Errorf("el email ya fue verificado")
+	}
+
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Consumir la verificación solo si nadie la usó en paralelo
+		result := tx.Model(&models.EmailVerification{}).
+			Where("id = ? AND used_at IS NULL", verification.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("enlace de verificación inválido o expirado")
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		if err.Error() == "enlace de verificación inválido o expirado" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error al verificar email: %w", err)
+	}
+	user.EmailVerifiedAt = &now
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &user.ID,
+		Action:     models.AuditActionEmailVerified,
+		Resource:   "user",
+		ResourceID: user.ID.String(),
+		NewValues:  map[string]interface{}{"email": user.Email},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("✅ Email verificado: %s", user.Email)
+	return user.ToProfile(), nil
+}
+
+// Resend envía un nuevo enlace y código. No revela si el email existe: solo las
+// cuentas pendientes reciben correo; el límite de reenvíos sí se informa.
+func (s *EmailVerificationService) Resend(ctx context.Context, email, requestIP string) error {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		return nil
+	}
+	if !user.IsPendingVerification() || !user.IsActive {
+		return nil
+	}
+
+	var last models.EmailVerification
+	err := s.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("created_at DESC").First(&last).Error
+	if err == nil && time.Since(last.CreatedAt) < verificationResendInterval {
+		return fmt.Errorf("debe esperar un minuto antes de solicitar otro código")
+	}
+
+	var sentToday int64
+	s.db.WithContext(ctx).Model(&models.EmailVerification{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-24*time.Hour)).
+		Count(&sentToday)
+	if sentToday >= verificationMaxPerDay {
+		return fmt.Errorf("alcanzó el límite de reenvíos por hoy, intente mañana")
+	}
+
+	return s.Issue(ctx, &user, requestIP)
+}
+
+// PurgeUnverified elimina las cuentas auto-registradas que no se verificaron a tiempo
+func (s *EmailVerificationService) PurgeUnverified(ctx context.Context) (int, error) {
+	if s.config.EmailVerificationPurgeDays <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.config.EmailVerificationPurgeDays)
+
+	var users []models.User
+	err := s.db.WithContext(ctx).
+		Where("email_verified_at IS NULL AND auth_source = ? AND is_service_account = ? AND created_at < ?",
+			models.AuthSourceLocal, false, cutoff).
+		Find(&users).Error
+	if err != nil {
+		return 0, fmt.Errorf("error al buscar cuentas no verificadas: %w", err)
+	}
+
+	purged := 0
+	for _, user := range users {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Los intentos de login fallidos quedan en la auditoría sin la referencia al usuario
+			if err := tx.Model(&models.AuditLog{}).Where("user_id = ?", user.ID).Update("user_id", nil).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&models.User{}, "id = ?", user.ID).Error
+		})
+		if err != nil {
+			logger.Error("Error al eliminar cuenta no verificada %s: %v", user.Email, err)
+			continue
+		}
+		purged++
+
+		s.auditService.Log(ctx, &LogRequest{
+			Action:     models.AuditActionUnverifiedPurged,
+			Resource:   "user",
+			ResourceID: user.ID.String(),
+			OldValues: map[string]interface{}{
+				"email":     user.Email,
+				"createdAt": user.CreatedAt,
+			},
+			Result: models.AuditResultSuccess,
+		})
+	}
+
+	if purged > 0 {
+		logger.Info("🧹 Cuentas no verificadas eliminadas: %d", purged)
+	}
+	return purged, nil
+}
+
+// sign firma el valor con el secreto del servidor: un volcado de la tabla no permite
+// probar códigos de 6 dígitos sin conocer el secreto
+func (s *EmailVerificationService) sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("email-verification:" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomDigits genera un código numérico aleatorio de n dígitos
+func randomDigits(n int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + digit.Int64()))
+	}
+	return sb.String(), nil
+}
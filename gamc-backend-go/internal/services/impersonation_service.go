@@ -0,0 +1,201 @@
+// internal/services/impersonation_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxImpersonationTTL vigencia máxima de una suplantación, sin importar la configuración
+const maxImpersonationTTL = time.Hour
+
+// ImpersonationService permite a soporte actuar como otro usuario con auditoría completa
+type ImpersonationService struct {
+	db                  *gorm.DB
+	sessionManager      *redis.SessionManager
+	jwtService          *auth.JWTService
+	sessionService      *SessionService
+	auditService        *AuditService
+	notificationService *NotificationService
+	config              *config.Config
+}
+
+// NewImpersonationService crea una nueva instancia del servicio de suplantación
+func NewImpersonationService(appCtx *config.AppContext) *ImpersonationService {
+	return &ImpersonationService{
+		db:                  appCtx.DB,
+		sessionManager:      redis.NewSessionManager(appCtx.Redis),
+		jwtService:          auth.NewJWTService(appCtx.Config),
+		sessionService:      NewSessionService(appCtx),
+		auditService:        NewAuditService(appCtx.DB),
+		notificationService: NewNotificationService(appCtx),
+		config:              appCtx.Config,
+	}
+}
+
+// Start emite un access token de corta duración para actuar como el usuario indicado.
+// No se puede suplantar a otros administradores, a cuentas de servicio ni encadenar suplantaciones.
+func (s *ImpersonationService) Start(ctx context.Context, admin *models.UserProfile, adminClaims *auth.JWTClaims, targetID, reason, ipAddress, userAgent string) (*models.ImpersonationResponse, error) {
+	if adminClaims != nil && adminClaims.IsImpersonation() {
+		return nil, fmt.Errorf("no se puede suplantar desde una sesión de suplantación")
+	}
+
+	parsedID, err := uuid.Parse(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("usuario no encontrado")
+	}
+	if parsedID == admin.ID {
+		return nil, fmt.Errorf("no puede suplantarse a sí mismo")
+	}
+
+	var target models.User
+	err = s.db.WithContext(ctx).Preload("OrganizationalUnit").Where("id = ?", parsedID).First(&target).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	switch {
+	case !target.IsActive:
+		return nil, fmt.Errorf("el usuario está inactivo")
+	case target.IsServiceAccount:
+		return nil, fmt.Errorf("no se pueden suplantar cuentas de servicio")
+	case target.Role == "admin":
+		return nil, fmt.Errorf("no se puede suplantar a otro administrador")
+	case target.OrganizationalUnitID == nil:
+		return nil, fmt.Errorf("el usuario no tiene unidad organizacional")
+	}
+
+	ttl := s.config.ImpersonationTTL
+	if ttl <= 0 || ttl > maxImpersonationTTL {
+		ttl = maxImpersonationTTL
+	}
+
+	// Sesión propia del usuario suplantado: aparece en su lista de sesiones y puede revocarla
+	sessionID := uuid.New().String()
+	now := time.Now()
+	sessionData := &redis.SessionData{
+		UserID:               target.ID.String(),
+		Email:                target.Email,
+		Role:                 target.Role,
+		OrganizationalUnitID: *target.OrganizationalUnitID,
+		SessionID:            sessionID,
+		CreatedAt:            now,
+		LastActivity:         now,
+		IPAddress:            ipAddress,
+		UserAgent:            userAgent,
+		ImpersonatorID:       admin.ID.String(),
+		ImpersonatorEmail:    admin.Email,
+		ImpersonationReason:  reason,
+	}
+	if err := s.sessionManager.SaveSession(ctx, sessionID, sessionData, ttl); err != nil {
+		return nil, fmt.Errorf("error al guardar sesión: %w", err)
+	}
+
+	accessToken, err := s.jwtService.GenerateImpersonationToken(
+		target.ID.String(),
+		target.Email,
+		target.Role,
+		*target.OrganizationalUnitID,
+		sessionID,
+		auth.Actor{Subject: admin.ID.String(), Email: admin.Email},
+		ttl,
+	)
+	if err != nil {
+		s.sessionManager.DeleteSession(ctx, sessionID)
+		return nil, fmt.Errorf("error al generar token: %w", err)
+	}
+	if err := s.sessionService.TrackAccessToken(ctx, sessionID, accessToken); err != nil {
+		logger.Warn("Error al registrar access token de la sesión %s: %v", sessionID, err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionImpersonationStart,
+		Resource:   "user",
+		ResourceID: target.ID.String(),
+		NewValues: map[string]interface{}{
+			"impersonatorId":    admin.ID.String(),
+			"impersonatorEmail": admin.Email,
+			"targetUserId":      target.ID.String(),
+			"targetEmail":       target.Email,
+			"reason":            reason,
+			"sessionId":         sessionID,
+			"expiresAt":         now.Add(ttl),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: sessionID,
+		Result:    models.AuditResultSuccess,
+	})
+
+	content := fmt.Sprintf("El administrador %s accedió al sistema en su nombre para brindarle soporte (motivo: %s). "+
+		"El acceso expira automáticamente; puede cerrarlo desde sus sesiones activas.", admin.Email, reason)
+	if err := s.notificationService.CreateAlertNotification(ctx, target.ID, "Soporte accedió a su cuenta", content); err != nil {
+		logger.Warn("Error al notificar suplantación a %s: %v", target.Email, err)
+	}
+
+	logger.Warn("🎭 Administrador %s suplanta a %s (motivo: %s)", admin.Email, target.Email, reason)
+
+	profile := target.ToProfile()
+	profile.Impersonation = &models.ImpersonationInfo{
+		ImpersonatorID:    admin.ID.String(),
+		ImpersonatorEmail: admin.Email,
+		Reason:            reason,
+		ExpiresAt:         now.Add(ttl),
+	}
+
+	return &models.ImpersonationResponse{
+		AccessToken:    accessToken,
+		ExpiresIn:      int64(ttl.Seconds()),
+		User:           profile,
+		ImpersonatorID: admin.ID,
+	}, nil
+}
+
+// LogRequest registra una petición hecha bajo suplantación con ambas identidades
+func (s *ImpersonationService) LogRequest(ctx context.Context, claims *auth.JWTClaims, method, path string, status int, duration time.Duration, ipAddress, userAgent string) {
+	targetID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return
+	}
+
+	result := models.AuditResultSuccess
+	if status >= 400 {
+		result = models.AuditResultFailure
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &targetID,
+		Action:     models.AuditActionImpersonationRequest,
+		Resource:   "impersonation",
+		ResourceID: claims.SessionID,
+		NewValues: map[string]interface{}{
+			"impersonatorId":    claims.Actor.Subject,
+			"impersonatorEmail": claims.Actor.Email,
+			"targetUserId":      claims.UserID,
+			"targetEmail":       claims.Email,
+			"method":            method,
+			"path":              path,
+			"status":            status,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: claims.SessionID,
+		Duration:  int(duration.Milliseconds()),
+		Result:    result,
+	})
+}
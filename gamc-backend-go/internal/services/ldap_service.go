@@ -0,0 +1,622 @@
+// internal/services/ldap_service.go
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/ldap"
+	"gamc-backend-go/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ldapSyncGuardMinUsers a partir de cuántos usuarios se aplica el límite de desactivaciones
+	ldapSyncGuardMinUsers = 10
+
+	// ldapSyncMaxDeactivationRatio fracción máxima de usuarios que una sincronización puede
+	// desactivar; un valor mayor suele indicar un error de configuración (base DN, filtro)
+	ldapSyncMaxDeactivationRatio = 0.5
+)
+
+// invalidUsernameChars caracteres no permitidos al derivar el username del email
+var invalidUsernameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// rolePrecedence orden de preferencia cuando el usuario pertenece a varios grupos de rol
+var rolePrecedence = []string{"admin", "input", "output"}
+
+// groupMapping asocia un grupo del directorio (CN o DN) con un rol o código de unidad
+type groupMapping struct {
+	value string
+	group string
+}
+
+// directoryUser datos del usuario leídos del directorio
+type directoryUser struct {
+	DN        string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+// LDAPService autentica contra el directorio, aprovisiona usuarios y los sincroniza
+type LDAPService struct {
+	db              *gorm.DB
+	config          *config.Config
+	passwordService *auth.PasswordService
+	sessionService  *SessionService
+	auditService    *AuditService
+	roleGroups      []groupMapping
+	unitGroups      []groupMapping
+}
+
+// NewLDAPService crea una nueva instancia del servicio de directorio
+func NewLDAPService(appCtx *config.AppContext) *LDAPService {
+	return &LDAPService{
+		db:              appCtx.DB,
+		config:          appCtx.Config,
+		passwordService: auth.NewPasswordService(),
+		sessionService:  NewSessionService(appCtx),
+		auditService:    NewAuditService(appCtx.DB),
+		roleGroups:      parseGroupMappings("LDAP_ROLE_GROUPS", appCtx.Config.LDAPRoleGroups),
+		unitGroups:      parseGroupMappings("LDAP_UNIT_GROUPS", appCtx.Config.LDAPUnitGroups),
+	}
+}
+
+// Name identifica la fuente en logs
+func (s *LDAPService) Name() string {
+	return models.AuthSourceLDAP
+}
+
+// ========================================
+// AUTENTICACIÓN
+// ========================================
+
+// Authenticate busca al usuario con la cuenta de servicio, verifica la contraseña
+// con un bind como el propio usuario y crea o actualiza el usuario local (JIT)
+func (s *LDAPService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := s.findUser(conn, email)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrIdentityNotFound
+	}
+
+	existing, err := s.findLocalUser(ctx, entry.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.IsDirectoryUser() {
+		return nil, fmt.Errorf("%w: el email pertenece a una cuenta local", ErrInvalidCredentials)
+	}
+
+	role, unitCode, err := s.bindDirectoryUser(conn, entry, password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return existing, err
+		}
+		return nil, err
+	}
+
+	return s.provision(ctx, existing, entry, role, unitCode)
+}
+
+// bindDirectoryUser verifica la contraseña con un bind como el propio usuario y
+// resuelve el rol y la unidad que le corresponden según sus grupos
+func (s *LDAPService) bindDirectoryUser(conn *ldap.Conn, entry *directoryUser, password string) (string, string, error) {
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return "", "", fmt.Errorf("%w: contraseña incorrecta", ErrInvalidCredentials)
+		}
+		return "", "", fmt.Errorf("%w: %v", ErrDirectoryUnavailable, err)
+	}
+
+	role, unitCode := s.mapGroups(entry.Groups)
+	if role == "" {
+		return "", "", fmt.Errorf("%w: usuario del directorio sin grupo de rol autorizado", ErrInvalidCredentials)
+	}
+	if unitCode == "" {
+		return "", "", fmt.Errorf("%w: usuario del directorio sin unidad organizacional", ErrInvalidCredentials)
+	}
+
+	return role, unitCode, nil
+}
+
+// provision crea el usuario en su primer login o lo actualiza con los datos del directorio
+func (s *LDAPService) provision(ctx context.Context, user *models.User, entry *directoryUser, role, unitCode string) (*models.User, error) {
+	var orgUnit models.OrganizationalUnit
+	err := s.db.WithContext(ctx).Where("code = ? AND is_active = ?", unitCode, true).First(&orgUnit).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("unidad organizacional del directorio no encontrada: %s", unitCode)
+		}
+		return nil, fmt.Errorf("error al verificar unidad organizacional: %w", err)
+	}
+
+	if user == nil {
+		user, err = s.createUser(ctx, entry, role, orgUnit.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		oldValues := directoryUserValues(user)
+		if err := applyDirectoryUser(user, entry, role, orgUnit.ID, time.Now()); err != nil {
+			return user, err
+		}
+
+		if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+			return nil, fmt.Errorf("error al actualizar usuario del directorio: %w", err)
+		}
+
+		if newValues := directoryUserValues(user); !sameValues(oldValues, newValues) {
+			s.auditService.Log(ctx, &LogRequest{
+				UserID:     &user.ID,
+				Action:     models.AuditActionUpdate,
+				Resource:   "user",
+				ResourceID: user.ID.String(),
+				OldValues:  oldValues,
+				NewValues:  newValues,
+				Result:     models.AuditResultSuccess,
+			})
+		}
+	}
+
+	// Recargar con las relaciones que espera el login
+	var loaded models.User
+	err = s.db.WithContext(ctx).
+		Preload("OrganizationalUnit").
+		Preload("SecurityQuestions", "is_active = ?", true).
+		First(&loaded, "id = ?", user.ID).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener usuario del directorio: %w", err)
+	}
+
+	return &loaded, nil
+}
+
+// createUser aprovisiona un usuario nuevo con una contraseña local inutilizable
+func (s *LDAPService) createUser(ctx context.Context, entry *directoryUser, role string, orgUnitID int) (*models.User, error) {
+	username, err := s.uniqueUsername(ctx, entry.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	unusable, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar credenciales: %w", err)
+	}
+	passwordHash, err := s.passwordService.HashPassword(unusable)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar credenciales: %w", err)
+	}
+
+	user := newDirectoryUser(entry, role, orgUnitID, username, passwordHash, time.Now())
+	if err := s.db.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, fmt.Errorf("error al crear usuario del directorio: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &user.ID,
+		Action:     models.AuditActionCreate,
+		Resource:   "user",
+		ResourceID: user.ID.String(),
+		NewValues:  directoryUserValues(user),
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("📒 Usuario %s aprovisionado desde el directorio (%s)", user.Email, role)
+
+	return user, nil
+}
+
+// newDirectoryUser arma el usuario local de un usuario del directorio en su primer login
+func newDirectoryUser(entry *directoryUser, role string, orgUnitID int, username, passwordHash string, now time.Time) *models.User {
+	return &models.User{
+		Username:             username,
+		Email:                entry.Email,
+		PasswordHash:         passwordHash,
+		FirstName:            truncateRunes(entry.FirstName, 50),
+		LastName:             truncateRunes(entry.LastName, 50),
+		Role:                 role,
+		OrganizationalUnitID: &orgUnitID,
+		IsActive:             true,
+		AuthSource:           models.AuthSourceLDAP,
+		ExternalID:           entry.DN,
+		DirectorySyncedAt:    &now,
+		EmailVerifiedAt:      &now, // El directorio ya verificó la identidad
+	}
+}
+
+// applyDirectoryUser actualiza el usuario local con los datos del directorio. Solo se
+// reactivan cuentas que desactivó la sincronización, no las desactivadas por un administrador
+func applyDirectoryUser(user *models.User, entry *directoryUser, role string, orgUnitID int, now time.Time) error {
+	if !user.IsActive && user.DirectoryDeactivatedAt == nil {
+		return fmt.Errorf("%w: cuenta desactivada", ErrInvalidCredentials)
+	}
+
+	user.FirstName = truncateRunes(entry.FirstName, 50)
+	user.LastName = truncateRunes(entry.LastName, 50)
+	user.Role = role
+	user.OrganizationalUnitID = &orgUnitID
+	user.ExternalID = entry.DN
+	user.IsActive = true
+	user.DirectoryDeactivatedAt = nil
+	user.DirectorySyncedAt = &now
+	return nil
+}
+
+// ========================================
+// SINCRONIZACIÓN (tarea periódica)
+// ========================================
+
+// Sync revisa a los usuarios del directorio: actualiza rol, unidad y nombre, y desactiva
+// (cerrando sus sesiones) a quienes ya no existen o perdieron los grupos autorizados
+func (s *LDAPService) Sync(ctx context.Context) (int, error) {
+	var users []models.User
+	err := s.db.WithContext(ctx).
+		Where("auth_source = ? AND is_active = ?", models.AuthSourceLDAP, true).
+		Find(&users).Error
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener usuarios del directorio: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, nil
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	updates, removed, err := s.reviewDirectoryUsers(conn, users)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkSyncDeactivationLimit(len(users), len(removed)); err != nil {
+		return 0, err
+	}
+
+	for _, u := range updates {
+		if _, err := s.provision(ctx, u.user, u.entry, u.role, u.unitCode); err != nil {
+			logger.Warn("Error al sincronizar usuario del directorio %s: %v", u.user.Email, err)
+		}
+	}
+
+	deactivated := 0
+	now := time.Now()
+	for _, user := range removed {
+		err := s.db.WithContext(ctx).Model(user).UpdateColumns(map[string]interface{}{
+			"is_active":                false,
+			"directory_deactivated_at": now,
+		}).Error
+		if err != nil {
+			logger.Error("❌ Error al desactivar usuario del directorio %s: %v", user.Email, err)
+			continue
+		}
+
+		if _, err := s.sessionService.RevokeAllSessions(ctx, user.ID.String()); err != nil {
+			logger.Warn("Error al cerrar sesiones de %s: %v", user.Email, err)
+		}
+
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &user.ID,
+			Action:     models.AuditActionUpdate,
+			Resource:   "user",
+			ResourceID: user.ID.String(),
+			OldValues:  map[string]interface{}{"isActive": true},
+			NewValues:  map[string]interface{}{"isActive": false, "reason": "eliminado del directorio o sin grupos autorizados"},
+			Result:     models.AuditResultSuccess,
+		})
+
+		logger.Warn("📒 Usuario %s desactivado: ya no está autorizado en el directorio", user.Email)
+		deactivated++
+	}
+
+	logger.Info("📒 Sincronización LDAP: %d usuarios vigentes, %d desactivados", len(updates), deactivated)
+
+	return deactivated, nil
+}
+
+// directorySyncUpdate usuario todavía autorizado en el directorio, con sus datos vigentes
+type directorySyncUpdate struct {
+	user     *models.User
+	entry    *directoryUser
+	role     string
+	unitCode string
+}
+
+// reviewDirectoryUsers busca a cada usuario local en el directorio y separa a los vigentes
+// de los que deben desactivarse (eliminados o sin grupos de rol y unidad)
+func (s *LDAPService) reviewDirectoryUsers(conn *ldap.Conn, users []models.User) ([]directorySyncUpdate, []*models.User, error) {
+	var updates []directorySyncUpdate
+	var removed []*models.User
+	for i := range users {
+		user := &users[i]
+		entry, err := s.findUser(conn, user.Email)
+		if err != nil {
+			return nil, nil, err
+		}
+		if entry == nil {
+			removed = append(removed, user)
+			continue
+		}
+		role, unitCode := s.mapGroups(entry.Groups)
+		if role == "" || unitCode == "" {
+			removed = append(removed, user)
+			continue
+		}
+		updates = append(updates, directorySyncUpdate{user: user, entry: entry, role: role, unitCode: unitCode})
+	}
+	return updates, removed, nil
+}
+
+// checkSyncDeactivationLimit impide que una sincronización desactive a la mayoría de los usuarios
+func checkSyncDeactivationLimit(total, removed int) error {
+	if total >= ldapSyncGuardMinUsers && float64(removed) > float64(total)*ldapSyncMaxDeactivationRatio {
+		return fmt.Errorf("la sincronización desactivaría %d de %d usuarios; revise la configuración LDAP", removed, total)
+	}
+	return nil
+}
+
+// ========================================
+// CONSULTAS AL DIRECTORIO
+// ========================================
+
+// connect abre una conexión autenticada con la cuenta de búsqueda
+func (s *LDAPService) connect() (*ldap.Conn, error) {
+	conn, err := ldap.Dial(s.config.LDAPURL, ldap.DialOptions{
+		Timeout:   s.config.LDAPTimeout,
+		StartTLS:  s.config.LDAPStartTLS,
+		TLSConfig: &tls.Config{InsecureSkipVerify: s.config.LDAPInsecureSkipVerify},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDirectoryUnavailable, err)
+	}
+
+	if s.config.LDAPBindDN != "" {
+		if err := conn.Bind(s.config.LDAPBindDN, s.config.LDAPBindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: bind de la cuenta de búsqueda: %v", ErrDirectoryUnavailable, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// findUser busca al usuario por email y resuelve sus grupos; retorna nil si no existe
+func (s *LDAPService) findUser(conn *ldap.Conn, email string) (*directoryUser, error) {
+	attributes := []string{s.config.LDAPEmailAttribute, s.config.LDAPFirstNameAttribute, s.config.LDAPLastNameAttribute}
+	if s.config.LDAPGroupAttribute != "" {
+		attributes = append(attributes, s.config.LDAPGroupAttribute)
+	}
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     s.config.LDAPBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     fmt.Sprintf(s.config.LDAPUserFilter, ldap.EscapeFilter(email)),
+		Attributes: attributes,
+		SizeLimit:  2,
+	})
+	var ldapErr *ldap.Error
+	if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.ResultSizeLimitExceeded {
+		return nil, fmt.Errorf("el email %s corresponde a varias entradas del directorio", email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: búsqueda de usuario: %v", ErrDirectoryUnavailable, err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if len(entries) > 1 {
+		return nil, fmt.Errorf("el email %s corresponde a varias entradas del directorio", email)
+	}
+
+	entry := entries[0]
+	user := &directoryUser{
+		DN:        entry.DN,
+		Email:     strings.ToLower(entry.GetAttributeValue(s.config.LDAPEmailAttribute)),
+		FirstName: entry.GetAttributeValue(s.config.LDAPFirstNameAttribute),
+		LastName:  entry.GetAttributeValue(s.config.LDAPLastNameAttribute),
+	}
+	if user.Email == "" {
+		user.Email = strings.ToLower(email)
+	}
+	if user.FirstName == "" {
+		user.FirstName = strings.Split(user.Email, "@")[0]
+	}
+	if s.config.LDAPGroupAttribute != "" {
+		user.Groups = entry.GetAttributeValues(s.config.LDAPGroupAttribute)
+	}
+
+	// Directorios sin memberOf: buscar los grupos que listan al usuario como miembro
+	if s.config.LDAPGroupFilter != "" {
+		groupBase := s.config.LDAPGroupBaseDN
+		if groupBase == "" {
+			groupBase = s.config.LDAPBaseDN
+		}
+		groups, err := conn.Search(&ldap.SearchRequest{
+			BaseDN:     groupBase,
+			Scope:      ldap.ScopeWholeSubtree,
+			Filter:     fmt.Sprintf(s.config.LDAPGroupFilter, ldap.EscapeFilter(entry.DN)),
+			Attributes: []string{"cn"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: búsqueda de grupos: %v", ErrDirectoryUnavailable, err)
+		}
+		for _, group := range groups {
+			user.Groups = append(user.Groups, group.DN)
+		}
+	}
+
+	return user, nil
+}
+
+// mapGroups traduce los grupos del usuario a rol y código de unidad según la configuración
+func (s *LDAPService) mapGroups(groups []string) (string, string) {
+	role := ""
+	for _, candidate := range rolePrecedence {
+		if matchesAnyGroup(s.roleGroups, candidate, groups) {
+			role = candidate
+			break
+		}
+	}
+	if role == "" {
+		role = s.config.LDAPDefaultRole
+	}
+
+	unitCode := ""
+	for _, mapping := range s.unitGroups {
+		if groupListContains(groups, mapping.group) {
+			unitCode = mapping.value
+			break
+		}
+	}
+	if unitCode == "" {
+		unitCode = s.config.LDAPDefaultUnitCode
+	}
+
+	return role, unitCode
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// findLocalUser busca el usuario local por email sin importar su estado
+func (s *LDAPService) findLocalUser(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	return &user, nil
+}
+
+// uniqueUsername deriva el username del email y agrega un sufijo si ya existe
+func (s *LDAPService) uniqueUsername(ctx context.Context, email string) (string, error) {
+	base := directoryUsernameBase(email)
+	candidate := base
+	for i := 2; i < 100; i++ {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("error al verificar username: %w", err)
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return "", fmt.Errorf("no se pudo generar un username para %s", email)
+}
+
+// directoryUsernameBase deriva el username de la parte local del email
+func directoryUsernameBase(email string) string {
+	base := invalidUsernameChars.ReplaceAllString(strings.ToLower(strings.Split(email, "@")[0]), "")
+	if base == "" {
+		base = "usuario"
+	}
+	return truncateRunes(base, 45)
+}
+
+// parseGroupMappings interpreta "valor=grupo;valor=grupo"; el grupo puede ser CN o DN completo
+func parseGroupMappings(name, raw string) []groupMapping {
+	var mappings []groupMapping
+	for _, item := range strings.Split(raw, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		value, group, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(value) == "" || strings.TrimSpace(group) == "" {
+			logger.Warn("Entrada inválida en %s ignorada: %q", name, item)
+			continue
+		}
+		mappings = append(mappings, groupMapping{value: strings.TrimSpace(value), group: strings.TrimSpace(group)})
+	}
+	return mappings
+}
+
+// matchesAnyGroup verifica si algún grupo mapeado al valor está entre los grupos del usuario
+func matchesAnyGroup(mappings []groupMapping, value string, groups []string) bool {
+	for _, mapping := range mappings {
+		if mapping.value == value && groupListContains(groups, mapping.group) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupListContains compara por DN completo o por CN
+func groupListContains(groups []string, group string) bool {
+	byDN := strings.Contains(group, "=")
+	for _, candidate := range groups {
+		if byDN && ldap.EqualDN(candidate, group) {
+			return true
+		}
+		if !byDN && strings.EqualFold(ldap.FirstRDNValue(candidate), group) {
+			return true
+		}
+	}
+	return false
+}
+
+// directoryUserValues datos sincronizados desde el directorio, para auditoría
+func directoryUserValues(user *models.User) map[string]interface{} {
+	values := map[string]interface{}{
+		"email":      user.Email,
+		"firstName":  user.FirstName,
+		"lastName":   user.LastName,
+		"role":       user.Role,
+		"isActive":   user.IsActive,
+		"externalId": user.ExternalID,
+		"authSource": models.AuthSourceLDAP,
+	}
+	if user.OrganizationalUnitID != nil {
+		values["organizationalUnitId"] = *user.OrganizationalUnitID
+	}
+	return values
+}
+
+// sameValues compara dos mapas de valores de auditoría
+func sameValues(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateRunes recorta la cadena a n caracteres sin cortar caracteres multibyte
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
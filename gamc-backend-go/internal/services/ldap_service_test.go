@@ -0,0 +1,429 @@
+// internal/services/ldap_service_test.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/ldap"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ldapTestPassword = "Directorio2024!"
+	ldapTestPeople   = "ou=people,dc=gamc,dc=gov,dc=bo"
+	ldapTestGroups   = "ou=groups,dc=gamc,dc=gov,dc=bo"
+)
+
+// newTestLDAPService levanta el directorio de desarrollo en el servidor en memoria y
+// configura el servicio como en .env.example
+func newTestLDAPService(t *testing.T) (*LDAPService, *ldap.Server) {
+	t.Helper()
+	file, err := os.Open("../../docker/ldap/gamc-directory.ldif")
+	if err != nil {
+		t.Fatalf("Open LDIF: %v", err)
+	}
+	entries, err := ldap.ParseLDIF(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("ParseLDIF: %v", err)
+	}
+
+	server := ldap.NewServer(entries)
+	server.SetRootCredentials("cn=admin,dc=gamc,dc=gov,dc=bo", "gamc_ldap_admin_2024")
+	addr, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	cfg := &config.Config{
+		LDAPURL:                "ldap://" + addr,
+		LDAPTimeout:            5 * time.Second,
+		LDAPBindDN:             "cn=admin,dc=gamc,dc=gov,dc=bo",
+		LDAPBindPassword:       "gamc_ldap_admin_2024",
+		LDAPBaseDN:             "dc=gamc,dc=gov,dc=bo",
+		LDAPUserFilter:         "(&(objectClass=person)(mail=%s))",
+		LDAPEmailAttribute:     "mail",
+		LDAPFirstNameAttribute: "givenName",
+		LDAPLastNameAttribute:  "sn",
+		LDAPGroupAttribute:     "memberOf",
+		LDAPGroupBaseDN:        ldapTestGroups,
+		LDAPGroupFilter:        "(&(objectClass=groupOfNames)(member=%s))",
+		LDAPRoleGroups:         "admin=gamc-admins;input=gamc-operadores;output=gamc-consulta",
+		LDAPUnitGroups:         "TECNOLOGIA=unidad-tecnologia;OBRAS_PUBLICAS=unidad-obras",
+	}
+
+	return &LDAPService{
+		config:     cfg,
+		roleGroups: parseGroupMappings("LDAP_ROLE_GROUPS", cfg.LDAPRoleGroups),
+		unitGroups: parseGroupMappings("LDAP_UNIT_GROUPS", cfg.LDAPUnitGroups),
+	}, server
+}
+
+// connectTestLDAP abre una conexión con la cuenta de búsqueda
+func connectTestLDAP(t *testing.T, s *LDAPService) *ldap.Conn {
+	t.Helper()
+	conn, err := s.connect()
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// setGroupMembers reemplaza los miembros de un grupo del directorio
+func setGroupMembers(server *ldap.Server, cn string, uids ...string) {
+	members := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, fmt.Sprintf("uid=%s,%s", uid, ldapTestPeople))
+	}
+	server.AddEntry(ldap.NewEntry(fmt.Sprintf("cn=%s,%s", cn, ldapTestGroups), map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {cn},
+		"member":      members,
+	}))
+}
+
+func TestLDAPFindUser(t *testing.T) {
+	s, _ := newTestLDAPService(t)
+	conn := connectTestLDAP(t, s)
+
+	entry, err := s.findUser(conn, "Lucia.Mendez@gamc.gov.bo")
+	if err != nil {
+		t.Fatalf("findUser: %v", err)
+	}
+	if entry == nil {
+		t.Fatal("findUser no encontró a lucia.mendez")
+	}
+	if entry.DN != "uid=lmendez,"+ldapTestPeople || entry.Email != "lucia.mendez@gamc.gov.bo" {
+		t.Errorf("DN = %q, Email = %q", entry.DN, entry.Email)
+	}
+	if entry.FirstName != "Lucía" || entry.LastName != "Méndez" {
+		t.Errorf("nombre = %q %q", entry.FirstName, entry.LastName)
+	}
+	if len(entry.Groups) != 2 {
+		t.Errorf("Groups = %v, se esperaban gamc-admins y unidad-tecnologia", entry.Groups)
+	}
+
+	role, unitCode := s.mapGroups(entry.Groups)
+	if role != "admin" || unitCode != "TECNOLOGIA" {
+		t.Errorf("mapGroups = (%q, %q), se esperaba (admin, TECNOLOGIA)", role, unitCode)
+	}
+
+	for _, email := range []string{"nadie@gamc.gov.bo", "*", "*@gamc.gov.bo", "x)(|(mail=*)"} {
+		entry, err := s.findUser(conn, email)
+		if err != nil {
+			t.Errorf("findUser(%q): %v", email, err)
+		}
+		if entry != nil {
+			t.Errorf("findUser(%q) = %s, se esperaba no encontrar", email, entry.DN)
+		}
+	}
+}
+
+func TestLDAPConnectRejectsWrongServiceAccount(t *testing.T) {
+	s, _ := newTestLDAPService(t)
+	s.config.LDAPBindPassword = "incorrecta"
+
+	if _, err := s.connect(); !errors.Is(err, ErrDirectoryUnavailable) {
+		t.Errorf("connect = %v, se esperaba %v", err, ErrDirectoryUnavailable)
+	}
+}
+
+func TestLDAPBindDirectoryUser(t *testing.T) {
+	s, _ := newTestLDAPService(t)
+	conn := connectTestLDAP(t, s)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		role     string
+		unitCode string
+		wantErr  string
+	}{
+		{name: "administradora", email: "lucia.mendez@gamc.gov.bo", password: ldapTestPassword, role: "admin", unitCode: "TECNOLOGIA"},
+		{name: "operador", email: "jorge.quispe@gamc.gov.bo", password: ldapTestPassword, role: "input", unitCode: "OBRAS_PUBLICAS"},
+		{name: "consulta", email: "rosa.flores@gamc.gov.bo", password: ldapTestPassword, role: "output", unitCode: "TECNOLOGIA"},
+		{name: "contraseña incorrecta", email: "lucia.mendez@gamc.gov.bo", password: "otra", wantErr: "contraseña incorrecta"},
+		{name: "contraseña vacía", email: "lucia.mendez@gamc.gov.bo", password: "", wantErr: "contraseña incorrecta"},
+		{name: "sin grupo de rol", email: "pedro.vargas@gamc.gov.bo", password: ldapTestPassword, wantErr: "sin grupo de rol autorizado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := s.findUser(conn, tt.email)
+			if err != nil || entry == nil {
+				t.Fatalf("findUser(%q) = %v, %v", tt.email, entry, err)
+			}
+
+			role, unitCode, err := s.bindDirectoryUser(conn, entry, tt.password)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidCredentials) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("bindDirectoryUser = %v, se esperaba %v (%s)", err, ErrInvalidCredentials, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bindDirectoryUser: %v", err)
+			}
+			if role != tt.role || unitCode != tt.unitCode {
+				t.Errorf("bindDirectoryUser = (%q, %q), se esperaba (%q, %q)", role, unitCode, tt.role, tt.unitCode)
+			}
+		})
+	}
+
+	t.Run("sin unidad organizacional", func(t *testing.T) {
+		s, server := newTestLDAPService(t)
+		setGroupMembers(server, "unidad-tecnologia")
+		conn := connectTestLDAP(t, s)
+
+		entry, err := s.findUser(conn, "lucia.mendez@gamc.gov.bo")
+		if err != nil || entry == nil {
+			t.Fatalf("findUser = %v, %v", entry, err)
+		}
+		if _, _, err := s.bindDirectoryUser(conn, entry, ldapTestPassword); !errors.Is(err, ErrInvalidCredentials) ||
+			!strings.Contains(err.Error(), "sin unidad organizacional") {
+			t.Errorf("bindDirectoryUser = %v, se esperaba rechazo sin unidad", err)
+		}
+
+		// Con unidad por defecto el login procede
+		s.config.LDAPDefaultUnitCode = "TECNOLOGIA"
+		if _, unitCode, err := s.bindDirectoryUser(conn, entry, ldapTestPassword); err != nil || unitCode != "TECNOLOGIA" {
+			t.Errorf("bindDirectoryUser con unidad por defecto = %q, %v", unitCode, err)
+		}
+	})
+
+	t.Run("directorio caído", func(t *testing.T) {
+		s, server := newTestLDAPService(t)
+		conn := connectTestLDAP(t, s)
+		entry, err := s.findUser(conn, "lucia.mendez@gamc.gov.bo")
+		if err != nil || entry == nil {
+			t.Fatalf("findUser = %v, %v", entry, err)
+		}
+
+		server.Close()
+		if _, _, err := s.bindDirectoryUser(conn, entry, ldapTestPassword); !errors.Is(err, ErrDirectoryUnavailable) {
+			t.Errorf("bindDirectoryUser = %v, se esperaba %v", err, ErrDirectoryUnavailable)
+		}
+		if _, err := s.connect(); !errors.Is(err, ErrDirectoryUnavailable) {
+			t.Errorf("connect = %v, se esperaba %v", err, ErrDirectoryUnavailable)
+		}
+	})
+}
+
+func TestLDAPJustInTimeProvisioning(t *testing.T) {
+	s, _ := newTestLDAPService(t)
+	conn := connectTestLDAP(t, s)
+
+	entry, err := s.findUser(conn, "jorge.quispe@gamc.gov.bo")
+	if err != nil || entry == nil {
+		t.Fatalf("findUser = %v, %v", entry, err)
+	}
+	role, unitCode, err := s.bindDirectoryUser(conn, entry, ldapTestPassword)
+	if err != nil {
+		t.Fatalf("bindDirectoryUser: %v", err)
+	}
+	if unitCode != "OBRAS_PUBLICAS" {
+		t.Fatalf("unitCode = %q", unitCode)
+	}
+
+	// Primer login: se crea el usuario local
+	now := time.Now()
+	username := directoryUsernameBase(entry.Email)
+	user := newDirectoryUser(entry, role, 7, username, "hash-inutilizable", now)
+
+	if user.Username != "jorge.quispe" || user.Email != "jorge.quispe@gamc.gov.bo" {
+		t.Errorf("Username = %q, Email = %q", user.Username, user.Email)
+	}
+	if user.FirstName != "Jorge" || user.LastName != "Quispe" || user.Role != "input" {
+		t.Errorf("FirstName = %q, LastName = %q, Role = %q", user.FirstName, user.LastName, user.Role)
+	}
+	if !user.IsDirectoryUser() || user.ExternalID != entry.DN || !user.IsActive {
+		t.Errorf("AuthSource = %q, ExternalID = %q, IsActive = %v", user.AuthSource, user.ExternalID, user.IsActive)
+	}
+	if user.OrganizationalUnitID == nil || *user.OrganizationalUnitID != 7 {
+		t.Errorf("OrganizationalUnitID = %v, se esperaba 7", user.OrganizationalUnitID)
+	}
+	if user.EmailVerifiedAt == nil || user.DirectorySyncedAt == nil || !user.DirectorySyncedAt.Equal(now) {
+		t.Errorf("EmailVerifiedAt = %v, DirectorySyncedAt = %v", user.EmailVerifiedAt, user.DirectorySyncedAt)
+	}
+	if user.PasswordHash != "hash-inutilizable" {
+		t.Errorf("PasswordHash = %q", user.PasswordHash)
+	}
+
+	t.Run("login posterior actualiza rol y unidad", func(t *testing.T) {
+		current := *user
+		current.Role = "output"
+		later := now.Add(time.Hour)
+
+		if err := applyDirectoryUser(&current, entry, "admin", 9, later); err != nil {
+			t.Fatalf("applyDirectoryUser: %v", err)
+		}
+		if current.Role != "admin" || *current.OrganizationalUnitID != 9 || !current.DirectorySyncedAt.Equal(later) {
+			t.Errorf("Role = %q, unidad = %d, DirectorySyncedAt = %v", current.Role, *current.OrganizationalUnitID, current.DirectorySyncedAt)
+		}
+		if sameValues(directoryUserValues(user), directoryUserValues(&current)) {
+			t.Error("directoryUserValues no refleja el cambio de rol para la auditoría")
+		}
+	})
+
+	t.Run("reactiva cuentas desactivadas por la sincronización", func(t *testing.T) {
+		deactivatedAt := now.Add(-time.Hour)
+		current := *user
+		current.IsActive = false
+		current.DirectoryDeactivatedAt = &deactivatedAt
+
+		if err := applyDirectoryUser(&current, entry, role, 7, now); err != nil {
+			t.Fatalf("applyDirectoryUser: %v", err)
+		}
+		if !current.IsActive || current.DirectoryDeactivatedAt != nil {
+			t.Errorf("IsActive = %v, DirectoryDeactivatedAt = %v", current.IsActive, current.DirectoryDeactivatedAt)
+		}
+	})
+
+	t.Run("no reactiva cuentas desactivadas por un administrador", func(t *testing.T) {
+		current := *user
+		current.IsActive = false
+
+		err := applyDirectoryUser(&current, entry, role, 7, now)
+		if !errors.Is(err, ErrInvalidCredentials) || !strings.Contains(err.Error(), "cuenta desactivada") {
+			t.Fatalf("applyDirectoryUser = %v, se esperaba rechazo", err)
+		}
+		if current.IsActive {
+			t.Error("la cuenta quedó activa")
+		}
+	})
+}
+
+func TestDirectoryUsernameBase(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"lucia.mendez@gamc.gov.bo", "lucia.mendez"},
+		{"Jorge.Quispe@gamc.gov.bo", "jorge.quispe"},
+		{"josé+alcaldía@gamc.gov.bo", "josalcalda"},
+		{"ñ@gamc.gov.bo", "usuario"},
+		{strings.Repeat("a", 60) + "@gamc.gov.bo", strings.Repeat("a", 45)},
+	}
+
+	for _, tt := range tests {
+		if got := directoryUsernameBase(tt.email); got != tt.want {
+			t.Errorf("directoryUsernameBase(%q) = %q, se esperaba %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestLDAPSyncDeactivatesRemovedUsers(t *testing.T) {
+	s, server := newTestLDAPService(t)
+
+	local := func(email string) models.User {
+		return models.User{ID: uuid.New(), Email: email, AuthSource: models.AuthSourceLDAP, IsActive: true}
+	}
+	users := []models.User{
+		local("lucia.mendez@gamc.gov.bo"),
+		local("jorge.quispe@gamc.gov.bo"),
+		local("rosa.flores@gamc.gov.bo"),
+		local("pedro.vargas@gamc.gov.bo"), // Sin grupo de rol
+		local("exfuncionario@gamc.gov.bo"),
+	}
+
+	// Jorge deja la institución y Rosa pasa a ser administradora
+	server.RemoveEntry("uid=jquispe," + ldapTestPeople)
+	setGroupMembers(server, "gamc-admins", "lmendez", "rflores")
+
+	conn := connectTestLDAP(t, s)
+	updates, removed, err := s.reviewDirectoryUsers(conn, users)
+	if err != nil {
+		t.Fatalf("reviewDirectoryUsers: %v", err)
+	}
+
+	active := make(map[string]string)
+	for _, u := range updates {
+		active[u.user.Email] = u.role + "/" + u.unitCode
+	}
+	expected := map[string]string{
+		"lucia.mendez@gamc.gov.bo": "admin/TECNOLOGIA",
+		"rosa.flores@gamc.gov.bo":  "admin/TECNOLOGIA",
+	}
+	if len(active) != len(expected) {
+		t.Errorf("vigentes = %v, se esperaba %v", active, expected)
+	}
+	for email, want := range expected {
+		if active[email] != want {
+			t.Errorf("%s = %q, se esperaba %q", email, active[email], want)
+		}
+	}
+
+	deactivated := make(map[string]bool)
+	for _, user := range removed {
+		deactivated[user.Email] = true
+	}
+	for _, email := range []string{"jorge.quispe@gamc.gov.bo", "pedro.vargas@gamc.gov.bo", "exfuncionario@gamc.gov.bo"} {
+		if !deactivated[email] {
+			t.Errorf("%s no se marcó para desactivar", email)
+		}
+	}
+	if len(removed) != 3 {
+		t.Errorf("desactivados = %d, se esperaba 3", len(removed))
+	}
+
+	// Los usuarios a desactivar apuntan a los de la lista, para actualizarlos por ID
+	for _, user := range removed {
+		if user.ID == uuid.Nil {
+			t.Errorf("%s sin ID", user.Email)
+		}
+	}
+}
+
+func TestLDAPSyncDirectoryUnavailable(t *testing.T) {
+	s, server := newTestLDAPService(t)
+	conn := connectTestLDAP(t, s)
+	server.Close()
+
+	users := []models.User{{ID: uuid.New(), Email: "lucia.mendez@gamc.gov.bo", AuthSource: models.AuthSourceLDAP, IsActive: true}}
+	updates, removed, err := s.reviewDirectoryUsers(conn, users)
+	if !errors.Is(err, ErrDirectoryUnavailable) {
+		t.Fatalf("reviewDirectoryUsers = %v, se esperaba %v", err, ErrDirectoryUnavailable)
+	}
+	// Un directorio caído nunca desactiva usuarios
+	if len(updates) != 0 || len(removed) != 0 {
+		t.Errorf("updates = %d, removed = %d", len(updates), len(removed))
+	}
+}
+
+func TestCheckSyncDeactivationLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		removed int
+		allowed bool
+	}{
+		{name: "pocos usuarios: sin límite", total: 4, removed: 4, allowed: true},
+		{name: "mitad exacta", total: 10, removed: 5, allowed: true},
+		{name: "más de la mitad", total: 10, removed: 6, allowed: false},
+		{name: "base DN equivocado", total: 200, removed: 200, allowed: false},
+		{name: "bajas normales", total: 200, removed: 3, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSyncDeactivationLimit(tt.total, tt.removed)
+			if tt.allowed && err != nil {
+				t.Errorf("checkSyncDeactivationLimit = %v, se esperaba permitir", err)
+			}
+			if !tt.allowed && err == nil {
+				t.Error("checkSyncDeactivationLimit permitió desactivar a la mayoría")
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// internal/services/lockout_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// LockoutError error de login bloqueado; conserva el tiempo restante para el header Retry-After
+type LockoutError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+// Error implementa la interfaz error
+func (e *LockoutError) Error() string {
+	if e.Scope == redis.LoginScopeIP {
+		return "demasiados intentos fallidos desde esta dirección IP"
+	}
+	return "cuenta bloqueada temporalmente por intentos fallidos"
+}
+
+// RetryAfterMinutes minutos restantes de bloqueo (redondeado hacia arriba)
+func (e *LockoutError) RetryAfterMinutes() int {
+	return int(math.Ceil(e.RetryAfter.Minutes()))
+}
+
+// LockoutService protege el login contra fuerza bruta por cuenta y por IP
+type LockoutService struct {
+	attemptManager      *redis.LoginAttemptManager
+	auditService        *AuditService
+	notificationService *NotificationService
+	policyService       *SecurityPolicyService
+	config              *config.Config
+}
+
+// NewLockoutService crea una nueva instancia del servicio de bloqueo
+func NewLockoutService(appCtx *config.AppContext) *LockoutService {
+	return &LockoutService{
+		attemptManager:      redis.NewLoginAttemptManager(appCtx.Redis),
+		auditService:        NewAuditService(appCtx.DB),
+		notificationService: NewNotificationService(appCtx),
+		policyService:       NewSecurityPolicyService(appCtx),
+		config:              appCtx.Config,
+	}
+}
+
+// UnlockRequest solicitud de desbloqueo administrativo
+type UnlockRequest struct {
+	Email     string `json:"email,omitempty" validate:"omitempty,email"`
+	IPAddress string `json:"ipAddress,omitempty" validate:"omitempty,ip"`
+}
+
+// CheckLogin verifica si el intento de login está bloqueado por IP o por cuenta
+func (s *LockoutService) CheckLogin(ctx context.Context, email, ipAddress string) error {
+	ipTTL, err := s.attemptManager.GetLockTTL(ctx, redis.LoginScopeIP, ipAddress)
+	if err != nil {
+		logger.Error("Error al consultar bloqueo de IP %s: %v", ipAddress, err)
+	} else if ipTTL > 0 {
+		return &LockoutError{Scope: redis.LoginScopeIP, RetryAfter: ipTTL}
+	}
+
+	accountTTL, err := s.attemptManager.GetLockTTL(ctx, redis.LoginScopeAccount, normalizeEmail(email))
+	if err != nil {
+		logger.Error("Error al consultar bloqueo de cuenta %s: %v", email, err)
+	} else if accountTTL > 0 {
+		return &LockoutError{Scope: redis.LoginScopeAccount, RetryAfter: accountTTL}
+	}
+
+	return nil
+}
+
+// RecordFailure registra un intento fallido y bloquea la cuenta o la IP al superar los límites.
+// user es nil cuando el email no corresponde a un usuario activo.
+func (s *LockoutService) RecordFailure(ctx context.Context, email string, user *models.User, ipAddress, userAgent, reason string) {
+	email = normalizeEmail(email)
+	policy := s.policyService.Current(ctx)
+	window := policy.LockoutDuration()
+
+	var userID *uuid.UUID
+	if user != nil {
+		userID = &user.ID
+	}
+
+	accountFailures, err := s.attemptManager.RegisterFailure(ctx, redis.LoginScopeAccount, email, window)
+	if err != nil {
+		logger.Error("Error al registrar fallo de login para %s: %v", email, err)
+	}
+
+	ipFailures, err := s.attemptManager.RegisterFailure(ctx, redis.LoginScopeIP, ipAddress, window)
+	if err != nil {
+		logger.Error("Error al registrar fallo de login para IP %s: %v", ipAddress, err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     userID,
+		Action:     models.AuditActionLoginFailed,
+		Resource:   "auth",
+		ResourceID: email,
+		NewValues: map[string]interface{}{
+			"email":           email,
+			"accountFailures": accountFailures,
+			"ipFailures":      ipFailures,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultFailure,
+		ErrorMsg:  reason,
+	})
+
+	if accountFailures >= int64(policy.MaxLoginAttempts) {
+		s.lockAccount(ctx, email, user, ipAddress, userAgent, accountFailures)
+	}
+
+	if ipFailures >= int64(s.config.MaxLoginAttemptsPerIP) {
+		s.lockIP(ctx, ipAddress, userAgent, ipFailures)
+	}
+}
+
+// RecordSuccess reinicia el contador de la cuenta tras un login correcto.
+// El contador de IP no se reinicia: un atacante con una cuenta válida no debe poder limpiarlo.
+func (s *LockoutService) RecordSuccess(ctx context.Context, email string) {
+	if err := s.attemptManager.ResetFailures(ctx, redis.LoginScopeAccount, normalizeEmail(email)); err != nil {
+		logger.Warn("Error al reiniciar intentos fallidos de %s: %v", email, err)
+	}
+}
+
+// Unlock desbloquea una cuenta o una IP (acción administrativa)
+func (s *LockoutService) Unlock(ctx context.Context, req *UnlockRequest, adminID uuid.UUID, ipAddress, userAgent string) error {
+	if req.Email == "" && req.IPAddress == "" {
+		return fmt.Errorf("debe indicar email o dirección IP")
+	}
+
+	if req.Email != "" {
+		email := normalizeEmail(req.Email)
+		if err := s.attemptManager.Unlock(ctx, redis.LoginScopeAccount, email); err != nil {
+			return fmt.Errorf("error al desbloquear cuenta: %w", err)
+		}
+
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &adminID,
+			Action:     models.AuditActionAccountUnlocked,
+			Resource:   "auth",
+			ResourceID: email,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			Result:     models.AuditResultSuccess,
+		})
+
+		logger.Info("🔓 Cuenta %s desbloqueada por administrador %s", email, adminID)
+	}
+
+	if req.IPAddress != "" {
+		if err := s.attemptManager.Unlock(ctx, redis.LoginScopeIP, req.IPAddress); err != nil {
+			return fmt.Errorf("error al desbloquear IP: %w", err)
+		}
+
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &adminID,
+			Action:     models.AuditActionIPUnlocked,
+			Resource:   "auth",
+			ResourceID: req.IPAddress,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			Result:     models.AuditResultSuccess,
+		})
+
+		logger.Info("🔓 IP %s desbloqueada por administrador %s", req.IPAddress, adminID)
+	}
+
+	return nil
+}
+
+// GetActiveLocks lista las cuentas e IPs bloqueadas actualmente
+func (s *LockoutService) GetActiveLocks(ctx context.Context) ([]redis.LoginLock, error) {
+	locks, err := s.attemptManager.GetActiveLocks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bloqueos activos: %w", err)
+	}
+	return locks, nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// lockAccount bloquea la cuenta, audita el evento y avisa al usuario
+func (s *LockoutService) lockAccount(ctx context.Context, email string, user *models.User, ipAddress, userAgent string, failures int64) {
+	lockoutDuration := s.policyService.Current(ctx).LockoutDuration()
+	duration, lockouts, err := s.attemptManager.Lock(ctx, redis.LoginScopeAccount, email, lockoutDuration, s.config.MaxLockoutDuration)
+	if err != nil {
+		logger.Error("Error al bloquear cuenta %s: %v", email, err)
+		return
+	}
+
+	logger.Warn("🚨 Cuenta %s bloqueada por %v tras %d intentos fallidos (bloqueo #%d, IP %s)", email, duration, failures, lockouts, ipAddress)
+
+	var userID *uuid.UUID
+	if user != nil {
+		userID = &user.ID
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     userID,
+		Action:     models.AuditActionAccountLocked,
+		Resource:   "auth",
+		ResourceID: email,
+		NewValues: map[string]interface{}{
+			"failures":        failures,
+			"lockouts":        lockouts,
+			"durationMinutes": int(duration.Minutes()),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultFailure,
+		ErrorMsg:  "límite de intentos fallidos alcanzado",
+	})
+
+	// Solo se notifica a cuentas existentes
+	if user != nil {
+		content := fmt.Sprintf(
+			"Su cuenta fue bloqueada temporalmente durante %d minutos tras %d intentos fallidos de inicio de sesión desde la IP %s. Si no fue usted, cambie su contraseña y contacte al administrador.",
+			int(duration.Minutes()), failures, ipAddress)
+		if err := s.notificationService.CreateAlertNotification(ctx, user.ID, "Cuenta bloqueada temporalmente", content); err != nil {
+			logger.Warn("Error al notificar bloqueo de cuenta %s: %v", email, err)
+		}
+	}
+}
+
+// lockIP bloquea una dirección IP y audita el evento
+func (s *LockoutService) lockIP(ctx context.Context, ipAddress, userAgent string, failures int64) {
+	lockoutDuration := s.policyService.Current(ctx).LockoutDuration()
+	duration, lockouts, err := s.attemptManager.Lock(ctx, redis.LoginScopeIP, ipAddress, lockoutDuration, s.config.MaxLockoutDuration)
+	if err != nil {
+		logger.Error("Error al bloquear IP %s: %v", ipAddress, err)
+		return
+	}
+
+	logger.Warn("🚨 IP %s bloqueada por %v tras %d intentos fallidos (bloqueo #%d)", ipAddress, duration, failures, lockouts)
+
+	s.auditService.Log(ctx, &LogRequest{
+		Action:     models.AuditActionIPLocked,
+		Resource:   "auth",
+		ResourceID: ipAddress,
+		NewValues: map[string]interface{}{
+			"failures":        failures,
+			"lockouts":        lockouts,
+			"durationMinutes": int(duration.Minutes()),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultFailure,
+		ErrorMsg:  "límite de intentos fallidos por IP alcanzado",
+	})
+}
+
+// normalizeEmail normaliza el email usado como identificador de cuenta
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,439 @@
+// internal/services/mail_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+	"gamc-backend-go/pkg/mailer"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	mailBatchSize     = 20
+	mailBaseBackoff   = time.Minute
+	mailMaxBackoff    = time.Hour
+	mailStaleSending  = 10 * time.Minute    // Un envío "sending" más antiguo se considera interrumpido
+	mailSentRetention = 30 * 24 * time.Hour // Los enviados contienen enlaces sensibles; no se guardan indefinidamente
+)
+
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// emailTemplate versión en texto plano (con el asunto) y HTML de una plantilla
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var (
+	emailTemplatesOnce sync.Once
+	emailTemplates     map[string]*emailTemplate
+
+	dkimSignerOnce sync.Once
+	dkimSigner     *mailer.DKIMSigner
+)
+
+// loadEmailTemplates compila las plantillas embebidas una sola vez
+func loadEmailTemplates() map[string]*emailTemplate {
+	emailTemplatesOnce.Do(func() {
+		emailTemplates = make(map[string]*emailTemplate)
+		for _, name := range []string{
+			models.EmailTemplatePasswordReset,
+			models.EmailTemplateSecurityAlert,
+			models.EmailTemplateNewMessage,
+			models.EmailTemplateVerification,
+			models.EmailTemplateNewDevice,
+		} {
+			emailTemplates[name] = &emailTemplate{
+				text: texttemplate.Must(texttemplate.ParseFS(emailTemplateFS, "templates/email/"+name+".txt")),
+				html: htmltemplate.Must(htmltemplate.ParseFS(emailTemplateFS,
+					"templates/email/layout.html", "templates/email/"+name+".html")),
+			}
+		}
+	})
+	return emailTemplates
+}
+
+// loadDKIMSigner carga la clave DKIM configurada; sin clave los correos salen sin firma
+func loadDKIMSigner(cfg *config.Config) *mailer.DKIMSigner {
+	dkimSignerOnce.Do(func() {
+		if cfg.DKIMDomain == "" || cfg.DKIMPrivateKeyFile == "" {
+			return
+		}
+		keyPEM, err := os.ReadFile(cfg.DKIMPrivateKeyFile)
+		if err != nil {
+			logger.Error("❌ No se pudo leer la clave DKIM %s: %v", cfg.DKIMPrivateKeyFile, err)
+			return
+		}
+		signer, err := mailer.NewDKIMSigner(cfg.DKIMDomain, cfg.DKIMSelector, keyPEM)
+		if err != nil {
+			logger.Error("❌ Clave DKIM inválida: %v", err)
+			return
+		}
+		dkimSigner = signer
+		logger.Info("🔏 Correos firmados con DKIM (%s._domainkey.%s)", cfg.DKIMSelector, cfg.DKIMDomain)
+	})
+	return dkimSigner
+}
+
+// MailService encola correos renderizados y los entrega con reintentos
+type MailService struct {
+	db        *gorm.DB
+	client    *mailer.Client
+	signer    *mailer.DKIMSigner
+	templates map[string]*emailTemplate
+	config    *config.Config
+}
+
+// NewMailService crea una nueva instancia del servicio de correo
+func NewMailService(appCtx *config.AppContext) *MailService {
+	cfg := appCtx.Config
+	return &MailService{
+		db: appCtx.DB,
+		client: &mailer.Client{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			StartTLS: cfg.SMTPUseTLS,
+		},
+		signer:    loadDKIMSigner(cfg),
+		templates: loadEmailTemplates(),
+		config:    cfg,
+	}
+}
+
+// Enabled indica si los correos se encolan y envían
+func (s *MailService) Enabled() bool {
+	return s.config.MailEnabled
+}
+
+// Link construye un enlace absoluto al frontend para incluir en los correos
+func (s *MailService) Link(path string) string {
+	return strings.TrimRight(s.config.MailBaseURL, "/") + path
+}
+
+// EmailRequest correo a encolar a partir de una plantilla
+type EmailRequest struct {
+	UserID   *uuid.UUID
+	To       string
+	Name     string
+	Template string
+	Data     map[string]interface{}
+}
+
+// Enqueue renderiza la plantilla y agrega el correo a la cola de salida
+func (s *MailService) Enqueue(ctx context.Context, req *EmailRequest) (*models.EmailMessage, error) {
+	if !s.Enabled() {
+		logger.Debug("Correo %s para %s omitido: MAIL_ENABLED=false", req.Template, req.To)
+		return nil, nil
+	}
+
+	tmpl, ok := s.templates[req.Template]
+	if !ok {
+		return nil, fmt.Errorf("plantilla de correo desconocida: %s", req.Template)
+	}
+
+	data := map[string]interface{}{
+		"Name":    req.Name,
+		"AppName": s.config.MailFromName,
+		"BaseURL": s.Link(""),
+		"Year":    time.Now().Year(),
+	}
+	for key, value := range req.Data {
+		data[key] = value
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("error al generar asunto: %w", err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("error al generar correo: %w", err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, fmt.Errorf("error al generar correo HTML: %w", err)
+	}
+
+	maxAttempts := s.config.MailMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	email := &models.EmailMessage{
+		UserID:        req.UserID,
+		Recipient:     req.To,
+		Template:      req.Template,
+		Subject:       truncateRunes(strings.TrimSpace(subject.String()), 255),
+		BodyText:      text.String(),
+		BodyHTML:      html.String(),
+		Status:        models.EmailStatusPending,
+		MaxAttempts:   maxAttempts,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(email).Error; err != nil {
+		return nil, fmt.Errorf("error al encolar correo: %w", err)
+	}
+
+	logger.Debug("📧 Correo %s encolado para %s (id %d)", req.Template, req.To, email.ID)
+	return email, nil
+}
+
+// SendToUser encola una plantilla para un usuario; las cuentas de servicio no reciben correo
+func (s *MailService) SendToUser(ctx context.Context, userID uuid.UUID, template string, data map[string]interface{}) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		return fmt.Errorf("usuario no encontrado: %w", err)
+	}
+	if !user.IsActive || user.IsServiceAccount {
+		return nil
+	}
+
+	_, err := s.Enqueue(ctx, &EmailRequest{
+		UserID:   &user.ID,
+		To:       user.Email,
+		Name:     user.FirstName,
+		Template: template,
+		Data:     data,
+	})
+	return err
+}
+
+// ========================================
+// PROCESAMIENTO DE LA COLA
+// ========================================
+
+// ProcessQueue entrega los correos pendientes cuyo próximo intento ya venció.
+// Retorna cuántos se enviaron; varias instancias pueden procesar la cola a la vez.
+func (s *MailService) ProcessQueue(ctx context.Context) (int, error) {
+	// Recuperar envíos interrumpidos (ej. reinicio durante la entrega)
+	s.db.WithContext(ctx).Model(&models.EmailMessage{}).
+		Where("status = ? AND updated_at < ?", models.EmailStatusSending, time.Now().Add(-mailStaleSending)).
+		Update("status", models.EmailStatusPending)
+
+	var batch []models.EmailMessage
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(mailBatchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+		return tx.Model(&models.EmailMessage{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.EmailStatusSending, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al leer la cola de correo: %w", err)
+	}
+
+	sent := 0
+	for i := range batch {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.deliver(ctx, &batch[i]) {
+			sent++
+		}
+	}
+
+	if len(batch) > 0 {
+		logger.Info("📧 Cola de correo: %d de %d enviados", sent, len(batch))
+	}
+	return sent, nil
+}
+
+// deliver envía un correo y registra el resultado; los rechazos definitivos no se reintentan
+func (s *MailService) deliver(ctx context.Context, email *models.EmailMessage) bool {
+	messageID, err := s.send(ctx, email)
+	updates := recordDeliveryAttempt(email, messageID, err, time.Now())
+
+	if dbErr := s.db.WithContext(ctx).Model(&models.EmailMessage{}).Where("id = ?", email.ID).Updates(updates).Error; dbErr != nil {
+		logger.Error("Error al registrar estado del correo %d: %v", email.ID, dbErr)
+	}
+	return err == nil
+}
+
+// recordDeliveryAttempt cuenta el intento y arma los cambios a guardar: enviado, pendiente
+// hasta el siguiente reintento, o fallido si el rechazo es definitivo o se agotaron los intentos
+func recordDeliveryAttempt(email *models.EmailMessage, messageID string, err error, now time.Time) map[string]interface{} {
+	email.Attempts++
+
+	updates := map[string]interface{}{
+		"attempts":   email.Attempts,
+		"updated_at": now,
+	}
+
+	if err == nil {
+		updates["status"] = models.EmailStatusSent
+		updates["sent_at"] = now
+		updates["message_id"] = messageID
+		updates["last_error"] = nil
+	} else {
+		updates["last_error"] = truncateRunes(err.Error(), 1000)
+		if mailer.IsPermanent(err) || email.Attempts >= email.MaxAttempts {
+			updates["status"] = models.EmailStatusFailed
+			logger.Error("❌ Correo %d para %s descartado tras %d intentos: %v", email.ID, email.Recipient, email.Attempts, err)
+		} else {
+			updates["status"] = models.EmailStatusPending
+			updates["next_attempt_at"] = now.Add(mailBackoff(email.Attempts))
+			logger.Warn("⚠️ Correo %d para %s falló (intento %d/%d): %v", email.ID, email.Recipient, email.Attempts, email.MaxAttempts, err)
+		}
+	}
+	return updates
+}
+
+// send construye, firma y entrega el mensaje; retorna su Message-ID
+func (s *MailService) send(ctx context.Context, email *models.EmailMessage) (string, error) {
+	msg := &mailer.Message{
+		From:    mail.Address{Name: s.config.MailFromName, Address: s.config.SMTPFrom},
+		To:      []mail.Address{{Address: email.Recipient}},
+		Subject: email.Subject,
+		Text:    email.BodyText,
+		HTML:    email.BodyHTML,
+		Headers: map[string]string{"Auto-Submitted": "auto-generated"},
+	}
+
+	raw, err := msg.Bytes()
+	if err != nil {
+		return "", err
+	}
+	if s.signer != nil {
+		if raw, err = s.signer.Sign(raw); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.client.Send(ctx, s.config.SMTPFrom, msg.Recipients(), raw); err != nil {
+		return "", err
+	}
+	return msg.MessageID, nil
+}
+
+// mailBackoff espera exponencial entre intentos: 1m, 2m, 4m... hasta 1h
+func mailBackoff(attempts int) time.Duration {
+	delay := time.Duration(float64(mailBaseBackoff) * math.Pow(2, float64(attempts-1)))
+	if delay > mailMaxBackoff || delay <= 0 {
+		return mailMaxBackoff
+	}
+	return delay
+}
+
+// PurgeSent elimina los correos enviados más antiguos que el período de retención
+func (s *MailService) PurgeSent(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", models.EmailStatusSent, time.Now().Add(-mailSentRetention)).
+		Delete(&models.EmailMessage{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error al limpiar correos enviados: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("🧹 Correos enviados eliminados: %d", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+// ========================================
+// ADMINISTRACIÓN
+// ========================================
+
+// ListQueue lista los correos de la cola, opcionalmente filtrados por estado
+func (s *MailService) ListQueue(ctx context.Context, status string, page, limit int) ([]models.EmailMessage, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.EmailMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error al contar correos: %w", err)
+	}
+
+	var emails []models.EmailMessage
+	if err := query.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&emails).Error; err != nil {
+		return nil, 0, fmt.Errorf("error al obtener correos: %w", err)
+	}
+	return emails, total, nil
+}
+
+// Stats cuenta los correos por estado
+func (s *MailService) Stats(ctx context.Context) (*models.EmailQueueStats, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.EmailMessage{}).
+		Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener estadísticas de correo: %w", err)
+	}
+
+	stats := &models.EmailQueueStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case models.EmailStatusPending:
+			stats.Pending = row.Count
+		case models.EmailStatusSending:
+			stats.Sending = row.Count
+		case models.EmailStatusSent:
+			stats.Sent = row.Count
+		case models.EmailStatusFailed:
+			stats.Failed = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// Retry vuelve a encolar un correo fallido con un nuevo ciclo de intentos
+func (s *MailService) Retry(ctx context.Context, id int64) error {
+	result := s.db.WithContext(ctx).Model(&models.EmailMessage{}).
+		Where("id = ? AND status = ?", id, models.EmailStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          models.EmailStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error al reintentar correo: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("correo no encontrado o no fallido")
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// internal/services/mail_service_test.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/mail"
+	"strconv"
+	"testing"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/mailer"
+)
+
+// newTestMailService arma el servicio de correo contra el servidor SMTP en memoria, con firma DKIM
+func newTestMailService(t *testing.T) (*MailService, *mailer.Server) {
+	t.Helper()
+	server := mailer.NewServer()
+	addr, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("SplitHostPort(%q): %v", addr, err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	signer, err := mailer.NewDKIMSigner("gamc.gob.bo", "mail2024", keyPEM)
+	if err != nil {
+		t.Fatalf("NewDKIMSigner: %v", err)
+	}
+
+	return &MailService{
+		client: &mailer.Client{Host: host, Port: port, Timeout: 5 * time.Second},
+		signer: signer,
+		config: &config.Config{SMTPFrom: "no-reply@gamc.gob.bo", MailFromName: "GAMC"},
+	}, server
+}
+
+func queuedEmail(maxAttempts int) *models.EmailMessage {
+	return &models.EmailMessage{
+		ID:          42,
+		Recipient:   "ana@gamc.gob.bo",
+		Template:    models.EmailTemplatePasswordReset,
+		Subject:     "Restablecer contraseña",
+		BodyText:    "Hola Ana, use el enlace para restablecer su contraseña.",
+		BodyHTML:    "<p>Hola Ana, use el enlace para restablecer su contraseña.</p>",
+		Status:      models.EmailStatusSending,
+		MaxAttempts: maxAttempts,
+	}
+}
+
+func TestMailDeliveryRecordsSentStatus(t *testing.T) {
+	s, server := newTestMailService(t)
+	email := queuedEmail(6)
+	now := time.Now()
+
+	messageID, err := s.send(context.Background(), email)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	updates := recordDeliveryAttempt(email, messageID, err, now)
+
+	if updates["status"] != models.EmailStatusSent {
+		t.Errorf("status = %v, se esperaba %s", updates["status"], models.EmailStatusSent)
+	}
+	if updates["attempts"] != 1 || email.Attempts != 1 {
+		t.Errorf("attempts = %v (email.Attempts = %d), se esperaba 1", updates["attempts"], email.Attempts)
+	}
+	if updates["sent_at"] != now || updates["message_id"] != messageID {
+		t.Errorf("sent_at = %v, message_id = %v", updates["sent_at"], updates["message_id"])
+	}
+	if v, ok := updates["last_error"]; !ok || v != nil {
+		t.Errorf("last_error = %v, se esperaba limpiarlo", v)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("mensajes recibidos = %d, se esperaba 1", len(messages))
+	}
+	received := messages[0]
+	if received.From != "no-reply@gamc.gob.bo" || len(received.To) != 1 || received.To[0] != email.Recipient {
+		t.Errorf("sobre SMTP From = %q, To = %v", received.From, received.To)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(received.Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := parsed.Header.Get("Message-ID"); got != messageID {
+		t.Errorf("Message-ID = %q, se registró %q", got, messageID)
+	}
+	if got := parsed.Header.Get("Auto-Submitted"); got != "auto-generated" {
+		t.Errorf("Auto-Submitted = %q", got)
+	}
+	if !bytes.HasPrefix(received.Data, []byte("DKIM-Signature: ")) {
+		t.Fatalf("el correo entregado no tiene DKIM-Signature")
+	}
+	if err := mailer.VerifyDKIM(received.Data, s.signer.PublicKey()); err != nil {
+		t.Errorf("VerifyDKIM del correo entregado: %v", err)
+	}
+}
+
+func TestMailDeliveryRetriesTemporaryFailures(t *testing.T) {
+	s, server := newTestMailService(t)
+	email := queuedEmail(3)
+	now := time.Now()
+
+	// Dos rechazos 4xx: el correo vuelve a la cola con espera creciente
+	server.FailNext(2, 451)
+	for attempt := 1; attempt <= 2; attempt++ {
+		messageID, err := s.send(context.Background(), email)
+		if err == nil {
+			t.Fatalf("intento %d: send no retornó error con rechazo 451", attempt)
+		}
+		updates := recordDeliveryAttempt(email, messageID, err, now)
+
+		if updates["status"] != models.EmailStatusPending {
+			t.Errorf("intento %d: status = %v, se esperaba %s", attempt, updates["status"], models.EmailStatusPending)
+		}
+		if want := now.Add(mailBackoff(attempt)); updates["next_attempt_at"] != want {
+			t.Errorf("intento %d: next_attempt_at = %v, se esperaba %v", attempt, updates["next_attempt_at"], want)
+		}
+		if lastErr, _ := updates["last_error"].(string); lastErr == "" {
+			t.Errorf("intento %d: last_error vacío", attempt)
+		}
+		if _, ok := updates["message_id"]; ok {
+			t.Errorf("intento %d: se registró message_id de un envío fallido", attempt)
+		}
+	}
+	if n := len(server.Messages()); n != 0 {
+		t.Fatalf("mensajes entregados tras los rechazos = %d", n)
+	}
+
+	// El tercer intento se entrega
+	messageID, err := s.send(context.Background(), email)
+	updates := recordDeliveryAttempt(email, messageID, err, now)
+	if err != nil || updates["status"] != models.EmailStatusSent {
+		t.Fatalf("tercer intento: err = %v, status = %v", err, updates["status"])
+	}
+	if email.Attempts != 3 {
+		t.Errorf("Attempts = %d, se esperaba 3", email.Attempts)
+	}
+	if n := len(server.Messages()); n != 1 {
+		t.Errorf("mensajes entregados = %d, se esperaba 1", n)
+	}
+}
+
+func TestMailDeliveryGivesUp(t *testing.T) {
+	t.Run("rechazo definitivo 5xx", func(t *testing.T) {
+		s, server := newTestMailService(t)
+		email := queuedEmail(6)
+		server.FailNext(1, 550)
+
+		messageID, err := s.send(context.Background(), email)
+		if !mailer.IsPermanent(err) {
+			t.Fatalf("send = %v, se esperaba un rechazo definitivo", err)
+		}
+		updates := recordDeliveryAttempt(email, messageID, err, time.Now())
+		if updates["status"] != models.EmailStatusFailed {
+			t.Errorf("status = %v, se esperaba %s al primer intento", updates["status"], models.EmailStatusFailed)
+		}
+		if _, ok := updates["next_attempt_at"]; ok {
+			t.Error("se programó un reintento para un rechazo definitivo")
+		}
+	})
+
+	t.Run("intentos agotados", func(t *testing.T) {
+		s, server := newTestMailService(t)
+		email := queuedEmail(2)
+		email.Attempts = 1
+		server.FailNext(1, 421)
+
+		messageID, err := s.send(context.Background(), email)
+		if err == nil || mailer.IsPermanent(err) {
+			t.Fatalf("send = %v, se esperaba un rechazo temporal", err)
+		}
+		updates := recordDeliveryAttempt(email, messageID, err, time.Now())
+		if updates["status"] != models.EmailStatusFailed {
+			t.Errorf("status = %v, se esperaba %s en el último intento", updates["status"], models.EmailStatusFailed)
+		}
+		if _, ok := updates["next_attempt_at"]; ok {
+			t.Error("se programó un reintento tras agotar los intentos")
+		}
+	})
+
+	t.Run("servidor inaccesible", func(t *testing.T) {
+		s, server := newTestMailService(t)
+		server.Close()
+		email := queuedEmail(6)
+
+		messageID, err := s.send(context.Background(), email)
+		if err == nil {
+			t.Fatal("send no retornó error con el servidor cerrado")
+		}
+		updates := recordDeliveryAttempt(email, messageID, err, time.Now())
+		if updates["status"] != models.EmailStatusPending {
+			t.Errorf("status = %v, se esperaba %s", updates["status"], models.EmailStatusPending)
+		}
+	})
+}
+
+func TestMailBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := mailBackoff(tt.attempts); got != tt.want {
+			t.Errorf("mailBackoff(%d) = %v, se esperaba %v", tt.attempts, got, tt.want)
+		}
+	}
+}
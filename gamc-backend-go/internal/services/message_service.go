@@ -5,10 +5,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"gamc-backend-go/internal/config"
 	"gamc-backend-go/internal/database/models"
 	"gamc-backend-go/internal/repositories"
+	"gamc-backend-go/internal/types/requests"
+	"gamc-backend-go/internal/types/responses"
 	"gamc-backend-go/pkg/logger"
 
 	"github.com/google/uuid"
@@ -21,16 +25,21 @@ type MessageService struct {
 	userRepo    *repositories.UserRepository
 	auditRepo   *repositories.AuditRepository
 	notifyRepo  *repositories.NotificationRepository
+	permissions *PermissionService
+	mailService *MailService
 	db          *gorm.DB
 }
 
 // NewMessageService crea una nueva instancia del servicio
-func NewMessageService(db *gorm.DB) *MessageService {
+func NewMessageService(appCtx *config.AppContext) *MessageService {
+	db := appCtx.DB
 	return &MessageService{
 		messageRepo: repositories.NewMessageRepository(db),
 		userRepo:    repositories.NewUserRepository(db),
 		auditRepo:   repositories.NewAuditRepository(db),
 		notifyRepo:  repositories.NewNotificationRepository(db),
+		permissions: NewPermissionService(db),
+		mailService: NewMailService(appCtx),
 		db:          db,
 	}
 }
@@ -130,7 +139,9 @@ func (s *MessageService) CreateMessage(ctx context.Context, req *CreateMessageRe
 	})
 
 	// Crear notificaciones para la unidad receptora
-	go s.createNotificationsForUnit(context.Background(), message.ID, req.ReceiverUnitID, req.Subject)
+	var senderUnit models.OrganizationalUnit
+	s.db.WithContext(ctx).Select("name").First(&senderUnit, req.SenderUnitID)
+	go s.createNotificationsForUnit(context.Background(), message.ID, req.ReceiverUnitID, req.Subject, senderUnit.Name)
 
 	logger.Info("✅ Mensaje creado exitosamente - ID: %d", message.ID)
 
@@ -261,7 +272,7 @@ func (s *MessageService) ArchiveMessage(ctx context.Context, messageID int64, us
 	}
 
 	// Verificar permisos
-	if err := s.verifyReadPermissions(ctx, message, userID); err != nil {
+	if err := s.verifyManagePermissions(ctx, message, userID); err != nil {
 		return err
 	}
 
@@ -289,7 +300,7 @@ func (s *MessageService) UnarchiveMessage(ctx context.Context, messageID int64,
 	}
 
 	// Verificar permisos
-	if err := s.verifyReadPermissions(ctx, message, userID); err != nil {
+	if err := s.verifyManagePermissions(ctx, message, userID); err != nil {
 		return err
 	}
 
@@ -412,7 +423,7 @@ func (s *MessageService) UpdateMessageStatus(ctx context.Context, messageID int6
 	}
 
 	// Verificar permisos
-	if err := s.verifyReadPermissions(ctx, message, userID); err != nil {
+	if err := s.verifyManagePermissions(ctx, message, userID); err != nil {
 		return err
 	}
 
@@ -439,6 +450,245 @@ func (s *MessageService) UpdateMessageStatus(ctx context.Context, messageID int6
 	return nil
 }
 
+// ReplyToMessage responde a un mensaje: la respuesta va a la otra unidad de la conversación,
+// queda enlazada en message_responses y el mensaje original se marca como respondido
+func (s *MessageService) ReplyToMessage(ctx context.Context, parentID int64, req *requests.MessageResponseRequest, user *models.UserProfile) (*MessageResponse, error) {
+	logger.Info("💬 Respondiendo mensaje - ID: %d, Usuario: %s", parentID, user.ID.String())
+
+	parent, err := s.messageRepo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("mensaje no encontrado")
+		}
+		return nil, fmt.Errorf("error al obtener mensaje: %w", err)
+	}
+
+	// Quien responde debe poder leer el mensaje original
+	if err := s.verifyReadPermissions(ctx, parent, user.ID); err != nil {
+		return nil, err
+	}
+
+	if user.OrganizationalUnitID == nil {
+		return nil, fmt.Errorf("el usuario no pertenece a una unidad organizacional")
+	}
+	senderUnitID := *user.OrganizationalUnitID
+
+	var messageType models.MessageType
+	if err := s.db.WithContext(ctx).First(&messageType, req.MessageTypeID).Error; err != nil {
+		return nil, fmt.Errorf("tipo de mensaje no encontrado")
+	}
+
+	var sentStatus, respondedStatus models.MessageStatus
+	if err := s.db.WithContext(ctx).Where("code = ?", "SENT").First(&sentStatus).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener estado de mensaje: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Where("code = ?", "RESPONDED").First(&respondedStatus).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener estado de mensaje: %w", err)
+	}
+
+	// La respuesta vuelve a la unidad que escribió; si responde la propia unidad emisora
+	// (seguimiento), va a la unidad receptora
+	receiverUnitID := parent.SenderUnitID
+	if senderUnitID == parent.SenderUnitID {
+		receiverUnitID = parent.ReceiverUnitID
+	}
+
+	reply := &models.Message{
+		Subject:        replySubject(parent.Subject),
+		Content:        req.Content,
+		SenderID:       user.ID,
+		SenderUnitID:   senderUnitID,
+		ReceiverUnitID: receiverUnitID,
+		MessageTypeID:  req.MessageTypeID,
+		StatusID:       sentStatus.ID,
+		PriorityLevel:  parent.PriorityLevel,
+		IsUrgent:       parent.IsUrgent,
+	}
+
+	// Un seguimiento del propio autor no cuenta como respuesta. Se conserva la fecha
+	// de la primera respuesta y un estado final no se reabre
+	updates := map[string]interface{}{}
+	oldValues := map[string]interface{}{
+		"responded_at": parent.RespondedAt,
+		"status_id":    parent.StatusID,
+	}
+	if user.ID != parent.SenderID {
+		if !parent.IsResponded() {
+			parent.MarkAsResponded()
+			updates["responded_at"] = parent.RespondedAt
+		}
+		if parent.Status == nil || !parent.Status.IsFinal {
+			parent.StatusID = respondedStatus.ID
+			updates["status_id"] = respondedStatus.ID
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reply).Error; err != nil {
+			return fmt.Errorf("error al crear respuesta: %w", err)
+		}
+
+		link := &models.MessageReply{OriginalMessageID: parent.ID, ResponseMessageID: reply.ID}
+		if err := tx.Create(link).Error; err != nil {
+			return fmt.Errorf("error al enlazar respuesta: %w", err)
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Message{}).Where("id = ?", parent.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("error al marcar como respondido: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Registrar en auditoría
+	s.auditLog(ctx, user.ID, models.AuditActionCreate, "messages", fmt.Sprintf("%d", reply.ID), nil, map[string]interface{}{
+		"subject":       reply.Subject,
+		"in_reply_to":   parent.ID,
+		"receiver_unit": receiverUnitID,
+		"message_type":  req.MessageTypeID,
+	})
+	if len(updates) > 0 {
+		s.auditLog(ctx, user.ID, models.AuditActionUpdate, "messages", fmt.Sprintf("%d", parent.ID), oldValues, updates)
+	}
+
+	var senderUnit models.OrganizationalUnit
+	s.db.WithContext(ctx).Select("name").First(&senderUnit, senderUnitID)
+	go s.createNotificationsForUnit(context.Background(), reply.ID, receiverUnitID, reply.Subject, senderUnit.Name)
+
+	logger.Info("✅ Respuesta creada - ID: %d (responde a %d)", reply.ID, parent.ID)
+
+	return s.GetMessageByID(ctx, reply.ID)
+}
+
+// GetMessageThread obtiene el hilo completo al que pertenece un mensaje, desde el mensaje
+// que lo inició, con las respuestas anidadas, los participantes y los no leídos por unidad
+func (s *MessageService) GetMessageThread(ctx context.Context, messageID int64, user *models.UserProfile) (*responses.MessageThreadResponse, error) {
+	logger.Debug("🧵 Obteniendo hilo del mensaje ID: %d", messageID)
+
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("mensaje no encontrado")
+		}
+		return nil, fmt.Errorf("error al obtener mensaje: %w", err)
+	}
+
+	// Poder leer un mensaje da acceso a la conversación de la que forma parte
+	if err := s.verifyReadPermissions(ctx, message, user.ID); err != nil {
+		return nil, err
+	}
+
+	rootID, err := s.messageRepo.GetThreadRootID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener hilo: %w", err)
+	}
+
+	messages, links, err := s.messageRepo.GetMessageThread(ctx, rootID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("mensaje no encontrado")
+		}
+		return nil, fmt.Errorf("error al obtener hilo: %w", err)
+	}
+
+	byID := make(map[int64]*models.Message, len(messages))
+	for _, m := range messages {
+		byID[m.ID] = m
+	}
+	parentOf := make(map[int64]int64, len(links))
+	for _, link := range links {
+		if _, ok := parentOf[link.ResponseMessageID]; !ok {
+			parentOf[link.ResponseMessageID] = link.OriginalMessageID
+		}
+	}
+	// Respuestas de cada mensaje en orden cronológico
+	children := make(map[int64][]int64)
+	for _, m := range messages {
+		if parentID, ok := parentOf[m.ID]; ok && m.ID != rootID {
+			children[parentID] = append(children[parentID], m.ID)
+		}
+	}
+
+	var build func(id int64, depth int) responses.MessageResponse
+	build = func(id int64, depth int) responses.MessageResponse {
+		node := toThreadMessage(byID[id])
+		if parentID, ok := parentOf[id]; ok && id != rootID {
+			node.ParentID = &parentID
+		}
+		node.ResponseCount = len(children[id])
+		if depth < repositories.MaxThreadDepth {
+			for _, childID := range children[id] {
+				node.Replies = append(node.Replies, build(childID, depth+1))
+			}
+		}
+		return node
+	}
+
+	if _, ok := byID[rootID]; !ok {
+		return nil, fmt.Errorf("mensaje no encontrado")
+	}
+	original := build(rootID, 0)
+	thread := &responses.MessageThreadResponse{
+		OriginalMessage: original,
+		Responses:       original.Replies,
+		TotalResponses:  len(messages) - 1,
+		Participants:    make([]responses.ThreadParticipant, 0),
+		Units:           make([]responses.ThreadUnitSummary, 0),
+		LastActivityAt:  messages[len(messages)-1].CreatedAt,
+	}
+	thread.OriginalMessage.Replies = nil
+	if thread.Responses == nil {
+		thread.Responses = make([]responses.MessageResponse, 0)
+	}
+
+	// Participantes y conteos por unidad, en orden de aparición
+	participantIndex := make(map[uuid.UUID]int)
+	unitIndex := make(map[int]int)
+	unitAt := func(id int, unit *models.OrganizationalUnit) *responses.ThreadUnitSummary {
+		i, ok := unitIndex[id]
+		if !ok {
+			summary := organizationSummary(unit)
+			summary.ID = id
+			thread.Units = append(thread.Units, responses.ThreadUnitSummary{Unit: summary})
+			i = len(thread.Units) - 1
+			unitIndex[id] = i
+		}
+		return &thread.Units[i]
+	}
+	for _, m := range messages {
+		i, ok := participantIndex[m.SenderID]
+		if !ok {
+			sender := userSummary(m.Sender)
+			sender.ID = m.SenderID
+			unit := organizationSummary(m.SenderUnit)
+			unit.ID = m.SenderUnitID
+			thread.Participants = append(thread.Participants, responses.ThreadParticipant{User: sender, Unit: unit})
+			i = len(thread.Participants) - 1
+			participantIndex[m.SenderID] = i
+		}
+		thread.Participants[i].MessageCount++
+		thread.Participants[i].LastMessageAt = m.CreatedAt
+
+		unitAt(m.SenderUnitID, m.SenderUnit).Sent++
+		receiver := unitAt(m.ReceiverUnitID, m.ReceiverUnit)
+		receiver.Received++
+		if !m.IsRead() {
+			receiver.Unread++
+		}
+	}
+	if user.OrganizationalUnitID != nil {
+		if i, ok := unitIndex[*user.OrganizationalUnitID]; ok {
+			thread.UnreadCount = thread.Units[i].Unread
+		}
+	}
+
+	return thread, nil
+}
+
 // Funciones auxiliares
 
 // buildMessageFilter construye el filtro del repositorio desde el request
@@ -515,8 +765,93 @@ func (s *MessageService) convertMessagesToResponses(messages []*models.Message)
 	return responses
 }
 
-// createNotificationsForUnit crea notificaciones para los usuarios de una unidad
-func (s *MessageService) createNotificationsForUnit(ctx context.Context, messageID int64, unitID int, subject string) {
+// replySubject asunto de una respuesta ("RE: " una sola vez, máximo 255 caracteres)
+func replySubject(subject string) string {
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(subject)), "RE:") {
+		subject = "RE: " + subject
+	}
+	if runes := []rune(subject); len(runes) > 255 {
+		subject = string(runes[:255])
+	}
+	return subject
+}
+
+// toThreadMessage convierte un mensaje al formato resumido de los hilos
+func toThreadMessage(message *models.Message) responses.MessageResponse {
+	node := responses.MessageResponse{
+		ID:              message.ID,
+		Subject:         message.Subject,
+		Content:         message.Content,
+		Sender:          userSummary(message.Sender),
+		SenderUnit:      organizationSummary(message.SenderUnit),
+		ReceiverUnit:    organizationSummary(message.ReceiverUnit),
+		PriorityLevel:   message.PriorityLevel,
+		IsUrgent:        message.IsUrgent,
+		ReadAt:          message.ReadAt,
+		RespondedAt:     message.RespondedAt,
+		ArchivedAt:      message.ArchivedAt,
+		Attachments:     make([]responses.AttachmentSummary, 0, len(message.Attachments)),
+		AttachmentCount: len(message.Attachments),
+		CreatedAt:       message.CreatedAt,
+		UpdatedAt:       message.UpdatedAt,
+	}
+	node.Sender.ID = message.SenderID
+	node.SenderUnit.ID = message.SenderUnitID
+	node.ReceiverUnit.ID = message.ReceiverUnitID
+
+	node.MessageType.ID = message.MessageTypeID
+	if message.MessageType != nil {
+		node.MessageType.Name = message.MessageType.Name
+		node.MessageType.Code = message.MessageType.Code
+		node.MessageType.Color = message.MessageType.Color
+	}
+	node.Status.ID = message.StatusID
+	if message.Status != nil {
+		node.Status.Name = message.Status.Name
+		node.Status.Code = message.Status.Code
+		node.Status.Color = message.Status.Color
+	}
+
+	for _, a := range message.Attachments {
+		node.Attachments = append(node.Attachments, responses.AttachmentSummary{
+			ID:           a.ID,
+			OriginalName: a.OriginalName,
+			FileSize:     a.FileSize,
+			MimeType:     a.MimeType,
+			Url:          fmt.Sprintf("/api/v1/files/%s", a.ID),
+			IsImage:      strings.HasPrefix(a.MimeType, "image/"),
+		})
+	}
+
+	return node
+}
+
+// userSummary resumen de usuario (vacío si la relación no se cargó)
+func userSummary(user *models.User) responses.UserSummary {
+	if user == nil {
+		return responses.UserSummary{}
+	}
+	return responses.UserSummary{
+		ID:       user.ID,
+		FullName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email:    user.Email,
+	}
+}
+
+// organizationSummary resumen de unidad (vacío si la relación no se cargó)
+func organizationSummary(unit *models.OrganizationalUnit) responses.OrganizationSummary {
+	if unit == nil {
+		return responses.OrganizationSummary{}
+	}
+	return responses.OrganizationSummary{
+		ID:   unit.ID,
+		Name: unit.Name,
+		Code: unit.Code,
+	}
+}
+
+// createNotificationsForUnit crea notificaciones (y correos) para los usuarios de una unidad
+func (s *MessageService) createNotificationsForUnit(ctx context.Context, messageID int64, unitID int, subject, senderUnit string) {
 	// Obtener usuarios de la unidad
 	users, err := s.userRepo.GetByOrganizationalUnit(ctx, unitID)
 	if err != nil {
@@ -542,31 +877,53 @@ func (s *MessageService) createNotificationsForUnit(ctx context.Context, message
 		if err := s.notifyRepo.Create(ctx, notification); err != nil {
 			logger.Error("Error al crear notificación para usuario %s: %v", user.ID, err)
 		}
+
+		if user.IsServiceAccount || !s.mailService.Enabled() {
+			continue
+		}
+		userID := user.ID
+		_, err := s.mailService.Enqueue(ctx, &EmailRequest{
+			UserID:   &userID,
+			To:       user.Email,
+			Name:     user.FirstName,
+			Template: models.EmailTemplateNewMessage,
+			Data: map[string]interface{}{
+				"Subject":    subject,
+				"SenderUnit": senderUnit,
+				"MessageURL": s.mailService.Link(fmt.Sprintf("/messages/%d", messageID)),
+			},
+		})
+		if err != nil {
+			logger.Error("Error al encolar correo de mensaje para %s: %v", user.Email, err)
+		}
 	}
 }
 
 // verifyReadPermissions verifica si un usuario tiene permisos para leer un mensaje
 func (s *MessageService) verifyReadPermissions(ctx context.Context, message *models.Message, userID uuid.UUID) error {
-	// Obtener usuario
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return fmt.Errorf("usuario no encontrado: %w", err)
-	}
+	return s.verifyMessagePermission(ctx, message.SenderUnitID, message.ReceiverUnitID, userID, models.PermissionMessagesRead)
+}
 
-	// Los administradores pueden leer cualquier mensaje
-	if user.Role == "admin" {
-		return nil
-	}
+// verifyManagePermissions verifica si un usuario puede cambiar el estado o archivar un mensaje
+func (s *MessageService) verifyManagePermissions(ctx context.Context, message *models.Message, userID uuid.UUID) error {
+	return s.verifyMessagePermission(ctx, message.SenderUnitID, message.ReceiverUnitID, userID, models.PermissionMessagesManage)
+}
 
-	// El usuario debe pertenecer a la unidad emisora o receptora
-	if user.OrganizationalUnitID != nil {
-		unitID := *user.OrganizationalUnitID
-		if unitID == message.SenderUnitID || unitID == message.ReceiverUnitID {
-			return nil
-		}
-	}
+// CheckReadAccess verifica si el usuario puede leer un mensaje entre las unidades indicadas
+func (s *MessageService) CheckReadAccess(ctx context.Context, senderUnitID, receiverUnitID int, userID uuid.UUID) error {
+	return s.verifyMessagePermission(ctx, senderUnitID, receiverUnitID, userID, models.PermissionMessagesRead)
+}
 
-	return fmt.Errorf("no tiene permisos para acceder a este mensaje")
+// verifyMessagePermission el permiso debe valer en la unidad emisora o en la receptora
+func (s *MessageService) verifyMessagePermission(ctx context.Context, senderUnitID, receiverUnitID int, userID uuid.UUID, permission string) error {
+	allowed, err := s.permissions.CanAny(ctx, userID, permission, senderUnitID, receiverUnitID)
+	if err != nil {
+		return fmt.Errorf("error al verificar permisos: %w", err)
+	}
+	if !allowed {
+		return fmt.Errorf("no tiene permisos para acceder a este mensaje")
+	}
+	return nil
 }
 
 // auditLog registra una acción en el log de auditoría
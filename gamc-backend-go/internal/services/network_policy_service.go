@@ -0,0 +1,678 @@
+// internal/services/network_policy_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// networkPolicyLocalTTL tiempo que cada proceso reutiliza las reglas sin consultar la
+	// base de datos; acota cuánto tarda un cambio en llegar a las demás instancias
+	networkPolicyLocalTTL = 30 * time.Second
+
+	// networkAuditInterval los rechazos y los usos de excepciones se auditan una vez
+	// por usuario e IP en este intervalo, no en cada petición
+	networkAuditInterval = time.Hour
+
+	// networkScopeGlobal alcance de security_policies.ip_whitelist
+	networkScopeGlobal = "global"
+)
+
+// networkCache reglas y excepciones vigentes compartidas por las instancias del servicio del proceso
+var networkCache struct {
+	mu       sync.RWMutex
+	snapshot *networkSnapshot
+	loadedAt time.Time
+}
+
+// networkSnapshot reglas de red ya interpretadas
+type networkSnapshot struct {
+	rules    []models.NetworkRule
+	roles    map[string][]netip.Prefix
+	units    map[int][]netip.Prefix
+	bypasses []models.IPAllowlistBypass
+}
+
+// NetworkSubject usuario cuyo acceso por red se evalúa
+type NetworkSubject struct {
+	UserID               uuid.UUID
+	Email                string
+	Role                 string
+	OrganizationalUnitID *int
+}
+
+// NetworkSubjectFromProfile arma el sujeto a partir del perfil autenticado
+func NetworkSubjectFromProfile(profile *models.UserProfile) *NetworkSubject {
+	return &NetworkSubject{
+		UserID:               profile.ID,
+		Email:                profile.Email,
+		Role:                 profile.Role,
+		OrganizationalUnitID: profile.OrganizationalUnitID,
+	}
+}
+
+// NetworkPolicyService restringe el acceso de cada rol y unidad a rangos IP
+type NetworkPolicyService struct {
+	db            *gorm.DB
+	cacheManager  *redis.CacheManager
+	policyService *SecurityPolicyService
+	auditService  *AuditService
+	config        *config.Config
+}
+
+// NewNetworkPolicyService crea una nueva instancia del servicio de política de red
+func NewNetworkPolicyService(appCtx *config.AppContext) *NetworkPolicyService {
+	return &NetworkPolicyService{
+		db:            appCtx.DB,
+		cacheManager:  redis.NewCacheManager(appCtx.Redis),
+		policyService: NewSecurityPolicyService(appCtx),
+		auditService:  NewAuditService(appCtx.DB),
+		config:        appCtx.Config,
+	}
+}
+
+// ========================================
+// VERIFICACIÓN
+// ========================================
+
+// Authorize verifica que el usuario pueda operar desde la IP. Retorna ErrIPNotAllowed
+// si la IP no está en los rangos de alguno de sus alcances y no hay una excepción vigente.
+// origin identifica el punto de control en la auditoría (login o la ruta solicitada).
+func (s *NetworkPolicyService) Authorize(ctx context.Context, subject *NetworkSubject, ipAddress, userAgent, origin string) error {
+	if !s.config.IPAllowlistEnabled {
+		return nil
+	}
+
+	decision, err := s.Evaluate(ctx, subject, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	if decision.BypassID != nil {
+		s.recordBypassUse(ctx, *decision.BypassID, subject, decision, userAgent, origin)
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	s.recordDenial(ctx, subject, decision, userAgent, origin)
+	return ErrIPNotAllowed
+}
+
+// Evaluate calcula qué alcances aplican al usuario y si la IP los cumple, sin auditar.
+// Los alcances se combinan: la IP debe estar en algún rango de cada uno que tenga reglas.
+func (s *NetworkPolicyService) Evaluate(ctx context.Context, subject *NetworkSubject, ipAddress string) (*models.NetworkDecision, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	whitelist := parseAllowlist(s.policyService.Current(ctx).IPWhitelist)
+	decision := evaluateNetwork(snapshot, whitelist, subject, ipAddress)
+	if decision.Allowed {
+		return decision, nil
+	}
+
+	now := time.Now()
+	for _, bypass := range snapshot.bypasses {
+		if bypass.RevokedAt == nil && now.Before(bypass.ExpiresAt) && bypass.AppliesTo(subject.UserID) {
+			id := bypass.ID
+			decision.BypassID = &id
+			decision.Allowed = true
+			break
+		}
+	}
+
+	return decision, nil
+}
+
+// evaluateNetwork aplica las reglas sin considerar excepciones
+func evaluateNetwork(snapshot *networkSnapshot, whitelist []netip.Prefix, subject *NetworkSubject, ipAddress string) *models.NetworkDecision {
+	decision := &models.NetworkDecision{IPAddress: ipAddress, Enforced: []string{}}
+
+	addr, err := netip.ParseAddr(ipAddress)
+	valid := err == nil
+	addr = addr.Unmap()
+
+	check := func(scope string, prefixes []netip.Prefix) {
+		if len(prefixes) == 0 {
+			return
+		}
+		decision.Enforced = append(decision.Enforced, scope)
+		if !valid || !containsAddr(prefixes, addr) {
+			decision.Failed = append(decision.Failed, scope)
+		}
+	}
+
+	check(networkScopeGlobal, whitelist)
+	check(models.NetworkRuleScopeRole, snapshot.roles[subject.Role])
+	if subject.OrganizationalUnitID != nil {
+		check(models.NetworkRuleScopeUnit, snapshot.units[*subject.OrganizationalUnitID])
+	}
+
+	decision.Allowed = len(decision.Failed) == 0
+	return decision
+}
+
+// recordBypassUse registra el uso de una excepción y lo audita una vez por usuario e IP
+func (s *NetworkPolicyService) recordBypassUse(ctx context.Context, bypassID int, subject *NetworkSubject, decision *models.NetworkDecision, userAgent, origin string) {
+	key := fmt.Sprintf("network_audit:bypass:%d:%s:%s", bypassID, subject.UserID, decision.IPAddress)
+	if !s.firstInInterval(ctx, key) {
+		return
+	}
+
+	now := time.Now()
+	err := s.db.WithContext(ctx).Model(&models.IPAllowlistBypass{}).Where("id = ?", bypassID).Updates(map[string]interface{}{
+		"use_count":    gorm.Expr("use_count + 1"),
+		"last_used_at": now,
+		"last_used_ip": decision.IPAddress,
+	}).Error
+	if err != nil {
+		logger.Warn("Error al registrar uso de la excepción de red %d: %v", bypassID, err)
+	}
+
+	logger.Warn("🚨 Excepción de red %d usada por %s desde %s (%s)", bypassID, subject.Email, decision.IPAddress, origin)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &subject.UserID,
+		Action:     models.AuditActionIPBypassUsed,
+		Resource:   "ip_allowlist_bypass",
+		ResourceID: fmt.Sprintf("%d", bypassID),
+		NewValues: map[string]interface{}{
+			"email":  subject.Email,
+			"role":   subject.Role,
+			"failed": decision.Failed,
+			"origin": origin,
+		},
+		IPAddress: decision.IPAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+}
+
+// recordDenial audita el rechazo una vez por usuario e IP
+func (s *NetworkPolicyService) recordDenial(ctx context.Context, subject *NetworkSubject, decision *models.NetworkDecision, userAgent, origin string) {
+	key := fmt.Sprintf("network_audit:denied:%s:%s", subject.UserID, decision.IPAddress)
+	if !s.firstInInterval(ctx, key) {
+		return
+	}
+
+	logger.Warn("🚫 Acceso de %s desde %s fuera de los rangos permitidos (%s)", subject.Email, decision.IPAddress, origin)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:   &subject.UserID,
+		Action:   models.AuditActionIPNotAllowed,
+		Resource: "network_policy",
+		NewValues: map[string]interface{}{
+			"email":                subject.Email,
+			"role":                 subject.Role,
+			"organizationalUnitId": subject.OrganizationalUnitID,
+			"enforced":             decision.Enforced,
+			"failed":               decision.Failed,
+			"origin":               origin,
+		},
+		IPAddress: decision.IPAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultFailure,
+		ErrorMsg:  ErrIPNotAllowed.Error(),
+	})
+}
+
+// firstInInterval indica si el evento aún no se registró en el intervalo de auditoría.
+// Si Redis no responde se registra igual.
+func (s *NetworkPolicyService) firstInInterval(ctx context.Context, key string) bool {
+	first, err := s.cacheManager.SetIfAbsent(ctx, key, networkAuditInterval)
+	if err != nil {
+		logger.Warn("Error al consultar la auditoría de red en cache: %v", err)
+		return true
+	}
+	return first
+}
+
+// ========================================
+// CACHE
+// ========================================
+
+// snapshot retorna las reglas vigentes. Ante errores usa la última copia conocida.
+func (s *NetworkPolicyService) snapshot(ctx context.Context) (*networkSnapshot, error) {
+	networkCache.mu.RLock()
+	cached, loadedAt := networkCache.snapshot, networkCache.loadedAt
+	networkCache.mu.RUnlock()
+
+	if cached != nil && time.Since(loadedAt) < networkPolicyLocalTTL {
+		return cached, nil
+	}
+
+	snapshot, err := s.load(ctx)
+	if err != nil {
+		if cached != nil {
+			logger.Warn("Error al cargar las reglas de red, se usa la copia anterior: %v", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	networkCache.mu.Lock()
+	networkCache.snapshot = snapshot
+	networkCache.loadedAt = time.Now()
+	networkCache.mu.Unlock()
+
+	return snapshot, nil
+}
+
+// load lee reglas y excepciones vigentes desde la base de datos
+func (s *NetworkPolicyService) load(ctx context.Context) (*networkSnapshot, error) {
+	var rules []models.NetworkRule
+	if err := s.db.WithContext(ctx).Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener reglas de red: %w", err)
+	}
+
+	var bypasses []models.IPAllowlistBypass
+	err := s.db.WithContext(ctx).
+		Where("revoked_at IS NULL AND expires_at > ?", time.Now()).
+		Order("expires_at DESC").
+		Find(&bypasses).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener excepciones de red: %w", err)
+	}
+
+	return buildNetworkSnapshot(rules, bypasses), nil
+}
+
+// buildNetworkSnapshot agrupa los rangos por rol y por unidad
+func buildNetworkSnapshot(rules []models.NetworkRule, bypasses []models.IPAllowlistBypass) *networkSnapshot {
+	snapshot := &networkSnapshot{
+		rules:    rules,
+		roles:    make(map[string][]netip.Prefix),
+		units:    make(map[int][]netip.Prefix),
+		bypasses: bypasses,
+	}
+
+	for _, rule := range rules {
+		prefix, err := parseAllowlistEntry(rule.CIDR)
+		if err != nil {
+			logger.Warn("Regla de red %d con rango inválido %q: %v", rule.ID, rule.CIDR, err)
+			continue
+		}
+		switch {
+		case rule.Scope == models.NetworkRuleScopeRole && rule.Role != nil:
+			snapshot.roles[*rule.Role] = append(snapshot.roles[*rule.Role], prefix)
+		case rule.Scope == models.NetworkRuleScopeUnit && rule.OrganizationalUnitID != nil:
+			snapshot.units[*rule.OrganizationalUnitID] = append(snapshot.units[*rule.OrganizationalUnitID], prefix)
+		}
+	}
+
+	return snapshot
+}
+
+// InvalidateNetworkCache descarta las reglas en memoria del proceso
+func InvalidateNetworkCache() {
+	networkCache.mu.Lock()
+	networkCache.snapshot = nil
+	networkCache.mu.Unlock()
+}
+
+// ========================================
+// ADMINISTRACIÓN DE REGLAS
+// ========================================
+
+// ListRules retorna las reglas de red ordenadas por alcance
+func (s *NetworkPolicyService) ListRules(ctx context.Context) ([]models.NetworkRule, error) {
+	var rules []models.NetworkRule
+	err := s.db.WithContext(ctx).
+		Preload("OrganizationalUnit").
+		Order("scope, role, organizational_unit_id, id").
+		Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reglas de red: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateRule agrega un rango a un rol o a una unidad. Se rechaza si dejaría al
+// administrador sin acceso desde su IP actual.
+func (s *NetworkPolicyService) CreateRule(ctx context.Context, req *models.NetworkRuleRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.NetworkRule, error) {
+	prefix, err := parseAllowlistEntry(req.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("rango IP inválido")
+	}
+
+	rule := &models.NetworkRule{
+		Scope:       req.Scope,
+		CIDR:        prefix.String(),
+		Description: strings.TrimSpace(req.Description),
+		CreatedBy:   &admin.ID,
+	}
+
+	switch req.Scope {
+	case models.NetworkRuleScopeRole:
+		if req.Role == "" || req.OrganizationalUnitID != 0 {
+			return nil, fmt.Errorf("una regla de rol debe indicar solo el rol")
+		}
+		role := req.Role
+		rule.Role = &role
+	case models.NetworkRuleScopeUnit:
+		if req.OrganizationalUnitID == 0 || req.Role != "" {
+			return nil, fmt.Errorf("una regla de unidad debe indicar solo la unidad organizacional")
+		}
+		var unit models.OrganizationalUnit
+		if err := s.db.WithContext(ctx).First(&unit, req.OrganizationalUnitID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("unidad organizacional no encontrada")
+			}
+			return nil, fmt.Errorf("error al buscar unidad organizacional: %w", err)
+		}
+		unitID := req.OrganizationalUnitID
+		rule.OrganizationalUnitID = &unitID
+	}
+
+	current, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range current.rules {
+		if sameRuleTarget(&existing, rule) && existing.CIDR == rule.CIDR {
+			return nil, fmt.Errorf("el rango ya está registrado para ese alcance")
+		}
+	}
+
+	candidate := buildNetworkSnapshot(append(append([]models.NetworkRule(nil), current.rules...), *rule), nil)
+	if err := s.checkSelfLockout(ctx, candidate, admin, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("error al guardar regla de red: %w", err)
+	}
+
+	InvalidateNetworkCache()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionCreate,
+		Resource:   "ip_allowlist_rule",
+		ResourceID: fmt.Sprintf("%d", rule.ID),
+		NewValues:  networkRuleToMap(rule),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🌐 Regla de red %s agregada por %s", rule.CIDR, admin.Email)
+
+	return rule, nil
+}
+
+// DeleteRule elimina un rango. Se rechaza si dejaría al administrador sin acceso.
+func (s *NetworkPolicyService) DeleteRule(ctx context.Context, ruleID int, admin *models.UserProfile, ipAddress, userAgent string) error {
+	var rule models.NetworkRule
+	if err := s.db.WithContext(ctx).First(&rule, ruleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("regla de red no encontrada")
+		}
+		return fmt.Errorf("error al buscar regla de red: %w", err)
+	}
+
+	current, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	remaining := make([]models.NetworkRule, 0, len(current.rules))
+	for _, existing := range current.rules {
+		if existing.ID != rule.ID {
+			remaining = append(remaining, existing)
+		}
+	}
+	if err := s.checkSelfLockout(ctx, buildNetworkSnapshot(remaining, nil), admin, ipAddress); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&rule).Error; err != nil {
+		return fmt.Errorf("error al eliminar regla de red: %w", err)
+	}
+
+	InvalidateNetworkCache()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionDelete,
+		Resource:   "ip_allowlist_rule",
+		ResourceID: fmt.Sprintf("%d", rule.ID),
+		OldValues:  networkRuleToMap(&rule),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🌐 Regla de red %s eliminada por %s", rule.CIDR, admin.Email)
+
+	return nil
+}
+
+// Check evalúa las reglas para un usuario y una IP, para probarlas antes de aplicarlas
+func (s *NetworkPolicyService) Check(ctx context.Context, req *models.NetworkCheckRequest) (*models.NetworkDecision, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("email = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	return s.Evaluate(ctx, &NetworkSubject{
+		UserID:               user.ID,
+		Email:                user.Email,
+		Role:                 user.Role,
+		OrganizationalUnitID: user.OrganizationalUnitID,
+	}, req.IPAddress)
+}
+
+// checkSelfLockout verifica que el administrador conserve el acceso con las reglas propuestas
+func (s *NetworkPolicyService) checkSelfLockout(ctx context.Context, candidate *networkSnapshot, admin *models.UserProfile, ipAddress string) error {
+	whitelist := parseAllowlist(s.policyService.Current(ctx).IPWhitelist)
+	decision := evaluateNetwork(candidate, whitelist, NetworkSubjectFromProfile(admin), ipAddress)
+	if !decision.Allowed {
+		return fmt.Errorf("el cambio le impediría acceder desde su IP actual (%s)", ipAddress)
+	}
+	return nil
+}
+
+// ========================================
+// EXCEPCIONES DE EMERGENCIA
+// ========================================
+
+// ListBypasses retorna las excepciones, solo las vigentes salvo que se pida el historial
+func (s *NetworkPolicyService) ListBypasses(ctx context.Context, includeInactive bool) ([]models.IPAllowlistBypass, error) {
+	query := s.db.WithContext(ctx).Preload("User")
+	if !includeInactive {
+		query = query.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	var bypasses []models.IPAllowlistBypass
+	if err := query.Order("created_at DESC").Limit(200).Find(&bypasses).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener excepciones de red: %w", err)
+	}
+	return bypasses, nil
+}
+
+// CreateBypass habilita temporalmente el acceso fuera de los rangos para un usuario o para todos
+func (s *NetworkPolicyService) CreateBypass(ctx context.Context, req *models.IPAllowlistBypassRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.IPAllowlistBypass, error) {
+	bypass := &models.IPAllowlistBypass{
+		Reason:    strings.TrimSpace(req.Reason),
+		ExpiresAt: time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute),
+		CreatedBy: admin.ID,
+	}
+
+	target := "todos los usuarios"
+	if req.UserID != "" {
+		var user models.User
+		err := s.db.WithContext(ctx).Where("id = ?", req.UserID).First(&user).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("usuario no encontrado")
+			}
+			return nil, fmt.Errorf("error al buscar usuario: %w", err)
+		}
+		bypass.UserID = &user.ID
+		target = user.Email
+	}
+
+	if err := s.db.WithContext(ctx).Create(bypass).Error; err != nil {
+		return nil, fmt.Errorf("error al guardar excepción de red: %w", err)
+	}
+
+	InvalidateNetworkCache()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionIPBypassCreated,
+		Resource:   "ip_allowlist_bypass",
+		ResourceID: fmt.Sprintf("%d", bypass.ID),
+		NewValues: map[string]interface{}{
+			"userId":    bypass.UserID,
+			"target":    target,
+			"reason":    bypass.Reason,
+			"expiresAt": bypass.ExpiresAt,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Warn("🚨 Excepción de red %d para %s creada por %s hasta %s: %s",
+		bypass.ID, target, admin.Email, bypass.ExpiresAt.Format(time.RFC3339), bypass.Reason)
+
+	return bypass, nil
+}
+
+// RevokeBypass termina una excepción antes de su vencimiento
+func (s *NetworkPolicyService) RevokeBypass(ctx context.Context, bypassID int, admin *models.UserProfile, ipAddress, userAgent string) error {
+	var bypass models.IPAllowlistBypass
+	if err := s.db.WithContext(ctx).First(&bypass, bypassID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("excepción de red no encontrada")
+		}
+		return fmt.Errorf("error al buscar excepción de red: %w", err)
+	}
+	if !bypass.IsActive() {
+		return fmt.Errorf("la excepción ya no está vigente")
+	}
+
+	now := time.Now()
+	err := s.db.WithContext(ctx).Model(&bypass).Updates(map[string]interface{}{
+		"revoked_at": now,
+		"revoked_by": admin.ID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("error al revocar excepción de red: %w", err)
+	}
+
+	InvalidateNetworkCache()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionIPBypassRevoked,
+		Resource:   "ip_allowlist_bypass",
+		ResourceID: fmt.Sprintf("%d", bypass.ID),
+		OldValues: map[string]interface{}{
+			"userId":    bypass.UserID,
+			"expiresAt": bypass.ExpiresAt,
+			"useCount":  bypass.UseCount,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("🌐 Excepción de red %d revocada por %s", bypass.ID, admin.Email)
+
+	return nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// parseAllowlistEntry interpreta una IP o un rango CIDR; una IP sola equivale a /32 o /128
+func parseAllowlistEntry(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseAllowlist interpreta la lista global de la política, descartando entradas inválidas
+func parseAllowlist(entries []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseAllowlistEntry(entry)
+		if err != nil {
+			logger.Warn("Entrada inválida en la lista de IPs permitidas %q: %v", entry, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// containsAddr indica si la IP está en alguno de los rangos
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRuleTarget indica si dos reglas aplican al mismo rol o unidad
+func sameRuleTarget(a, b *models.NetworkRule) bool {
+	if a.Scope != b.Scope {
+		return false
+	}
+	if a.Scope == models.NetworkRuleScopeRole {
+		return a.Role != nil && b.Role != nil && *a.Role == *b.Role
+	}
+	return a.OrganizationalUnitID != nil && b.OrganizationalUnitID != nil && *a.OrganizationalUnitID == *b.OrganizationalUnitID
+}
+
+// networkRuleToMap convierte la regla al formato de valores de auditoría
+func networkRuleToMap(rule *models.NetworkRule) map[string]interface{} {
+	return map[string]interface{}{
+		"scope":                rule.Scope,
+		"role":                 rule.Role,
+		"organizationalUnitId": rule.OrganizationalUnitID,
+		"cidr":                 rule.CIDR,
+		"description":          rule.Description,
+	}
+}
@@ -4,8 +4,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
+	"gamc-backend-go/internal/config"
 	"gamc-backend-go/internal/database/models"
 	"gamc-backend-go/internal/repositories"
 	"gamc-backend-go/pkg/logger"
@@ -16,17 +18,19 @@ import (
 
 // NotificationService maneja la lógica de negocio para notificaciones
 type NotificationService struct {
-	notifyRepo *repositories.NotificationRepository
-	userRepo   *repositories.UserRepository
-	db         *gorm.DB
+	notifyRepo  *repositories.NotificationRepository
+	userRepo    *repositories.UserRepository
+	mailService *MailService
+	db          *gorm.DB
 }
 
 // NewNotificationService crea una nueva instancia del servicio
-func NewNotificationService(db *gorm.DB) *NotificationService {
+func NewNotificationService(appCtx *config.AppContext) *NotificationService {
 	return &NotificationService{
-		notifyRepo: repositories.NewNotificationRepository(db),
-		userRepo:   repositories.NewUserRepository(db),
-		db:         db,
+		notifyRepo:  repositories.NewNotificationRepository(appCtx.DB),
+		userRepo:    repositories.NewUserRepository(appCtx.DB),
+		mailService: NewMailService(appCtx),
+		db:          appCtx.DB,
 	}
 }
 
@@ -415,6 +419,66 @@ func (s *NotificationService) CreateAlertNotification(ctx context.Context, userI
 		Priority: models.NotificationPriorityHigh,
 	}
 
+	if _, err := s.CreateNotification(ctx, req); err != nil {
+		return err
+	}
+
+	// Las alertas también se envían por correo: el usuario puede no tener una sesión abierta
+	err := s.mailService.SendToUser(ctx, userID, models.EmailTemplateSecurityAlert, map[string]interface{}{
+		"Title":       title,
+		"Content":     content,
+		"SecurityURL": s.mailService.Link("/profile/security"),
+		"OccurredAt":  time.Now().Format("02/01/2006 15:04"),
+	})
+	if err != nil {
+		logger.Warn("Error al encolar correo de alerta para %s: %v", userID, err)
+	}
+	return nil
+}
+
+// CreatePasswordExpiryNotification avisa que la contraseña expirará pronto
+func (s *NotificationService) CreatePasswordExpiryNotification(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error {
+	days := int(math.Ceil(time.Until(expiresAt).Hours() / 24))
+	req := &CreateNotificationRequest{
+		UserID:    userID,
+		Type:      models.NotificationTypeSecurity,
+		Title:     "Su contraseña expirará pronto",
+		Content:   fmt.Sprintf("Su contraseña expira en %d días (%s). Cámbiela antes de esa fecha para evitar restricciones al iniciar sesión.", days, expiresAt.Format("02/01/2006")),
+		Priority:  models.NotificationPriorityHigh,
+		ActionURL: "/profile/change-password",
+		Metadata: map[string]interface{}{
+			"expires_at": expiresAt,
+		},
+	}
+
 	_, err := s.CreateNotification(ctx, req)
 	return err
 }
+
+// CreateSecurityQuestionRetiredNotifications pide reconfigurar las preguntas de seguridad a los
+// usuarios que respondían una pregunta retirada del catálogo
+func (s *NotificationService) CreateSecurityQuestionRetiredNotifications(ctx context.Context, userIDs []uuid.UUID, question *models.SecurityQuestion) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	content := fmt.Sprintf("La pregunta de seguridad \"%s\" ya no está disponible y su respuesta dejó de usarse para recuperar la contraseña. Elija otra pregunta en su perfil de seguridad.", question.QuestionText)
+	notifications := make([]*models.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, &models.Notification{
+			UserID:          userID,
+			Type:            models.NotificationTypeSecurity,
+			Title:           "Reconfigure sus preguntas de seguridad",
+			Content:         content,
+			Priority:        models.NotificationPriorityHigh,
+			RelatedEntity:   "security_questions",
+			RelatedEntityID: fmt.Sprintf("%d", question.ID),
+			ActionURL:       "/profile/security",
+		})
+	}
+
+	if err := s.notifyRepo.CreateBatch(ctx, notifications); err != nil {
+		return fmt.Errorf("error al crear notificaciones de reconfiguración: %w", err)
+	}
+	return nil
+}
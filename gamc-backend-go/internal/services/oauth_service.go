@@ -0,0 +1,883 @@
+// internal/services/oauth_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Parámetros del flujo authorization code
+const (
+	OAuthCodeTTL = 2 * time.Minute
+)
+
+// Prefijo del cache de tokens inactivos en la introspección
+const introspectionInactivePrefix = "introspect:inactive:"
+
+// OAuthError error con código estándar RFC 6749 (invalid_request, invalid_grant, ...)
+type OAuthError struct {
+	Code        string
+	Description string
+	Status      int
+}
+
+// Error implementa la interfaz error
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+// newOAuthError crea un error OAuth con estado HTTP 400
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description, Status: 400}
+}
+
+// OAuthService implementa el proveedor OAuth2 / OpenID Connect
+type OAuthService struct {
+	db             *gorm.DB
+	codeManager    *redis.OAuthCodeManager
+	sessionManager *redis.SessionManager
+	sessionService *SessionService
+	jwtService     *auth.JWTService
+	blacklist      *redis.JWTBlacklistManager
+	cacheManager   *redis.CacheManager
+	auditService   *AuditService
+	networkPolicy  *NetworkPolicyService
+	config         *config.Config
+}
+
+// NewOAuthService crea una nueva instancia del servicio OAuth
+func NewOAuthService(appCtx *config.AppContext) *OAuthService {
+	return &OAuthService{
+		db:             appCtx.DB,
+		codeManager:    redis.NewOAuthCodeManager(appCtx.Redis),
+		sessionManager: redis.NewSessionManager(appCtx.Redis),
+		sessionService: NewSessionService(appCtx),
+		jwtService:     auth.NewJWTService(appCtx.Config),
+		blacklist:      redis.NewJWTBlacklistManager(appCtx.Redis),
+		cacheManager:   redis.NewCacheManager(appCtx.Redis),
+		auditService:   NewAuditService(appCtx.DB),
+		networkPolicy:  NewNetworkPolicyService(appCtx),
+		config:         appCtx.Config,
+	}
+}
+
+// ========================================
+// ADMINISTRACIÓN DE CLIENTES
+// ========================================
+
+// ListClients lista los clientes registrados
+func (s *OAuthService) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	if err := s.db.WithContext(ctx).Order("name").Find(&clients).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener clientes: %w", err)
+	}
+	return clients, nil
+}
+
+// CreateClient registra un cliente. El secreto solo se retorna en esta respuesta.
+func (s *OAuthService) CreateClient(ctx context.Context, req *models.OAuthClientCreateRequest, adminID uuid.UUID) (*models.OAuthClientSecretResponse, error) {
+	scopes := req.AllowedScopes
+	if len(scopes) == 0 {
+		scopes = []string{models.OAuthScopeOpenID, models.OAuthScopeProfile, models.OAuthScopeEmail}
+	}
+
+	confidential := true
+	if req.IsConfidential != nil {
+		confidential = *req.IsConfidential
+	}
+
+	clientID, err := randomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar client_id: %w", err)
+	}
+
+	client := &models.OAuthClient{
+		ClientID:       clientID,
+		Name:           req.Name,
+		Description:    req.Description,
+		RedirectURIs:   req.RedirectURIs,
+		AllowedScopes:  scopes,
+		IsConfidential: confidential,
+		IsActive:       true,
+		CreatedBy:      &adminID,
+	}
+
+	var secret string
+	if confidential {
+		secret, err = randomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("error al generar client_secret: %w", err)
+		}
+		hash := hashClientSecret(secret)
+		client.ClientSecretHash = &hash
+	}
+
+	if err := s.db.WithContext(ctx).Create(client).Error; err != nil {
+		return nil, fmt.Errorf("error al registrar cliente: %w", err)
+	}
+
+	logger.Info("🔑 Cliente OAuth %s (%s) registrado por %s", client.Name, client.ClientID, adminID)
+
+	return &models.OAuthClientSecretResponse{Client: client, ClientSecret: secret}, nil
+}
+
+// UpdateClient actualiza los datos de un cliente
+func (s *OAuthService) UpdateClient(ctx context.Context, clientID string, req *models.OAuthClientUpdateRequest) (*models.OAuthClient, error) {
+	client, err := s.getClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		client.Name = *req.Name
+	}
+	if req.Description != nil {
+		client.Description = *req.Description
+	}
+	if len(req.RedirectURIs) > 0 {
+		client.RedirectURIs = req.RedirectURIs
+	}
+	if len(req.AllowedScopes) > 0 {
+		client.AllowedScopes = req.AllowedScopes
+	}
+	if req.IsActive != nil {
+		client.IsActive = *req.IsActive
+	}
+
+	if err := s.db.WithContext(ctx).Save(client).Error; err != nil {
+		return nil, fmt.Errorf("error al actualizar cliente: %w", err)
+	}
+
+	return client, nil
+}
+
+// RotateClientSecret genera un nuevo secreto; el anterior deja de ser válido de inmediato
+func (s *OAuthService) RotateClientSecret(ctx context.Context, clientID string) (*models.OAuthClientSecretResponse, error) {
+	client, err := s.getClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsConfidential {
+		return nil, fmt.Errorf("los clientes públicos no tienen secreto")
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar client_secret: %w", err)
+	}
+	hash := hashClientSecret(secret)
+	client.ClientSecretHash = &hash
+
+	if err := s.db.WithContext(ctx).Save(client).Error; err != nil {
+		return nil, fmt.Errorf("error al rotar secreto: %w", err)
+	}
+
+	logger.Info("🔑 Secreto del cliente OAuth %s rotado", client.ClientID)
+
+	return &models.OAuthClientSecretResponse{Client: client, ClientSecret: secret}, nil
+}
+
+// DeleteClient elimina un cliente y sus consentimientos
+func (s *OAuthService) DeleteClient(ctx context.Context, clientID string) error {
+	result := s.db.WithContext(ctx).Where("client_id = ?", clientID).Delete(&models.OAuthClient{})
+	if result.Error != nil {
+		return fmt.Errorf("error al eliminar cliente: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("cliente no encontrado")
+	}
+	return nil
+}
+
+// ========================================
+// AUTORIZACIÓN Y CONSENTIMIENTO
+// ========================================
+
+// ValidateAuthorization valida la solicitud de autorización y retorna los datos
+// para la pantalla de consentimiento
+func (s *OAuthService) ValidateAuthorization(ctx context.Context, userID string, req *models.OAuthAuthorizeRequest) (*models.OAuthAuthorizeResponse, error) {
+	client, scopes, err := s.validateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	consentRequired, err := s.consentRequired(ctx, userID, client.ClientID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthAuthorizeResponse{
+		Client: models.OAuthClientInfo{
+			ClientID:    client.ClientID,
+			Name:        client.Name,
+			Description: client.Description,
+		},
+		Scopes:          scopes,
+		ConsentRequired: consentRequired,
+	}, nil
+}
+
+// Authorize registra la decisión del usuario y retorna la URL de redirección al cliente
+// con el código de autorización (o access_denied)
+func (s *OAuthService) Authorize(ctx context.Context, userID, sessionID string, decision *models.OAuthConsentDecision) (*models.OAuthRedirectResponse, error) {
+	req := &decision.OAuthAuthorizeRequest
+
+	client, scopes, err := s.validateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !decision.Approve {
+		return &models.OAuthRedirectResponse{
+			RedirectTo: buildRedirect(req.RedirectURI, map[string]string{
+				"error":             "access_denied",
+				"error_description": "el usuario denegó el acceso",
+				"state":             req.State,
+			}),
+		}, nil
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ID de usuario inválido")
+	}
+
+	if err := s.saveConsent(ctx, parsedUserID, client.ClientID, scopes); err != nil {
+		return nil, err
+	}
+
+	authTime := time.Now()
+	var ipAddress, userAgent string
+	if session, err := s.sessionManager.GetSession(ctx, sessionID); err == nil && session != nil {
+		authTime = session.CreatedAt
+		ipAddress = session.IPAddress
+		userAgent = session.UserAgent
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar código de autorización: %w", err)
+	}
+
+	err = s.codeManager.SaveCode(ctx, code, &redis.OAuthAuthorizationCode{
+		ClientID:      client.ClientID,
+		UserID:        userID,
+		SessionID:     sessionID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		AuthTime:      authTime,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+	}, OAuthCodeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error al guardar código de autorización: %w", err)
+	}
+
+	return &models.OAuthRedirectResponse{
+		RedirectTo: buildRedirect(req.RedirectURI, map[string]string{
+			"code":  code,
+			"state": req.State,
+		}),
+	}, nil
+}
+
+// ========================================
+// TOKEN ENDPOINT
+// ========================================
+
+// ExchangeCode canjea un código de autorización por access token e ID token.
+// Cada canje crea una sesión propia del cliente, visible y revocable por el usuario.
+func (s *OAuthService) ExchangeCode(ctx context.Context, req *models.OAuthTokenRequest, ipAddress, userAgent string) (*models.OAuthTokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return nil, newOAuthError("unsupported_grant_type", "solo se admite grant_type=authorization_code")
+	}
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		return nil, newOAuthError("invalid_request", "code, redirect_uri y code_verifier son requeridos")
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.codeManager.ConsumeCode(ctx, req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener código de autorización: %w", err)
+	}
+	if code == nil || code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return nil, newOAuthError("invalid_grant", "código de autorización inválido o expirado")
+	}
+
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, newOAuthError("invalid_grant", "code_verifier inválido")
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", code.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newOAuthError("invalid_grant", "usuario no encontrado o inactivo")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	// Las mismas verificaciones de cuenta y red que el login, con la red desde la que el
+	// usuario otorgó el consentimiento (el canje lo hace el servidor del cliente)
+	userIP, userUA := code.IPAddress, code.UserAgent
+	if userIP == "" {
+		userIP, userUA = ipAddress, userAgent
+	}
+	if err := checkLoginAllowed(ctx, s.networkPolicy, &user, userIP, userUA, "oauth:"+client.ClientID); err != nil {
+		if errors.Is(err, ErrAccountExpired) || errors.Is(err, ErrEmailNotVerified) ||
+			errors.Is(err, ErrPasswordResetRequired) || errors.Is(err, ErrIPNotAllowed) {
+			return nil, newOAuthError("invalid_grant", err.Error())
+		}
+		return nil, err
+	}
+
+	orgUnitID := 0
+	if user.OrganizationalUnitID != nil {
+		orgUnitID = *user.OrganizationalUnitID
+	}
+
+	// Sesión del cliente; vive lo mismo que su access token
+	sessionID := uuid.New().String()
+	now := time.Now()
+	sessionData := &redis.SessionData{
+		UserID:               user.ID.String(),
+		Email:                user.Email,
+		Role:                 user.Role,
+		OrganizationalUnitID: orgUnitID,
+		SessionID:            sessionID,
+		CreatedAt:            now,
+		LastActivity:         now,
+		IPAddress:            ipAddress,
+		UserAgent:            userAgent,
+		ClientID:             client.ClientID,
+		Scopes:               code.Scopes,
+	}
+	if err := s.sessionManager.SaveSession(ctx, sessionID, sessionData, s.config.JWTExpiresIn); err != nil {
+		return nil, fmt.Errorf("error al guardar sesión: %w", err)
+	}
+
+	accessToken, err := s.jwtService.GenerateClientAccessToken(user.ID.String(), user.Email, user.Role, orgUnitID, sessionID, client.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar access token: %w", err)
+	}
+	if err := s.sessionService.TrackAccessToken(ctx, sessionID, accessToken); err != nil {
+		logger.Warn("Error al registrar access token de la sesión %s: %v", sessionID, err)
+	}
+
+	result := &models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.JWTExpiresIn.Seconds()),
+		Scope:       strings.Join(code.Scopes, " "),
+	}
+
+	if containsScope(code.Scopes, models.OAuthScopeOpenID) {
+		idToken, err := s.jwtService.GenerateIDToken(s.buildIDTokenClaims(&user, client.ClientID, code, orgUnitID), req.ClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("error al generar ID token: %w", err)
+		}
+		result.IDToken = idToken
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &user.ID,
+		Action:     models.AuditActionLogin,
+		Resource:   "oauth",
+		ResourceID: client.ClientID,
+		NewValues: map[string]interface{}{
+			"client": client.Name,
+			"scopes": code.Scopes,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: sessionID,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("✅ Token OAuth emitido para %s al cliente %s", user.Email, client.ClientID)
+
+	return result, nil
+}
+
+// ========================================
+// USERINFO Y DISCOVERY
+// ========================================
+
+// UserInfo retorna los claims del usuario según los scopes de la sesión.
+// Las sesiones del propio frontend (sin cliente OAuth) tienen acceso a todos los claims.
+func (s *OAuthService) UserInfo(ctx context.Context, userID, sessionID string) (map[string]interface{}, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("usuario no encontrado")
+	}
+
+	session, err := s.sessionManager.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener sesión: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("sesión expirada")
+	}
+
+	scopes := session.Scopes
+	if session.ClientID == "" {
+		scopes = []string{models.OAuthScopeOpenID, models.OAuthScopeProfile, models.OAuthScopeEmail}
+	}
+	if !containsScope(scopes, models.OAuthScopeOpenID) {
+		return nil, fmt.Errorf("el token no incluye el scope openid")
+	}
+
+	claims := map[string]interface{}{
+		"sub":                  user.ID.String(),
+		"role":                 user.Role,
+		"organizationalUnitId": session.OrganizationalUnitID,
+	}
+	if containsScope(scopes, models.OAuthScopeProfile) {
+		claims["name"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims["given_name"] = user.FirstName
+		claims["family_name"] = user.LastName
+		claims["preferred_username"] = user.Username
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if containsScope(scopes, models.OAuthScopeEmail) {
+		claims["email"] = user.Email
+	}
+
+	return claims, nil
+}
+
+// Discovery genera el documento de descubrimiento OpenID Connect
+func (s *OAuthService) Discovery() map[string]interface{} {
+	issuer := strings.TrimRight(s.config.OIDCIssuer, "/")
+	apiBase := issuer + s.config.APIPrefix
+
+	return map[string]interface{}{
+		"issuer":                                        issuer,
+		"authorization_endpoint":                        s.config.OIDCAuthorizeURL,
+		"token_endpoint":                                apiBase + "/oauth/token",
+		"userinfo_endpoint":                             apiBase + "/oauth/userinfo",
+		"jwks_uri":                                      issuer + "/.well-known/jwks.json",
+		"response_types_supported":                      []string{"code"},
+		"grant_types_supported":                         []string{"authorization_code"},
+		"subject_types_supported":                       []string{"public"},
+		"id_token_signing_alg_values_supported":         []string{s.jwtService.SigningAlgorithm()},
+		"scopes_supported":                              []string{models.OAuthScopeOpenID, models.OAuthScopeProfile, models.OAuthScopeEmail},
+		"token_endpoint_auth_methods_supported":         []string{"client_secret_basic", "client_secret_post", "none"},
+		"introspection_endpoint":                        apiBase + "/auth/introspect",
+		"introspection_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+		"code_challenge_methods_supported":              []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "given_name", "family_name", "preferred_username", "email",
+			"role", "organizationalUnitId",
+		},
+	}
+}
+
+// ========================================
+// INTROSPECCIÓN (RFC 7662)
+// ========================================
+
+// Introspect informa a un cliente confidencial si un access token sigue vigente y a quién
+// pertenece. Los tokens inactivos se recuerdan brevemente para aliviar a los servicios que
+// reintentan con el mismo token; los activos nunca se cachean para que una revocación se
+// note de inmediato.
+func (s *OAuthService) Introspect(ctx context.Context, req *models.OAuthIntrospectionRequest) (*models.OAuthIntrospectionResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsConfidential {
+		return nil, &OAuthError{Code: "invalid_client", Description: "solo los clientes confidenciales pueden introspeccionar tokens", Status: 401}
+	}
+	if req.Token == "" {
+		return nil, newOAuthError("invalid_request", "token es requerido")
+	}
+
+	tokenHash := sha256.Sum256([]byte(req.Token))
+	cacheKey := introspectionInactivePrefix + hex.EncodeToString(tokenHash[:])
+
+	var cachedInactive bool
+	if err := s.cacheManager.Get(ctx, cacheKey, &cachedInactive); err != nil {
+		logger.Warn("⚠️ Error al leer cache de introspección: %v", err)
+	}
+	if cachedInactive {
+		return &models.OAuthIntrospectionResponse{Active: false}, nil
+	}
+
+	result, expiresAt, err := s.introspectAccessToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Active {
+		ttl := s.config.IntrospectionNegativeCacheTTL
+		if !expiresAt.IsZero() {
+			if remaining := time.Until(expiresAt); remaining < ttl {
+				ttl = remaining
+			}
+		}
+		if ttl > 0 {
+			if err := s.cacheManager.Set(ctx, cacheKey, true, ttl); err != nil {
+				logger.Warn("⚠️ Error al cachear token inactivo: %v", err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// introspectAccessToken evalúa el token contra la firma, la lista negra, la sesión y el usuario.
+// Retorna error solo ante fallas de infraestructura, que no deben cachearse como token inactivo.
+// expiresAt es cero cuando el token ni siquiera es un access token válido.
+func (s *OAuthService) introspectAccessToken(ctx context.Context, token string) (*models.OAuthIntrospectionResponse, time.Time, error) {
+	inactive := &models.OAuthIntrospectionResponse{Active: false}
+
+	claims, err := s.jwtService.ParseAccessToken(token)
+	if err != nil {
+		return inactive, time.Time{}, nil
+	}
+	if claims.ExpiresAt == nil {
+		return inactive, time.Time{}, nil
+	}
+	expiresAt := claims.ExpiresAt.Time
+
+	// Los tokens restringidos solo sirven para cambiar la contraseña en este backend
+	if claims.Restriction != "" {
+		return inactive, expiresAt, nil
+	}
+
+	if claims.JTI != "" {
+		blacklisted, err := s.blacklist.IsTokenBlacklisted(ctx, claims.JTI)
+		if err != nil {
+			return nil, expiresAt, fmt.Errorf("error al verificar token: %w", err)
+		}
+		if blacklisted {
+			return inactive, expiresAt, nil
+		}
+	}
+
+	session, err := s.sessionManager.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return nil, expiresAt, fmt.Errorf("error al obtener sesión: %w", err)
+	}
+	if session == nil || session.UserID != claims.UserID || session.ClientID != claims.ClientID {
+		return inactive, expiresAt, nil
+	}
+	// El barrido de sesiones se encarga de cerrarla; aquí solo se informa
+	if IsInteractiveSession(session) && s.sessionService.Expiry(ctx, session) != "" {
+		return inactive, expiresAt, nil
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", claims.UserID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return inactive, expiresAt, nil
+		}
+		return nil, expiresAt, fmt.Errorf("error al obtener usuario: %w", err)
+	}
+	if !user.IsActive || user.IsAccountExpired() {
+		return inactive, expiresAt, nil
+	}
+
+	scopes := session.Scopes
+	if session.ClientID == "" {
+		scopes = []string{models.OAuthScopeOpenID, models.OAuthScopeProfile, models.OAuthScopeEmail}
+	}
+
+	result := &models.OAuthIntrospectionResponse{
+		Active:               true,
+		Scope:                strings.Join(scopes, " "),
+		ClientID:             session.ClientID,
+		Username:             user.Email,
+		TokenType:            "Bearer",
+		Exp:                  expiresAt.Unix(),
+		Sub:                  claims.UserID,
+		Iss:                  claims.Issuer,
+		Jti:                  claims.JTI,
+		SessionID:            claims.SessionID,
+		Role:                 user.Role,
+		OrganizationalUnitID: session.OrganizationalUnitID,
+	}
+	if claims.IssuedAt != nil {
+		result.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.Actor != nil {
+		result.Act = &models.OAuthIntrospectionAct{Sub: claims.Actor.Subject, Email: claims.Actor.Email}
+	}
+
+	return result, expiresAt, nil
+}
+
+// ========================================
+// CONSENTIMIENTOS DEL USUARIO
+// ========================================
+
+// ListConsents lista las aplicaciones autorizadas por el usuario
+func (s *OAuthService) ListConsents(ctx context.Context, userID string) ([]models.OAuthConsent, error) {
+	var consents []models.OAuthConsent
+	err := s.db.WithContext(ctx).
+		Preload("Client").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Find(&consents).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener aplicaciones autorizadas: %w", err)
+	}
+	return consents, nil
+}
+
+// RevokeConsent retira el acceso de una aplicación y cierra sus sesiones
+func (s *OAuthService) RevokeConsent(ctx context.Context, userID, clientID string) error {
+	result := s.db.WithContext(ctx).
+		Where("user_id = ? AND client_id = ?", userID, clientID).
+		Delete(&models.OAuthConsent{})
+	if result.Error != nil {
+		return fmt.Errorf("error al revocar autorización: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("autorización no encontrada")
+	}
+
+	count, err := s.sessionService.RevokeClientSessions(ctx, userID, clientID)
+	if err != nil {
+		logger.Warn("Error al cerrar sesiones del cliente %s: %v", clientID, err)
+	}
+
+	logger.Info("🔒 Usuario %s revocó el acceso del cliente %s (%d sesiones cerradas)", userID, clientID, count)
+	return nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// getClient busca un cliente por client_id
+func (s *OAuthService) getClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := s.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("cliente no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar cliente: %w", err)
+	}
+	return &client, nil
+}
+
+// validateAuthorizeRequest valida cliente, redirect_uri, scopes y PKCE
+func (s *OAuthService) validateAuthorizeRequest(ctx context.Context, req *models.OAuthAuthorizeRequest) (*models.OAuthClient, []string, error) {
+	client, err := s.getClient(ctx, req.ClientID)
+	if err != nil || !client.IsActive {
+		return nil, nil, newOAuthError("invalid_client", "cliente no registrado o inactivo")
+	}
+
+	// Nunca redirigir a una URI no registrada
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, nil, newOAuthError("invalid_request", "redirect_uri no registrada para este cliente")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, nil, newOAuthError("unsupported_response_type", "solo se admite response_type=code")
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = []string{models.OAuthScopeOpenID}
+	}
+	for _, scope := range scopes {
+		if !client.AllowsScope(scope) {
+			return nil, nil, newOAuthError("invalid_scope", fmt.Sprintf("scope no permitido: %s", scope))
+		}
+	}
+
+	// PKCE obligatorio para todos los clientes
+	if req.CodeChallenge == "" {
+		return nil, nil, newOAuthError("invalid_request", "code_challenge es requerido (PKCE)")
+	}
+	if req.CodeChallengeMethod != "S256" {
+		return nil, nil, newOAuthError("invalid_request", "code_challenge_method debe ser S256")
+	}
+
+	return client, scopes, nil
+}
+
+// consentRequired verifica si el usuario ya otorgó todos los scopes solicitados
+func (s *OAuthService) consentRequired(ctx context.Context, userID, clientID string, scopes []string) (bool, error) {
+	var consent models.OAuthConsent
+	err := s.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).First(&consent).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error al consultar consentimiento: %w", err)
+	}
+
+	for _, scope := range scopes {
+		if !containsScope(consent.Scopes, scope) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// saveConsent crea o amplía el consentimiento del usuario
+func (s *OAuthService) saveConsent(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) error {
+	var consent models.OAuthConsent
+	err := s.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).First(&consent).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("error al consultar consentimiento: %w", err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		consent = models.OAuthConsent{UserID: userID, ClientID: clientID, Scopes: scopes}
+		if err := s.db.WithContext(ctx).Create(&consent).Error; err != nil {
+			return fmt.Errorf("error al guardar consentimiento: %w", err)
+		}
+		return nil
+	}
+
+	for _, scope := range scopes {
+		if !containsScope(consent.Scopes, scope) {
+			consent.Scopes = append(consent.Scopes, scope)
+		}
+	}
+	if err := s.db.WithContext(ctx).Save(&consent).Error; err != nil {
+		return fmt.Errorf("error al actualizar consentimiento: %w", err)
+	}
+	return nil
+}
+
+// authenticateClient autentica al cliente en el token endpoint.
+// Los clientes confidenciales deben presentar su secreto; los públicos dependen de PKCE.
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error) {
+	invalidClient := &OAuthError{Code: "invalid_client", Description: "autenticación de cliente fallida", Status: 401}
+
+	if clientID == "" {
+		return nil, invalidClient
+	}
+
+	client, err := s.getClient(ctx, clientID)
+	if err != nil || !client.IsActive {
+		return nil, invalidClient
+	}
+
+	if client.IsConfidential {
+		if clientSecret == "" || client.ClientSecretHash == nil {
+			return nil, invalidClient
+		}
+		hash := hashClientSecret(clientSecret)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(*client.ClientSecretHash)) != 1 {
+			return nil, invalidClient
+		}
+	}
+
+	return client, nil
+}
+
+// buildIDTokenClaims arma los claims del ID token según los scopes otorgados
+func (s *OAuthService) buildIDTokenClaims(user *models.User, clientID string, code *redis.OAuthAuthorizationCode, orgUnitID int) *auth.IDTokenClaims {
+	now := time.Now()
+
+	claims := &auth.IDTokenClaims{
+		Nonce:                code.Nonce,
+		AuthTime:             code.AuthTime.Unix(),
+		Role:                 user.Role,
+		OrganizationalUnitID: orgUnitID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    strings.TrimRight(s.config.OIDCIssuer, "/"),
+			Subject:   user.ID.String(),
+			Audience:  []string{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.JWTExpiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	if containsScope(code.Scopes, models.OAuthScopeProfile) {
+		claims.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims.GivenName = user.FirstName
+		claims.FamilyName = user.LastName
+		claims.PreferredUsername = user.Username
+	}
+	if containsScope(code.Scopes, models.OAuthScopeEmail) {
+		claims.Email = user.Email
+	}
+
+	return claims
+}
+
+// verifyPKCE compara BASE64URL(SHA256(code_verifier)) con el code_challenge (RFC 7636)
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	hash := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// buildRedirect agrega parámetros a la redirect_uri registrada
+func buildRedirect(redirectURI string, params map[string]string) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+// hashClientSecret calcula el hash del secreto del cliente (alta entropía, no requiere bcrypt)
+func hashClientSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// randomToken genera un token aleatorio en hexadecimal
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// containsScope verifica si el scope está en la lista
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
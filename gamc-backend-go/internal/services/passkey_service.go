@@ -0,0 +1,630 @@
+// internal/services/passkey_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+	"gamc-backend-go/pkg/webauthn"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// PasskeyCeremonyTTL vigencia del desafío entre las opciones y la respuesta del navegador
+	PasskeyCeremonyTTL = webauthn.DefaultTimeout
+	// MaxPasskeysPerUser passkeys vigentes permitidas por usuario
+	MaxPasskeysPerUser = 10
+)
+
+// errPasskeyNotRecognized respuesta única para passkeys desconocidas, revocadas o de otra cuenta
+var errPasskeyNotRecognized = errors.New("passkey no reconocida")
+
+// PasskeyAssertion resultado de verificar una passkey en el login
+type PasskeyAssertion struct {
+	UserID       string
+	CredentialID int
+	// Desafío 2FA del login con contraseña que completa esta passkey (vacío = login sin contraseña)
+	TwoFactorToken string
+}
+
+// PasskeyService maneja las ceremonias WebAuthn y las passkeys de los usuarios
+type PasskeyService struct {
+	db               *gorm.DB
+	relyingParty     *webauthn.RelyingParty
+	ceremonyManager  *redis.WebAuthnCeremonyManager
+	twoFactorService *TwoFactorService
+	auditService     *AuditService
+}
+
+// NewPasskeyService crea una nueva instancia del servicio de passkeys
+func NewPasskeyService(appCtx *config.AppContext) *PasskeyService {
+	return &PasskeyService{
+		db: appCtx.DB,
+		relyingParty: &webauthn.RelyingParty{
+			ID:      appCtx.Config.WebAuthnRPID,
+			Name:    appCtx.Config.WebAuthnRPName,
+			Origins: appCtx.Config.GetWebAuthnOrigins(),
+		},
+		ceremonyManager:  redis.NewWebAuthnCeremonyManager(appCtx.Redis),
+		twoFactorService: NewTwoFactorService(appCtx),
+		auditService:     NewAuditService(appCtx.DB),
+	}
+}
+
+// ========================================
+// REGISTRO
+// ========================================
+
+// BeginRegistration emite las opciones para crear una passkey en el dispositivo del usuario
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID string) (*models.PasskeyRegistrationOptions, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	credentials, err := s.ListCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) >= MaxPasskeysPerUser {
+		return nil, fmt.Errorf("alcanzó el máximo de %d passkeys, elimine una para registrar otra", MaxPasskeysPerUser)
+	}
+
+	// El autenticador rechaza crear otra passkey de la misma cuenta
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for i := range credentials {
+		exclude = append(exclude, credentials[i].Descriptor())
+	}
+
+	ceremonyID, challenge, err := s.startCeremony(ctx, &redis.WebAuthnCeremony{
+		Type:   redis.WebAuthnCeremonyRegistration,
+		UserID: user.ID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userEntity := webauthn.UserEntity{
+		ID:          webauthn.EncodeID(user.ID[:]),
+		Name:        user.Email,
+		DisplayName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+	}
+
+	return &models.PasskeyRegistrationOptions{
+		CeremonyID: ceremonyID,
+		PublicKey:  s.relyingParty.CreationOptions(userEntity, challenge, exclude),
+	}, nil
+}
+
+// FinishRegistration verifica la respuesta del navegador y guarda la passkey
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID string, req *models.PasskeyRegistrationRequest, ipAddress, userAgent string) (*models.WebAuthnCredential, error) {
+	ceremony, err := s.ceremonyManager.ConsumeCeremony(ctx, req.CeremonyID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener desafío: %w", err)
+	}
+	if ceremony == nil || ceremony.Type != redis.WebAuthnCeremonyRegistration || ceremony.UserID != userID {
+		return nil, fmt.Errorf("registro de passkey inválido o expirado")
+	}
+
+	verified, err := s.relyingParty.VerifyRegistration(&req.Credential, ceremony.Challenge, true)
+	if err != nil {
+		logger.Warn("🔑 Registro de passkey rechazado para usuario %s: %v", userID, err)
+		return nil, fmt.Errorf("passkey inválida: %s", passkeyErrorReason(err))
+	}
+
+	credentialID := webauthn.EncodeID(verified.ID)
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.WebAuthnCredential{}).Where("credential_id = ?", credentialID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("error al verificar passkey existente: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("la passkey ya está registrada")
+	}
+
+	credential := newPasskeyCredential(uuid.MustParse(userID), verified, req.Name, userAgent)
+	if err := s.db.WithContext(ctx).Create(credential).Error; err != nil {
+		return nil, fmt.Errorf("error al guardar passkey: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &credential.UserID,
+		Action:     models.AuditActionPasskeyRegistered,
+		Resource:   "webauthn_credential",
+		ResourceID: fmt.Sprintf("%d", credential.ID),
+		NewValues: map[string]interface{}{
+			"name":           credential.Name,
+			"algorithm":      credential.Algorithm,
+			"backupEligible": credential.BackupEligible,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("🔑 Passkey \"%s\" registrada para usuario %s", credential.Name, userID)
+	return credential, nil
+}
+
+// ========================================
+// LOGIN
+// ========================================
+
+// BeginLogin emite las opciones de autenticación. Sin email ni desafío 2FA se ofrecen las
+// passkeys residentes del navegador; un email desconocido recibe las mismas opciones
+// para no revelar qué cuentas existen.
+func (s *PasskeyService) BeginLogin(ctx context.Context, req *models.PasskeyLoginOptionsRequest, ipAddress string) (*models.PasskeyLoginOptions, error) {
+	ceremony := newLoginCeremony(ipAddress, "", "")
+
+	var allow []webauthn.CredentialDescriptor
+	switch {
+	case req.ChallengeToken != "":
+		// Segundo factor: la contraseña ya se verificó, basta con la presencia del usuario
+		challenge, err := s.twoFactorService.GetChallenge(ctx, req.ChallengeToken)
+		if err != nil {
+			return nil, err
+		}
+		userID, err := uuid.Parse(challenge.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("desafío 2FA inválido o expirado")
+		}
+		credentials, err := s.ListCredentials(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(credentials) == 0 {
+			return nil, fmt.Errorf("no tiene passkeys registradas")
+		}
+		for i := range credentials {
+			allow = append(allow, credentials[i].Descriptor())
+		}
+		ceremony = newLoginCeremony(ipAddress, challenge.UserID, req.ChallengeToken)
+
+	case req.Email != "":
+		var user models.User
+		err := s.db.WithContext(ctx).
+			Where("email = ? AND is_active = ? AND is_service_account = ?", req.Email, true, false).
+			First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("error al buscar usuario: %w", err)
+		}
+		if err == nil {
+			credentials, err := s.ListCredentials(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			for i := range credentials {
+				allow = append(allow, credentials[i].Descriptor())
+			}
+			if len(allow) > 0 {
+				ceremony.UserID = user.ID.String()
+			}
+		}
+	}
+
+	ceremonyID, challenge, err := s.startCeremony(ctx, ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyLoginOptions{
+		CeremonyID: ceremonyID,
+		PublicKey:  s.relyingParty.RequestOptions(challenge, allow, ceremony.UserVerification),
+	}, nil
+}
+
+// VerifyLogin verifica la firma de la passkey y actualiza su contador.
+// Las verificaciones de la cuenta (bloqueo, expiración, red) quedan a cargo de AuthService.
+func (s *PasskeyService) VerifyLogin(ctx context.Context, req *models.PasskeyLoginRequest, ipAddress, userAgent string) (*PasskeyAssertion, error) {
+	ceremony, err := s.ceremonyManager.ConsumeCeremony(ctx, req.CeremonyID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener desafío: %w", err)
+	}
+	if ceremony == nil || ceremony.Type != redis.WebAuthnCeremonyLogin {
+		return nil, fmt.Errorf("inicio de sesión con passkey inválido o expirado")
+	}
+
+	rawID, err := req.Credential.CredentialID()
+	if err != nil {
+		return nil, fmt.Errorf("passkey inválida: %s", passkeyErrorReason(err))
+	}
+
+	var credential models.WebAuthnCredential
+	err = s.db.WithContext(ctx).Where("credential_id = ?", webauthn.EncodeID(rawID)).First(&credential).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errPasskeyNotRecognized
+		}
+		return nil, fmt.Errorf("error al buscar passkey: %w", err)
+	}
+
+	result, err := verifyPasskeyAssertion(s.relyingParty, ceremony, &credential, &req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPasskeyNotRecognized):
+			return nil, err
+		case errors.Is(err, webauthn.ErrSignCountRegression):
+			s.reportSignCountRegression(ctx, &credential, ipAddress, userAgent)
+			return nil, errPasskeyNotRecognized
+		default:
+			logger.Warn("🔑 Passkey %d de usuario %s rechazada: %v", credential.ID, credential.UserID, err)
+			return nil, fmt.Errorf("passkey inválida: %s", passkeyErrorReason(err))
+		}
+	}
+
+	// Otra aserción concurrente con el mismo contador pudo ganar la actualización
+	err = updatePasskeyUse(s.db.WithContext(ctx), &credential, result, ipAddress, time.Now())
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountRegression) {
+			s.reportSignCountRegression(ctx, &credential, ipAddress, userAgent)
+			return nil, errPasskeyNotRecognized
+		}
+		return nil, err
+	}
+
+	return &PasskeyAssertion{
+		UserID:         credential.UserID.String(),
+		CredentialID:   credential.ID,
+		TwoFactorToken: ceremony.TwoFactorToken,
+	}, nil
+}
+
+// ========================================
+// PASSKEYS DEL USUARIO
+// ========================================
+
+// ListCredentials lista las passkeys vigentes del usuario
+func (s *PasskeyService) ListCredentials(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at").
+		Find(&credentials).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener passkeys: %w", err)
+	}
+	return credentials, nil
+}
+
+// RenameCredential cambia el nombre descriptivo de una passkey propia
+func (s *PasskeyService) RenameCredential(ctx context.Context, userID uuid.UUID, credentialID int, name string) (*models.WebAuthnCredential, error) {
+	credential, err := s.getActiveCredential(ctx, userID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential.Name = strings.TrimSpace(name)
+	if err := s.db.WithContext(ctx).Model(credential).Update("name", credential.Name).Error; err != nil {
+		return nil, fmt.Errorf("error al renombrar passkey: %w", err)
+	}
+	return credential, nil
+}
+
+// RemoveCredential revoca una passkey propia. No se permite quitar la última si el rol
+// exige 2FA y el usuario no tiene TOTP activado.
+func (s *PasskeyService) RemoveCredential(ctx context.Context, user *models.UserProfile, credentialID int, ipAddress, userAgent string) error {
+	credential, err := s.getActiveCredential(ctx, user.ID, credentialID)
+	if err != nil {
+		return err
+	}
+
+	requiredForRole := s.twoFactorService.IsRequiredForRole(ctx, user.Role)
+	totpEnabled := false
+	var remaining int64
+	if requiredForRole {
+		totpEnabled, err = s.twoFactorService.IsEnabled(ctx, user.ID.String())
+		if err != nil {
+			return fmt.Errorf("error al verificar 2FA: %w", err)
+		}
+		err = s.db.WithContext(ctx).Model(&models.WebAuthnCredential{}).
+			Where("user_id = ? AND revoked_at IS NULL AND id <> ?", user.ID, credential.ID).
+			Count(&remaining).Error
+		if err != nil {
+			return fmt.Errorf("error al contar passkeys: %w", err)
+		}
+	}
+	if err := checkPasskeyRemoval(requiredForRole, totpEnabled, remaining); err != nil {
+		return err
+	}
+
+	return s.revoke(ctx, []models.WebAuthnCredential{*credential}, user, "removed_by_user", ipAddress, userAgent)
+}
+
+// ========================================
+// ADMINISTRACIÓN
+// ========================================
+
+// AdminListCredentials lista las passkeys de un usuario, incluidas las revocadas si se pide
+func (s *PasskeyService) AdminListCredentials(ctx context.Context, userID uuid.UUID, includeRevoked bool) ([]models.WebAuthnCredential, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+
+	var credentials []models.WebAuthnCredential
+	if err := query.Order("created_at").Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener passkeys: %w", err)
+	}
+	return credentials, nil
+}
+
+// RevokeCredential revoca una passkey de un usuario (dispositivo perdido o robado)
+func (s *PasskeyService) RevokeCredential(ctx context.Context, userID uuid.UUID, credentialID int, admin *models.UserProfile, ipAddress, userAgent string) error {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+
+	credential, err := s.getActiveCredential(ctx, userID, credentialID)
+	if err != nil {
+		return err
+	}
+
+	return s.revoke(ctx, []models.WebAuthnCredential{*credential}, admin, "revoked_by_admin", ipAddress, userAgent)
+}
+
+// RevokeAllCredentials revoca todas las passkeys vigentes de un usuario y retorna cuántas
+func (s *PasskeyService) RevokeAllCredentials(ctx context.Context, userID uuid.UUID, admin *models.UserProfile, ipAddress, userAgent string) (int, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	credentials, err := s.ListCredentials(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(credentials) == 0 {
+		return 0, nil
+	}
+
+	if err := s.revoke(ctx, credentials, admin, "revoked_by_admin", ipAddress, userAgent); err != nil {
+		return 0, err
+	}
+	return len(credentials), nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// startCeremony genera el desafío y guarda la ceremonia; retorna su ID y el desafío
+func (s *PasskeyService) startCeremony(ctx context.Context, ceremony *redis.WebAuthnCeremony) (string, string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", "", err
+	}
+	ceremonyID, err := randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("error al generar desafío: %w", err)
+	}
+
+	ceremony.Challenge = challenge
+	ceremony.CreatedAt = time.Now()
+	if err := s.ceremonyManager.SaveCeremony(ctx, ceremonyID, ceremony, PasskeyCeremonyTTL); err != nil {
+		return "", "", fmt.Errorf("error al guardar desafío: %w", err)
+	}
+	return ceremonyID, challenge, nil
+}
+
+// getActiveCredential obtiene una passkey vigente del usuario
+func (s *PasskeyService) getActiveCredential(ctx context.Context, userID uuid.UUID, credentialID int) (*models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	err := s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", credentialID, userID).
+		First(&credential).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("passkey no encontrada")
+		}
+		return nil, fmt.Errorf("error al buscar passkey: %w", err)
+	}
+	return &credential, nil
+}
+
+// ensureUserExists verifica que el usuario exista (activo o no)
+func (s *PasskeyService) ensureUserExists(ctx context.Context, userID uuid.UUID) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("usuario no encontrado")
+	}
+	return nil
+}
+
+// revoke marca las passkeys como revocadas y registra la auditoría
+func (s *PasskeyService) revoke(ctx context.Context, credentials []models.WebAuthnCredential, actor *models.UserProfile, reason, ipAddress, userAgent string) error {
+	ids := make([]int, 0, len(credentials))
+	for i := range credentials {
+		ids = append(ids, credentials[i].ID)
+	}
+
+	err := s.db.WithContext(ctx).Model(&models.WebAuthnCredential{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": actor.ID,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("error al revocar passkey: %w", err)
+	}
+
+	for i := range credentials {
+		credential := &credentials[i]
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &actor.ID,
+			Action:     models.AuditActionPasskeyRevoked,
+			Resource:   "webauthn_credential",
+			ResourceID: fmt.Sprintf("%d", credential.ID),
+			OldValues: map[string]interface{}{
+				"userId": credential.UserID,
+				"name":   credential.Name,
+			},
+			NewValues: map[string]interface{}{
+				"reason": reason,
+			},
+			IPAddress: ipAddress,
+			UserAgent: userAgent,
+			Result:    models.AuditResultSuccess,
+		})
+		logger.Info("🔑 Passkey \"%s\" de usuario %s revocada por %s", credential.Name, credential.UserID, actor.Email)
+	}
+	return nil
+}
+
+// reportSignCountRegression audita una passkey cuyo contador retrocedió: el autenticador
+// pudo ser clonado. La passkey no se revoca automáticamente; queda para revisión.
+func (s *PasskeyService) reportSignCountRegression(ctx context.Context, credential *models.WebAuthnCredential, ipAddress, userAgent string) {
+	logger.Warn("🚨 Contador de firmas de la passkey %d de usuario %s retrocedió: posible autenticador clonado", credential.ID, credential.UserID)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &credential.UserID,
+		Action:     models.AuditActionPasskeyCloned,
+		Resource:   "webauthn_credential",
+		ResourceID: fmt.Sprintf("%d", credential.ID),
+		OldValues:  map[string]interface{}{"signCount": credential.SignCount},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultFailure,
+		ErrorMsg:   "el contador de firmas retrocedió",
+	})
+}
+
+// newLoginCeremony arma la ceremonia de login. Sin contraseña (twoFactorToken vacío) se
+// exige la verificación del usuario en el dispositivo; como segundo factor basta su presencia.
+func newLoginCeremony(ipAddress, userID, twoFactorToken string) *redis.WebAuthnCeremony {
+	ceremony := &redis.WebAuthnCeremony{
+		Type:             redis.WebAuthnCeremonyLogin,
+		UserID:           userID,
+		UserVerification: webauthn.VerificationRequired,
+		IPAddress:        ipAddress,
+	}
+	if twoFactorToken != "" {
+		ceremony.TwoFactorToken = twoFactorToken
+		ceremony.UserVerification = webauthn.VerificationPreferred
+	}
+	return ceremony
+}
+
+// newPasskeyCredential arma la passkey a guardar a partir de la credencial verificada
+func newPasskeyCredential(userID uuid.UUID, verified *webauthn.Credential, name, userAgent string) *models.WebAuthnCredential {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey en " + describeDevice(userAgent)
+	}
+
+	return &models.WebAuthnCredential{
+		UserID:         userID,
+		CredentialID:   webauthn.EncodeID(verified.ID),
+		PublicKey:      verified.PublicKey,
+		Algorithm:      verified.Algorithm,
+		SignCount:      int64(verified.SignCount),
+		AAGUID:         formatAAGUID(verified.AAGUID),
+		Transports:     strings.Join(verified.Transports, ","),
+		Name:           name,
+		BackupEligible: verified.BackupEligible,
+		BackedUp:       verified.BackedUp,
+	}
+}
+
+// verifyPasskeyAssertion verifica la respuesta de get() contra la ceremonia y la passkey guardada.
+// Retorna errPasskeyNotRecognized si la passkey está revocada o pertenece a otra cuenta, y los
+// errores de webauthn (incluido ErrSignCountRegression) si la firma no es válida.
+func verifyPasskeyAssertion(rp *webauthn.RelyingParty, ceremony *redis.WebAuthnCeremony, credential *models.WebAuthnCredential, resp *webauthn.AuthenticationResponse) (*webauthn.AssertionResult, error) {
+	if credential.IsRevoked() {
+		logger.Warn("🔑 Intento de login con passkey revocada %d de usuario %s", credential.ID, credential.UserID)
+		return nil, errPasskeyNotRecognized
+	}
+	if ceremony.UserID != "" && ceremony.UserID != credential.UserID.String() {
+		return nil, errPasskeyNotRecognized
+	}
+
+	requireUV := ceremony.UserVerification == webauthn.VerificationRequired
+	result, err := rp.VerifyAssertion(resp, ceremony.Challenge, credential.PublicKey, uint32(credential.SignCount), requireUV)
+	if err != nil {
+		return nil, err
+	}
+
+	// El user handle de las passkeys residentes es el ID del usuario
+	if len(result.UserHandle) > 0 && !bytes.Equal(result.UserHandle, credential.UserID[:]) {
+		return nil, errPasskeyNotRecognized
+	}
+	return result, nil
+}
+
+// updatePasskeyUse guarda el nuevo contador de firmas solo si el de la base no lo alcanzó
+// todavía, para que de dos aserciones con el mismo contador prospere una sola. Los
+// autenticadores sin contador (siempre 0) se actualizan si el guardado sigue en 0.
+// Retorna webauthn.ErrSignCountRegression si ninguna fila cumplió la condición.
+func updatePasskeyUse(db *gorm.DB, credential *models.WebAuthnCredential, result *webauthn.AssertionResult, ipAddress string, now time.Time) error {
+	query := db.Model(&models.WebAuthnCredential{}).Where("id = ?", credential.ID)
+	if result.SignCount != 0 {
+		query = query.Where("sign_count < ?", int64(result.SignCount))
+	} else {
+		query = query.Where("sign_count = ?", credential.SignCount)
+	}
+
+	res := query.Updates(map[string]interface{}{
+		"sign_count":   int64(result.SignCount),
+		"backed_up":    result.BackedUp,
+		"last_used_at": now,
+		"last_used_ip": ipAddress,
+	})
+	if res.Error != nil {
+		return fmt.Errorf("error al actualizar passkey: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return webauthn.ErrSignCountRegression
+	}
+	return nil
+}
+
+// checkPasskeyRemoval impide quitar la última passkey cuando el rol exige 2FA y el usuario
+// no tiene TOTP activado; remaining son las passkeys vigentes que quedarían
+func checkPasskeyRemoval(requiredForRole, totpEnabled bool, remaining int64) error {
+	if requiredForRole && !totpEnabled && remaining == 0 {
+		return fmt.Errorf("su rol requiere autenticación de dos factores")
+	}
+	return nil
+}
+
+// passkeyErrorReason resume el error de verificación para el usuario sin el prefijo del paquete
+func passkeyErrorReason(err error) string {
+	switch {
+	case errors.Is(err, webauthn.ErrOriginMismatch), errors.Is(err, webauthn.ErrRPIDMismatch):
+		return "la passkey no corresponde a este sitio"
+	case errors.Is(err, webauthn.ErrUserNotVerified):
+		return "el dispositivo no verificó su identidad (PIN o biometría)"
+	case errors.Is(err, webauthn.ErrUnsupportedAlgorithm):
+		return "el dispositivo usa un algoritmo no soportado"
+	case errors.Is(err, webauthn.ErrChallengeMismatch):
+		return "el desafío no coincide, intente nuevamente"
+	default:
+		return "respuesta del dispositivo inválida"
+	}
+}
+
+// formatAAGUID da formato UUID al identificador del modelo de autenticador
+func formatAAGUID(aaguid []byte) string {
+	id, err := uuid.FromBytes(aaguid)
+	if err != nil || id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,300 @@
+// internal/services/passkey_service_test.go
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/webauthn"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const passkeyTestOrigin = "https://gamc.gob.bo"
+
+func passkeyTestRelyingParty() *webauthn.RelyingParty {
+	return &webauthn.RelyingParty{ID: "gamc.gob.bo", Name: "GAMC", Origins: []string{passkeyTestOrigin}}
+}
+
+// registerPasskey reproduce BeginRegistration + FinishRegistration con el autenticador de
+// software y retorna la passkey tal como se guardaría
+func registerPasskey(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthn.Authenticator, userID uuid.UUID) *models.WebAuthnCredential {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge: %v", err)
+	}
+	user := webauthn.UserEntity{ID: webauthn.EncodeID(userID[:]), Name: "ana@gamc.gob.bo", DisplayName: "Ana Pérez"}
+
+	resp, err := authenticator.Create(passkeyTestOrigin, rp.CreationOptions(user, challenge, nil))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	verified, err := rp.VerifyRegistration(resp, challenge, true)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+
+	return newPasskeyCredential(userID, verified, "", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Safari/604.1")
+}
+
+// signIn reproduce BeginLogin + navigator.credentials.get() para la ceremonia indicada
+func signIn(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthn.Authenticator, ceremony *redis.WebAuthnCeremony, origin string) *webauthn.AuthenticationResponse {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge: %v", err)
+	}
+	ceremony.Challenge = challenge
+
+	resp, err := authenticator.Get(origin, rp.RequestOptions(challenge, nil, ceremony.UserVerification))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return resp
+}
+
+func TestPasskeyRegistrationAndPasswordlessLogin(t *testing.T) {
+	rp := passkeyTestRelyingParty()
+	authenticator := webauthn.NewAuthenticator()
+	userID := uuid.New()
+
+	credential := registerPasskey(t, rp, authenticator, userID)
+	if credential.Name != "Passkey en Safari en iOS" {
+		t.Errorf("Name = %q", credential.Name)
+	}
+	if credential.Algorithm != webauthn.AlgES256 || len(credential.PublicKey) == 0 {
+		t.Errorf("Algorithm = %d, PublicKey de %d bytes", credential.Algorithm, len(credential.PublicKey))
+	}
+
+	for i := 1; i <= 2; i++ {
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+
+		result, err := verifyPasskeyAssertion(rp, ceremony, credential, resp)
+		if err != nil {
+			t.Fatalf("login %d: verifyPasskeyAssertion: %v", i, err)
+		}
+		if int64(result.SignCount) <= credential.SignCount {
+			t.Errorf("login %d: SignCount = %d, guardado %d", i, result.SignCount, credential.SignCount)
+		}
+		credential.SignCount = int64(result.SignCount)
+	}
+}
+
+func TestPasskeyLoginUserVerification(t *testing.T) {
+	rp := passkeyTestRelyingParty()
+	authenticator := webauthn.NewAuthenticator()
+	userID := uuid.New()
+	credential := registerPasskey(t, rp, authenticator, userID)
+
+	// El dispositivo solo confirma la presencia, sin PIN ni biometría
+	authenticator.UserVerification = false
+
+	passwordless := newLoginCeremony("10.0.0.1", "", "")
+	if passwordless.UserVerification != webauthn.VerificationRequired {
+		t.Fatalf("login sin contraseña con userVerification %q", passwordless.UserVerification)
+	}
+	resp := signIn(t, rp, authenticator, passwordless, passkeyTestOrigin)
+	_, err := verifyPasskeyAssertion(rp, passwordless, credential, resp)
+	if !errors.Is(err, webauthn.ErrUserNotVerified) {
+		t.Fatalf("login sin contraseña sin UV = %v, se esperaba %v", err, webauthn.ErrUserNotVerified)
+	}
+	if reason := passkeyErrorReason(err); reason != "el dispositivo no verificó su identidad (PIN o biometría)" {
+		t.Errorf("passkeyErrorReason = %q", reason)
+	}
+
+	// Como segundo factor la contraseña ya se verificó: basta la presencia
+	secondFactor := newLoginCeremony("10.0.0.1", userID.String(), "desafio-2fa")
+	if secondFactor.UserVerification != webauthn.VerificationPreferred || secondFactor.TwoFactorToken != "desafio-2fa" {
+		t.Fatalf("segundo factor con userVerification %q y token %q", secondFactor.UserVerification, secondFactor.TwoFactorToken)
+	}
+	resp = signIn(t, rp, authenticator, secondFactor, passkeyTestOrigin)
+	if _, err := verifyPasskeyAssertion(rp, secondFactor, credential, resp); err != nil {
+		t.Errorf("segundo factor sin UV: %v", err)
+	}
+}
+
+func TestPasskeyLoginRejects(t *testing.T) {
+	rp := passkeyTestRelyingParty()
+	authenticator := webauthn.NewAuthenticator()
+	userID := uuid.New()
+	credential := registerPasskey(t, rp, authenticator, userID)
+
+	t.Run("contador de firmas retrocedió", func(t *testing.T) {
+		stored := *credential
+		stored.SignCount = 1000
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+
+		if _, err := verifyPasskeyAssertion(rp, ceremony, &stored, resp); !errors.Is(err, webauthn.ErrSignCountRegression) {
+			t.Errorf("verifyPasskeyAssertion = %v, se esperaba %v", err, webauthn.ErrSignCountRegression)
+		}
+	})
+
+	t.Run("origen de otro sitio", func(t *testing.T) {
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, "https://phishing.example")
+
+		_, err := verifyPasskeyAssertion(rp, ceremony, credential, resp)
+		if !errors.Is(err, webauthn.ErrOriginMismatch) {
+			t.Fatalf("verifyPasskeyAssertion = %v, se esperaba %v", err, webauthn.ErrOriginMismatch)
+		}
+		if reason := passkeyErrorReason(err); reason != "la passkey no corresponde a este sitio" {
+			t.Errorf("passkeyErrorReason = %q", reason)
+		}
+	})
+
+	t.Run("rpId de otro sitio", func(t *testing.T) {
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+		other := &webauthn.RelyingParty{ID: "evil.example", Name: "Evil", Origins: []string{passkeyTestOrigin}}
+
+		if _, err := verifyPasskeyAssertion(other, ceremony, credential, resp); !errors.Is(err, webauthn.ErrRPIDMismatch) {
+			t.Errorf("verifyPasskeyAssertion = %v, se esperaba %v", err, webauthn.ErrRPIDMismatch)
+		}
+	})
+
+	t.Run("passkey revocada", func(t *testing.T) {
+		revokedAt := time.Now()
+		stored := *credential
+		stored.RevokedAt = &revokedAt
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+
+		if _, err := verifyPasskeyAssertion(rp, ceremony, &stored, resp); !errors.Is(err, errPasskeyNotRecognized) {
+			t.Errorf("verifyPasskeyAssertion = %v, se esperaba %v", err, errPasskeyNotRecognized)
+		}
+	})
+
+	t.Run("ceremonia de otra cuenta", func(t *testing.T) {
+		ceremony := newLoginCeremony("10.0.0.1", uuid.New().String(), "desafio-2fa")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+
+		if _, err := verifyPasskeyAssertion(rp, ceremony, credential, resp); !errors.Is(err, errPasskeyNotRecognized) {
+			t.Errorf("verifyPasskeyAssertion = %v, se esperaba %v", err, errPasskeyNotRecognized)
+		}
+	})
+
+	t.Run("user handle de otra cuenta", func(t *testing.T) {
+		otherAuthenticator := webauthn.NewAuthenticator()
+		foreign := registerPasskey(t, rp, otherAuthenticator, uuid.New())
+		foreign.UserID = userID // La passkey quedó asociada a otra cuenta que la del user handle
+
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, otherAuthenticator, ceremony, passkeyTestOrigin)
+
+		if _, err := verifyPasskeyAssertion(rp, ceremony, foreign, resp); !errors.Is(err, errPasskeyNotRecognized) {
+			t.Errorf("verifyPasskeyAssertion = %v, se esperaba %v", err, errPasskeyNotRecognized)
+		}
+	})
+}
+
+// dryRunDB abre gorm sin conexión a PostgreSQL: cada UPDATE se genera pero no se ejecuta y
+// onUpdate recibe el SQL y sus parámetros y retorna las filas que la base habría modificado
+func dryRunDB(t *testing.T, onUpdate func(sql string, vars []interface{}) int64) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 dbname=gamc_test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	err = db.Callback().Update().After("gorm:update").Register("test:rows_affected", func(tx *gorm.DB) {
+		tx.RowsAffected = onUpdate(tx.Statement.SQL.String(), tx.Statement.Vars)
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return db
+}
+
+func TestPasskeyLoginRejectsConcurrentSignCount(t *testing.T) {
+	rp := passkeyTestRelyingParty()
+	authenticator := webauthn.NewAuthenticator()
+	credential := registerPasskey(t, rp, authenticator, uuid.New())
+	credential.ID = 7
+
+	// Dos aserciones verificadas contra el mismo contador guardado, como dos logins simultáneos
+	var results []*webauthn.AssertionResult
+	for i := 0; i < 2; i++ {
+		ceremony := newLoginCeremony("10.0.0.1", "", "")
+		resp := signIn(t, rp, authenticator, ceremony, passkeyTestOrigin)
+		result, err := verifyPasskeyAssertion(rp, ceremony, credential, resp)
+		if err != nil {
+			t.Fatalf("verifyPasskeyAssertion %d: %v", i+1, err)
+		}
+		results = append(results, result)
+	}
+
+	// La base guarda primero la segunda: la primera ya no cumple sign_count < nuevo
+	stored := int64(credential.SignCount)
+	db := dryRunDB(t, func(sql string, vars []interface{}) int64 {
+		if !strings.Contains(sql, "sign_count < $") {
+			t.Errorf("UPDATE sin condición sobre el contador: %s", sql)
+		}
+		next, _ := vars[len(vars)-1].(int64)
+		if stored >= next {
+			return 0
+		}
+		stored = next
+		return 1
+	})
+
+	if err := updatePasskeyUse(db, credential, results[1], "10.0.0.1", time.Now()); err != nil {
+		t.Fatalf("updatePasskeyUse(contador %d) = %v", results[1].SignCount, err)
+	}
+	err := updatePasskeyUse(db, credential, results[0], "10.0.0.2", time.Now())
+	if !errors.Is(err, webauthn.ErrSignCountRegression) {
+		t.Errorf("updatePasskeyUse(contador %d) = %v, se esperaba %v", results[0].SignCount, err, webauthn.ErrSignCountRegression)
+	}
+
+	t.Run("autenticador sin contador", func(t *testing.T) {
+		var got string
+		db := dryRunDB(t, func(sql string, vars []interface{}) int64 {
+			got = sql
+			return 1
+		})
+		if err := updatePasskeyUse(db, credential, &webauthn.AssertionResult{}, "10.0.0.1", time.Now()); err != nil {
+			t.Fatalf("updatePasskeyUse = %v", err)
+		}
+		if !strings.Contains(got, "sign_count = $") {
+			t.Errorf("UPDATE sin condición sobre el contador guardado: %s", got)
+		}
+	})
+}
+
+func TestCheckPasskeyRemoval(t *testing.T) {
+	tests := []struct {
+		name            string
+		requiredForRole bool
+		totpEnabled     bool
+		remaining       int64
+		allowed         bool
+	}{
+		{name: "última passkey con 2FA exigido y sin TOTP", requiredForRole: true, allowed: false},
+		{name: "última passkey con TOTP activado", requiredForRole: true, totpEnabled: true, allowed: true},
+		{name: "quedan otras passkeys", requiredForRole: true, remaining: 1, allowed: true},
+		{name: "rol sin 2FA exigido", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPasskeyRemoval(tt.requiredForRole, tt.totpEnabled, tt.remaining)
+			if tt.allowed && err != nil {
+				t.Errorf("checkPasskeyRemoval = %v, se esperaba permitir", err)
+			}
+			if !tt.allowed && (err == nil || err.Error() != "su rol requiere autenticación de dos factores") {
+				t.Errorf("checkPasskeyRemoval = %v, se esperaba rechazar", err)
+			}
+		})
+	}
+}
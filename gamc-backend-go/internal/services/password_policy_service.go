@@ -0,0 +1,173 @@
+// internal/services/password_policy_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyService aplica el historial y la expiración de contraseñas
+type PasswordPolicyService struct {
+	db                  *gorm.DB
+	passwordService     *auth.PasswordService
+	notificationService *NotificationService
+	policyService       *SecurityPolicyService
+}
+
+// NewPasswordPolicyService crea una nueva instancia del servicio de política de contraseñas
+func NewPasswordPolicyService(appCtx *config.AppContext) *PasswordPolicyService {
+	return &PasswordPolicyService{
+		db:                  appCtx.DB,
+		passwordService:     auth.NewPasswordService(),
+		notificationService: NewNotificationService(appCtx),
+		policyService:       NewSecurityPolicyService(appCtx),
+	}
+}
+
+// ========================================
+// EXPIRACIÓN
+// ========================================
+
+// ExpiresAt retorna la fecha de expiración de la contraseña (nil si la expiración está desactivada)
+func (s *PasswordPolicyService) ExpiresAt(ctx context.Context, user *models.User) *time.Time {
+	return passwordExpiresAt(s.policyService.Current(ctx), user)
+}
+
+// IsExpired verifica si la contraseña del usuario ya expiró
+func (s *PasswordPolicyService) IsExpired(ctx context.Context, user *models.User) bool {
+	expiresAt := s.ExpiresAt(ctx, user)
+	return expiresAt != nil && time.Now().After(*expiresAt)
+}
+
+// passwordExpiresAt calcula la expiración según la política indicada.
+// Las contraseñas del directorio las gobierna el propio directorio.
+func passwordExpiresAt(policy *models.SecurityPolicy, user *models.User) *time.Time {
+	if policy.PasswordExpirationDays <= 0 || user.IsDirectoryUser() {
+		return nil
+	}
+	expiresAt := user.PasswordChangedAt.AddDate(0, 0, policy.PasswordExpirationDays)
+	return &expiresAt
+}
+
+// ========================================
+// HISTORIAL
+// ========================================
+
+// CheckReuse rechaza la nueva contraseña si coincide con la actual o con alguna del historial
+func (s *PasswordPolicyService) CheckReuse(ctx context.Context, user *models.User, newPassword string) error {
+	historyCount := s.policyService.Current(ctx).PasswordHistoryCount
+	if historyCount <= 0 {
+		return nil
+	}
+
+	var history []models.PasswordHistory
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", user.ID).
+		Order("created_at DESC, id DESC").
+		Limit(historyCount).
+		Find(&history).Error
+	if err != nil {
+		return fmt.Errorf("error al consultar historial de contraseñas: %w", err)
+	}
+
+	hashes := []string{user.PasswordHash}
+	for _, entry := range history {
+		if entry.PasswordHash != user.PasswordHash {
+			hashes = append(hashes, entry.PasswordHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		if s.passwordService.ComparePassword(newPassword, hash) == nil {
+			return fmt.Errorf("la nueva contraseña no puede ser igual a ninguna de las últimas %d contraseñas", historyCount)
+		}
+	}
+
+	return nil
+}
+
+// RecordPassword guarda el hash en el historial y descarta las entradas que exceden el límite.
+// Debe llamarse dentro de la misma transacción que actualiza la contraseña.
+func (s *PasswordPolicyService) RecordPassword(ctx context.Context, tx *gorm.DB, userID uuid.UUID, passwordHash string) error {
+	keep := s.policyService.Current(ctx).PasswordHistoryCount
+	if keep < 1 {
+		keep = 1
+	}
+
+	entry := models.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("error al registrar historial de contraseñas: %w", err)
+	}
+
+	err := tx.Exec(`DELETE FROM password_history
+		WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT ?
+		)`, userID, userID, keep).Error
+	if err != nil {
+		return fmt.Errorf("error al depurar historial de contraseñas: %w", err)
+	}
+
+	return nil
+}
+
+// ========================================
+// AVISOS DE EXPIRACIÓN (tarea diaria)
+// ========================================
+
+// SendExpiryWarnings notifica a los usuarios cuya contraseña expira dentro del período de aviso.
+// Cada contraseña se avisa una sola vez: password_expiry_warned_at se compara con password_changed_at.
+func (s *PasswordPolicyService) SendExpiryWarnings(ctx context.Context) (int, error) {
+	policy := s.policyService.Current(ctx)
+	if policy.PasswordExpirationDays <= 0 || policy.PasswordExpiryWarningDays <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	expiredBefore := now.AddDate(0, 0, -policy.PasswordExpirationDays)
+	warnBefore := now.AddDate(0, 0, policy.PasswordExpiryWarningDays-policy.PasswordExpirationDays)
+
+	var users []models.User
+	err := s.db.WithContext(ctx).
+		Where("is_active = ? AND is_service_account = ? AND auth_source = ?", true, false, models.AuthSourceLocal).
+		Where("password_changed_at > ? AND password_changed_at <= ?", expiredBefore, warnBefore).
+		Where("password_expiry_warned_at IS NULL OR password_expiry_warned_at < password_changed_at").
+		Find(&users).Error
+	if err != nil {
+		return 0, fmt.Errorf("error al buscar contraseñas próximas a expirar: %w", err)
+	}
+
+	warned := 0
+	for i := range users {
+		user := &users[i]
+		expiresAt := passwordExpiresAt(policy, user)
+
+		if err := s.notificationService.CreatePasswordExpiryNotification(ctx, user.ID, *expiresAt); err != nil {
+			logger.Error("❌ Error al notificar expiración de contraseña a %s: %v", user.Email, err)
+			continue
+		}
+
+		if err := s.db.WithContext(ctx).Model(user).UpdateColumn("password_expiry_warned_at", now).Error; err != nil {
+			logger.Error("❌ Error al registrar aviso de expiración de %s: %v", user.Email, err)
+			continue
+		}
+		warned++
+	}
+
+	if warned > 0 {
+		logger.Info("🔑 Avisos de expiración de contraseña enviados: %d", warned)
+	}
+
+	return warned, nil
+}
@@ -0,0 +1,518 @@
+// internal/services/permission_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// permissionCacheTTL tiempo que cada proceso reutiliza los permisos resueltos de un usuario;
+// acota cuánto tarda un cambio de roles en llegar a las demás instancias
+const permissionCacheTTL = 30 * time.Second
+
+// roleCodePattern formato de los códigos de rol
+var roleCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,49}$`)
+
+// permissionCache permisos efectivos por usuario, compartidos por todas las instancias del servicio del proceso
+var permissionCache struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID]cachedPermissions
+}
+
+type cachedPermissions struct {
+	permissions *models.EffectivePermissions
+	loadedAt    time.Time
+}
+
+// PermissionService resuelve y administra permisos granulares
+type PermissionService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewPermissionService crea una nueva instancia del servicio de permisos
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{
+		db:           db,
+		auditService: NewAuditService(db),
+	}
+}
+
+// ========================================
+// VERIFICACIÓN
+// ========================================
+
+// Can responde si el usuario puede ejercer el permiso sobre la unidad indicada
+func (s *PermissionService) Can(ctx context.Context, userID uuid.UUID, permission string, unitID int) (bool, error) {
+	effective, err := s.Effective(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return effective.Can(permission, unitID), nil
+}
+
+// CanAny responde si el usuario puede ejercer el permiso sobre alguna de las unidades
+func (s *PermissionService) CanAny(ctx context.Context, userID uuid.UUID, permission string, unitIDs ...int) (bool, error) {
+	effective, err := s.Effective(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, unitID := range unitIDs {
+		if effective.Can(permission, unitID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasPermission responde si el usuario tiene el permiso en al menos una unidad
+func (s *PermissionService) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	effective, err := s.Effective(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return effective.HasAnywhere(permission), nil
+}
+
+// Effective retorna los permisos resueltos del usuario: su rol base (global o
+// limitado a su unidad según el rol) más las asignaciones vigentes
+func (s *PermissionService) Effective(ctx context.Context, userID uuid.UUID) (*models.EffectivePermissions, error) {
+	permissionCache.mu.RLock()
+	cached, ok := permissionCache.entries[userID]
+	permissionCache.mu.RUnlock()
+
+	if ok && time.Since(cached.loadedAt) < permissionCacheTTL {
+		return cached.permissions, nil
+	}
+
+	effective, err := s.resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionCache.mu.Lock()
+	if permissionCache.entries == nil {
+		permissionCache.entries = make(map[uuid.UUID]cachedPermissions)
+	}
+	permissionCache.entries[userID] = cachedPermissions{permissions: effective, loadedAt: time.Now()}
+	permissionCache.mu.Unlock()
+
+	return effective, nil
+}
+
+// resolve calcula los permisos del usuario desde la base de datos
+func (s *PermissionService) resolve(ctx context.Context, userID uuid.UUID) (*models.EffectivePermissions, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	effective := models.NewEffectivePermissions()
+
+	// Rol base: admin aplica a todas las unidades, input/output solo a la propia
+	var baseRole models.Role
+	err := s.db.WithContext(ctx).Preload("Permissions").Where("code = ?", user.Role).First(&baseRole).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error al obtener rol base: %w", err)
+	}
+	if err == nil {
+		switch {
+		case baseRole.BaseScope == models.RoleScopeGlobal:
+			effective.Grant(nil, baseRole.PermissionCodes()...)
+		case user.OrganizationalUnitID != nil:
+			effective.Grant(user.OrganizationalUnitID, baseRole.PermissionCodes()...)
+		}
+	}
+
+	var assignments []models.UserRoleAssignment
+	err = s.db.WithContext(ctx).
+		Preload("Role.Permissions").
+		Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener roles asignados: %w", err)
+	}
+	for _, assignment := range assignments {
+		if assignment.Role != nil {
+			effective.Grant(assignment.OrganizationalUnitID, assignment.Role.PermissionCodes()...)
+		}
+	}
+
+	return effective, nil
+}
+
+// InvalidateUser descarta los permisos en cache de un usuario
+func (s *PermissionService) InvalidateUser(userID uuid.UUID) {
+	permissionCache.mu.Lock()
+	delete(permissionCache.entries, userID)
+	permissionCache.mu.Unlock()
+}
+
+// InvalidateAll descarta todos los permisos en cache (cambios en roles)
+func (s *PermissionService) InvalidateAll() {
+	permissionCache.mu.Lock()
+	permissionCache.entries = nil
+	permissionCache.mu.Unlock()
+}
+
+// ========================================
+// CATÁLOGO Y ROLES
+// ========================================
+
+// ListPermissions retorna el catálogo de permisos
+func (s *PermissionService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := s.db.WithContext(ctx).Order("category, code").Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener permisos: %w", err)
+	}
+	return permissions, nil
+}
+
+// ListRoles retorna los roles con sus permisos
+func (s *PermissionService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	if err := s.db.WithContext(ctx).Preload("Permissions").Order("is_system DESC, code").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener roles: %w", err)
+	}
+	return roles, nil
+}
+
+// CreateRole crea un rol con los permisos indicados
+func (s *PermissionService) CreateRole(ctx context.Context, req *models.RoleCreateRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.Role, error) {
+	if !roleCodePattern.MatchString(req.Code) {
+		return nil, fmt.Errorf("código de rol inválido: use minúsculas, números y guion bajo")
+	}
+
+	permissions, err := s.loadPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Role{}).Where("code = ?", req.Code).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("error al verificar rol: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("ya existe un rol con ese código")
+	}
+
+	baseScope := req.BaseScope
+	if baseScope == "" {
+		baseScope = models.RoleScopeUnit
+	}
+
+	role := &models.Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		BaseScope:   baseScope,
+		CreatedBy:   &adminID,
+		Permissions: permissions,
+	}
+	if err := s.db.WithContext(ctx).Create(role).Error; err != nil {
+		return nil, fmt.Errorf("error al crear rol: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionCreate,
+		Resource:   "role",
+		ResourceID: role.Code,
+		NewValues:  map[string]interface{}{"name": role.Name, "permissions": role.PermissionCodes()},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🛡️ Rol %s creado por administrador %s", role.Code, adminID)
+
+	return role, nil
+}
+
+// UpdateRole reemplaza nombre, descripción y permisos de un rol
+func (s *PermissionService) UpdateRole(ctx context.Context, code string, req *models.RoleUpdateRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.Role, error) {
+	role, err := s.getRole(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.loadPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// El administrador no puede quitarse a sí mismo la gestión de roles por esta vía
+	if role.Code == "admin" && !containsPermission(permissions, models.PermissionRolesManage) {
+		return nil, fmt.Errorf("el rol admin debe conservar el permiso %s", models.PermissionRolesManage)
+	}
+
+	oldValues := map[string]interface{}{"name": role.Name, "permissions": role.PermissionCodes()}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role.Name = req.Name
+		role.Description = req.Description
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Permissions").Replace(permissions)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al actualizar rol: %w", err)
+	}
+	role.Permissions = permissions
+
+	s.InvalidateAll()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionUpdate,
+		Resource:   "role",
+		ResourceID: role.Code,
+		OldValues:  oldValues,
+		NewValues:  map[string]interface{}{"name": role.Name, "permissions": role.PermissionCodes()},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🛡️ Rol %s actualizado por administrador %s", role.Code, adminID)
+
+	return role, nil
+}
+
+// DeleteRole elimina un rol que no sea de sistema junto con sus asignaciones
+func (s *PermissionService) DeleteRole(ctx context.Context, code string, adminID uuid.UUID, ipAddress, userAgent string) error {
+	role, err := s.getRole(ctx, code)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return fmt.Errorf("los roles de sistema no se pueden eliminar")
+	}
+
+	// role_permissions y user_role_assignments se eliminan en cascada
+	if err := s.db.WithContext(ctx).Delete(role).Error; err != nil {
+		return fmt.Errorf("error al eliminar rol: %w", err)
+	}
+
+	s.InvalidateAll()
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionDelete,
+		Resource:   "role",
+		ResourceID: role.Code,
+		OldValues:  map[string]interface{}{"name": role.Name, "permissions": role.PermissionCodes()},
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	return nil
+}
+
+// ========================================
+// ASIGNACIONES
+// ========================================
+
+// ListAssignments retorna los roles adicionales de un usuario
+func (s *PermissionService) ListAssignments(ctx context.Context, userID uuid.UUID) ([]models.UserRoleAssignment, error) {
+	var assignments []models.UserRoleAssignment
+	err := s.db.WithContext(ctx).
+		Preload("Role").
+		Preload("OrganizationalUnit").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener roles asignados: %w", err)
+	}
+	return assignments, nil
+}
+
+// Assign otorga un rol a un usuario, global o limitado a una unidad
+func (s *PermissionService) Assign(ctx context.Context, userID uuid.UUID, req *models.RoleAssignmentRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.UserRoleAssignment, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	role, err := s.getRole(ctx, req.RoleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OrganizationalUnitID != nil {
+		var count int64
+		err := s.db.WithContext(ctx).Model(&models.OrganizationalUnit{}).
+			Where("id = ? AND is_active = ?", *req.OrganizationalUnitID, true).
+			Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("error al verificar unidad organizacional: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("unidad organizacional no encontrada")
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("la fecha de expiración debe ser futura")
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.UserRoleAssignment{}).Where("user_id = ? AND role_id = ?", userID, role.ID)
+	if req.OrganizationalUnitID != nil {
+		query = query.Where("organizational_unit_id = ?", *req.OrganizationalUnitID)
+	} else {
+		query = query.Where("organizational_unit_id IS NULL")
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("error al verificar asignación: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("el usuario ya tiene ese rol en ese alcance")
+	}
+
+	assignment := &models.UserRoleAssignment{
+		UserID:               userID,
+		RoleID:               role.ID,
+		OrganizationalUnitID: req.OrganizationalUnitID,
+		ExpiresAt:            req.ExpiresAt,
+		GrantedBy:            &adminID,
+	}
+	if err := s.db.WithContext(ctx).Create(assignment).Error; err != nil {
+		return nil, fmt.Errorf("error al asignar rol: %w", err)
+	}
+	assignment.Role = role
+
+	s.InvalidateUser(userID)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionCreate,
+		Resource:   "role_assignment",
+		ResourceID: fmt.Sprintf("%d", assignment.ID),
+		NewValues:  assignmentValues(userID, role.Code, req.OrganizationalUnitID),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🛡️ Rol %s asignado a usuario %s por %s", role.Code, userID, adminID)
+
+	return assignment, nil
+}
+
+// RemoveAssignment retira un rol adicional de un usuario
+func (s *PermissionService) RemoveAssignment(ctx context.Context, userID uuid.UUID, assignmentID int, adminID uuid.UUID, ipAddress, userAgent string) error {
+	var assignment models.UserRoleAssignment
+	err := s.db.WithContext(ctx).Preload("Role").
+		Where("id = ? AND user_id = ?", assignmentID, userID).
+		First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("asignación no encontrada")
+		}
+		return fmt.Errorf("error al buscar asignación: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&assignment).Error; err != nil {
+		return fmt.Errorf("error al retirar rol: %w", err)
+	}
+
+	s.InvalidateUser(userID)
+
+	roleCode := ""
+	if assignment.Role != nil {
+		roleCode = assignment.Role.Code
+	}
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionDelete,
+		Resource:   "role_assignment",
+		ResourceID: fmt.Sprintf("%d", assignment.ID),
+		OldValues:  assignmentValues(userID, roleCode, assignment.OrganizationalUnitID),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	return nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// getRole busca un rol por código
+func (s *PermissionService) getRole(ctx context.Context, code string) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.WithContext(ctx).Preload("Permissions").Where("code = ?", code).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("rol no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar rol: %w", err)
+	}
+	return &role, nil
+}
+
+// loadPermissions valida que todos los códigos existan en el catálogo
+func (s *PermissionService) loadPermissions(ctx context.Context, codes []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(codes) == 0 {
+		return permissions, nil
+	}
+	if err := s.db.WithContext(ctx).Where("code IN ?", codes).Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener permisos: %w", err)
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Code] = true
+	}
+	for _, code := range codes {
+		if !found[code] {
+			return nil, fmt.Errorf("permiso desconocido: %s", code)
+		}
+	}
+
+	return permissions, nil
+}
+
+// containsPermission verifica si el código está en la lista
+func containsPermission(permissions []models.Permission, code string) bool {
+	for _, permission := range permissions {
+		if permission.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// assignmentValues datos de una asignación para auditoría
+func assignmentValues(userID uuid.UUID, roleCode string, unitID *int) map[string]interface{} {
+	values := map[string]interface{}{
+		"userId": userID.String(),
+		"role":   roleCode,
+	}
+	if unitID != nil {
+		values["organizationalUnitId"] = *unitID
+	}
+	return values
+}
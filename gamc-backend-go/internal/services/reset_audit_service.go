@@ -0,0 +1,297 @@
+// internal/services/reset_audit_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/repositories"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ResetAuditDefaultDays período por defecto del tablero de resets
+	ResetAuditDefaultDays = 30
+	// ResetAuditMaxDays período máximo que se puede consultar de una vez
+	ResetAuditMaxDays = 366
+	// resetAuditTopIPs IPs con más solicitudes que se muestran en el tablero
+	resetAuditTopIPs = 20
+	// resetAuditExportLimit filas máximas de la exportación CSV
+	resetAuditExportLimit = 10000
+)
+
+// resetOutcomeLabels etiquetas de los resultados para el tablero y el CSV
+var resetOutcomeLabels = map[string]string{
+	models.ResetOutcomeCompleted:              "Completado",
+	models.ResetOutcomeFailedSecurityQuestion: "Falló preguntas de seguridad",
+	models.ResetOutcomeExpired:                "Expirado",
+	models.ResetOutcomePending:                "Pendiente",
+}
+
+// ResetAuditFilter filtros del tablero de auditoría de resets
+type ResetAuditFilter struct {
+	DateFrom             *time.Time
+	DateTo               *time.Time
+	UserID               *uuid.UUID
+	IPAddress            string
+	OrganizationalUnitID *int
+	Outcome              string
+	Search               string
+	Page                 int
+	Limit                int
+}
+
+// ResetAuditService agrega las solicitudes de reset de contraseña para los administradores
+type ResetAuditService struct {
+	auditRepo    *repositories.AuditRepository
+	auditService *AuditService
+	config       *config.Config
+}
+
+// NewResetAuditService crea una nueva instancia del servicio de auditoría de resets
+func NewResetAuditService(appCtx *config.AppContext) *ResetAuditService {
+	return &ResetAuditService{
+		auditRepo:    repositories.NewAuditRepository(appCtx.DB),
+		auditService: NewAuditService(appCtx.DB),
+		config:       appCtx.Config,
+	}
+}
+
+// GetReport arma el tablero del período: totales, desglose por día, unidad, resultado
+// e IP, y las anomalías que superan los umbrales configurados
+func (s *ResetAuditService) GetReport(ctx context.Context, filter *ResetAuditFilter) (*models.PasswordResetAuditReport, error) {
+	repoFilter, err := s.toRepoFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PasswordResetAuditReport{
+		From:        *repoFilter.DateFrom,
+		To:          *repoFilter.DateTo,
+		Anomalies:   make([]models.PasswordResetAuditAnomaly, 0),
+		GeneratedAt: time.Now(),
+	}
+
+	totals, err := s.auditRepo.GetResetTotals(ctx, repoFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener totales de resets: %w", err)
+	}
+	totals.Key = "total"
+	totals.Label = "Total"
+	report.Totals = *totals
+
+	if report.ByDay, err = s.auditRepo.GetResetBreakdown(ctx, repoFilter, "day", 0); err != nil {
+		return nil, fmt.Errorf("error al agrupar resets por día: %w", err)
+	}
+	if report.ByUnit, err = s.auditRepo.GetResetBreakdown(ctx, repoFilter, "unit", 0); err != nil {
+		return nil, fmt.Errorf("error al agrupar resets por unidad: %w", err)
+	}
+	if report.ByIP, err = s.auditRepo.GetResetBreakdown(ctx, repoFilter, "ip", resetAuditTopIPs); err != nil {
+		return nil, fmt.Errorf("error al agrupar resets por IP: %w", err)
+	}
+
+	// Todos los resultados aparecen, aunque no tengan solicitudes
+	byOutcome, err := s.auditRepo.GetResetBreakdown(ctx, repoFilter, "outcome", 0)
+	if err != nil {
+		return nil, fmt.Errorf("error al agrupar resets por resultado: %w", err)
+	}
+	found := make(map[string]models.PasswordResetAuditBucket, len(byOutcome))
+	for _, b := range byOutcome {
+		found[b.Key] = b
+	}
+	report.ByOutcome = make([]models.PasswordResetAuditBucket, 0, len(models.ResetOutcomes))
+	for _, outcome := range models.ResetOutcomes {
+		bucket, ok := found[outcome]
+		if !ok {
+			bucket = models.PasswordResetAuditBucket{Key: outcome}
+		}
+		bucket.Label = resetOutcomeLabels[outcome]
+		report.ByOutcome = append(report.ByOutcome, bucket)
+	}
+
+	if report.ByDay == nil {
+		report.ByDay = make([]models.PasswordResetAuditBucket, 0)
+	}
+	if report.ByUnit == nil {
+		report.ByUnit = make([]models.PasswordResetAuditBucket, 0)
+	}
+	if report.ByIP == nil {
+		report.ByIP = make([]models.PasswordResetAuditBucket, 0)
+	}
+
+	// Anomalías: una IP que pide resets para muchas cuentas
+	if threshold := s.config.ResetAuditIPAccountThreshold; threshold > 0 {
+		ipAnomalies, err := s.auditRepo.GetResetIPAnomalies(ctx, repoFilter, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("error al detectar anomalías por IP: %w", err)
+		}
+		for _, a := range ipAnomalies {
+			a.Type = models.ResetAnomalyIPManyAccounts
+			a.Description = fmt.Sprintf("La IP %s solicitó %d resets para %d cuentas distintas", a.IPAddress, a.Requests, a.Accounts)
+			report.Anomalies = append(report.Anomalies, a)
+		}
+	}
+
+	// Anomalías: una cuenta que recibe resets desde muchas IPs
+	if threshold := s.config.ResetAuditAccountIPThreshold; threshold > 0 {
+		accountAnomalies, err := s.auditRepo.GetResetAccountAnomalies(ctx, repoFilter, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("error al detectar anomalías por cuenta: %w", err)
+		}
+		for _, a := range accountAnomalies {
+			a.Type = models.ResetAnomalyAccountManyIPs
+			a.Description = fmt.Sprintf("La cuenta %s recibió %d solicitudes de reset desde %d IPs distintas", a.Email, a.Requests, a.IPs)
+			report.Anomalies = append(report.Anomalies, a)
+		}
+	}
+
+	return report, nil
+}
+
+// ListResets lista las solicitudes del período con paginación
+func (s *ResetAuditService) ListResets(ctx context.Context, filter *ResetAuditFilter) ([]*models.PasswordResetAuditEntry, int64, error) {
+	repoFilter, err := s.toRepoFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := filter.Limit
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	repoFilter.Limit = limit
+	repoFilter.Offset = (page - 1) * limit
+
+	entries, total, err := s.auditRepo.GetResetsByFilter(ctx, repoFilter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener solicitudes de reset: %w", err)
+	}
+	return entries, total, nil
+}
+
+// ExportCSV exporta las solicitudes del período (hasta 10000) y registra la exportación
+func (s *ResetAuditService) ExportCSV(ctx context.Context, filter *ResetAuditFilter, admin *models.UserProfile, ipAddress, userAgent string) ([]byte, error) {
+	repoFilter, err := s.toRepoFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	repoFilter.Limit = resetAuditExportLimit
+
+	entries, total, err := s.auditRepo.GetResetsByFilter(ctx, repoFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener solicitudes de reset: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"ID", "Fecha", "Email", "Nombre", "Apellido", "Unidad", "IP", "User Agent",
+		"Requiere preguntas", "Intentos fallidos", "Resultado", "Expira", "Usado",
+	})
+	for _, e := range entries {
+		usedAt := ""
+		if e.UsedAt != nil {
+			usedAt = e.UsedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339),
+			csvSafe(e.Email),
+			csvSafe(e.FirstName),
+			csvSafe(e.LastName),
+			csvSafe(e.UnitName),
+			csvSafe(e.RequestIP),
+			csvSafe(e.UserAgent),
+			strconv.FormatBool(e.RequiresSecurityQuestion),
+			strconv.Itoa(e.SecurityQuestionAttempts),
+			resetOutcomeLabels[e.Outcome],
+			e.ExpiresAt.Format(time.RFC3339),
+			usedAt,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("error al generar CSV: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionExport,
+		Resource:   "password_reset_tokens",
+		ResourceID: "bulk",
+		NewValues: map[string]interface{}{
+			"from":     repoFilter.DateFrom,
+			"to":       repoFilter.DateTo,
+			"outcome":  repoFilter.Outcome,
+			"exported": len(entries),
+			"total":    total,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("📤 Auditoría de resets exportada por %s (%d filas)", admin.Email, len(entries))
+
+	return buf.Bytes(), nil
+}
+
+// toRepoFilter valida el período (últimos 30 días por defecto) y traduce el filtro
+func (s *ResetAuditService) toRepoFilter(filter *ResetAuditFilter) (*repositories.ResetAuditFilter, error) {
+	to := time.Now()
+	if filter.DateTo != nil {
+		to = *filter.DateTo
+	}
+	from := to.AddDate(0, 0, -ResetAuditDefaultDays)
+	if filter.DateFrom != nil {
+		from = *filter.DateFrom
+	}
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("rango de fechas inválido")
+	}
+	if to.Sub(from) > ResetAuditMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("el período no puede superar %d días", ResetAuditMaxDays)
+	}
+	if filter.Outcome != "" {
+		if _, ok := resetOutcomeLabels[filter.Outcome]; !ok {
+			return nil, fmt.Errorf("resultado inválido: %s", filter.Outcome)
+		}
+	}
+
+	return &repositories.ResetAuditFilter{
+		AuditFilter: repositories.AuditFilter{
+			UserID:     filter.UserID,
+			IPAddress:  filter.IPAddress,
+			DateFrom:   &from,
+			DateTo:     &to,
+			SearchTerm: filter.Search,
+			SortBy:     "created_at",
+			SortDesc:   true,
+		},
+		OrganizationalUnitID: filter.OrganizationalUnitID,
+		Outcome:              filter.Outcome,
+	}, nil
+}
+
+// csvSafe neutraliza celdas que una hoja de cálculo interpretaría como fórmula (inyección
+// CSV): los valores que empiezan con =, +, -, @, tabulación o retorno llevan un ' delante
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,34 @@
+// internal/services/reset_audit_service_test.go
+package services
+
+import (
+	"testing"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{name: "fórmula con =", cell: `=HYPERLINK("http://evil.example","clic")`, want: `'=HYPERLINK("http://evil.example","clic")`},
+		{name: "fórmula con +", cell: "+1+cmd|' /C calc'!A0", want: "'+1+cmd|' /C calc'!A0"},
+		{name: "fórmula con -", cell: "-2+3", want: "'-2+3"},
+		{name: "fórmula con @", cell: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{name: "tabulación inicial", cell: "\t=1+1", want: "'\t=1+1"},
+		{name: "retorno inicial", cell: "\r=1+1", want: "'\r=1+1"},
+		{name: "correo", cell: "ana@gamc.gob.bo", want: "ana@gamc.gob.bo"},
+		{name: "user agent", cell: "Mozilla/5.0 (X11; Linux x86_64)", want: "Mozilla/5.0 (X11; Linux x86_64)"},
+		{name: "IPv6", cell: "::1", want: "::1"},
+		{name: "signo en medio", cell: "Pérez-Rojas", want: "Pérez-Rojas"},
+		{name: "vacío", cell: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvSafe(tt.cell); got != tt.want {
+				t.Errorf("csvSafe(%q) = %q, se esperaba %q", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
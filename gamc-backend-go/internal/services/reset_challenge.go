@@ -0,0 +1,149 @@
+// internal/services/reset_challenge.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+)
+
+// resetChallenge preguntas y contadores con los que se crea un token de reset
+type resetChallenge struct {
+	questionIDs   []int
+	attempts      map[int]int
+	totalAttempts int
+}
+
+// prepareResetChallenge elige las preguntas del desafío. Si el usuario tiene un
+// desafío vigente sin completar se conservan sus preguntas e intentos: pedir un
+// nuevo reset no permite cambiar de pregunta ni reiniciar el contador, y las
+// preguntas ya respondidas deben responderse otra vez.
+func (s *AuthService) prepareResetChallenge(ctx context.Context, user *models.User) (*resetChallenge, error) {
+	active := make(map[int]bool, len(user.SecurityQuestions))
+	for _, q := range user.SecurityQuestions {
+		active[q.SecurityQuestionID] = true
+	}
+
+	var previous models.PasswordResetToken
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL AND expires_at > ? AND requires_security_question = ? AND security_question_verified = ?",
+			user.ID, time.Now(), true, false).
+		Where("challenge_question_ids <> '[]'::jsonb").
+		Order("created_at DESC").
+		First(&previous).Error
+	if err == nil && containsAll(active, previous.ChallengeQuestionIDs) {
+		if previous.HasExceededSecurityAttempts() {
+			minutes := int(time.Until(previous.ExpiresAt).Minutes()) + 1
+			return nil, fmt.Errorf("demasiados intentos fallidos en las preguntas de seguridad, intente nuevamente en %d minutos", minutes)
+		}
+		return &resetChallenge{
+			questionIDs:   previous.ChallengeQuestionIDs,
+			attempts:      previous.QuestionAttempts,
+			totalAttempts: previous.SecurityQuestionAttempts,
+		}, nil
+	}
+
+	required := s.policyService.Current(ctx).RequiredSecurityAnswers()
+
+	questionIDs, err := pickRandomQuestions(user.SecurityQuestions, required)
+	if err != nil {
+		return nil, fmt.Errorf("error al elegir preguntas de seguridad: %w", err)
+	}
+
+	return &resetChallenge{questionIDs: questionIDs, attempts: map[int]int{}}, nil
+}
+
+// pickRandomQuestions elige n preguntas distintas al azar (Fisher-Yates con crypto/rand)
+func pickRandomQuestions(questions []models.UserSecurityQuestion, n int) ([]int, error) {
+	ids := make([]int, len(questions))
+	for i, q := range questions {
+		ids[i] = q.SecurityQuestionID
+	}
+
+	for i := len(ids) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		ids[i], ids[j.Int64()] = ids[j.Int64()], ids[i]
+	}
+
+	if n > len(ids) {
+		n = len(ids)
+	}
+	return ids[:n], nil
+}
+
+// challengeQuestion arma la pregunta en curso del desafío para la respuesta al cliente
+func challengeQuestion(user *models.User, resetToken *models.PasswordResetToken) *models.SecurityQuestionForResetResponse {
+	questionID, ok := resetToken.CurrentChallengeQuestionID()
+	if !ok {
+		return nil
+	}
+
+	for _, q := range user.SecurityQuestions {
+		if q.SecurityQuestionID != questionID || q.SecurityQuestion == nil {
+			continue
+		}
+		step, total := resetToken.ChallengeStep()
+		return &models.SecurityQuestionForResetResponse{
+			QuestionID:   questionID,
+			QuestionText: q.SecurityQuestion.QuestionText,
+			Attempts:     resetToken.QuestionAttempts[questionID],
+			MaxAttempts:  models.MaxSecurityQuestionAttempts,
+			Step:         step,
+			TotalSteps:   total,
+		}
+	}
+	return nil
+}
+
+// verifySecurityAnswer compara la respuesta en tiempo constante. Las respuestas
+// guardadas con la normalización anterior se aceptan una vez y se re-hashean.
+func (s *AuthService) verifySecurityAnswer(ctx context.Context, userSecQuestion *models.UserSecurityQuestion, answer string) bool {
+	answerHash := s.hashSecurityAnswer(answer)
+	if subtle.ConstantTimeCompare([]byte(answerHash), []byte(userSecQuestion.AnswerHash)) == 1 {
+		return true
+	}
+
+	if subtle.ConstantTimeCompare([]byte(legacySecurityAnswerHash(answer)), []byte(userSecQuestion.AnswerHash)) != 1 {
+		return false
+	}
+
+	err := s.db.WithContext(ctx).Model(userSecQuestion).Update("answer_hash", answerHash).Error
+	if err != nil {
+		logger.Warn("Error al actualizar el hash de la respuesta de seguridad %d: %v", userSecQuestion.ID, err)
+	}
+	return true
+}
+
+// legacyWhitespace espacios colapsados por la normalización anterior
+var legacyWhitespace = regexp.MustCompile(`\s+`)
+
+// legacySecurityAnswerHash hash con la normalización anterior (minúsculas y espacios),
+// equivalente a hash_security_answer() de la base de datos
+func legacySecurityAnswerHash(answer string) string {
+	normalized := legacyWhitespace.ReplaceAllString(strings.ToLower(strings.TrimSpace(answer)), " ")
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+// containsAll verifica que todas las preguntas sigan activas para el usuario
+func containsAll(active map[int]bool, ids []int) bool {
+	for _, id := range ids {
+		if !active[id] {
+			return false
+		}
+	}
+	return true
+}
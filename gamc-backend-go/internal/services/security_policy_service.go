@@ -0,0 +1,298 @@
+// internal/services/security_policy_service.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/internal/types/requests"
+	"gamc-backend-go/pkg/logger"
+	"gamc-backend-go/pkg/validator"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// securityPolicyCacheKey clave de la política en el cache compartido (Redis DB 1)
+	securityPolicyCacheKey = "security_policy"
+	securityPolicyCacheTTL = 10 * time.Minute
+
+	// securityPolicyLocalTTL tiempo que cada proceso reutiliza su copia sin consultar Redis;
+	// acota cuánto tarda un cambio en llegar a las demás instancias
+	securityPolicyLocalTTL = 30 * time.Second
+
+	// defaultSessionMaxLifetimeHours vigencia máxima de la sesión si la política no la define
+	defaultSessionMaxLifetimeHours = 12
+)
+
+// policyCache copia en memoria compartida por todas las instancias del servicio del proceso
+var policyCache struct {
+	mu       sync.RWMutex
+	policy   *models.SecurityPolicy
+	loadedAt time.Time
+}
+
+// SecurityPolicyService administra la política de seguridad persistida
+type SecurityPolicyService struct {
+	db           *gorm.DB
+	cacheManager *redis.CacheManager
+	auditService *AuditService
+	config       *config.Config
+}
+
+// NewSecurityPolicyService crea una nueva instancia del servicio de política de seguridad
+func NewSecurityPolicyService(appCtx *config.AppContext) *SecurityPolicyService {
+	return &SecurityPolicyService{
+		db:           appCtx.DB,
+		cacheManager: redis.NewCacheManager(appCtx.Redis),
+		auditService: NewAuditService(appCtx.DB),
+		config:       appCtx.Config,
+	}
+}
+
+// InitializeSecurityPolicy carga la política vigente y la registra como fuente
+// de las reglas de contraseña del paquete validator
+func InitializeSecurityPolicy(appCtx *config.AppContext) *models.SecurityPolicy {
+	service := NewSecurityPolicyService(appCtx)
+
+	validator.SetPasswordRulesProvider(func() validator.PasswordRules {
+		return passwordRulesFromPolicy(service.Current(context.Background()))
+	})
+
+	return service.Current(context.Background())
+}
+
+// ========================================
+// LECTURA
+// ========================================
+
+// Current retorna la política vigente. Nunca falla: ante errores usa la última
+// copia conocida o los valores por defecto de la configuración.
+func (s *SecurityPolicyService) Current(ctx context.Context) *models.SecurityPolicy {
+	policyCache.mu.RLock()
+	cached, loadedAt := policyCache.policy, policyCache.loadedAt
+	policyCache.mu.RUnlock()
+
+	if cached != nil && time.Since(loadedAt) < securityPolicyLocalTTL {
+		return clonePolicy(cached)
+	}
+
+	policy, err := s.load(ctx)
+	if err != nil {
+		logger.Warn("Error al cargar la política de seguridad: %v", err)
+		if cached != nil {
+			return clonePolicy(cached)
+		}
+		return s.defaultPolicy()
+	}
+
+	policyCache.mu.Lock()
+	policyCache.policy = policy
+	policyCache.loadedAt = time.Now()
+	policyCache.mu.Unlock()
+
+	return clonePolicy(policy)
+}
+
+// load lee la política desde Redis o, si no está en cache, desde la base de datos
+func (s *SecurityPolicyService) load(ctx context.Context) (*models.SecurityPolicy, error) {
+	var policy models.SecurityPolicy
+	if err := s.cacheManager.Get(ctx, securityPolicyCacheKey, &policy); err != nil {
+		logger.Warn("Error al leer la política de seguridad del cache: %v", err)
+	} else if policy.ID == models.SecurityPolicyID {
+		return &policy, nil
+	}
+
+	err := s.db.WithContext(ctx).First(&policy, models.SecurityPolicyID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.defaultPolicy(), nil
+		}
+		return nil, fmt.Errorf("error al obtener política de seguridad: %w", err)
+	}
+
+	if err := s.cacheManager.Set(ctx, securityPolicyCacheKey, &policy, securityPolicyCacheTTL); err != nil {
+		logger.Warn("Error al guardar la política de seguridad en cache: %v", err)
+	}
+
+	return &policy, nil
+}
+
+// ========================================
+// ADMINISTRACIÓN
+// ========================================
+
+// Update reemplaza la política vigente e invalida los caches
+func (s *SecurityPolicyService) Update(ctx context.Context, req *requests.SecurityPolicyRequest, adminID uuid.UUID, ipAddress, userAgent string) (*models.SecurityPolicy, error) {
+	if req.PasswordExpirationDays > 0 && req.PasswordExpiryWarningDays >= req.PasswordExpirationDays {
+		return nil, fmt.Errorf("el aviso de expiración debe ser menor a la vigencia de la contraseña")
+	}
+
+	maxLifetimeHours := req.SessionMaxLifetimeHours
+	if maxLifetimeHours == 0 {
+		maxLifetimeHours = defaultSessionMaxLifetimeHours
+	}
+	roleTimeouts := req.RoleSessionTimeouts
+	if roleTimeouts == nil {
+		roleTimeouts = map[string]int{}
+	}
+	// La inactividad no tiene efecto si supera la vigencia máxima de la sesión
+	for role, minutes := range roleTimeouts {
+		if minutes > maxLifetimeHours*60 {
+			return nil, fmt.Errorf("la inactividad del rol %s supera la vigencia máxima de la sesión", role)
+		}
+	}
+	if req.SessionTimeoutMinutes > maxLifetimeHours*60 {
+		return nil, fmt.Errorf("la inactividad de sesión supera la vigencia máxima de la sesión")
+	}
+
+	previous, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ipWhitelist := req.IPWhitelist
+	if ipWhitelist == nil {
+		ipWhitelist = []string{}
+	}
+	// Una lista que no incluya la IP del administrador lo dejaría sin acceso
+	if prefixes := parseAllowlist(ipWhitelist); len(prefixes) > 0 {
+		addr, err := netip.ParseAddr(ipAddress)
+		if err != nil || !containsAddr(prefixes, addr.Unmap()) {
+			return nil, fmt.Errorf("la lista de IPs permitidas debe incluir su IP actual (%s)", ipAddress)
+		}
+	}
+
+	policy := *previous
+	policy.ID = models.SecurityPolicyID
+	policy.MinPasswordLength = req.MinPasswordLength
+	policy.RequireUppercase = req.RequireUppercase
+	policy.RequireLowercase = req.RequireLowercase
+	policy.RequireNumbers = req.RequireNumbers
+	policy.RequireSpecialChars = req.RequireSpecialChars
+	policy.BlockCommonPasswords = req.BlockCommonPasswords
+	policy.BlockSequentialChars = req.BlockSequentialChars
+	policy.PasswordHistoryCount = req.PasswordHistoryCount
+	policy.PasswordExpirationDays = req.PasswordExpirationDays
+	policy.PasswordExpiryWarningDays = req.PasswordExpiryWarningDays
+	policy.ResetSecurityAnswersRequired = req.ResetSecurityAnswersRequired
+	if policy.ResetSecurityAnswersRequired == 0 {
+		policy.ResetSecurityAnswersRequired = 1
+	}
+	policy.MaxLoginAttempts = req.MaxLoginAttempts
+	policy.LockoutDurationMinutes = req.LockoutDurationMinutes
+	policy.SessionTimeoutMinutes = req.SessionTimeoutMinutes
+	policy.RoleSessionTimeouts = roleTimeouts
+	policy.SessionMaxLifetimeHours = maxLifetimeHours
+	policy.TwoFactorRequired = req.TwoFactorRequired
+	policy.IPWhitelist = ipWhitelist
+	policy.UpdatedBy = &adminID
+
+	if err := s.db.WithContext(ctx).Save(&policy).Error; err != nil {
+		return nil, fmt.Errorf("error al guardar política de seguridad: %w", err)
+	}
+
+	s.Invalidate(ctx)
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &adminID,
+		Action:     models.AuditActionUpdate,
+		Resource:   "security_policy",
+		ResourceID: fmt.Sprintf("%d", models.SecurityPolicyID),
+		OldValues:  policyToMap(previous),
+		NewValues:  policyToMap(&policy),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("🛡️ Política de seguridad actualizada por administrador %s", adminID)
+
+	return s.Current(ctx), nil
+}
+
+// Invalidate descarta la política en cache (Redis y memoria del proceso)
+func (s *SecurityPolicyService) Invalidate(ctx context.Context) {
+	if err := s.cacheManager.Delete(ctx, securityPolicyCacheKey); err != nil {
+		logger.Warn("Error al invalidar la política de seguridad en cache: %v", err)
+	}
+
+	policyCache.mu.Lock()
+	policyCache.policy = nil
+	policyCache.mu.Unlock()
+}
+
+// ========================================
+// FUNCIONES AUXILIARES
+// ========================================
+
+// defaultPolicy política usada si aún no existe la fila en la base de datos
+func (s *SecurityPolicyService) defaultPolicy() *models.SecurityPolicy {
+	rules := validator.DefaultPasswordRules()
+	return &models.SecurityPolicy{
+		ID:                           models.SecurityPolicyID,
+		MinPasswordLength:            rules.MinLength,
+		RequireUppercase:             rules.RequireUppercase,
+		RequireLowercase:             rules.RequireLowercase,
+		RequireNumbers:               rules.RequireNumbers,
+		RequireSpecialChars:          rules.RequireSpecialChars,
+		BlockCommonPasswords:         rules.BlockCommonPasswords,
+		BlockSequentialChars:         rules.BlockSequentialChars,
+		PasswordHistoryCount:         s.config.PasswordHistoryCount,
+		PasswordExpirationDays:       s.config.PasswordExpirationDays,
+		PasswordExpiryWarningDays:    s.config.PasswordExpiryWarningDays,
+		ResetSecurityAnswersRequired: 1,
+		MaxLoginAttempts:             s.config.MaxLoginAttempts,
+		LockoutDurationMinutes:       int(s.config.LockoutDuration.Minutes()),
+		SessionTimeoutMinutes:        480,
+		RoleSessionTimeouts:          map[string]int{},
+		SessionMaxLifetimeHours:      defaultSessionMaxLifetimeHours,
+		IPWhitelist:                  []string{},
+	}
+}
+
+// passwordRulesFromPolicy traduce la política a las reglas del validador
+func passwordRulesFromPolicy(policy *models.SecurityPolicy) validator.PasswordRules {
+	return validator.PasswordRules{
+		MinLength:            policy.MinPasswordLength,
+		MaxLength:            models.PasswordMaxLength,
+		RequireUppercase:     policy.RequireUppercase,
+		RequireLowercase:     policy.RequireLowercase,
+		RequireNumbers:       policy.RequireNumbers,
+		RequireSpecialChars:  policy.RequireSpecialChars,
+		SpecialChars:         models.PasswordSpecialChars,
+		BlockCommonPasswords: policy.BlockCommonPasswords,
+		BlockSequentialChars: policy.BlockSequentialChars,
+	}
+}
+
+// clonePolicy evita que los llamadores modifiquen la copia en cache
+func clonePolicy(policy *models.SecurityPolicy) *models.SecurityPolicy {
+	clone := *policy
+	clone.IPWhitelist = append([]string(nil), policy.IPWhitelist...)
+	clone.RoleSessionTimeouts = make(map[string]int, len(policy.RoleSessionTimeouts))
+	for role, minutes := range policy.RoleSessionTimeouts {
+		clone.RoleSessionTimeouts[role] = minutes
+	}
+	return &clone
+}
+
+// policyToMap convierte la política al formato de valores de auditoría
+func policyToMap(policy *models.SecurityPolicy) map[string]interface{} {
+	values := make(map[string]interface{})
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return values
+	}
+	json.Unmarshal(data, &values)
+	return values
+}
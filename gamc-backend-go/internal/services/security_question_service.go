@@ -0,0 +1,512 @@
+// internal/services/security_question_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// SecurityQuestionStatsDefaultDays período por defecto de las estadísticas de resets
+	SecurityQuestionStatsDefaultDays = 30
+	// SecurityQuestionStatsMaxDays período máximo de las estadísticas de resets
+	SecurityQuestionStatsMaxDays = 365
+)
+
+// SecurityQuestionService administra el catálogo de preguntas de seguridad
+type SecurityQuestionService struct {
+	db                  *gorm.DB
+	policyService       *SecurityPolicyService
+	notificationService *NotificationService
+	auditService        *AuditService
+}
+
+// NewSecurityQuestionService crea una nueva instancia del servicio del catálogo
+func NewSecurityQuestionService(appCtx *config.AppContext) *SecurityQuestionService {
+	return &SecurityQuestionService{
+		db:                  appCtx.DB,
+		policyService:       NewSecurityPolicyService(appCtx),
+		notificationService: NewNotificationService(appCtx),
+		auditService:        NewAuditService(appCtx.DB),
+	}
+}
+
+// ========================================
+// CATÁLOGO
+// ========================================
+
+// ListQuestions lista el catálogo en el orden en que lo ven los usuarios
+func (s *SecurityQuestionService) ListQuestions(ctx context.Context, category string, includeInactive bool) ([]models.SecurityQuestion, error) {
+	query := s.db.WithContext(ctx)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var questions []models.SecurityQuestion
+	if err := query.Order("category, sort_order, id").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener preguntas de seguridad: %w", err)
+	}
+	return questions, nil
+}
+
+// CreateQuestion agrega una pregunta al catálogo. Sin sortOrder se ubica al final.
+func (s *SecurityQuestionService) CreateQuestion(ctx context.Context, req *models.SecurityQuestionCreateRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.SecurityQuestion, error) {
+	text := strings.TrimSpace(req.QuestionText)
+	if err := s.checkDuplicateText(ctx, text, 0); err != nil {
+		return nil, err
+	}
+
+	question := &models.SecurityQuestion{
+		QuestionText: text,
+		Category:     req.Category,
+		IsActive:     true,
+	}
+	if req.SortOrder != nil {
+		question.SortOrder = *req.SortOrder
+	} else {
+		var last int
+		err := s.db.WithContext(ctx).Model(&models.SecurityQuestion{}).
+			Select("COALESCE(MAX(sort_order), 0)").Scan(&last).Error
+		if err != nil {
+			return nil, fmt.Errorf("error al calcular el orden: %w", err)
+		}
+		question.SortOrder = last + 1
+	}
+
+	if err := s.db.WithContext(ctx).Create(question).Error; err != nil {
+		return nil, fmt.Errorf("error al crear pregunta de seguridad: %w", err)
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionCreate,
+		Resource:   "security_questions",
+		ResourceID: fmt.Sprintf("%d", question.ID),
+		NewValues:  securityQuestionToMap(question),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	logger.Info("❓ Pregunta de seguridad %d creada por %s", question.ID, admin.Email)
+
+	return question, nil
+}
+
+// UpdateQuestion edita una pregunta del catálogo. isActive=false la retira igual que
+// DeactivateQuestion; retorna cuántos usuarios deben reconfigurar sus preguntas.
+func (s *SecurityQuestionService) UpdateQuestion(ctx context.Context, questionID int, req *models.SecurityQuestionEditRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.SecurityQuestion, int, error) {
+	question, err := s.getQuestion(ctx, questionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	oldValues := securityQuestionToMap(question)
+
+	updates := map[string]interface{}{}
+	if req.QuestionText != nil {
+		text := strings.TrimSpace(*req.QuestionText)
+		if text != question.QuestionText {
+			if err := s.checkDuplicateText(ctx, text, question.ID); err != nil {
+				return nil, 0, err
+			}
+			updates["question_text"] = text
+		}
+	}
+	if req.Category != nil && *req.Category != question.Category {
+		updates["category"] = *req.Category
+	}
+	if req.SortOrder != nil && *req.SortOrder != question.SortOrder {
+		updates["sort_order"] = *req.SortOrder
+	}
+	deactivate := req.IsActive != nil && !*req.IsActive && question.IsActive
+	if req.IsActive != nil && *req.IsActive && !question.IsActive {
+		updates["is_active"] = true
+		updates["deactivated_at"] = nil
+		updates["deactivated_by"] = nil
+	}
+
+	var affected []uuid.UUID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(question).Updates(updates).Error; err != nil {
+				return fmt.Errorf("error al actualizar pregunta de seguridad: %w", err)
+			}
+		}
+		if deactivate {
+			var err error
+			affected, err = s.retire(tx, question, admin)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if question, err = s.getQuestion(ctx, questionID); err != nil {
+		return nil, 0, err
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionUpdate,
+		Resource:   "security_questions",
+		ResourceID: fmt.Sprintf("%d", question.ID),
+		OldValues:  oldValues,
+		NewValues:  securityQuestionToMap(question),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Result:     models.AuditResultSuccess,
+	})
+
+	if deactivate {
+		s.notifyRetired(ctx, question, affected)
+	}
+
+	logger.Info("❓ Pregunta de seguridad %d actualizada por %s", question.ID, admin.Email)
+
+	return question, len(affected), nil
+}
+
+// DeactivateQuestion retira una pregunta del catálogo. Las respuestas de los usuarios que
+// la usaban se desactivan (ya no se eligen en el desafío de reset) y se les pide elegir
+// otra. Retorna cuántos usuarios deben reconfigurar sus preguntas.
+func (s *SecurityQuestionService) DeactivateQuestion(ctx context.Context, questionID int, admin *models.UserProfile, ipAddress, userAgent string) (int, error) {
+	question, err := s.getQuestion(ctx, questionID)
+	if err != nil {
+		return 0, err
+	}
+	if !question.IsActive {
+		return 0, fmt.Errorf("la pregunta de seguridad ya está desactivada")
+	}
+	oldValues := securityQuestionToMap(question)
+
+	var affected []uuid.UUID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		affected, err = s.retire(tx, question, admin)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionDelete,
+		Resource:   "security_questions",
+		ResourceID: fmt.Sprintf("%d", question.ID),
+		OldValues:  oldValues,
+		NewValues: map[string]interface{}{
+			"is_active":      false,
+			"affected_users": len(affected),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	s.notifyRetired(ctx, question, affected)
+
+	logger.Info("❓ Pregunta de seguridad %d desactivada por %s (%d usuarios deben reconfigurar)", question.ID, admin.Email, len(affected))
+
+	return len(affected), nil
+}
+
+// ReorderQuestions asigna el orden de las preguntas de la categoría (o de todo el
+// catálogo). Las preguntas indicadas quedan primero, en ese orden; las demás del
+// mismo alcance les siguen en su orden actual.
+func (s *SecurityQuestionService) ReorderQuestions(ctx context.Context, req *models.SecurityQuestionReorderRequest, admin *models.UserProfile, ipAddress, userAgent string) ([]models.SecurityQuestion, error) {
+	query := s.db.WithContext(ctx)
+	if req.Category != "" {
+		query = query.Where("category = ?", req.Category)
+	}
+	var current []models.SecurityQuestion
+	if err := query.Order("sort_order, id").Find(&current).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener preguntas de seguridad: %w", err)
+	}
+
+	byID := make(map[int]bool, len(current))
+	oldOrder := make([]int, 0, len(current))
+	for _, q := range current {
+		byID[q.ID] = true
+		oldOrder = append(oldOrder, q.ID)
+	}
+
+	listed := make(map[int]bool, len(req.QuestionIDs))
+	order := make([]int, 0, len(current))
+	for _, id := range req.QuestionIDs {
+		if !byID[id] {
+			return nil, fmt.Errorf("pregunta de seguridad ID %d no encontrada", id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("la pregunta ID %d está repetida", id)
+		}
+		listed[id] = true
+		order = append(order, id)
+	}
+	for _, id := range oldOrder {
+		if !listed[id] {
+			order = append(order, id)
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range order {
+			err := tx.Model(&models.SecurityQuestion{}).Where("id = ?", id).Update("sort_order", i+1).Error
+			if err != nil {
+				return fmt.Errorf("error al reordenar preguntas de seguridad: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:    &admin.ID,
+		Action:    models.AuditActionUpdate,
+		Resource:  "security_questions",
+		OldValues: map[string]interface{}{"category": req.Category, "order": oldOrder},
+		NewValues: map[string]interface{}{"category": req.Category, "order": order},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("❓ Preguntas de seguridad reordenadas por %s", admin.Email)
+
+	return s.ListQuestions(ctx, req.Category, true)
+}
+
+// ========================================
+// ESTADÍSTICAS
+// ========================================
+
+// GetStats reporta la adopción de cada pregunta, los usuarios con menos preguntas que las
+// exigidas en el reset y la tasa de fallos de los desafíos de los últimos days días
+func (s *SecurityQuestionService) GetStats(ctx context.Context, days int) (*models.SecurityQuestionStatsResponse, error) {
+	if days <= 0 {
+		days = SecurityQuestionStatsDefaultDays
+	}
+	if days > SecurityQuestionStatsMaxDays {
+		days = SecurityQuestionStatsMaxDays
+	}
+
+	stats := &models.SecurityQuestionStatsResponse{
+		RequiredQuestions: s.policyService.Current(ctx).RequiredSecurityAnswers(),
+		Questions:         make([]models.SecurityQuestionAdoption, 0),
+		GeneratedAt:       time.Now(),
+	}
+
+	// Solo los usuarios locales pueden restablecer su contraseña con preguntas
+	eligible := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("is_active = ? AND is_service_account = ? AND auth_source = ?", true, false, models.AuthSourceLocal)
+	if err := eligible.Count(&stats.EligibleUsers).Error; err != nil {
+		return nil, fmt.Errorf("error al contar usuarios: %w", err)
+	}
+
+	activeAnswers := "(SELECT COUNT(*) FROM user_security_questions usq WHERE usq.user_id = users.id AND usq.is_active = true)"
+	err := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("is_active = ? AND is_service_account = ? AND auth_source = ?", true, false, models.AuthSourceLocal).
+		Where(activeAnswers+" < ?", stats.RequiredQuestions).
+		Count(&stats.UsersBelowRequired).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al contar usuarios sin preguntas suficientes: %w", err)
+	}
+	err = s.db.WithContext(ctx).Model(&models.User{}).
+		Where("is_active = ? AND is_service_account = ? AND auth_source = ?", true, false, models.AuthSourceLocal).
+		Where(activeAnswers + " = 0").
+		Count(&stats.UsersWithoutQuestions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al contar usuarios sin preguntas: %w", err)
+	}
+
+	// Adopción por pregunta
+	var questions []models.SecurityQuestion
+	if err := s.db.WithContext(ctx).Order("category, sort_order, id").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener preguntas de seguridad: %w", err)
+	}
+
+	var adoption []struct {
+		SecurityQuestionID int
+		Users              int64
+	}
+	err = s.db.WithContext(ctx).Table("user_security_questions usq").
+		Select("usq.security_question_id, COUNT(*) AS users").
+		Joins("JOIN users u ON u.id = usq.user_id").
+		Where("usq.is_active = ? AND u.is_active = ? AND u.is_service_account = ? AND u.auth_source = ?",
+			true, true, false, models.AuthSourceLocal).
+		Group("usq.security_question_id").
+		Scan(&adoption).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular adopción de preguntas: %w", err)
+	}
+	usersByQuestion := make(map[int]int64, len(adoption))
+	for _, row := range adoption {
+		usersByQuestion[row.SecurityQuestionID] = row.Users
+	}
+
+	// Desafíos de reset del período
+	var tokens []models.PasswordResetToken
+	err = s.db.WithContext(ctx).
+		Select("id, security_question_verified, security_question_attempts, challenge_question_ids, question_attempts").
+		Where("requires_security_question = ? AND created_at >= ?", true, time.Now().AddDate(0, 0, -days)).
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener desafíos de reset: %w", err)
+	}
+
+	resets := &stats.Resets
+	resets.PeriodDays = days
+	challengesByQuestion := make(map[int]int64)
+	failuresByQuestion := make(map[int]int64)
+	for i := range tokens {
+		token := &tokens[i]
+		resets.Challenges++
+		resets.FailedAttempts += int64(token.SecurityQuestionAttempts)
+		if token.SecurityQuestionVerified {
+			resets.Verified++
+		}
+		if token.SecurityQuestionAttempts > 0 {
+			resets.WithFailures++
+		}
+		if token.HasExceededSecurityAttempts() {
+			resets.LockedOut++
+		}
+		for _, questionID := range token.ChallengeQuestionIDs {
+			challengesByQuestion[questionID]++
+		}
+		for questionID, attempts := range token.QuestionAttempts {
+			failuresByQuestion[questionID] += int64(attempts)
+		}
+	}
+	if resets.Challenges > 0 {
+		resets.AverageFailedAttempts = float64(resets.FailedAttempts) / float64(resets.Challenges)
+		resets.FailureRate = float64(resets.WithFailures) / float64(resets.Challenges) * 100
+		resets.LockoutRate = float64(resets.LockedOut) / float64(resets.Challenges) * 100
+	}
+
+	for _, q := range questions {
+		item := models.SecurityQuestionAdoption{
+			QuestionID:     q.ID,
+			QuestionText:   q.QuestionText,
+			Category:       q.Category,
+			IsActive:       q.IsActive,
+			Users:          usersByQuestion[q.ID],
+			Challenges:     challengesByQuestion[q.ID],
+			FailedAttempts: failuresByQuestion[q.ID],
+		}
+		if stats.EligibleUsers > 0 {
+			item.AdoptionRate = float64(item.Users) / float64(stats.EligibleUsers) * 100
+		}
+		if item.Challenges > 0 {
+			item.FailureRate = float64(item.FailedAttempts) / float64(item.Challenges) * 100
+		}
+		stats.Questions = append(stats.Questions, item)
+	}
+
+	return stats, nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// retire desactiva la pregunta y las respuestas vigentes de los usuarios dentro de tx.
+// deactivated_at usa la hora de la transacción, la misma que el trigger asigna a
+// updated_at de las respuestas: así se distinguen las respuestas retiradas.
+func (s *SecurityQuestionService) retire(tx *gorm.DB, question *models.SecurityQuestion, admin *models.UserProfile) ([]uuid.UUID, error) {
+	var active int64
+	err := tx.Model(&models.SecurityQuestion{}).
+		Where("is_active = ? AND id <> ?", true, question.ID).
+		Count(&active).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al contar preguntas activas: %w", err)
+	}
+	if active < models.MaxSecurityQuestionsPerUser {
+		return nil, fmt.Errorf("el catálogo debe conservar al menos %d preguntas activas", models.MaxSecurityQuestionsPerUser)
+	}
+
+	err = tx.Model(question).Updates(map[string]interface{}{
+		"is_active":      false,
+		"deactivated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		"deactivated_by": admin.ID,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al desactivar pregunta de seguridad: %w", err)
+	}
+
+	var affected []uuid.UUID
+	err = tx.Model(&models.UserSecurityQuestion{}).
+		Where("security_question_id = ? AND is_active = ?", question.ID, true).
+		Pluck("user_id", &affected).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar usuarios afectados: %w", err)
+	}
+
+	err = tx.Model(&models.UserSecurityQuestion{}).
+		Where("security_question_id = ? AND is_active = ?", question.ID, true).
+		Update("is_active", false).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al desactivar respuestas: %w", err)
+	}
+
+	return affected, nil
+}
+
+// notifyRetired pide a los usuarios afectados que elijan otra pregunta
+func (s *SecurityQuestionService) notifyRetired(ctx context.Context, question *models.SecurityQuestion, affected []uuid.UUID) {
+	if err := s.notificationService.CreateSecurityQuestionRetiredNotifications(ctx, affected, question); err != nil {
+		logger.Warn("Error al notificar el retiro de la pregunta %d: %v", question.ID, err)
+	}
+}
+
+func (s *SecurityQuestionService) getQuestion(ctx context.Context, questionID int) (*models.SecurityQuestion, error) {
+	var question models.SecurityQuestion
+	if err := s.db.WithContext(ctx).First(&question, questionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("pregunta de seguridad no encontrada")
+		}
+		return nil, fmt.Errorf("error al buscar pregunta de seguridad: %w", err)
+	}
+	return &question, nil
+}
+
+// checkDuplicateText rechaza un texto ya usado por otra pregunta (sin distinguir mayúsculas)
+func (s *SecurityQuestionService) checkDuplicateText(ctx context.Context, text string, excludeID int) error {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.SecurityQuestion{}).
+		Where("LOWER(question_text) = LOWER(?) AND id <> ?", text, excludeID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("error al verificar pregunta de seguridad: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("ya existe una pregunta con ese texto")
+	}
+	return nil
+}
+
+func securityQuestionToMap(q *models.SecurityQuestion) map[string]interface{} {
+	return map[string]interface{}{
+		"question_text": q.QuestionText,
+		"category":      q.Category,
+		"sort_order":    q.SortOrder,
+		"is_active":     q.IsActive,
+	}
+}
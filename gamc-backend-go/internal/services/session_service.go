@@ -0,0 +1,491 @@
+// internal/services/session_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sessionActivityInterval intervalo mínimo entre escrituras de la última actividad;
+	// la inactividad se mide con esa precisión
+	sessionActivityInterval = time.Minute
+
+	// sessionExpiryGrace margen de la clave en Redis sobre la expiración de la sesión, para
+	// que el barrido periódico la encuentre y avise al cliente antes de que Redis la borre
+	sessionExpiryGrace = 5 * time.Minute
+
+	// sessionSweepLease reserva del barrido de sesiones: cada minuto lo ejecuta una sola
+	// réplica; vence antes del siguiente intervalo por si la instancia que la tomó cae
+	sessionSweepLease = 50 * time.Second
+)
+
+// Motivos de expiración informados en el evento session:expired
+const (
+	SessionExpiredIdle     = "idle"
+	SessionExpiredLifetime = "lifetime"
+)
+
+// SessionService maneja la consulta y revocación de sesiones activas
+type SessionService struct {
+	sessionManager      *redis.SessionManager
+	refreshManager      *redis.RefreshTokenManager
+	blacklistManager    *redis.JWTBlacklistManager
+	tokenManager        *redis.SessionTokenManager
+	cacheManager        *redis.CacheManager
+	eventPublisher      *redis.EventPublisher
+	jwtService          *auth.JWTService
+	policyService       *SecurityPolicyService
+	auditService        *AuditService
+	notificationService *NotificationService
+}
+
+// NewSessionService crea una nueva instancia del servicio de sesiones
+func NewSessionService(appCtx *config.AppContext) *SessionService {
+	return &SessionService{
+		sessionManager:      redis.NewSessionManager(appCtx.Redis),
+		refreshManager:      redis.NewRefreshTokenManager(appCtx.Redis),
+		blacklistManager:    redis.NewJWTBlacklistManager(appCtx.Redis),
+		tokenManager:        redis.NewSessionTokenManager(appCtx.Redis),
+		cacheManager:        redis.NewCacheManager(appCtx.Redis),
+		eventPublisher:      redis.NewEventPublisher(appCtx.Redis),
+		jwtService:          auth.NewJWTService(appCtx.Config),
+		policyService:       NewSecurityPolicyService(appCtx),
+		auditService:        NewAuditService(appCtx.DB),
+		notificationService: NewNotificationService(appCtx),
+	}
+}
+
+// TrackAccessToken registra el JTI de un access token recién emitido para poder revocarlo con la sesión
+func (s *SessionService) TrackAccessToken(ctx context.Context, sessionID, accessToken string) error {
+	claims, err := s.jwtService.ParseAccessToken(accessToken)
+	if err != nil {
+		return fmt.Errorf("error al leer access token: %w", err)
+	}
+	if claims.JTI == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.tokenManager.TrackToken(ctx, sessionID, claims.JTI, claims.ExpiresAt.Unix(), ttl)
+}
+
+// ListSessions lista las sesiones activas del usuario, marcando la sesión actual
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]models.SessionInfo, error) {
+	sessionIDs, err := s.sessionManager.GetUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener sesiones: %w", err)
+	}
+
+	sessions := make([]models.SessionInfo, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		data, err := s.sessionManager.GetSession(ctx, sessionID)
+		if err != nil || data == nil {
+			continue // expiró entre la búsqueda y la lectura
+		}
+
+		device := describeDevice(data.UserAgent)
+		if data.ClientID != "" {
+			device = fmt.Sprintf("Aplicación externa (%s)", data.ClientID)
+		}
+		if data.ImpersonatorEmail != "" {
+			device = fmt.Sprintf("Soporte (%s)", data.ImpersonatorEmail)
+		}
+
+		sessions = append(sessions, models.SessionInfo{
+			SessionID:    sessionID,
+			Device:       device,
+			IPAddress:    data.IPAddress,
+			UserAgent:    data.UserAgent,
+			CreatedAt:    data.CreatedAt,
+			LastActivity: data.LastActivity,
+			IsCurrent:    sessionID == currentSessionID,
+		})
+	}
+
+	// Sesión actual primero, luego por última actividad
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].IsCurrent != sessions[j].IsCurrent {
+			return sessions[i].IsCurrent
+		}
+		return sessions[i].LastActivity.After(sessions[j].LastActivity)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession cierra una sesión específica del usuario
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID, ipAddress, userAgent string) error {
+	data, err := s.sessionManager.GetSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("error al obtener sesión: %w", err)
+	}
+	// No se distingue entre sesión inexistente y ajena
+	if data == nil || data.UserID != userID {
+		return fmt.Errorf("sesión no encontrada")
+	}
+
+	if err := s.revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	s.logRevocation(ctx, userID, []string{sessionID}, "single", ipAddress, userAgent)
+	logger.Info("🔒 Sesión %s revocada por usuario %s", sessionID, userID)
+
+	return nil
+}
+
+// RevokeOtherSessions cierra todas las sesiones del usuario excepto la actual.
+// Retorna la cantidad de sesiones cerradas.
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID, ipAddress, userAgent string) (int, error) {
+	sessionIDs, err := s.sessionManager.GetUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener sesiones: %w", err)
+	}
+
+	revoked := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		if sessionID == currentSessionID {
+			continue
+		}
+		if err := s.revoke(ctx, userID, sessionID); err != nil {
+			logger.Warn("Error al revocar sesión %s: %v", sessionID, err)
+			continue
+		}
+		revoked = append(revoked, sessionID)
+	}
+
+	if len(revoked) > 0 {
+		s.logRevocation(ctx, userID, revoked, "others", ipAddress, userAgent)
+	}
+	logger.Info("🔒 %d sesiones revocadas para usuario %s (se conserva la actual)", len(revoked), userID)
+
+	return len(revoked), nil
+}
+
+// RevokeAllSessions cierra todas las sesiones del usuario (logout global, cambio de contraseña).
+// Retorna la cantidad de sesiones cerradas.
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	sessionIDs, err := s.sessionManager.GetUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener sesiones: %w", err)
+	}
+
+	count := 0
+	for _, sessionID := range sessionIDs {
+		if err := s.revoke(ctx, userID, sessionID); err != nil {
+			logger.Warn("Error al revocar sesión %s: %v", sessionID, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// RevokeClientSessions cierra las sesiones emitidas a un cliente OAuth.
+// Retorna la cantidad de sesiones cerradas.
+func (s *SessionService) RevokeClientSessions(ctx context.Context, userID, clientID string) (int, error) {
+	sessionIDs, err := s.sessionManager.GetUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener sesiones: %w", err)
+	}
+
+	count := 0
+	for _, sessionID := range sessionIDs {
+		data, err := s.sessionManager.GetSession(ctx, sessionID)
+		if err != nil || data == nil || data.ClientID != clientID {
+			continue
+		}
+		if err := s.revoke(ctx, userID, sessionID); err != nil {
+			logger.Warn("Error al revocar sesión %s: %v", sessionID, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// EndSession cierra una sesión sin verificaciones adicionales (logout de la sesión actual)
+func (s *SessionService) EndSession(ctx context.Context, userID, sessionID string) error {
+	return s.revoke(ctx, userID, sessionID)
+}
+
+// RevokeCompromisedSession revoca la sesión cuya familia de refresh tokens fue reutilizada,
+// lo registra en auditoría y alerta al usuario
+func (s *SessionService) RevokeCompromisedSession(ctx context.Context, userID, sessionID, ipAddress, userAgent string) {
+	if err := s.revoke(ctx, userID, sessionID); err != nil {
+		logger.Error("Error al revocar sesión comprometida %s: %v", sessionID, err)
+	}
+
+	logger.Warn("🚨 Reutilización de refresh token detectada: sesión %s del usuario %s revocada (IP %s)", sessionID, userID, ipAddress)
+
+	parsedID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &parsedID,
+		Action:     models.AuditActionTokenReuse,
+		Resource:   "session",
+		ResourceID: sessionID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		SessionID:  sessionID,
+		Result:     models.AuditResultFailure,
+		ErrorMsg:   "refresh token ya rotado presentado nuevamente",
+	})
+
+	content := fmt.Sprintf(
+		"Se detectó el uso de un token de sesión ya reemplazado desde la IP %s. Por seguridad cerramos esa sesión. Si no reconoce esta actividad, cambie su contraseña y revise sus sesiones activas.",
+		ipAddress)
+	if err := s.notificationService.CreateAlertNotification(ctx, parsedID, "Posible robo de sesión detectado", content); err != nil {
+		logger.Warn("Error al notificar reutilización de refresh token a %s: %v", userID, err)
+	}
+}
+
+// ========================================
+// INACTIVIDAD Y VIGENCIA MÁXIMA
+// ========================================
+
+// Expiry indica por qué la sesión ya no es válida: inactividad mayor a la de su rol
+// o vigencia máxima desde el login superada. Retorna "" si sigue vigente.
+func (s *SessionService) Expiry(ctx context.Context, data *redis.SessionData) string {
+	policy := s.policyService.Current(ctx)
+	now := time.Now()
+
+	if now.Sub(data.CreatedAt) >= policy.SessionMaxLifetime() {
+		return SessionExpiredLifetime
+	}
+	if now.Sub(data.LastActivity) >= policy.IdleTimeoutFor(data.Role) {
+		return SessionExpiredIdle
+	}
+	return ""
+}
+
+// SessionTTL vida de la clave de la sesión en Redis: hasta que expiraría por inactividad
+// o por vigencia máxima, lo que ocurra primero, más el margen del barrido
+func (s *SessionService) SessionTTL(ctx context.Context, data *redis.SessionData) time.Duration {
+	policy := s.policyService.Current(ctx)
+
+	ttl := policy.IdleTimeoutFor(data.Role)
+	if remaining := time.Until(data.CreatedAt.Add(policy.SessionMaxLifetime())); remaining < ttl {
+		ttl = remaining
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return ttl + sessionExpiryGrace
+}
+
+// Touch registra actividad en la sesión con ExtendSession. Escribe en Redis como
+// máximo una vez por sessionActivityInterval para no hacerlo en cada petición.
+func (s *SessionService) Touch(ctx context.Context, sessionID string, data *redis.SessionData, ttl time.Duration) error {
+	if time.Since(data.LastActivity) < sessionActivityInterval {
+		return nil
+	}
+
+	if _, err := s.sessionManager.ExtendSession(ctx, sessionID, data, ttl); err != nil {
+		return fmt.Errorf("error al extender sesión: %w", err)
+	}
+	return nil
+}
+
+// ExpireSession cierra la sesión expirada, lo registra en auditoría y avisa al cliente
+// con el evento session:expired. Si otra instancia ya la expiró no hace nada.
+func (s *SessionService) ExpireSession(ctx context.Context, data *redis.SessionData, reason string) {
+	first, err := s.cacheManager.SetIfAbsent(ctx, "session_expired:"+data.SessionID, sessionExpiryGrace)
+	if err != nil {
+		logger.Warn("Error al marcar expiración de la sesión %s: %v", data.SessionID, err)
+	} else if !first {
+		return
+	}
+
+	if err := s.revoke(ctx, data.UserID, data.SessionID); err != nil {
+		logger.Error("Error al cerrar sesión expirada %s: %v", data.SessionID, err)
+		return
+	}
+
+	logger.Info("⏰ Sesión %s de %s expirada (%s)", data.SessionID, data.Email, reason)
+
+	event := newUserEvent(data.UserID, config.EventTypeSessionExpired, map[string]interface{}{
+		"sessionId":    data.SessionID,
+		"reason":       reason,
+		"lastActivity": data.LastActivity,
+	})
+	if err := s.eventPublisher.Publish(ctx, event); err != nil {
+		logger.Warn("Error al publicar expiración de la sesión %s: %v", data.SessionID, err)
+	}
+
+	parsedID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return
+	}
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &parsedID,
+		Action:     models.AuditActionSessionExpired,
+		Resource:   "session",
+		ResourceID: data.SessionID,
+		NewValues: map[string]interface{}{
+			"reason":       reason,
+			"createdAt":    data.CreatedAt,
+			"lastActivity": data.LastActivity,
+		},
+		SessionID: data.SessionID,
+		Result:    models.AuditResultSuccess,
+	})
+}
+
+// ExpireInactiveSessions cierra las sesiones interactivas inactivas o vencidas para que
+// el cliente reciba session:expired aunque no haga más peticiones. Con varias réplicas,
+// solo la que obtiene la reserva en Redis hace el barrido.
+// Retorna la cantidad de sesiones cerradas.
+func (s *SessionService) ExpireInactiveSessions(ctx context.Context) (int, error) {
+	acquired, err := s.cacheManager.SetIfAbsent(ctx, "job_lease:session_expiry", sessionSweepLease)
+	if err != nil {
+		return 0, fmt.Errorf("error al reservar el barrido de sesiones: %w", err)
+	}
+	if !acquired {
+		return 0, nil // Otra réplica ya hizo el barrido en este intervalo
+	}
+
+	count := 0
+	err = s.sessionManager.ForEachSession(ctx, func(data *redis.SessionData) {
+		if !IsInteractiveSession(data) {
+			return
+		}
+		if reason := s.Expiry(ctx, data); reason != "" {
+			s.ExpireSession(ctx, data, reason)
+			count++
+		}
+	})
+	if err != nil {
+		return count, fmt.Errorf("error al obtener sesiones: %w", err)
+	}
+
+	if count > 0 {
+		logger.Info("⏰ %d sesiones expiradas por inactividad o vigencia máxima", count)
+	}
+	return count, nil
+}
+
+// IsInteractiveSession indica si la sesión es de un usuario en el navegador. Las de clientes
+// OAuth y las de suplantación tienen su propia vigencia y no expiran por inactividad.
+func IsInteractiveSession(data *redis.SessionData) bool {
+	return data.ClientID == "" && data.ImpersonatorID == ""
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// revoke agrega a la blacklist los access tokens vigentes de la sesión y elimina sesión, refresh token y su familia
+func (s *SessionService) revoke(ctx context.Context, userID, sessionID string) error {
+	tokens, err := s.tokenManager.GetTokens(ctx, sessionID)
+	if err != nil {
+		logger.Warn("Error al obtener tokens de la sesión %s: %v", sessionID, err)
+	}
+	for jti, exp := range tokens {
+		if err := s.blacklistManager.BlacklistToken(ctx, jti, exp); err != nil {
+			logger.Warn("Error al revocar token %s: %v", jti, err)
+		}
+	}
+
+	if err := s.refreshManager.DeleteRefreshToken(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("error al eliminar refresh token: %w", err)
+	}
+	if err := s.sessionManager.DeleteSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("error al eliminar sesión: %w", err)
+	}
+	if err := s.tokenManager.DeleteTokens(ctx, sessionID); err != nil {
+		logger.Warn("Error al eliminar registro de tokens de la sesión %s: %v", sessionID, err)
+	}
+	if err := s.refreshManager.DeleteTokenFamily(ctx, sessionID); err != nil {
+		logger.Warn("Error al eliminar familia de refresh tokens de la sesión %s: %v", sessionID, err)
+	}
+
+	return nil
+}
+
+// logRevocation registra en auditoría la revocación de sesiones
+func (s *SessionService) logRevocation(ctx context.Context, userID string, sessionIDs []string, mode, ipAddress, userAgent string) {
+	parsedID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &parsedID,
+		Action:     models.AuditActionSessionRevoked,
+		Resource:   "session",
+		ResourceID: strings.Join(sessionIDs, ","),
+		NewValues: map[string]interface{}{
+			"mode":     mode,
+			"sessions": len(sessionIDs),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+}
+
+// describeDevice genera una descripción legible del dispositivo a partir del User-Agent
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Dispositivo desconocido"
+	}
+
+	browser := "Navegador desconocido"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/") || strings.Contains(ua, "postman") || strings.Contains(ua, "go-http-client"):
+		browser = "Cliente API"
+	}
+
+	system := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		system = "iOS"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os"):
+		system = "macOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+
+	if system == "" {
+		return browser
+	}
+	return fmt.Sprintf("%s en %s", browser, system)
+}
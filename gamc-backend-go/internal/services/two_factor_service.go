@@ -0,0 +1,539 @@
+// internal/services/two_factor_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/redis"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// TwoFactorChallengeTTL vigencia del desafío entre el paso 1 y 2 del login
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// MaxTwoFactorChallengeAttempts intentos de código permitidos por desafío
+	MaxTwoFactorChallengeAttempts = 5
+)
+
+var (
+	// ErrInvalidTwoFactorCode el código TOTP o de recuperación no es válido
+	ErrInvalidTwoFactorCode = errors.New("código de verificación inválido")
+
+	// ErrTooManyTwoFactorAttempts el desafío se invalidó por exceder los intentos permitidos
+	ErrTooManyTwoFactorAttempts = errors.New("demasiados intentos fallidos - inicie sesión nuevamente")
+)
+
+// TwoFactorService maneja la autenticación de dos factores (TOTP)
+type TwoFactorService struct {
+	db               *gorm.DB
+	challengeManager *redis.TwoFactorChallengeManager
+	totpService      *auth.TOTPService
+	passwordService  *auth.PasswordService
+	policyService    *SecurityPolicyService
+	issuer           string
+}
+
+// NewTwoFactorService crea una nueva instancia del servicio de 2FA
+func NewTwoFactorService(appCtx *config.AppContext) *TwoFactorService {
+	return &TwoFactorService{
+		db:               appCtx.DB,
+		challengeManager: redis.NewTwoFactorChallengeManager(appCtx.Redis),
+		totpService:      auth.NewTOTPService(appCtx.Config.TwoFactorIssuer),
+		passwordService:  auth.NewPasswordService(),
+		policyService:    NewSecurityPolicyService(appCtx),
+		issuer:           appCtx.Config.TwoFactorIssuer,
+	}
+}
+
+// ========================================
+// ENROLAMIENTO
+// ========================================
+
+// GetStatus obtiene el estado de 2FA de un usuario
+func (s *TwoFactorService) GetStatus(ctx context.Context, userID string, role string) (*models.TwoFactorStatusResponse, error) {
+	status := &models.TwoFactorStatusResponse{
+		Required: s.IsRequiredForRole(ctx, role),
+	}
+
+	// Una passkey también satisface el requerimiento del rol
+	if err := s.activePasskeys(ctx, userID).Count(&status.Passkeys).Error; err != nil {
+		return nil, fmt.Errorf("error al contar passkeys: %w", err)
+	}
+
+	settings, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil || !settings.IsEnabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.ConfirmedAt = settings.ConfirmedAt
+
+	if err := s.db.WithContext(ctx).
+		Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesRemaining).Error; err != nil {
+		return nil, fmt.Errorf("error al contar códigos de recuperación: %w", err)
+	}
+
+	return status, nil
+}
+
+// BeginSetup genera un nuevo secreto pendiente de confirmación
+func (s *TwoFactorService) BeginSetup(ctx context.Context, userID string) (*models.TwoFactorSetupResponse, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	existing, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.IsEnabled {
+		return nil, fmt.Errorf("la autenticación de dos factores ya está activada")
+	}
+
+	secret, err := s.totpService.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reemplazar cualquier enrolamiento pendiente
+	if existing != nil {
+		existing.Secret = secret
+		existing.LastUsedStep = 0
+		if err := s.db.WithContext(ctx).Save(existing).Error; err != nil {
+			return nil, fmt.Errorf("error al guardar configuración 2FA: %w", err)
+		}
+	} else {
+		settings := &models.UserTwoFactor{
+			UserID: user.ID,
+			Secret: secret,
+		}
+		if err := s.db.WithContext(ctx).Create(settings).Error; err != nil {
+			return nil, fmt.Errorf("error al guardar configuración 2FA: %w", err)
+		}
+	}
+
+	logger.Info("🔐 Enrolamiento 2FA iniciado para usuario %s", user.Email)
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: s.totpService.ProvisioningURI(user.Email, secret),
+		Issuer:          s.issuer,
+		AccountName:     user.Email,
+	}, nil
+}
+
+// ConfirmSetup activa 2FA tras validar el primer código y genera códigos de recuperación
+func (s *TwoFactorService) ConfirmSetup(ctx context.Context, userID, code string) (*models.TwoFactorConfirmResponse, error) {
+	settings, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, fmt.Errorf("no hay enrolamiento 2FA pendiente")
+	}
+	if settings.IsEnabled {
+		return nil, fmt.Errorf("la autenticación de dos factores ya está activada")
+	}
+
+	step, ok := s.totpService.ValidateCode(settings.Secret, code, time.Now(), settings.LastUsedStep)
+	if !ok {
+		return nil, fmt.Errorf("código de verificación inválido")
+	}
+
+	codes, err := s.totpService.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		settings.IsEnabled = true
+		settings.ConfirmedAt = &now
+		settings.LastUsedStep = step
+		if err := tx.Save(settings).Error; err != nil {
+			return fmt.Errorf("error al activar 2FA: %w", err)
+		}
+
+		return s.replaceRecoveryCodes(tx, settings.UserID, codes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("✅ 2FA activado para usuario %s", userID)
+
+	return &models.TwoFactorConfirmResponse{
+		Enabled:       true,
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Disable desactiva 2FA verificando contraseña y código
+func (s *TwoFactorService) Disable(ctx context.Context, userID string, req *models.TwoFactorDisableRequest) error {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("usuario no encontrado")
+		}
+		return fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	if s.IsRequiredForRole(ctx, user.Role) {
+		hasPasskey, err := s.HasPasskey(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !hasPasskey {
+			return fmt.Errorf("su rol requiere autenticación de dos factores")
+		}
+	}
+
+	if err := s.passwordService.ComparePassword(req.Password, user.PasswordHash); err != nil {
+		return fmt.Errorf("contraseña incorrecta")
+	}
+
+	if err := s.VerifyCode(ctx, userID, req.Code); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("error al eliminar códigos de recuperación: %w", err)
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return fmt.Errorf("error al desactivar 2FA: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("🔓 2FA desactivado para usuario %s", user.Email)
+	return nil
+}
+
+// RegenerateRecoveryCodes invalida los códigos anteriores y genera nuevos
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	settings, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil || !settings.IsEnabled {
+		return nil, fmt.Errorf("la autenticación de dos factores no está activada")
+	}
+
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.totpService.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.replaceRecoveryCodes(tx, settings.UserID, codes)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("🔐 Códigos de recuperación regenerados para usuario %s", userID)
+	return codes, nil
+}
+
+// ========================================
+// VERIFICACIÓN
+// ========================================
+
+// IsEnabled indica si el usuario tiene 2FA activo
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	settings, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return settings != nil && settings.IsEnabled, nil
+}
+
+// VerifyCode valida un código TOTP o, si no tiene formato TOTP, un código de recuperación
+func (s *TwoFactorService) VerifyCode(ctx context.Context, userID, code string) error {
+	settings, err := s.getConfig(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || !settings.IsEnabled {
+		return fmt.Errorf("la autenticación de dos factores no está activada")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == auth.TOTPDigits {
+		step, ok := s.totpService.ValidateCode(settings.Secret, code, time.Now(), settings.LastUsedStep)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		// Registrar el paso usado para impedir reutilizar el mismo código. La condición hace
+		// que, entre peticiones concurrentes con el mismo código, solo una lo consuma.
+		result := s.db.WithContext(ctx).
+			Model(&models.UserTwoFactor{}).
+			Where("id = ? AND (last_used_step IS NULL OR last_used_step < ?)", settings.ID, step).
+			Update("last_used_step", step)
+		if result.Error != nil {
+			return fmt.Errorf("error al registrar uso de código: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			logger.Warn("🚨 Código 2FA reutilizado para usuario %s", userID)
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	return s.consumeRecoveryCode(ctx, userID, code)
+}
+
+// HasPasskey indica si el usuario tiene passkeys vigentes, que valen como segundo factor
+func (s *TwoFactorService) HasPasskey(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	if err := s.activePasskeys(ctx, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("error al verificar passkeys: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ========================================
+// DESAFÍOS DE LOGIN
+// ========================================
+
+// CreateChallenge emite un token de desafío tras validar la contraseña
+func (s *TwoFactorService) CreateChallenge(ctx context.Context, userID, ipAddress, userAgent string) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error al generar desafío: %w", err)
+	}
+	token := hex.EncodeToString(bytes)
+
+	challenge := &redis.TwoFactorChallenge{
+		UserID:    userID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.challengeManager.SaveChallenge(ctx, token, challenge, TwoFactorChallengeTTL); err != nil {
+		return "", fmt.Errorf("error al guardar desafío: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifyChallenge valida el código contra el desafío y retorna el usuario asociado
+func (s *TwoFactorService) VerifyChallenge(ctx context.Context, token, code string) (string, error) {
+	challenge, err := s.challengeManager.GetChallenge(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("error al obtener desafío: %w", err)
+	}
+	if challenge == nil {
+		return "", fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+
+	// El intento se reserva antes de verificar el código: peticiones concurrentes sobre
+	// el mismo desafío no pueden superar MaxTwoFactorChallengeAttempts
+	attempt, err := s.challengeManager.ReserveAttempt(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("error al registrar intento 2FA: %w", err)
+	}
+	if attempt == 0 {
+		return "", fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+	if attempt > MaxTwoFactorChallengeAttempts {
+		s.challengeManager.DeleteChallenge(ctx, token)
+		return "", ErrTooManyTwoFactorAttempts
+	}
+
+	if err := s.VerifyCode(ctx, challenge.UserID, code); err != nil {
+		if attempt >= MaxTwoFactorChallengeAttempts {
+			s.challengeManager.DeleteChallenge(ctx, token)
+			logger.Warn("🚨 Desafío 2FA invalidado por intentos fallidos para usuario %s", challenge.UserID)
+			return "", ErrTooManyTwoFactorAttempts
+		}
+		return "", err
+	}
+
+	// El desafío es de un solo uso
+	consumed, err := s.challengeManager.ConsumeChallenge(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("error al consumir desafío: %w", err)
+	}
+	if !consumed {
+		return "", fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+	return challenge.UserID, nil
+}
+
+// GetChallenge obtiene el desafío sin consumirlo, para emitir las opciones de la passkey
+func (s *TwoFactorService) GetChallenge(ctx context.Context, token string) (*redis.TwoFactorChallenge, error) {
+	challenge, err := s.challengeManager.GetChallenge(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener desafío: %w", err)
+	}
+	if challenge == nil {
+		return nil, fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+	return challenge, nil
+}
+
+// CompleteChallenge consume el desafío tras verificar el segundo factor con una passkey
+func (s *TwoFactorService) CompleteChallenge(ctx context.Context, token, userID string) error {
+	challenge, err := s.GetChallenge(ctx, token)
+	if err != nil {
+		return err
+	}
+	if challenge.UserID != userID {
+		return fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+
+	consumed, err := s.challengeManager.ConsumeChallenge(ctx, token)
+	if err != nil {
+		return fmt.Errorf("error al consumir desafío: %w", err)
+	}
+	if !consumed {
+		return fmt.Errorf("desafío 2FA inválido o expirado")
+	}
+	return nil
+}
+
+// ========================================
+// REQUERIMIENTO POR ROL
+// ========================================
+
+// IsRequiredForRole indica si el rol debe usar 2FA
+func (s *TwoFactorService) IsRequiredForRole(ctx context.Context, role string) bool {
+	// La política de seguridad puede exigir 2FA a todos los roles
+	if s.policyService.Current(ctx).TwoFactorRequired {
+		return true
+	}
+
+	var requirement models.RoleTwoFactorRequirement
+	err := s.db.WithContext(ctx).Where("role = ?", role).First(&requirement).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Error al consultar requerimiento 2FA del rol %s: %v", role, err)
+		}
+		return false
+	}
+	return requirement.Required
+}
+
+// GetRoleRequirements lista el requerimiento de 2FA de cada rol
+func (s *TwoFactorService) GetRoleRequirements(ctx context.Context) ([]models.RoleTwoFactorRequirement, error) {
+	var requirements []models.RoleTwoFactorRequirement
+	if err := s.db.WithContext(ctx).Order("role").Find(&requirements).Error; err != nil {
+		return nil, fmt.Errorf("error al obtener requerimientos 2FA: %w", err)
+	}
+	return requirements, nil
+}
+
+// SetRoleRequirement define si un rol debe usar 2FA
+func (s *TwoFactorService) SetRoleRequirement(ctx context.Context, req *models.RoleTwoFactorUpdateRequest, adminID uuid.UUID) (*models.RoleTwoFactorRequirement, error) {
+	if !models.IsValidRole(req.Role) {
+		return nil, fmt.Errorf("rol inválido")
+	}
+
+	requirement := &models.RoleTwoFactorRequirement{
+		Role:      req.Role,
+		Required:  req.Required,
+		UpdatedBy: &adminID,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.db.WithContext(ctx).Save(requirement).Error; err != nil {
+		return nil, fmt.Errorf("error al actualizar requerimiento 2FA: %w", err)
+	}
+
+	logger.Info("🔐 Requerimiento 2FA para rol %s actualizado a %v por %s", req.Role, req.Required, adminID)
+	return requirement, nil
+}
+
+// ========================================
+// FUNCIONES AUXILIARES PRIVADAS
+// ========================================
+
+// getConfig obtiene la configuración 2FA del usuario (nil si no existe)
+func (s *TwoFactorService) getConfig(ctx context.Context, userID string) (*models.UserTwoFactor, error) {
+	var settings models.UserTwoFactor
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener configuración 2FA: %w", err)
+	}
+	return &settings, nil
+}
+
+// activePasskeys consulta las passkeys no revocadas del usuario
+func (s *TwoFactorService) activePasskeys(ctx context.Context, userID string) *gorm.DB {
+	return s.db.WithContext(ctx).
+		Model(&models.WebAuthnCredential{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+}
+
+// consumeRecoveryCode marca como usado un código de recuperación válido
+func (s *TwoFactorService) consumeRecoveryCode(ctx context.Context, userID, code string) error {
+	codeHash := s.totpService.HashRecoveryCode(code)
+
+	result := s.db.WithContext(ctx).
+		Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error al verificar código de recuperación: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	logger.Warn("🔑 Código de recuperación 2FA utilizado por usuario %s", userID)
+	return nil
+}
+
+// replaceRecoveryCodes reemplaza los códigos de recuperación dentro de una transacción
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("error al eliminar códigos de recuperación: %w", err)
+	}
+
+	recoveryCodes := make([]models.UserRecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		recoveryCodes = append(recoveryCodes, models.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: s.totpService.HashRecoveryCode(code),
+		})
+	}
+
+	if err := tx.Create(&recoveryCodes).Error; err != nil {
+		return fmt.Errorf("error al guardar códigos de recuperación: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// internal/services/user_admin_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gamc-backend-go/internal/auth"
+	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/database/models"
+	"gamc-backend-go/internal/types/requests"
+	"gamc-backend-go/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserAdminService administra las cuentas de usuario desde el panel de administración:
+// alta con contraseña temporal, cambio de contraseña exigido y cuentas temporales
+type UserAdminService struct {
+	db              *gorm.DB
+	passwordService *auth.PasswordService
+	passwordPolicy  *PasswordPolicyService
+	sessionService  *SessionService
+	auditService    *AuditService
+}
+
+// NewUserAdminService crea una nueva instancia del servicio de administración de usuarios
+func NewUserAdminService(appCtx *config.AppContext) *UserAdminService {
+	return &UserAdminService{
+		db:              appCtx.DB,
+		passwordService: auth.NewPasswordService(),
+		passwordPolicy:  NewPasswordPolicyService(appCtx),
+		sessionService:  NewSessionService(appCtx),
+		auditService:    NewAuditService(appCtx.DB),
+	}
+}
+
+// CreateUser crea una cuenta local con el email ya verificado. Con MustChangePassword
+// el usuario cambia la contraseña asignada en su primer login.
+func (s *UserAdminService) CreateUser(ctx context.Context, req *requests.CreateUserRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.UserProfile, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("la fecha de expiración debe ser futura")
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("email = ? OR username = ?", req.Email, req.Username).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar usuario existente: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("ya existe un usuario con ese email o username")
+	}
+
+	var orgUnit models.OrganizationalUnit
+	err = s.db.WithContext(ctx).Where("id = ? AND is_active = ?", req.OrganizationalUnitID, true).First(&orgUnit).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("unidad organizacional no encontrada")
+		}
+		return nil, fmt.Errorf("error al verificar unidad organizacional: %w", err)
+	}
+
+	if isValid, validationErrors := s.passwordService.IsValidPassword(req.Password); !isValid {
+		return nil, fmt.Errorf("contraseña inválida: %v", validationErrors)
+	}
+
+	passwordHash, err := s.passwordService.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error al hashear contraseña: %w", err)
+	}
+
+	now := time.Now()
+	user := models.User{
+		Username:             req.Username,
+		Email:                req.Email,
+		PasswordHash:         passwordHash,
+		FirstName:            req.FirstName,
+		LastName:             req.LastName,
+		Role:                 req.Role,
+		OrganizationalUnitID: &req.OrganizationalUnitID,
+		IsActive:             req.IsActive,
+		PasswordChangedAt:    now,
+		EmailVerifiedAt:      &now, // Alta hecha por un administrador: el email no requiere confirmación
+		MustChangePassword:   req.MustChangePassword,
+		ExpiresAt:            req.ExpiresAt,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("error al crear usuario: %w", err)
+		}
+		return s.passwordPolicy.RecordPassword(ctx, tx, user.ID, passwordHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, &LogRequest{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionCreate,
+		Resource:   "user",
+		ResourceID: user.ID.String(),
+		NewValues: map[string]interface{}{
+			"email":                user.Email,
+			"role":                 user.Role,
+			"organizationalUnitId": req.OrganizationalUnitID,
+			"isActive":             user.IsActive,
+			"mustChangePassword":   user.MustChangePassword,
+			"expiresAt":            user.ExpiresAt,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Result:    models.AuditResultSuccess,
+	})
+
+	logger.Info("👤 Usuario %s creado por administrador %s", user.Email, admin.Email)
+
+	user.OrganizationalUnit = &orgUnit
+	return user.ToProfile(), nil
+}
+
+// UpdateUser actualiza los datos de la cuenta. Desactivarla o exigir el cambio de
+// contraseña cierra las sesiones abiertas para que rija desde el próximo login.
+func (s *UserAdminService) UpdateUser(ctx context.Context, userID uuid.UUID, req *requests.UpdateUserRequest, admin *models.UserProfile, ipAddress, userAgent string) (*models.UserProfile, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("id = ? AND is_service_account = ?", userID, false).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+	previous := make(map[string]interface{})
+	set := func(column string, oldValue, newValue interface{}) {
+		previous[column] = oldValue
+		updates[column] = newValue
+	}
+
+	if req.Email != "" && req.Email != user.Email {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.User{}).Where("email = ?", req.Email).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("error al verificar email: %w", err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("ya existe un usuario con ese email o username")
+		}
+		set("email", user.Email, req.Email)
+	}
+	if req.FirstName != "" && req.FirstName != user.FirstName {
+		set("first_name", user.FirstName, req.FirstName)
+	}
+	if req.LastName != "" && req.LastName != user.LastName {
+		set("last_name", user.LastName, req.LastName)
+	}
+	if req.Role != "" && req.Role != user.Role {
+		if user.ID == admin.ID {
+			return nil, fmt.Errorf("no puede cambiar su propio rol")
+		}
+		set("role", user.Role, req.Role)
+	}
+	if req.OrganizationalUnitID != nil && (user.OrganizationalUnitID == nil || *req.OrganizationalUnitID != *user.OrganizationalUnitID) {
+		var count int64
+		err := s.db.WithContext(ctx).Model(&models.OrganizationalUnit{}).
+			Where("id = ? AND is_active = ?", *req.OrganizationalUnitID, true).
+			Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("error al verificar unidad organizacional: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("unidad organizacional no encontrada")
+		}
+		set("organizational_unit_id", user.OrganizationalUnitID, *req.OrganizationalUnitID)
+	}
+
+	if req.MustChangePassword != nil && *req.MustChangePassword != user.MustChangePassword {
+		if *req.MustChangePassword && user.IsDirectoryUser() {
+			return nil, fmt.Errorf("la contraseña de los usuarios del directorio se cambia en el directorio institucional")
+		}
+		set("must_change_password", user.MustChangePassword, *req.MustChangePassword)
+	}
+
+	expiresAt := user.ExpiresAt
+	switch {
+	case req.ClearExpiresAt:
+		if user.ExpiresAt != nil {
+			set("expires_at", user.ExpiresAt, nil)
+		}
+		expiresAt = nil
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("la fecha de expiración debe ser futura")
+		}
+		set("expires_at", user.ExpiresAt, *req.ExpiresAt)
+		expiresAt = req.ExpiresAt
+	}
+
+	if req.IsActive != nil && *req.IsActive != user.IsActive {
+		if !*req.IsActive && user.ID == admin.ID {
+			return nil, fmt.Errorf("no puede desactivar su propia cuenta")
+		}
+		// Una cuenta temporal vencida volvería a desactivarse en la tarea nocturna
+		if *req.IsActive && expiresAt != nil && !time.Now().Before(*expiresAt) {
+			return nil, fmt.Errorf("la cuenta expiró: indique una nueva fecha de expiración para reactivarla")
+		}
+		set("is_active", user.IsActive, *req.IsActive)
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(&user).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("error al actualizar usuario: %w", err)
+		}
+
+		deactivated := updates["is_active"] == false
+		mustChange := updates["must_change_password"] == true
+		if deactivated || mustChange {
+			if _, err := s.sessionService.RevokeAllSessions(ctx, user.ID.String()); err != nil {
+				logger.Warn("Error al cerrar sesiones de %s: %v", user.Email, err)
+			}
+		}
+
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &admin.ID,
+			Action:     models.AuditActionUpdate,
+			Resource:   "user",
+			ResourceID: user.ID.String(),
+			OldValues:  previous,
+			NewValues:  updates,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			Result:     models.AuditResultSuccess,
+		})
+
+		logger.Info("👤 Usuario %s actualizado por administrador %s", user.Email, admin.Email)
+	}
+
+	var updated models.User
+	err = s.db.WithContext(ctx).Preload("OrganizationalUnit").Where("id = ?", user.ID).First(&updated).Error
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener usuario: %w", err)
+	}
+	return updated.ToProfile(), nil
+}
+
+// DeactivateExpiredAccounts desactiva las cuentas temporales vencidas y cierra sus sesiones.
+// Retorna la cantidad de cuentas desactivadas.
+func (s *UserAdminService) DeactivateExpiredAccounts(ctx context.Context) (int, error) {
+	var users []models.User
+	err := s.db.WithContext(ctx).
+		Where("is_active = ? AND expires_at <= ?", true, time.Now()).
+		Find(&users).Error
+	if err != nil {
+		return 0, fmt.Errorf("error al buscar cuentas expiradas: %w", err)
+	}
+
+	count := 0
+	for i := range users {
+		user := &users[i]
+
+		result := s.db.WithContext(ctx).Model(&models.User{}).
+			Where("id = ? AND is_active = ?", user.ID, true).
+			Update("is_active", false)
+		if result.Error != nil {
+			logger.Error("Error al desactivar cuenta expirada %s: %v", user.Email, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if _, err := s.sessionService.RevokeAllSessions(ctx, user.ID.String()); err != nil {
+			logger.Warn("Error al cerrar sesiones de la cuenta expirada %s: %v", user.Email, err)
+		}
+
+		s.auditService.Log(ctx, &LogRequest{
+			UserID:     &user.ID,
+			Action:     models.AuditActionAccountExpired,
+			Resource:   "user",
+			ResourceID: user.ID.String(),
+			OldValues:  map[string]interface{}{"isActive": true},
+			NewValues: map[string]interface{}{
+				"isActive":  false,
+				"expiresAt": user.ExpiresAt,
+			},
+			Result: models.AuditResultSuccess,
+		})
+
+		logger.Info("⌛ Cuenta temporal %s desactivada (expiró %s)", user.Email, user.ExpiresAt.Format(time.RFC3339))
+		count++
+	}
+
+	return count, nil
+}
@@ -2,12 +2,17 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"sync"
+	"time"
 
 	"gamc-backend-go/internal/config"
+	"gamc-backend-go/internal/redis"
 	"gamc-backend-go/pkg/logger"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -80,3 +85,50 @@ func (ws *WebSocketService) BroadcastMessage(message interface{}) {
 		}
 	}
 }
+
+// RelayEvents forwards the events published by any backend instance to the
+// connected users until ctx is cancelled
+func (ws *WebSocketService) RelayEvents(ctx context.Context, publisher *redis.EventPublisher) {
+	pubsub := publisher.Subscribe(ctx)
+	defer pubsub.Close()
+
+	events := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+
+			var event config.WebSocketMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				logger.Error("Invalid WebSocket event: %v", err)
+				continue
+			}
+
+			if event.UserID == "" {
+				ws.BroadcastMessage(&event)
+				continue
+			}
+			if err := ws.SendMessage(event.UserID, &event); err != nil {
+				logger.Error("Error sending event %s to user %s: %v", event.Type, event.UserID, err)
+			}
+		}
+	}
+}
+
+// newUserEvent builds an event addressed to a single user
+func newUserEvent(userID string, eventType config.WebSocketEventType, data interface{}) *config.WebSocketMessage {
+	return &config.WebSocketMessage{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Timestamp: time.Now(),
+		UserID:    userID,
+		Data:      data,
+		Metadata: map[string]interface{}{
+			"room": config.GetUserRoom(userID),
+		},
+	}
+}
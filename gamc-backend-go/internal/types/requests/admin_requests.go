@@ -11,7 +11,7 @@ import (
 type CreateUserRequest struct {
 	Username             string     `json:"username" binding:"required,min=3,max=50,alphanum"`
 	Email                string     `json:"email" binding:"required,email"`
-	Password             string     `json:"password" binding:"required,min=8"`
+	Password             string     `json:"password" binding:"required"`
 	FirstName            string     `json:"firstName" binding:"required,min=2,max=50"`
 	LastName             string     `json:"lastName" binding:"required,min=2,max=50"`
 	Role                 string     `json:"role" binding:"required,oneof=admin input output"`
@@ -35,6 +35,8 @@ type UpdateUserRequest struct {
 	IsActive             *bool      `json:"isActive,omitempty"`
 	MustChangePassword   *bool      `json:"mustChangePassword,omitempty"`
 	ExpiresAt            *time.Time `json:"expiresAt,omitempty"`
+	// Quita la fecha de expiración (la cuenta deja de ser temporal)
+	ClearExpiresAt bool `json:"clearExpiresAt,omitempty"`
 }
 
 // UserFilterRequest filtros para listar usuarios
@@ -56,7 +58,7 @@ type UserFilterRequest struct {
 // ResetUserPasswordRequest resetear contraseña de usuario
 type ResetUserPasswordRequest struct {
 	UserID      uuid.UUID `json:"userId" binding:"required"`
-	NewPassword string    `json:"newPassword" binding:"required,min=8"`
+	NewPassword string    `json:"newPassword" binding:"required"`
 	Notify      bool      `json:"notify"`
 }
 
@@ -129,17 +131,27 @@ type BulkUserActionRequest struct {
 
 // SecurityPolicyRequest política de seguridad
 type SecurityPolicyRequest struct {
-	MinPasswordLength      int      `json:"minPasswordLength" binding:"min=8,max=32"`
-	RequireUppercase       bool     `json:"requireUppercase"`
-	RequireLowercase       bool     `json:"requireLowercase"`
-	RequireNumbers         bool     `json:"requireNumbers"`
-	RequireSpecialChars    bool     `json:"requireSpecialChars"`
-	PasswordExpirationDays int      `json:"passwordExpirationDays" binding:"min=0,max=365"`
-	MaxLoginAttempts       int      `json:"maxLoginAttempts" binding:"min=3,max=10"`
-	LockoutDurationMinutes int      `json:"lockoutDurationMinutes" binding:"min=5,max=1440"`
-	SessionTimeoutMinutes  int      `json:"sessionTimeoutMinutes" binding:"min=5,max=480"`
-	TwoFactorRequired      bool     `json:"twoFactorRequired"`
-	IPWhitelist            []string `json:"ipWhitelist,omitempty"`
+	MinPasswordLength         int  `json:"minPasswordLength" binding:"min=8,max=32"`
+	RequireUppercase          bool `json:"requireUppercase"`
+	RequireLowercase          bool `json:"requireLowercase"`
+	RequireNumbers            bool `json:"requireNumbers"`
+	RequireSpecialChars       bool `json:"requireSpecialChars"`
+	BlockCommonPasswords      bool `json:"blockCommonPasswords"`
+	BlockSequentialChars      bool `json:"blockSequentialChars"`
+	PasswordHistoryCount      int  `json:"passwordHistoryCount" binding:"min=0,max=24"`
+	PasswordExpirationDays    int  `json:"passwordExpirationDays" binding:"min=0,max=365"`
+	PasswordExpiryWarningDays int  `json:"passwordExpiryWarningDays" binding:"min=0,max=30"`
+	// 0 conserva el valor por defecto (una respuesta)
+	ResetSecurityAnswersRequired int `json:"resetSecurityAnswersRequired" binding:"min=0,max=2"`
+	MaxLoginAttempts             int `json:"maxLoginAttempts" binding:"min=3,max=10"`
+	LockoutDurationMinutes       int `json:"lockoutDurationMinutes" binding:"min=5,max=1440"`
+	SessionTimeoutMinutes        int `json:"sessionTimeoutMinutes" binding:"min=5,max=480"`
+	// Inactividad por rol (admin, input, output); los roles ausentes usan sessionTimeoutMinutes
+	RoleSessionTimeouts map[string]int `json:"roleSessionTimeouts,omitempty" binding:"omitempty,dive,keys,oneof=admin input output,endkeys,min=5,max=480"`
+	// 0 conserva el valor por defecto (12 horas); no puede superar la vigencia del refresh token
+	SessionMaxLifetimeHours int      `json:"sessionMaxLifetimeHours" binding:"min=0,max=168"`
+	TwoFactorRequired       bool     `json:"twoFactorRequired"`
+	IPWhitelist             []string `json:"ipWhitelist,omitempty" binding:"omitempty,dive,cidr|ip"`
 }
 
 // NotificationTemplateRequest plantilla de notificación
@@ -25,6 +25,8 @@ type MessageResponse struct {
 	Attachments     []AttachmentSummary  `json:"attachments"`
 	AttachmentCount int                  `json:"attachmentCount"`
 	ResponseCount   int                  `json:"responseCount"`
+	ParentID        *int64               `json:"parentId,omitempty"` // Mensaje al que responde
+	Replies         []MessageResponse    `json:"replies,omitempty"`  // Respuestas anidadas (hilos)
 	CreatedAt       time.Time            `json:"createdAt"`
 	UpdatedAt       time.Time            `json:"updatedAt"`
 }
@@ -151,9 +153,29 @@ type MessageTrend struct {
 
 // MessageThreadResponse hilo de conversación
 type MessageThreadResponse struct {
-	OriginalMessage MessageResponse   `json:"originalMessage"`
-	Responses       []MessageResponse `json:"responses"`
-	TotalResponses  int               `json:"totalResponses"`
+	OriginalMessage MessageResponse     `json:"originalMessage"`
+	Responses       []MessageResponse   `json:"responses"` // Respuestas directas, cada una con las suyas
+	TotalResponses  int                 `json:"totalResponses"`
+	Participants    []ThreadParticipant `json:"participants"`
+	Units           []ThreadUnitSummary `json:"units"`
+	UnreadCount     int                 `json:"unreadCount"` // No leídos por la unidad del usuario
+	LastActivityAt  time.Time           `json:"lastActivityAt"`
+}
+
+// ThreadParticipant usuario que escribió en el hilo
+type ThreadParticipant struct {
+	User          UserSummary         `json:"user"`
+	Unit          OrganizationSummary `json:"unit"`
+	MessageCount  int                 `json:"messageCount"`
+	LastMessageAt time.Time           `json:"lastMessageAt"`
+}
+
+// ThreadUnitSummary mensajes enviados, recibidos y sin leer de una unidad en el hilo
+type ThreadUnitSummary struct {
+	Unit     OrganizationSummary `json:"unit"`
+	Sent     int                 `json:"sent"`
+	Received int                 `json:"received"`
+	Unread   int                 `json:"unread"`
 }
 
 // MessageSearchResponse respuesta de búsqueda
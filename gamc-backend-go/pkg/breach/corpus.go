@@ -0,0 +1,104 @@
+// pkg/breach/corpus.go
+package breach
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CorpusFormat formato de las líneas del corpus de origen
+type CorpusFormat int
+
+const (
+	// FormatSHA1 "HASH[:OCURRENCIAS]" con el SHA-1 en hexadecimal (formato de Have I Been Pwned)
+	FormatSHA1 CorpusFormat = iota
+	// FormatPlain una contraseña en texto plano por línea (listas tipo rockyou)
+	FormatPlain
+)
+
+// CorpusStats resumen de la lectura de un corpus
+type CorpusStats struct {
+	Lines   uint64 // Líneas leídas
+	Added   uint64 // Digests entregados al callback
+	Skipped uint64 // Líneas vacías o por debajo del mínimo de ocurrencias
+}
+
+// ReadCorpus recorre el corpus y entrega cada digest a fn. En FormatSHA1 se
+// descartan las entradas con menos de minCount ocurrencias (0 = todas).
+func ReadCorpus(r io.Reader, format CorpusFormat, minCount uint64, fn func(Digest)) (CorpusStats, error) {
+	var stats CorpusStats
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		stats.Lines++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if format == FormatPlain {
+			if line == "" {
+				stats.Skipped++
+				continue
+			}
+			fn(Hash(line))
+			stats.Added++
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			stats.Skipped++
+			continue
+		}
+		digest, count, err := ParseHashLine(line)
+		if err != nil {
+			return stats, fmt.Errorf("línea %d: %w", stats.Lines, err)
+		}
+		if minCount > 0 && count < minCount {
+			stats.Skipped++
+			continue
+		}
+		fn(digest)
+		stats.Added++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("error leyendo corpus: %w", err)
+	}
+	return stats, nil
+}
+
+// ParseHashLine interpreta una línea "HASH[:OCURRENCIAS]". Sin contador se asume 1.
+func ParseHashLine(line string) (Digest, uint64, error) {
+	var digest Digest
+
+	hashPart, countPart, hasCount := strings.Cut(line, ":")
+	if len(hashPart) != hex.EncodedLen(len(digest)) {
+		return digest, 0, fmt.Errorf("hash SHA-1 inválido %q", truncate(hashPart, 48))
+	}
+	if _, err := hex.Decode(digest[:], []byte(hashPart)); err != nil {
+		return digest, 0, fmt.Errorf("hash SHA-1 inválido %q", truncate(hashPart, 48))
+	}
+
+	count := uint64(1)
+	if hasCount {
+		parsed, err := strconv.ParseUint(strings.TrimSpace(countPart), 10, 64)
+		if err != nil {
+			return digest, 0, fmt.Errorf("contador de ocurrencias inválido %q", truncate(countPart, 24))
+		}
+		count = parsed
+	}
+
+	return digest, count, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,236 @@
+// pkg/breach/filter.go
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+)
+
+// fileMagic identifica el formato del archivo del filtro (versión 1)
+var fileMagic = [8]byte{'G', 'A', 'M', 'C', 'B', 'L', 'M', '1'}
+
+const (
+	// headerSize bytes de la cabecera: magic, m, k y count
+	headerSize = 28
+	// maxFilterBits tamaño máximo aceptado al leer un filtro (4 GiB), holgado para el
+	// corpus completo de HIBP con una tasa de 1e-6
+	maxFilterBits = 1 << 35
+)
+
+// Digest hash SHA-1 de una contraseña, el formato de los corpus de contraseñas filtradas
+type Digest [sha1.Size]byte
+
+// Hash calcula el digest de una contraseña en texto plano
+func Hash(password string) Digest {
+	return sha1.Sum([]byte(password))
+}
+
+// Filter filtro de Bloom sobre digests SHA-1. No tiene falsos negativos: si una
+// contraseña del corpus se consulta, Contains siempre responde true. Los falsos
+// positivos ocurren con la probabilidad elegida al construirlo.
+type Filter struct {
+	bits  []uint64
+	m     uint64 // Cantidad de bits
+	k     uint32 // Cantidad de funciones hash
+	count uint64 // Digests agregados
+}
+
+// NewFilter dimensiona un filtro para n elementos con la tasa de falsos positivos indicada
+func NewFilter(n uint64, falsePositiveRate float64) (*Filter, error) {
+	if n == 0 {
+		return nil, errors.New("el filtro debe admitir al menos un elemento")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("la tasa de falsos positivos debe estar entre 0 y 1")
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) / 64 * 64
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &Filter{bits: make([]uint64, m/64), m: m, k: k}, nil
+}
+
+// Add agrega un digest al filtro
+func (f *Filter) Add(d Digest) {
+	h1, h2 := split(d)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.count++
+}
+
+// Contains indica si el digest probablemente pertenece al corpus
+func (f *Filter) Contains(d Digest) bool {
+	h1, h2 := split(d)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsPassword indica si la contraseña probablemente aparece en el corpus
+func (f *Filter) ContainsPassword(password string) bool {
+	return f.Contains(Hash(password))
+}
+
+// Count retorna la cantidad de digests agregados
+func (f *Filter) Count() uint64 {
+	return f.count
+}
+
+// SizeBytes retorna el tamaño del filtro en memoria
+func (f *Filter) SizeBytes() uint64 {
+	return f.m / 8
+}
+
+// FalsePositiveRate estima la tasa de falsos positivos con los elementos actuales
+func (f *Filter) FalsePositiveRate() float64 {
+	k, m, n := float64(f.k), float64(f.m), float64(f.count)
+	return math.Pow(1-math.Exp(-k*n/m), k)
+}
+
+// split deriva los dos hashes del esquema de doble hashing (Kirsch-Mitzenmacher).
+// SHA-1 ya es uniforme: basta con tomar dos bloques de 64 bits del digest.
+func split(d Digest) (uint64, uint64) {
+	return binary.BigEndian.Uint64(d[0:8]), binary.BigEndian.Uint64(d[8:16]) | 1
+}
+
+// ========================================
+// PERSISTENCIA
+// ========================================
+
+// WriteTo serializa el filtro: cabecera, bits en little-endian y CRC32 de los bits
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriterSize(w, 1<<20)
+	crc := crc32.NewIEEE()
+	out := io.MultiWriter(bw, crc)
+
+	var header [headerSize]byte
+	copy(header[0:8], fileMagic[:])
+	binary.LittleEndian.PutUint64(header[8:16], f.m)
+	binary.LittleEndian.PutUint32(header[16:20], f.k)
+	binary.LittleEndian.PutUint64(header[20:28], f.count)
+	if _, err := bw.Write(header[:]); err != nil {
+		return 0, err
+	}
+
+	var word [8]byte
+	for _, bits := range f.bits {
+		binary.LittleEndian.PutUint64(word[:], bits)
+		if _, err := out.Write(word[:]); err != nil {
+			return 0, err
+		}
+	}
+
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc.Sum32())
+	if _, err := bw.Write(sum[:]); err != nil {
+		return 0, err
+	}
+
+	return int64(len(header)) + int64(f.m/8) + int64(len(sum)), bw.Flush()
+}
+
+// ReadFilter lee un filtro serializado con WriteTo
+func ReadFilter(r io.Reader) (*Filter, error) {
+	return readFilter(r, -1)
+}
+
+// readFilter lee el filtro validando la cabecera antes de reservar memoria para los bits.
+// Si se conoce el tamaño del archivo (size >= 0), la cabecera debe coincidir con él.
+func readFilter(r io.Reader, size int64) (*Filter, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+
+	var header [headerSize]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("cabecera del filtro incompleta: %w", err)
+	}
+	if [8]byte(header[0:8]) != fileMagic {
+		return nil, errors.New("el archivo no es un filtro de contraseñas filtradas")
+	}
+
+	f := &Filter{
+		m:     binary.LittleEndian.Uint64(header[8:16]),
+		k:     binary.LittleEndian.Uint32(header[16:20]),
+		count: binary.LittleEndian.Uint64(header[20:28]),
+	}
+	if f.m == 0 || f.m%64 != 0 || f.k == 0 {
+		return nil, errors.New("cabecera del filtro inválida")
+	}
+	if f.m > maxFilterBits {
+		return nil, fmt.Errorf("cabecera del filtro inválida: %d bits supera el máximo de %d", f.m, uint64(maxFilterBits))
+	}
+	if size >= 0 && uint64(size) != headerSize+f.m/8+4 {
+		return nil, fmt.Errorf("filtro truncado: el archivo tiene %d bytes y la cabecera indica %d", size, headerSize+f.m/8+4)
+	}
+
+	f.bits = make([]uint64, f.m/64)
+	crc := crc32.NewIEEE()
+	var word [8]byte
+	for i := range f.bits {
+		if _, err := io.ReadFull(br, word[:]); err != nil {
+			return nil, fmt.Errorf("filtro truncado: %w", err)
+		}
+		crc.Write(word[:])
+		f.bits[i] = binary.LittleEndian.Uint64(word[:])
+	}
+
+	var sum [4]byte
+	if _, err := io.ReadFull(br, sum[:]); err != nil {
+		return nil, fmt.Errorf("filtro truncado: %w", err)
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != crc.Sum32() {
+		return nil, errors.New("el filtro está dañado (CRC incorrecto)")
+	}
+
+	return f, nil
+}
+
+// LoadFile lee un filtro desde disco
+func LoadFile(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return readFilter(file, info.Size())
+}
+
+// SaveFile escribe el filtro en disco de forma atómica (archivo temporal y rename)
+func (f *Filter) SaveFile(path string) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteTo(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,218 @@
+// pkg/breach/filter_test.go
+package breach
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testElements = 20000
+	testFPRate   = 0.01
+)
+
+// newTestFilter construye un filtro con testElements contraseñas deterministas
+func newTestFilter(t *testing.T) *Filter {
+	t.Helper()
+	f, err := NewFilter(testElements, testFPRate)
+	if err != nil {
+		t.Fatalf("NewFilter: %v", err)
+	}
+	for i := 0; i < testElements; i++ {
+		f.Add(Hash(fmt.Sprintf("filtrada-%d", i)))
+	}
+	return f
+}
+
+// serialize escribe el filtro con WriteTo y retorna los bytes
+func serialize(t *testing.T, f *Filter) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo retornó %d bytes, se escribieron %d", n, buf.Len())
+	}
+	return buf.Bytes()
+}
+
+func TestFilterHasNoFalseNegatives(t *testing.T) {
+	f := newTestFilter(t)
+	for i := 0; i < testElements; i++ {
+		password := fmt.Sprintf("filtrada-%d", i)
+		if !f.ContainsPassword(password) {
+			t.Fatalf("ContainsPassword(%q) = false para una contraseña agregada", password)
+		}
+	}
+	if f.Count() != testElements {
+		t.Errorf("Count = %d, se esperaba %d", f.Count(), testElements)
+	}
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	f := newTestFilter(t)
+
+	const queries = 200000
+	positives := 0
+	for i := 0; i < queries; i++ {
+		if f.ContainsPassword(fmt.Sprintf("ausente-%d", i)) {
+			positives++
+		}
+	}
+
+	observed := float64(positives) / queries
+	if observed < testFPRate/2 || observed > testFPRate*1.5 {
+		t.Errorf("tasa de falsos positivos observada = %.4f, se esperaba cerca de %.4f", observed, testFPRate)
+	}
+	if estimated := f.FalsePositiveRate(); estimated < testFPRate/2 || estimated > testFPRate*1.5 {
+		t.Errorf("FalsePositiveRate = %.4f, se esperaba cerca de %.4f", estimated, testFPRate)
+	}
+}
+
+func TestNewFilterRejectsInvalidParameters(t *testing.T) {
+	for _, rate := range []float64{0, 1, -0.1, 1.5} {
+		if _, err := NewFilter(100, rate); err == nil {
+			t.Errorf("NewFilter(100, %v) no retornó error", rate)
+		}
+	}
+	if _, err := NewFilter(0, testFPRate); err == nil {
+		t.Error("NewFilter(0) no retornó error")
+	}
+}
+
+func TestFilterRoundTrip(t *testing.T) {
+	f := newTestFilter(t)
+
+	read, err := ReadFilter(bytes.NewReader(serialize(t, f)))
+	if err != nil {
+		t.Fatalf("ReadFilter: %v", err)
+	}
+	if read.m != f.m || read.k != f.k || read.Count() != f.Count() {
+		t.Errorf("cabecera leída m=%d k=%d count=%d, se esperaba m=%d k=%d count=%d",
+			read.m, read.k, read.Count(), f.m, f.k, f.Count())
+	}
+	for i := range f.bits {
+		if read.bits[i] != f.bits[i] {
+			t.Fatalf("bits[%d] = %x, se esperaba %x", i, read.bits[i], f.bits[i])
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+	if err := f.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !loaded.ContainsPassword("filtrada-0") || loaded.Count() != f.Count() {
+		t.Errorf("LoadFile: filtro distinto del guardado")
+	}
+}
+
+func TestReadFilterRejectsCorruptFiles(t *testing.T) {
+	data := serialize(t, newTestFilter(t))
+
+	tests := []struct {
+		name    string
+		corrupt func([]byte) []byte
+		message string
+	}{
+		{
+			name:    "cabecera incompleta",
+			corrupt: func(b []byte) []byte { return b[:10] },
+			message: "cabecera del filtro incompleta",
+		},
+		{
+			name:    "bits truncados",
+			corrupt: func(b []byte) []byte { return b[:len(b)/2] },
+			message: "filtro truncado",
+		},
+		{
+			name:    "sin CRC",
+			corrupt: func(b []byte) []byte { return b[:len(b)-4] },
+			message: "filtro truncado",
+		},
+		{
+			name: "magic incorrecto",
+			corrupt: func(b []byte) []byte {
+				b[0] = 'X'
+				return b
+			},
+			message: "no es un filtro",
+		},
+		{
+			name: "bit alterado",
+			corrupt: func(b []byte) []byte {
+				b[headerSize+100] ^= 0x04
+				return b
+			},
+			message: "CRC incorrecto",
+		},
+		{
+			name: "CRC alterado",
+			corrupt: func(b []byte) []byte {
+				b[len(b)-1] ^= 0x80
+				return b
+			},
+			message: "CRC incorrecto",
+		},
+		{
+			name: "m no múltiplo de 64",
+			corrupt: func(b []byte) []byte {
+				binary.LittleEndian.PutUint64(b[8:16], 100)
+				return b
+			},
+			message: "cabecera del filtro inválida",
+		},
+		{
+			name: "m desmesurado",
+			corrupt: func(b []byte) []byte {
+				binary.LittleEndian.PutUint64(b[8:16], 1<<62)
+				return b
+			},
+			message: "supera el máximo",
+		},
+		{
+			name: "k cero",
+			corrupt: func(b []byte) []byte {
+				binary.LittleEndian.PutUint32(b[16:20], 0)
+				return b
+			},
+			message: "cabecera del filtro inválida",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted := tt.corrupt(bytes.Clone(data))
+			_, err := ReadFilter(bytes.NewReader(corrupted))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("ReadFilter = %v, se esperaba un error con %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestLoadFileChecksSizeBeforeAllocating(t *testing.T) {
+	data := serialize(t, newTestFilter(t))
+
+	// Cabecera que declara un filtro más grande que el archivo: se rechaza sin leer los bits
+	binary.LittleEndian.PutUint64(data[8:16], 1<<34)
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	_, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "filtro truncado") {
+		t.Errorf("LoadFile = %v, se esperaba rechazo por tamaño", err)
+	}
+}
@@ -0,0 +1,186 @@
+// pkg/introspection/client.go
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Cliente del endpoint de introspección de GAMC (RFC 7662) para los servicios que
+// reciben access tokens emitidos por el backend. Solo depende de la librería estándar.
+//
+//	client := introspection.NewClient("https://gamc.example/api/v1/auth/introspect", "mi-servicio", secreto)
+//	http.Handle("/api/", client.Middleware(apiHandler))
+
+// DefaultTimeout tiempo máximo de una consulta si no se indica otro
+const DefaultTimeout = 5 * time.Second
+
+// ErrInactiveToken el token no está vigente (expirado, revocado o desconocido)
+var ErrInactiveToken = errors.New("introspection: token inactivo")
+
+// ErrInvalidClient el backend rechazó las credenciales del cliente
+var ErrInvalidClient = errors.New("introspection: credenciales de cliente inválidas")
+
+// Error respuesta de error del endpoint (RFC 6749 §5.2)
+type Error struct {
+	Status      int
+	Code        string
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("introspection: %s (%d): %s", e.Code, e.Status, e.Description)
+	}
+	return fmt.Sprintf("introspection: %s (%d)", e.Code, e.Status)
+}
+
+// Is permite comparar con ErrInvalidClient mediante errors.Is
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalidClient && e.Code == "invalid_client"
+}
+
+// Actor administrador que actúa en nombre del sujeto (suplantación de soporte)
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// Result datos del token según el backend. Si Active es false el resto viene vacío.
+type Result struct {
+	Active               bool   `json:"active"`
+	Scope                string `json:"scope,omitempty"`
+	ClientID             string `json:"client_id,omitempty"`
+	Username             string `json:"username,omitempty"`
+	TokenType            string `json:"token_type,omitempty"`
+	Exp                  int64  `json:"exp,omitempty"`
+	Iat                  int64  `json:"iat,omitempty"`
+	Subject              string `json:"sub,omitempty"`
+	Issuer               string `json:"iss,omitempty"`
+	JTI                  string `json:"jti,omitempty"`
+	SessionID            string `json:"sid,omitempty"`
+	Role                 string `json:"role,omitempty"`
+	OrganizationalUnitID int    `json:"organizationalUnitId,omitempty"`
+	Actor                *Actor `json:"act,omitempty"`
+}
+
+// Scopes retorna los scopes del token como lista
+func (r *Result) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+// HasScope indica si el token incluye el scope
+func (r *Result) HasScope(scope string) bool {
+	for _, s := range r.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiresAt momento en que expira el token (cero si el backend no lo informó)
+func (r *Result) ExpiresAt() time.Time {
+	if r.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(r.Exp, 0)
+}
+
+// IsImpersonated indica si un administrador actúa en nombre del usuario
+func (r *Result) IsImpersonated() bool {
+	return r.Actor != nil
+}
+
+// Client consulta el endpoint de introspección con las credenciales de un cliente
+// confidencial registrado en /api/v1/admin/oauth/clients. Es seguro para uso concurrente.
+type Client struct {
+	Endpoint     string
+	ClientID     string
+	ClientSecret string
+
+	// HTTPClient opcional; por defecto uno con DefaultTimeout
+	HTTPClient *http.Client
+}
+
+// NewClient crea un cliente con el timeout por defecto
+func NewClient(endpoint, clientID, clientSecret string) *Client {
+	return &Client{
+		Endpoint:     endpoint,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		HTTPClient:   &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Introspect consulta el estado del token. Un token inactivo no es un error:
+// se retorna Result con Active en false. Use Validate si prefiere un error.
+func (c *Client) Introspect(ctx context.Context, token string) (*Result, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("introspection: petición inválida: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: error de conexión: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("introspection: error al leer respuesta: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{Status: resp.StatusCode, Code: "server_error"}
+		var payload struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+			apiErr.Code = payload.Error
+			apiErr.Description = payload.ErrorDescription
+		}
+		return nil, apiErr
+	}
+
+	var result Result
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("introspection: respuesta inválida: %w", err)
+	}
+	return &result, nil
+}
+
+// Validate es Introspect pero retorna ErrInactiveToken si el token no está vigente
+func (c *Client) Validate(ctx context.Context, token string) (*Result, error) {
+	result, err := c.Introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Active {
+		return nil, ErrInactiveToken
+	}
+	return result, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
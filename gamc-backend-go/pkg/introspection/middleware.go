@@ -0,0 +1,74 @@
+// pkg/introspection/middleware.go
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// FromContext obtiene el resultado de la introspección guardado por Middleware
+func FromContext(ctx context.Context) (*Result, bool) {
+	result, ok := ctx.Value(contextKey{}).(*Result)
+	return result, ok
+}
+
+// Middleware exige un Bearer token vigente y deja el Result en el contexto de la petición.
+// Responde 401 si el token falta o está inactivo, y 503 si el backend no pudo responder.
+func (c *Client) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			writeError(w, http.StatusUnauthorized, "invalid_request", "token de acceso requerido")
+			return
+		}
+
+		result, err := c.Validate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, ErrInactiveToken) {
+				writeError(w, http.StatusUnauthorized, "invalid_token", "token inválido o expirado")
+				return
+			}
+			writeError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "no se pudo validar el token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, result)))
+	})
+}
+
+// RequireScope rechaza con 403 las peticiones cuyo token no incluye el scope.
+// Debe ir dentro de Middleware.
+func RequireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := FromContext(r.Context())
+		if !ok || !result.HasScope(scope) {
+			writeError(w, http.StatusForbidden, "insufficient_scope", "el token no incluye el scope "+scope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken extrae el token de la cabecera Authorization
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// writeError responde con el formato de error de RFC 6750
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		w.Header().Set("WWW-Authenticate", `Bearer error="`+code+`"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": description})
+}
@@ -0,0 +1,250 @@
+// pkg/ldap/ber.go
+package ldap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Codificación BER mínima para LDAPv3 (RFC 4511): etiquetas de un byte y
+// longitudes definidas. Acepta longitudes no mínimas (Active Directory usa 0x84).
+
+const (
+	classUniversal   byte = 0x00
+	classApplication byte = 0x40
+	classContext     byte = 0x80
+
+	typeConstructed byte = 0x20
+
+	tagBoolean     byte = 0x01
+	tagInteger     byte = 0x02
+	tagOctetString byte = 0x04
+	tagNull        byte = 0x05
+	tagEnumerated  byte = 0x0a
+	tagSequence    byte = 0x10
+	tagSet         byte = 0x11
+
+	// maxPacketSize límite de un mensaje LDAP recibido
+	maxPacketSize = 16 << 20
+)
+
+// packet elemento BER decodificado
+type packet struct {
+	class       byte
+	constructed bool
+	tag         byte
+	value       []byte
+	children    []*packet
+}
+
+// is verifica la clase y etiqueta del elemento
+func (p *packet) is(class, tag byte) bool {
+	return p.class == class && p.tag == tag
+}
+
+// str retorna el contenido como cadena
+func (p *packet) str() string {
+	return string(p.value)
+}
+
+// int decodifica un INTEGER o ENUMERATED en complemento a dos
+func (p *packet) int() int64 {
+	var v int64
+	for i, b := range p.value {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+// bool decodifica un BOOLEAN (cualquier valor distinto de cero es verdadero)
+func (p *packet) bool() bool {
+	return len(p.value) > 0 && p.value[0] != 0
+}
+
+// child retorna el hijo i o nil si no existe
+func (p *packet) child(i int) *packet {
+	if i < 0 || i >= len(p.children) {
+		return nil
+	}
+	return p.children[i]
+}
+
+// ========================================
+// CODIFICACIÓN
+// ========================================
+
+// berElement codifica un elemento con su identificador y contenido
+func berElement(identifier byte, content []byte) []byte {
+	out := make([]byte, 0, len(content)+6)
+	out = append(out, identifier)
+	out = append(out, berLength(len(content))...)
+	return append(out, content...)
+}
+
+// berLength codifica la longitud en forma corta o larga mínima
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for v := n; v > 0; v >>= 8 {
+		digits = append([]byte{byte(v)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// berConstructed codifica un elemento construido a partir de sus hijos ya codificados
+func berConstructed(identifier byte, children ...[]byte) []byte {
+	var content []byte
+	for _, child := range children {
+		content = append(content, child...)
+	}
+	return berElement(identifier|typeConstructed, content)
+}
+
+// berSequence codifica un SEQUENCE universal
+func berSequence(children ...[]byte) []byte {
+	return berConstructed(classUniversal|tagSequence, children...)
+}
+
+// berString codifica un OCTET STRING con el identificador indicado
+func berString(identifier byte, s string) []byte {
+	return berElement(identifier, []byte(s))
+}
+
+// berOctetString codifica un OCTET STRING universal
+func berOctetString(s string) []byte {
+	return berString(classUniversal|tagOctetString, s)
+}
+
+// berInteger codifica un INTEGER o ENUMERATED en complemento a dos mínimo
+func berInteger(identifier byte, v int64) []byte {
+	var content []byte
+	for {
+		content = append([]byte{byte(v)}, content...)
+		if (v < 0x80 && v >= -0x80) || len(content) == 8 {
+			break
+		}
+		v >>= 8
+	}
+	return berElement(identifier, content)
+}
+
+// berBoolean codifica un BOOLEAN
+func berBoolean(b bool) []byte {
+	if b {
+		return berElement(classUniversal|tagBoolean, []byte{0xff})
+	}
+	return berElement(classUniversal|tagBoolean, []byte{0x00})
+}
+
+// ========================================
+// DECODIFICACIÓN
+// ========================================
+
+// parsePacket decodifica un elemento completo y retorna los bytes consumidos
+func parsePacket(data []byte) (*packet, int, error) {
+	if len(data) < 2 {
+		return nil, 0, fmt.Errorf("ldap: elemento BER truncado")
+	}
+
+	identifier := data[0]
+	if identifier&0x1f == 0x1f {
+		return nil, 0, fmt.Errorf("ldap: etiquetas BER de más de un byte no soportadas")
+	}
+
+	length, header, err := parseLength(data[1:])
+	if err != nil {
+		return nil, 0, err
+	}
+	header++
+
+	if length > len(data)-header {
+		return nil, 0, fmt.Errorf("ldap: elemento BER truncado")
+	}
+
+	p := &packet{
+		class:       identifier & 0xc0,
+		constructed: identifier&typeConstructed != 0,
+		tag:         identifier & 0x1f,
+		value:       data[header : header+length],
+	}
+
+	if p.constructed {
+		for rest := p.value; len(rest) > 0; {
+			child, n, err := parsePacket(rest)
+			if err != nil {
+				return nil, 0, err
+			}
+			p.children = append(p.children, child)
+			rest = rest[n:]
+		}
+	}
+
+	return p, header + length, nil
+}
+
+// parseLength decodifica la longitud y retorna cuántos bytes ocupó
+func parseLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, fmt.Errorf("ldap: longitud BER truncada")
+	}
+	first := data[0]
+	if first < 0x80 {
+		return int(first), 1, nil
+	}
+
+	size := int(first & 0x7f)
+	if size == 0 {
+		return 0, 0, fmt.Errorf("ldap: longitud BER indefinida no soportada")
+	}
+	if size > 4 || size >= len(data) {
+		return 0, 0, fmt.Errorf("ldap: longitud BER inválida")
+	}
+
+	length := 0
+	for _, b := range data[1 : size+1] {
+		length = length<<8 | int(b)
+	}
+	if length > maxPacketSize {
+		return 0, 0, fmt.Errorf("ldap: mensaje demasiado grande (%d bytes)", length)
+	}
+	return length, size + 1, nil
+}
+
+// readPacket lee un mensaje BER completo desde la conexión
+func readPacket(r *bufio.Reader) (*packet, error) {
+	header := make([]byte, 2, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if header[1]&0x80 != 0 {
+		extra := make([]byte, int(header[1]&0x7f))
+		if len(extra) > 4 {
+			return nil, fmt.Errorf("ldap: longitud BER inválida")
+		}
+		if _, err := io.ReadFull(r, extra); err != nil {
+			return nil, err
+		}
+		header = append(header, extra...)
+	}
+
+	length, _, err := parseLength(header[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, len(header)+length)
+	copy(data, header)
+	if _, err := io.ReadFull(r, data[len(header):]); err != nil {
+		return nil, err
+	}
+
+	p, _, err := parsePacket(data)
+	return p, err
+}
@@ -0,0 +1,336 @@
+// pkg/ldap/client.go
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Operaciones del protocolo (etiquetas APPLICATION de RFC 4511)
+const (
+	opBindRequest      byte = 0
+	opBindResponse     byte = 1
+	opUnbindRequest    byte = 2
+	opSearchRequest    byte = 3
+	opSearchEntry      byte = 4
+	opSearchDone       byte = 5
+	opSearchReference  byte = 19
+	opExtendedRequest  byte = 23
+	opExtendedResponse byte = 24
+
+	oidStartTLS = "1.3.6.1.4.1.1466.20037"
+)
+
+// Códigos de resultado usados por el cliente y el servidor en memoria
+const (
+	ResultSuccess            = 0
+	ResultProtocolError      = 2
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+	ResultUnwillingToPerform = 53
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultLDAPPort  = "389"
+	defaultLDAPSPort = "636"
+)
+
+// Alcances de búsqueda
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// ErrInvalidCredentials el servidor rechazó el DN o la contraseña (resultCode 49)
+var ErrInvalidCredentials = errors.New("ldap: credenciales inválidas")
+
+// Error resultado distinto de éxito retornado por el servidor
+type Error struct {
+	ResultCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: resultado %d", e.ResultCode)
+	}
+	return fmt.Sprintf("ldap: resultado %d: %s", e.ResultCode, e.Message)
+}
+
+// Is permite comparar con ErrInvalidCredentials mediante errors.Is
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalidCredentials && e.ResultCode == ResultInvalidCredentials
+}
+
+// DialOptions opciones de conexión
+type DialOptions struct {
+	Timeout   time.Duration
+	StartTLS  bool        // Eleva ldap:// a TLS antes de autenticar
+	TLSConfig *tls.Config // Usado por ldaps:// y StartTLS
+}
+
+// SearchRequest parámetros de búsqueda
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     string
+	Attributes []string
+	SizeLimit  int
+}
+
+// Conn conexión LDAPv3 sincrónica: una operación a la vez
+type Conn struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	nextID  int64
+	timeout time.Duration
+}
+
+// Dial abre una conexión a ldap://host[:puerto] o ldaps://host[:puerto]
+func Dial(rawURL string, opts DialOptions) (*Conn, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: URL inválida: %w", err)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	host := parsed.Host
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = parsed.Hostname()
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+
+	switch parsed.Scheme {
+	case "ldap":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(host, defaultLDAPPort)
+		}
+		conn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(host, defaultLDAPSPort)
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	default:
+		return nil, fmt.Errorf("ldap: esquema no soportado: %q", parsed.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap: error al conectar con %s: %w", host, err)
+	}
+
+	c := &Conn{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: timeout,
+	}
+
+	if opts.StartTLS && parsed.Scheme == "ldap" {
+		if err := c.startTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Bind autentica la conexión con un bind simple. Las contraseñas vacías se rechazan:
+// el servidor las trataría como bind anónimo exitoso (RFC 4513 §5.1.2).
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return ErrInvalidCredentials
+	}
+
+	op := berConstructed(classApplication|opBindRequest,
+		berInteger(classUniversal|tagInteger, 3),
+		berOctetString(dn),
+		berString(classContext|0, password),
+	)
+
+	return c.roundTrip(op, func(resp *packet) (bool, error) {
+		if !resp.is(classApplication, opBindResponse) {
+			return false, fmt.Errorf("ldap: respuesta inesperada a bind")
+		}
+		return true, resultError(resp)
+	})
+}
+
+// Search ejecuta una búsqueda y retorna todas las entradas encontradas
+func (c *Conn) Search(req *SearchRequest) ([]*Entry, error) {
+	filter, err := ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([][]byte, 0, len(req.Attributes))
+	for _, attr := range req.Attributes {
+		attributes = append(attributes, berOctetString(attr))
+	}
+
+	op := berConstructed(classApplication|opSearchRequest,
+		berOctetString(req.BaseDN),
+		berInteger(classUniversal|tagEnumerated, int64(req.Scope)),
+		berInteger(classUniversal|tagEnumerated, 0), // derefAliases: never
+		berInteger(classUniversal|tagInteger, int64(req.SizeLimit)),
+		berInteger(classUniversal|tagInteger, int64(c.timeout/time.Second)),
+		berBoolean(false),
+		filter.encode(),
+		berSequence(attributes...),
+	)
+
+	var entries []*Entry
+	err = c.roundTrip(op, func(resp *packet) (bool, error) {
+		switch {
+		case resp.is(classApplication, opSearchEntry):
+			entry, err := decodeEntry(resp)
+			if err != nil {
+				return false, err
+			}
+			entries = append(entries, entry)
+			return false, nil
+		case resp.is(classApplication, opSearchReference):
+			return false, nil // Las referencias a otros servidores no se siguen
+		case resp.is(classApplication, opSearchDone):
+			return true, resultError(resp)
+		default:
+			return false, fmt.Errorf("ldap: respuesta inesperada a búsqueda")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Close envía unbind y cierra la conexión
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	msg := berSequence(
+		berInteger(classUniversal|tagInteger, c.nextID),
+		berElement(classApplication|opUnbindRequest, nil),
+	)
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	c.conn.Write(msg)
+
+	return c.conn.Close()
+}
+
+// startTLS negocia TLS sobre la conexión ya abierta
+func (c *Conn) startTLS(config *tls.Config) error {
+	op := berConstructed(classApplication|opExtendedRequest,
+		berString(classContext|0, oidStartTLS),
+	)
+
+	err := c.roundTrip(op, func(resp *packet) (bool, error) {
+		if !resp.is(classApplication, opExtendedResponse) {
+			return false, fmt.Errorf("ldap: respuesta inesperada a StartTLS")
+		}
+		return true, resultError(resp)
+	})
+	if err != nil {
+		return fmt.Errorf("ldap: StartTLS rechazado: %w", err)
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	tlsConn.SetDeadline(time.Now().Add(c.timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("ldap: error en handshake TLS: %w", err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// roundTrip envía una operación y entrega cada respuesta a handle hasta que indique fin
+func (c *Conn) roundTrip(op []byte, handle func(*packet) (bool, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	messageID := c.nextID
+	msg := berSequence(berInteger(classUniversal|tagInteger, messageID), op)
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if _, err := c.conn.Write(msg); err != nil {
+		return fmt.Errorf("ldap: error al enviar: %w", err)
+	}
+
+	for {
+		resp, err := readPacket(c.reader)
+		if err != nil {
+			return fmt.Errorf("ldap: error al leer respuesta: %w", err)
+		}
+		if len(resp.children) < 2 {
+			return fmt.Errorf("ldap: mensaje mal formado")
+		}
+		if resp.children[0].int() != messageID {
+			continue // Notificaciones no solicitadas (ej. aviso de desconexión)
+		}
+
+		done, err := handle(resp.children[1])
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// resultError convierte un LDAPResult en error (nil si es éxito)
+func resultError(resp *packet) error {
+	if len(resp.children) < 3 {
+		return fmt.Errorf("ldap: resultado mal formado")
+	}
+	code := int(resp.children[0].int())
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{ResultCode: code, Message: resp.children[2].str()}
+}
+
+// decodeEntry decodifica un SearchResultEntry
+func decodeEntry(resp *packet) (*Entry, error) {
+	if len(resp.children) < 2 {
+		return nil, fmt.Errorf("ldap: entrada mal formada")
+	}
+
+	entry := NewEntry(resp.children[0].str(), nil)
+	for _, attr := range resp.children[1].children {
+		if len(attr.children) < 2 {
+			continue
+		}
+		name := attr.children[0].str()
+		for _, value := range attr.children[1].children {
+			entry.Attributes[name] = append(entry.Attributes[name], value.str())
+		}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,121 @@
+// pkg/ldap/entry.go
+package ldap
+
+import (
+	"strings"
+)
+
+// Entry entrada del directorio con sus atributos
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// NewEntry crea una entrada con los atributos indicados
+func NewEntry(dn string, attributes map[string][]string) *Entry {
+	if attributes == nil {
+		attributes = make(map[string][]string)
+	}
+	return &Entry{DN: dn, Attributes: attributes}
+}
+
+// GetAttributeValues retorna los valores del atributo (nombre sin distinguir mayúsculas)
+func (e *Entry) GetAttributeValues(name string) []string {
+	if values, ok := e.Attributes[name]; ok {
+		return values
+	}
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// GetAttributeValue retorna el primer valor del atributo o cadena vacía
+func (e *Entry) GetAttributeValue(name string) string {
+	values := e.GetAttributeValues(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// ========================================
+// DISTINGUISHED NAMES
+// ========================================
+
+// NormalizeDN normaliza un DN para compararlo: minúsculas y sin espacios alrededor de separadores
+func NormalizeDN(dn string) string {
+	rdns := splitDN(dn)
+	for i, rdn := range rdns {
+		if attr, value, ok := strings.Cut(rdn, "="); ok {
+			rdns[i] = strings.ToLower(strings.TrimSpace(attr)) + "=" + strings.ToLower(strings.TrimSpace(value))
+		} else {
+			rdns[i] = strings.ToLower(strings.TrimSpace(rdn))
+		}
+	}
+	return strings.Join(rdns, ",")
+}
+
+// EqualDN compara dos DN sin distinguir mayúsculas ni espacios
+func EqualDN(a, b string) bool {
+	return NormalizeDN(a) == NormalizeDN(b)
+}
+
+// FirstRDNValue retorna el valor del primer RDN (ej. "gamc-admins" de "cn=gamc-admins,ou=groups,...")
+func FirstRDNValue(dn string) string {
+	rdns := splitDN(dn)
+	if len(rdns) == 0 {
+		return ""
+	}
+	_, value, ok := strings.Cut(rdns[0], "=")
+	if !ok {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimSpace(value), `\,`, ",")
+}
+
+// ParentDN retorna el DN del contenedor de la entrada
+func ParentDN(dn string) string {
+	rdns := splitDN(dn)
+	if len(rdns) <= 1 {
+		return ""
+	}
+	return strings.Join(rdns[1:], ",")
+}
+
+// IsDescendantDN verifica si dn está dentro de base (o es la misma entrada)
+func IsDescendantDN(dn, base string) bool {
+	if base == "" {
+		return true
+	}
+	dn, base = NormalizeDN(dn), NormalizeDN(base)
+	return dn == base || strings.HasSuffix(dn, ","+base)
+}
+
+// splitDN separa los RDN respetando comas escapadas
+func splitDN(dn string) []string {
+	var rdns []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range dn {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			current.WriteRune(r)
+			escaped = true
+		case r == ',':
+			rdns = append(rdns, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 || len(rdns) > 0 {
+		rdns = append(rdns, current.String())
+	}
+	return rdns
+}
@@ -0,0 +1,342 @@
+// pkg/ldap/filter.go
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// filterOp tipo de filtro de búsqueda (RFC 4515)
+type filterOp byte
+
+// Los valores coinciden con las etiquetas de contexto del protocolo
+const (
+	filterAnd            filterOp = 0
+	filterOr             filterOp = 1
+	filterNot            filterOp = 2
+	filterEquality       filterOp = 3
+	filterSubstrings     filterOp = 4
+	filterGreaterOrEqual filterOp = 5
+	filterLessOrEqual    filterOp = 6
+	filterPresent        filterOp = 7
+	filterApprox         filterOp = 8
+)
+
+// Filter filtro de búsqueda LDAP ya interpretado
+type Filter struct {
+	op       filterOp
+	attr     string
+	value    string
+	initial  string
+	any      []string
+	final    string
+	children []*Filter
+}
+
+// ParseFilter interpreta un filtro en notación de cadena, ej. (&(objectClass=person)(mail=x))
+func ParseFilter(s string) (*Filter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("ldap: filtro vacío")
+	}
+	if s[0] != '(' {
+		s = "(" + s + ")"
+	}
+
+	f, rest, err := parseFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("ldap: contenido inesperado después del filtro: %q", rest)
+	}
+	return f, nil
+}
+
+// EscapeFilter escapa un valor para incluirlo en un filtro
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, `\%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseFilter interpreta un filtro entre paréntesis y retorna el resto de la cadena
+func parseFilter(s string) (*Filter, string, error) {
+	if len(s) < 3 || s[0] != '(' {
+		return nil, "", fmt.Errorf("ldap: filtro mal formado: %q", s)
+	}
+
+	switch s[1] {
+	case '&', '|':
+		op := filterAnd
+		if s[1] == '|' {
+			op = filterOr
+		}
+		f := &Filter{op: op}
+		rest := s[2:]
+		for {
+			rest = strings.TrimLeft(rest, " ")
+			if rest == "" {
+				return nil, "", fmt.Errorf("ldap: filtro sin cerrar")
+			}
+			if rest[0] == ')' {
+				return f, rest[1:], nil
+			}
+			child, next, err := parseFilter(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			f.children = append(f.children, child)
+			rest = next
+		}
+	case '!':
+		child, rest, err := parseFilter(strings.TrimLeft(s[2:], " "))
+		if err != nil {
+			return nil, "", err
+		}
+		if rest == "" || rest[0] != ')' {
+			return nil, "", fmt.Errorf("ldap: filtro NOT sin cerrar")
+		}
+		return &Filter{op: filterNot, children: []*Filter{child}}, rest[1:], nil
+	}
+
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return nil, "", fmt.Errorf("ldap: filtro sin cerrar")
+	}
+	f, err := parseItem(s[1:end])
+	if err != nil {
+		return nil, "", err
+	}
+	return f, s[end+1:], nil
+}
+
+// parseItem interpreta una comparación atributo-valor
+func parseItem(item string) (*Filter, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("ldap: comparación inválida: %q", item)
+	}
+
+	attr, raw := item[:eq], item[eq+1:]
+	op := filterEquality
+	switch attr[len(attr)-1] {
+	case '>':
+		op, attr = filterGreaterOrEqual, attr[:len(attr)-1]
+	case '<':
+		op, attr = filterLessOrEqual, attr[:len(attr)-1]
+	case '~':
+		op, attr = filterApprox, attr[:len(attr)-1]
+	}
+	attr = strings.TrimSpace(attr)
+	if attr == "" {
+		return nil, fmt.Errorf("ldap: comparación sin atributo: %q", item)
+	}
+
+	if op == filterEquality && raw == "*" {
+		return &Filter{op: filterPresent, attr: attr}, nil
+	}
+
+	if op == filterEquality && strings.Contains(raw, "*") {
+		parts := strings.Split(raw, "*")
+		f := &Filter{op: filterSubstrings, attr: attr}
+		for i, part := range parts {
+			value, err := unescapeFilterValue(part)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case i == 0:
+				f.initial = value
+			case i == len(parts)-1:
+				f.final = value
+			case value != "":
+				f.any = append(f.any, value)
+			}
+		}
+		return f, nil
+	}
+
+	value, err := unescapeFilterValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{op: op, attr: attr, value: value}, nil
+}
+
+// unescapeFilterValue decodifica los escapes \XX de un valor
+func unescapeFilterValue(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("ldap: escape incompleto en filtro: %q", s)
+		}
+		decoded, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("ldap: escape inválido en filtro: %q", s)
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// ========================================
+// CODIFICACIÓN BER
+// ========================================
+
+// encode codifica el filtro para un SearchRequest
+func (f *Filter) encode() []byte {
+	identifier := classContext | byte(f.op)
+
+	switch f.op {
+	case filterAnd, filterOr, filterNot:
+		children := make([][]byte, 0, len(f.children))
+		for _, child := range f.children {
+			children = append(children, child.encode())
+		}
+		return berConstructed(identifier, children...)
+	case filterPresent:
+		return berString(identifier, f.attr)
+	case filterSubstrings:
+		var parts [][]byte
+		if f.initial != "" {
+			parts = append(parts, berString(classContext|0, f.initial))
+		}
+		for _, value := range f.any {
+			parts = append(parts, berString(classContext|1, value))
+		}
+		if f.final != "" {
+			parts = append(parts, berString(classContext|2, f.final))
+		}
+		return berConstructed(identifier, berOctetString(f.attr), berSequence(parts...))
+	default:
+		return berConstructed(identifier, berOctetString(f.attr), berOctetString(f.value))
+	}
+}
+
+// decodeFilter decodifica un filtro recibido en un SearchRequest
+func decodeFilter(p *packet) (*Filter, error) {
+	if p.class != classContext || p.tag > byte(filterApprox) {
+		return nil, fmt.Errorf("ldap: tipo de filtro no soportado")
+	}
+
+	f := &Filter{op: filterOp(p.tag)}
+	switch f.op {
+	case filterAnd, filterOr, filterNot:
+		for _, child := range p.children {
+			decoded, err := decodeFilter(child)
+			if err != nil {
+				return nil, err
+			}
+			f.children = append(f.children, decoded)
+		}
+		if f.op == filterNot && len(f.children) != 1 {
+			return nil, fmt.Errorf("ldap: filtro NOT inválido")
+		}
+	case filterPresent:
+		f.attr = p.str()
+	case filterSubstrings:
+		if len(p.children) != 2 {
+			return nil, fmt.Errorf("ldap: filtro de subcadenas inválido")
+		}
+		f.attr = p.children[0].str()
+		for _, part := range p.children[1].children {
+			switch part.tag {
+			case 0:
+				f.initial = part.str()
+			case 1:
+				f.any = append(f.any, part.str())
+			case 2:
+				f.final = part.str()
+			}
+		}
+	default:
+		if len(p.children) != 2 {
+			return nil, fmt.Errorf("ldap: comparación inválida")
+		}
+		f.attr, f.value = p.children[0].str(), p.children[1].str()
+	}
+
+	return f, nil
+}
+
+// ========================================
+// EVALUACIÓN (servidor en memoria)
+// ========================================
+
+// Match evalúa el filtro sobre una entrada; las comparaciones no distinguen mayúsculas
+func (f *Filter) Match(e *Entry) bool {
+	switch f.op {
+	case filterAnd:
+		for _, child := range f.children {
+			if !child.Match(e) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range f.children {
+			if child.Match(e) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !f.children[0].Match(e)
+	case filterPresent:
+		return len(e.GetAttributeValues(f.attr)) > 0
+	}
+
+	for _, value := range e.GetAttributeValues(f.attr) {
+		if f.matchValue(strings.ToLower(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue compara un valor del atributo (ya en minúsculas) con el filtro
+func (f *Filter) matchValue(value string) bool {
+	expected := strings.ToLower(f.value)
+	switch f.op {
+	case filterEquality, filterApprox:
+		return value == expected
+	case filterGreaterOrEqual:
+		return value >= expected
+	case filterLessOrEqual:
+		return value <= expected
+	case filterSubstrings:
+		initial, final := strings.ToLower(f.initial), strings.ToLower(f.final)
+		if !strings.HasPrefix(value, initial) {
+			return false
+		}
+		value = value[len(initial):]
+		for _, part := range f.any {
+			part = strings.ToLower(part)
+			idx := strings.Index(value, part)
+			if idx < 0 {
+				return false
+			}
+			value = value[idx+len(part):]
+		}
+		return strings.HasSuffix(value, final)
+	}
+	return false
+}
@@ -0,0 +1,387 @@
+// pkg/ldap/ldap_test.go
+package ldap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDirectoryLDIF = `# Directorio de prueba
+version: 1
+
+dn: ou=people,dc=gamc,dc=gov,dc=bo
+objectClass: organizationalUnit
+ou: people
+
+dn: uid=lmendez,ou=people,dc=gamc,dc=gov,dc=bo
+objectClass: person
+uid: lmendez
+givenName: Lucía
+sn: Méndez
+mail: lucia.mendez@gamc.gov.bo
+userPassword: Directorio2024!
+
+dn: uid=jquispe,ou=people,dc=gamc,dc=gov,dc=bo
+objectClass: person
+uid: jquispe
+givenName: Jorge
+sn: Quispe
+mail: jorge.quispe@gamc.gov.bo
+userPassword: Directorio2024!
+
+dn: uid=asterisco,ou=people,dc=gamc,dc=gov,dc=bo
+objectClass: person
+uid: asterisco
+givenName:: QXN0ZXJpc2Nv
+mail: a*b@gamc.gov.bo
+userPassword: Directorio2024!
+
+dn: ou=groups,dc=gamc,dc=gov,dc=bo
+objectClass: organizationalUnit
+ou: groups
+
+dn: cn=gamc-admins,ou=groups,dc=gamc,dc=gov,dc=bo
+objectClass: groupOfNames
+cn: gamc-admins
+member: uid=lmendez,ou=people,dc=gamc,dc=gov,dc=bo
+`
+
+const (
+	testRootDN       = "cn=admin,dc=gamc,dc=gov,dc=bo"
+	testRootPassword = "gamc_ldap_admin_2024"
+	testBaseDN       = "dc=gamc,dc=gov,dc=bo"
+	testUserDN       = "uid=lmendez,ou=people,dc=gamc,dc=gov,dc=bo"
+)
+
+// startTestServer levanta el servidor en memoria con el directorio de prueba y abre una conexión
+func startTestServer(t *testing.T) (*Server, *Conn) {
+	t.Helper()
+	entries, err := ParseLDIF(strings.NewReader(testDirectoryLDIF))
+	if err != nil {
+		t.Fatalf("ParseLDIF: %v", err)
+	}
+
+	server := NewServer(entries)
+	server.SetRootCredentials(testRootDN, testRootPassword)
+	addr, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := Dial("ldap://"+addr, DialOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return server, conn
+}
+
+func TestParseLDIF(t *testing.T) {
+	entries, err := ParseLDIF(strings.NewReader(testDirectoryLDIF))
+	if err != nil {
+		t.Fatalf("ParseLDIF: %v", err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("entradas = %d, se esperaba 6", len(entries))
+	}
+	if got := entries[3].GetAttributeValue("givenName"); got != "Asterisco" {
+		t.Errorf("valor base64 = %q, se esperaba %q", got, "Asterisco")
+	}
+
+	if _, err := ParseLDIF(strings.NewReader("dn: cn=x\nchangetype: add\n")); err == nil {
+		t.Error("ParseLDIF aceptó un registro de cambio")
+	}
+}
+
+func TestBind(t *testing.T) {
+	_, conn := startTestServer(t)
+
+	tests := []struct {
+		name     string
+		dn       string
+		password string
+		wantErr  error
+	}{
+		{name: "usuario con contraseña correcta", dn: testUserDN, password: "Directorio2024!"},
+		{name: "DN con otras mayúsculas y espacios", dn: "UID=lmendez, ou=People, dc=gamc,dc=gov,dc=bo", password: "Directorio2024!"},
+		{name: "cuenta de búsqueda", dn: testRootDN, password: testRootPassword},
+		{name: "contraseña incorrecta", dn: testUserDN, password: "otra", wantErr: ErrInvalidCredentials},
+		{name: "DN inexistente", dn: "uid=nadie,ou=people,dc=gamc,dc=gov,dc=bo", password: "Directorio2024!", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := conn.Bind(tt.dn, tt.password)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Bind: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bind = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				var ldapErr *Error
+				if !errors.As(err, &ldapErr) || ldapErr.ResultCode != ResultInvalidCredentials {
+					t.Errorf("Bind = %#v, se esperaba resultCode %d del servidor", err, ResultInvalidCredentials)
+				}
+			}
+		})
+	}
+}
+
+func TestBindRejectsEmptyPassword(t *testing.T) {
+	_, conn := startTestServer(t)
+
+	// Con contraseña vacía el servidor podría aceptar un bind no autenticado;
+	// el cliente lo rechaza sin enviarlo
+	err := conn.Bind(testUserDN, "")
+	if err != ErrInvalidCredentials {
+		t.Fatalf("Bind con contraseña vacía = %v, se esperaba %v", err, ErrInvalidCredentials)
+	}
+	if err := conn.Bind("", ""); err != ErrInvalidCredentials {
+		t.Fatalf("bind anónimo = %v, se esperaba %v", err, ErrInvalidCredentials)
+	}
+
+	// La conexión sigue utilizable
+	if err := conn.Bind(testUserDN, "Directorio2024!"); err != nil {
+		t.Errorf("Bind posterior: %v", err)
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"lucia.mendez@gamc.gov.bo", "lucia.mendez@gamc.gov.bo"},
+		{"*", `\2a`},
+		{"a*b@gamc.gov.bo", `a\2ab@gamc.gov.bo`},
+		{"x)(|(mail=*)", `x\29\28|\28mail=\2a\29`},
+		{`dominio\usuario`, `dominio\5cusuario`},
+		{"nul\x00", `nul\00`},
+		{"Lucía", "Lucía"},
+	}
+
+	for _, tt := range tests {
+		got := EscapeFilter(tt.value)
+		if got != tt.want {
+			t.Errorf("EscapeFilter(%q) = %q, se esperaba %q", tt.value, got, tt.want)
+			continue
+		}
+
+		// El valor escapado se interpreta como una igualdad literal
+		f, err := ParseFilter("(mail=" + got + ")")
+		if err != nil {
+			t.Errorf("ParseFilter con %q: %v", got, err)
+			continue
+		}
+		if f.op != filterEquality || f.value != tt.value {
+			t.Errorf("filtro de %q: op %d valor %q, se esperaba igualdad con %q", tt.value, f.op, f.value, tt.value)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	entry := NewEntry(testUserDN, map[string][]string{
+		"objectClass": {"top", "person"},
+		"mail":        {"Lucia.Mendez@gamc.gov.bo"},
+		"uid":         {"lmendez"},
+	})
+
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{"(mail=lucia.mendez@gamc.gov.bo)", true},
+		{"mail=lucia.mendez@gamc.gov.bo", true},
+		{"(&(objectClass=person)(uid=lmendez))", true},
+		{"(&(objectClass=person)(uid=jquispe))", false},
+		{"(|(uid=jquispe)(uid=lmendez))", true},
+		{"(!(uid=lmendez))", false},
+		{"(mail=*)", true},
+		{"(telephoneNumber=*)", false},
+		{"(mail=lucia*@gamc*)", true},
+		{"(mail=*@otro.bo)", false},
+		{"(uid>=l)", true},
+		{"(uid<=k)", false},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.filter, err)
+			continue
+		}
+		if got := f.Match(entry); got != tt.match {
+			t.Errorf("%s: Match = %v, se esperaba %v", tt.filter, got, tt.match)
+		}
+	}
+
+	for _, invalid := range []string{"", "(mail=x", "(=x)", "(mail=x))", `(mail=\zz)`, `(mail=\2)`} {
+		if _, err := ParseFilter(invalid); err == nil {
+			t.Errorf("ParseFilter(%q) no retornó error", invalid)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, conn := startTestServer(t)
+	if err := conn.Bind(testRootDN, testRootPassword); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	t.Run("por email con atributos solicitados", func(t *testing.T) {
+		entries, err := conn.Search(&SearchRequest{
+			BaseDN:     testBaseDN,
+			Scope:      ScopeWholeSubtree,
+			Filter:     "(&(objectClass=person)(mail=" + EscapeFilter("LUCIA.MENDEZ@gamc.gov.bo") + "))",
+			Attributes: []string{"mail", "givenName", "sn", "userPassword"},
+		})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(entries) != 1 || !EqualDN(entries[0].DN, testUserDN) {
+			t.Fatalf("entradas = %v, se esperaba %s", entries, testUserDN)
+		}
+		entry := entries[0]
+		if got := entry.GetAttributeValue("GIVENNAME"); got != "Lucía" {
+			t.Errorf("givenName = %q", got)
+		}
+		if got := entry.GetAttributeValue("uid"); got != "" {
+			t.Errorf("uid no solicitado retornado: %q", got)
+		}
+		if got := entry.GetAttributeValues("userPassword"); got != nil {
+			t.Errorf("userPassword retornado: %v", got)
+		}
+	})
+
+	t.Run("valores escapados no amplían la búsqueda", func(t *testing.T) {
+		for _, email := range []string{"*", "x)(|(mail=*)", "*@gamc.gov.bo"} {
+			entries, err := conn.Search(&SearchRequest{
+				BaseDN: testBaseDN,
+				Scope:  ScopeWholeSubtree,
+				Filter: "(&(objectClass=person)(mail=" + EscapeFilter(email) + "))",
+			})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", email, err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("Search(%q) retornó %d entradas, se esperaba 0", email, len(entries))
+			}
+		}
+
+		// El asterisco escapado coincide solo con el valor literal
+		entries, err := conn.Search(&SearchRequest{
+			BaseDN: testBaseDN,
+			Scope:  ScopeWholeSubtree,
+			Filter: "(mail=" + EscapeFilter("a*b@gamc.gov.bo") + ")",
+		})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(entries) != 1 || entries[0].GetAttributeValue("uid") != "asterisco" {
+			t.Errorf("entradas = %v, se esperaba uid=asterisco", entries)
+		}
+	})
+
+	t.Run("alcances", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			baseDN string
+			scope  int
+			want   int
+		}{
+			{"objeto base", "ou=people," + testBaseDN, ScopeBaseObject, 1},
+			{"un nivel", "ou=people," + testBaseDN, ScopeSingleLevel, 3},
+			{"subárbol", testBaseDN, ScopeWholeSubtree, 6},
+			{"un nivel bajo la raíz", testBaseDN, ScopeSingleLevel, 2},
+		}
+		for _, tt := range tests {
+			entries, err := conn.Search(&SearchRequest{BaseDN: tt.baseDN, Scope: tt.scope, Filter: "(objectClass=*)"})
+			if err != nil {
+				t.Fatalf("%s: Search: %v", tt.name, err)
+			}
+			if len(entries) != tt.want {
+				t.Errorf("%s: %d entradas, se esperaba %d", tt.name, len(entries), tt.want)
+			}
+		}
+	})
+
+	t.Run("límite de resultados", func(t *testing.T) {
+		_, err := conn.Search(&SearchRequest{BaseDN: testBaseDN, Scope: ScopeWholeSubtree, Filter: "(objectClass=person)", SizeLimit: 2})
+		var ldapErr *Error
+		if !errors.As(err, &ldapErr) || ldapErr.ResultCode != ResultSizeLimitExceeded {
+			t.Errorf("Search = %v, se esperaba resultCode %d", err, ResultSizeLimitExceeded)
+		}
+	})
+
+	t.Run("grupos por miembro", func(t *testing.T) {
+		entries, err := conn.Search(&SearchRequest{
+			BaseDN:     "ou=groups," + testBaseDN,
+			Scope:      ScopeWholeSubtree,
+			Filter:     "(&(objectClass=groupOfNames)(member=" + EscapeFilter(testUserDN) + "))",
+			Attributes: []string{"cn"},
+		})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(entries) != 1 || FirstRDNValue(entries[0].DN) != "gamc-admins" {
+			t.Errorf("grupos = %v, se esperaba gamc-admins", entries)
+		}
+	})
+
+	t.Run("filtro inválido", func(t *testing.T) {
+		if _, err := conn.Search(&SearchRequest{BaseDN: testBaseDN, Filter: "(mail=x"}); err == nil {
+			t.Error("Search con filtro inválido no retornó error")
+		}
+	})
+}
+
+func TestServerEntryChanges(t *testing.T) {
+	server, conn := startTestServer(t)
+
+	if !server.RemoveEntry("UID=jquispe,ou=people,dc=gamc,dc=gov,dc=bo") {
+		t.Fatal("RemoveEntry no encontró la entrada")
+	}
+	if err := conn.Bind("uid=jquispe,ou=people,dc=gamc,dc=gov,dc=bo", "Directorio2024!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Bind de entrada eliminada = %v, se esperaba %v", err, ErrInvalidCredentials)
+	}
+
+	server.AddEntry(NewEntry(testUserDN, map[string][]string{"objectClass": {"person"}, "userPassword": {"Nueva2025!"}}))
+	if err := conn.Bind(testUserDN, "Directorio2024!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Bind con la contraseña reemplazada = %v, se esperaba %v", err, ErrInvalidCredentials)
+	}
+	if err := conn.Bind(testUserDN, "Nueva2025!"); err != nil {
+		t.Errorf("Bind con la contraseña nueva: %v", err)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	if _, err := Dial("http://127.0.0.1:389", DialOptions{}); err == nil {
+		t.Error("Dial aceptó un esquema no LDAP")
+	}
+	if _, err := Dial("ldap://127.0.0.1:1", DialOptions{Timeout: time.Second}); err == nil {
+		t.Error("Dial a un puerto cerrado no retornó error")
+	}
+}
+
+func TestDNHelpers(t *testing.T) {
+	if got := NormalizeDN("UID=LMendez, OU=People ,DC=gamc"); got != "uid=lmendez,ou=people,dc=gamc" {
+		t.Errorf("NormalizeDN = %q", got)
+	}
+	if got := FirstRDNValue(`cn=Obras\, Públicas,ou=groups,dc=gamc`); got != "Obras, Públicas" {
+		t.Errorf("FirstRDNValue = %q", got)
+	}
+	if got := ParentDN(testUserDN); got != "ou=people,dc=gamc,dc=gov,dc=bo" {
+		t.Errorf("ParentDN = %q", got)
+	}
+	if !IsDescendantDN(testUserDN, "DC=gamc,DC=gov,DC=bo") || IsDescendantDN(testUserDN, "ou=groups,dc=gamc,dc=gov,dc=bo") {
+		t.Error("IsDescendantDN no respeta la base")
+	}
+	if IsDescendantDN("uid=x,dc=otrogamc,dc=gov,dc=bo", "dc=gamc,dc=gov,dc=bo") {
+		t.Error("IsDescendantDN aceptó un sufijo que no es un RDN completo")
+	}
+}
@@ -0,0 +1,90 @@
+// pkg/ldap/ldif.go
+package ldap
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseLDIF lee entradas en formato LDIF (RFC 2849): "atributo: valor",
+// valores en base64 con "::", líneas de continuación y comentarios.
+// Solo se soportan registros de contenido (sin changetype).
+func ParseLDIF(r io.Reader) ([]*Entry, error) {
+	var (
+		entries []*Entry
+		current *Entry
+		lines   []string
+	)
+
+	flush := func() error {
+		for _, line := range lines {
+			attr, value, err := parseLDIFLine(line)
+			if err != nil {
+				return err
+			}
+			switch {
+			case strings.EqualFold(attr, "version") && current == nil:
+				continue
+			case strings.EqualFold(attr, "dn"):
+				current = NewEntry(value, nil)
+			case current == nil:
+				return fmt.Errorf("ldap: atributo %q antes del dn", attr)
+			case strings.EqualFold(attr, "changetype"):
+				return fmt.Errorf("ldap: registros de cambio LDIF no soportados (%s)", current.DN)
+			default:
+				current.Attributes[attr] = append(current.Attributes[attr], value)
+			}
+		}
+		if current != nil {
+			entries = append(entries, current)
+		}
+		current, lines = nil, nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, " ") && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// parseLDIFLine separa "atributo: valor" o "atributo:: base64"
+func parseLDIFLine(line string) (string, string, error) {
+	attr, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", fmt.Errorf("ldap: línea LDIF inválida: %q", line)
+	}
+
+	if strings.HasPrefix(value, ":") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("ldap: valor base64 inválido en %q: %w", attr, err)
+		}
+		return attr, string(decoded), nil
+	}
+
+	return attr, strings.TrimLeft(value, " "), nil
+}
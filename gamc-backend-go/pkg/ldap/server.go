@@ -0,0 +1,290 @@
+// pkg/ldap/server.go
+package ldap
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Server servidor LDAP en memoria para desarrollo y pruebas de integración.
+// Soporta bind simple (contra el atributo userPassword), búsqueda y unbind;
+// no implementa TLS, escrituras ni control de acceso. No usar en producción.
+type Server struct {
+	mu       sync.RWMutex
+	entries  []*Entry
+	rootDN   string
+	rootPass string
+
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer crea un servidor con las entradas indicadas
+func NewServer(entries []*Entry) *Server {
+	return &Server{
+		entries: entries,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+// SetRootCredentials define un DN administrativo que no necesita existir como entrada
+func (s *Server) SetRootCredentials(dn, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rootDN, s.rootPass = dn, password
+}
+
+// AddEntry agrega o reemplaza una entrada
+func (s *Server) AddEntry(entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.entries {
+		if EqualDN(existing.DN, entry.DN) {
+			s.entries[i] = entry
+			return
+		}
+	}
+	s.entries = append(s.entries, entry)
+}
+
+// RemoveEntry elimina una entrada; retorna false si no existía
+func (s *Server) RemoveEntry(dn string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.entries {
+		if EqualDN(existing.DN, dn) {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Start escucha en addr (ej. "127.0.0.1:0") y atiende conexiones en segundo plano.
+// Retorna la dirección efectiva para construir la URL ldap://.
+func (s *Server) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = struct{}{}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serveConn(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), nil
+}
+
+// Close detiene el servidor y cierra las conexiones abiertas
+func (s *Server) Close() error {
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// serveConn atiende las operaciones de una conexión hasta unbind o error
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := readPacket(reader)
+		if err != nil || len(msg.children) < 2 {
+			return
+		}
+
+		messageID := msg.children[0].int()
+		op := msg.children[1]
+		if op.class != classApplication {
+			return
+		}
+
+		var responses [][]byte
+		switch op.tag {
+		case opBindRequest:
+			responses = [][]byte{s.handleBind(op)}
+		case opSearchRequest:
+			responses = s.handleSearch(op)
+		case opUnbindRequest:
+			return
+		case opExtendedRequest:
+			responses = [][]byte{ldapResult(opExtendedResponse, ResultProtocolError, "operación extendida no soportada")}
+		default:
+			return
+		}
+
+		for _, resp := range responses {
+			out := berSequence(berInteger(classUniversal|tagInteger, messageID), resp)
+			if _, err := conn.Write(out); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleBind valida un bind simple
+func (s *Server) handleBind(op *packet) []byte {
+	if len(op.children) < 3 || !op.children[2].is(classContext, 0) {
+		return ldapResult(opBindResponse, ResultUnwillingToPerform, "solo se soporta bind simple")
+	}
+
+	dn, password := op.children[1].str(), op.children[2].str()
+	if dn == "" && password == "" {
+		return ldapResult(opBindResponse, ResultSuccess, "") // Bind anónimo
+	}
+	if password == "" {
+		return ldapResult(opBindResponse, ResultUnwillingToPerform, "bind no autenticado no permitido")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.rootDN != "" && EqualDN(dn, s.rootDN) && password == s.rootPass {
+		return ldapResult(opBindResponse, ResultSuccess, "")
+	}
+	for _, entry := range s.entries {
+		if !EqualDN(entry.DN, dn) {
+			continue
+		}
+		for _, stored := range entry.GetAttributeValues("userPassword") {
+			if stored == password {
+				return ldapResult(opBindResponse, ResultSuccess, "")
+			}
+		}
+	}
+
+	return ldapResult(opBindResponse, ResultInvalidCredentials, "credenciales inválidas")
+}
+
+// handleSearch ejecuta una búsqueda y retorna las entradas seguidas del resultado final
+func (s *Server) handleSearch(op *packet) [][]byte {
+	if len(op.children) < 8 {
+		return [][]byte{ldapResult(opSearchDone, ResultProtocolError, "búsqueda mal formada")}
+	}
+
+	baseDN := op.children[0].str()
+	scope := int(op.children[1].int())
+	sizeLimit := int(op.children[3].int())
+	filter, err := decodeFilter(op.children[6])
+	if err != nil {
+		return [][]byte{ldapResult(opSearchDone, ResultProtocolError, err.Error())}
+	}
+
+	var requested []string
+	for _, attr := range op.children[7].children {
+		requested = append(requested, attr.str())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var responses [][]byte
+	for _, entry := range s.entries {
+		if !inScope(entry.DN, baseDN, scope) || !filter.Match(entry) {
+			continue
+		}
+		if sizeLimit > 0 && len(responses) >= sizeLimit {
+			responses = append(responses, ldapResult(opSearchDone, ResultSizeLimitExceeded, "límite de resultados excedido"))
+			return responses
+		}
+		responses = append(responses, encodeEntry(entry, requested))
+	}
+
+	return append(responses, ldapResult(opSearchDone, ResultSuccess, ""))
+}
+
+// inScope verifica si la entrada está dentro del alcance de la búsqueda
+func inScope(dn, baseDN string, scope int) bool {
+	switch scope {
+	case ScopeBaseObject:
+		return EqualDN(dn, baseDN)
+	case ScopeSingleLevel:
+		return EqualDN(ParentDN(dn), baseDN)
+	default:
+		return IsDescendantDN(dn, baseDN)
+	}
+}
+
+// encodeEntry codifica una entrada; userPassword nunca se retorna
+func encodeEntry(entry *Entry, requested []string) []byte {
+	all := len(requested) == 0
+	for _, attr := range requested {
+		if attr == "*" {
+			all = true
+		}
+	}
+
+	var attributes [][]byte
+	for name, values := range entry.Attributes {
+		if strings.EqualFold(name, "userPassword") || (!all && !containsFold(requested, name)) {
+			continue
+		}
+		encoded := make([][]byte, 0, len(values))
+		for _, value := range values {
+			encoded = append(encoded, berOctetString(value))
+		}
+		attributes = append(attributes, berSequence(
+			berOctetString(name),
+			berConstructed(classUniversal|tagSet, encoded...),
+		))
+	}
+
+	return berConstructed(classApplication|opSearchEntry,
+		berOctetString(entry.DN),
+		berSequence(attributes...),
+	)
+}
+
+// ldapResult codifica un LDAPResult con la etiqueta de respuesta indicada
+func ldapResult(op byte, code int, message string) []byte {
+	return berConstructed(classApplication|op,
+		berInteger(classUniversal|tagEnumerated, int64(code)),
+		berOctetString(""),
+		berOctetString(message),
+	)
+}
+
+// containsFold verifica si el nombre está en la lista sin distinguir mayúsculas
+func containsFold(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// pkg/mailer/client.go
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client entrega mensajes a un servidor SMTP (relay institucional)
+type Client struct {
+	Host      string
+	Port      int
+	Username  string // Vacío: sin autenticación
+	Password  string
+	StartTLS  bool   // Exige STARTTLS; el puerto 465 usa TLS implícito
+	LocalName string // Nombre anunciado en EHLO
+	Timeout   time.Duration
+	TLSConfig *tls.Config
+}
+
+// Send entrega un mensaje ya construido (y firmado) a los destinatarios indicados
+func (c *Client) Send(ctx context.Context, from string, to []string, raw []byte) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mailer: error al conectar con %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if c.Port == 465 {
+		conn = tls.Client(conn, c.tlsConfig())
+	}
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: saludo SMTP inválido: %w", err)
+	}
+	defer client.Close()
+
+	if c.LocalName != "" {
+		if err := client.Hello(c.LocalName); err != nil {
+			return err
+		}
+	}
+
+	if c.StartTLS && c.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("mailer: el servidor no soporta STARTTLS")
+		}
+		if err := client.StartTLS(c.tlsConfig()); err != nil {
+			return fmt.Errorf("mailer: error en STARTTLS: %w", err)
+		}
+	}
+
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			// Se reintenta: es un problema de configuración, no del mensaje
+			return fmt.Errorf("mailer: autenticación SMTP fallida: %v", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(raw); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (c *Client) tlsConfig() *tls.Config {
+	if c.TLSConfig != nil {
+		return c.TLSConfig
+	}
+	return &tls.Config{ServerName: c.Host, MinVersion: tls.VersionTLS12}
+}
+
+// IsPermanent indica si el servidor rechazó el envío de forma definitiva (respuesta 5xx);
+// los errores de red y las respuestas 4xx se pueden reintentar
+func IsPermanent(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
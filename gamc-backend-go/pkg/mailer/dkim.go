@@ -0,0 +1,262 @@
+// pkg/mailer/dkim.go
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dkimHeaders cabeceras firmadas cuando están presentes en el mensaje
+var dkimHeaders = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"}
+
+// DKIMSigner firma mensajes con DKIM (RFC 6376): rsa-sha256, canonicalización relaxed/relaxed
+type DKIMSigner struct {
+	Domain   string
+	Selector string
+	key      *rsa.PrivateKey
+}
+
+// NewDKIMSigner crea un firmador a partir de una clave RSA en PEM (PKCS#1 o PKCS#8)
+func NewDKIMSigner(domain, selector string, keyPEM []byte) (*DKIMSigner, error) {
+	if domain == "" || selector == "" {
+		return nil, fmt.Errorf("mailer: dominio y selector DKIM son obligatorios")
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("mailer: clave DKIM no está en formato PEM")
+	}
+
+	var key *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		key = parsed
+	} else {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: clave DKIM inválida: %w", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("mailer: la clave DKIM debe ser RSA")
+		}
+		key = rsaKey
+	}
+	if key.N.BitLen() < 1024 {
+		return nil, fmt.Errorf("mailer: la clave DKIM debe tener al menos 1024 bits")
+	}
+
+	return &DKIMSigner{Domain: domain, Selector: selector, key: key}, nil
+}
+
+// DNSRecord retorna el registro TXT a publicar en <selector>._domainkey.<dominio>
+func (s *DKIMSigner) DNSRecord() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der), nil
+}
+
+// PublicKey retorna la clave pública del firmador
+func (s *DKIMSigner) PublicKey() *rsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+// Sign retorna el mensaje con la cabecera DKIM-Signature antepuesta
+func (s *DKIMSigner) Sign(raw []byte) ([]byte, error) {
+	headers, body, err := splitMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	signed := make([]string, 0, len(dkimHeaders))
+	var hashed bytes.Buffer
+	for _, name := range dkimHeaders {
+		if header, ok := lastHeader(headers, name); ok {
+			signed = append(signed, strings.ToLower(name))
+			hashed.WriteString(relaxedHeader(header))
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=rsa-sha256; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.Domain, s.Selector, time.Now().Unix(), strings.Join(signed, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	hashed.WriteString(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value), "\r\n"))
+
+	digest := sha256.Sum256(hashed.Bytes())
+	signature, err := rsa.SignPKCS1v15(nil, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("mailer: error al firmar DKIM: %w", err)
+	}
+
+	out := make([]byte, 0, len(raw)+512)
+	out = append(out, "DKIM-Signature: "+value+base64.StdEncoding.EncodeToString(signature)+"\r\n"...)
+	out = append(out, raw...)
+	return out, nil
+}
+
+// VerifyDKIM verifica la primera firma DKIM del mensaje con la clave pública indicada.
+// Pensado para el servidor SMTP de desarrollo; no consulta DNS.
+func VerifyDKIM(raw []byte, publicKey *rsa.PublicKey) error {
+	headers, body, err := splitMessage(raw)
+	if err != nil {
+		return err
+	}
+
+	signatureHeader, ok := firstHeader(headers, "DKIM-Signature")
+	if !ok {
+		return errors.New("mailer: el mensaje no tiene firma DKIM")
+	}
+	tags := parseTags(signatureHeader[strings.Index(signatureHeader, ":")+1:])
+	if tags["a"] != "rsa-sha256" || tags["c"] != "relaxed/relaxed" {
+		return errors.New("mailer: algoritmo o canonicalización DKIM no soportados")
+	}
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+	if base64.StdEncoding.EncodeToString(bodyHash[:]) != tags["bh"] {
+		return errors.New("mailer: el hash del cuerpo DKIM no coincide")
+	}
+
+	var hashed bytes.Buffer
+	for _, name := range strings.Split(tags["h"], ":") {
+		if header, ok := lastHeader(headers, strings.TrimSpace(name)); ok {
+			hashed.WriteString(relaxedHeader(header))
+		}
+	}
+	bTag := signatureTagIndex(signatureHeader, "b")
+	if bTag < 0 {
+		return errors.New("mailer: firma DKIM sin tag b=")
+	}
+	unsigned := signatureHeader[:bTag+2]
+	if end := strings.Index(signatureHeader[bTag:], ";"); end >= 0 {
+		unsigned += signatureHeader[bTag+end:]
+	}
+	hashed.WriteString(strings.TrimSuffix(relaxedHeader(unsigned), "\r\n"))
+
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return fmt.Errorf("mailer: firma DKIM mal codificada: %w", err)
+	}
+	digest := sha256.Sum256(hashed.Bytes())
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return errors.New("mailer: firma DKIM inválida")
+	}
+	return nil
+}
+
+// splitMessage separa las cabeceras (con sus líneas de continuación) del cuerpo
+func splitMessage(raw []byte) ([]string, []byte, error) {
+	end := bytes.Index(raw, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil, nil, errors.New("mailer: mensaje sin separación entre cabeceras y cuerpo")
+	}
+
+	var headers []string
+	for _, line := range strings.Split(string(raw[:end]), "\r\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(headers) > 0 {
+			headers[len(headers)-1] += "\r\n" + line
+			continue
+		}
+		headers = append(headers, line)
+	}
+	return headers, raw[end+4:], nil
+}
+
+// lastHeader retorna la última aparición de una cabecera (orden de firma de RFC 6376 §5.4.2)
+func lastHeader(headers []string, name string) (string, bool) {
+	for i := len(headers) - 1; i >= 0; i-- {
+		if headerName(headers[i]) == strings.ToLower(name) {
+			return headers[i], true
+		}
+	}
+	return "", false
+}
+
+// firstHeader retorna la primera aparición de una cabecera
+func firstHeader(headers []string, name string) (string, bool) {
+	for _, header := range headers {
+		if headerName(header) == strings.ToLower(name) {
+			return header, true
+		}
+	}
+	return "", false
+}
+
+// headerName nombre de la cabecera en minúsculas
+func headerName(header string) string {
+	colon := strings.Index(header, ":")
+	if colon < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(header[:colon]))
+}
+
+// relaxedHeader canonicaliza una cabecera: nombre en minúsculas, valor desplegado y espacios comprimidos
+func relaxedHeader(header string) string {
+	colon := strings.Index(header, ":")
+	name := strings.ToLower(strings.TrimSpace(header[:colon]))
+	value := strings.NewReplacer("\r\n", "").Replace(header[colon+1:])
+	value = strings.Join(strings.Fields(value), " ")
+	return name + ":" + value + "\r\n"
+}
+
+// relaxedBody canonicaliza el cuerpo: espacios comprimidos y sin líneas vacías finales
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", " ")
+		for strings.Contains(line, "  ") {
+			line = strings.ReplaceAll(line, "  ", " ")
+		}
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// parseTags interpreta la lista tag=valor de una firma DKIM
+func parseTags(value string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.TrimSpace(key)] = strings.Join(strings.Fields(val), "")
+	}
+	return tags
+}
+
+// signatureTagIndex posición del tag indicado (inicio de "<tag>=") dentro de la cabecera de firma
+func signatureTagIndex(header, tag string) int {
+	start := strings.Index(header, ":") + 1
+	for start > 0 && start < len(header) {
+		end := strings.Index(header[start:], ";")
+		if end < 0 {
+			end = len(header) - start
+		}
+		part := header[start : start+end]
+		trimmed := strings.TrimLeft(part, " \t\r\n")
+		if strings.HasPrefix(trimmed, tag+"=") {
+			return start + len(part) - len(trimmed)
+		}
+		start += end + 1
+	}
+	return -1
+}
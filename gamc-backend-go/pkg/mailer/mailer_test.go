@@ -0,0 +1,319 @@
+// pkg/mailer/mailer_test.go
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestServer levanta el servidor SMTP en memoria y retorna un cliente apuntando a él
+func startTestServer(t *testing.T) (*Server, *Client) {
+	t.Helper()
+	server := NewServer()
+	addr, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("SplitHostPort(%q): %v", addr, err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return server, &Client{Host: host, Port: port, LocalName: "backend.gamc.gob.bo", Timeout: 5 * time.Second}
+}
+
+func testMessage() *Message {
+	return &Message{
+		From:    mail.Address{Name: "GAMC", Address: "no-reply@gamc.gob.bo"},
+		To:      []mail.Address{{Name: "Ana Pérez", Address: "ana@gamc.gob.bo"}},
+		Subject: "Restablecer contraseña",
+		Text:    "Hola Ana,\r\n.línea que empieza con punto\r\nSaludos",
+		HTML:    "<p>Hola Ana</p>",
+		Headers: map[string]string{"Auto-Submitted": "auto-generated"},
+	}
+}
+
+// testDKIMKey genera una clave RSA en PEM (PKCS#1 o PKCS#8)
+func testDKIMKey(t *testing.T, pkcs8 bool) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if !pkcs8 {
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestClientSendDeliversMessage(t *testing.T) {
+	server, client := startTestServer(t)
+	client.Username, client.Password = "gamc", "secreto"
+
+	msg := testMessage()
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if err := client.Send(context.Background(), msg.From.Address, msg.Recipients(), raw); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("mensajes recibidos = %d, se esperaba 1", len(messages))
+	}
+	received := messages[0]
+	if received.From != "no-reply@gamc.gob.bo" {
+		t.Errorf("From = %q", received.From)
+	}
+	if len(received.To) != 1 || received.To[0] != "ana@gamc.gob.bo" {
+		t.Errorf("To = %v", received.To)
+	}
+	// El dot-stuffing se revierte: el servidor recibe exactamente lo enviado
+	if !bytes.Equal(received.Data, raw) {
+		t.Errorf("el contenido recibido difiere del enviado:\n%s", received.Data)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(received.Data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := parsed.Header.Get("Message-ID"); got != msg.MessageID || !strings.HasSuffix(got, "@gamc.gob.bo>") {
+		t.Errorf("Message-ID = %q, se esperaba %q", got, msg.MessageID)
+	}
+	if got := parsed.Header.Get("Auto-Submitted"); got != "auto-generated" {
+		t.Errorf("Auto-Submitted = %q", got)
+	}
+	if got := parsed.Header.Get("Content-Type"); !strings.HasPrefix(got, "multipart/alternative;") {
+		t.Errorf("Content-Type = %q", got)
+	}
+}
+
+func TestClientSendRejections(t *testing.T) {
+	server, client := startTestServer(t)
+	msg := testMessage()
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		code      int
+		permanent bool
+	}{
+		{name: "rechazo temporal 451", code: 451, permanent: false},
+		{name: "buzón lleno 452", code: 452, permanent: false},
+		{name: "rechazo definitivo 550", code: 550, permanent: true},
+		{name: "rechazo definitivo 554", code: 554, permanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Reset()
+			server.FailNext(1, tt.code)
+
+			err := client.Send(context.Background(), msg.From.Address, msg.Recipients(), raw)
+			if err == nil {
+				t.Fatalf("Send no retornó error con respuesta %d", tt.code)
+			}
+			if got := IsPermanent(err); got != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, se esperaba %v", err, got, tt.permanent)
+			}
+			if n := len(server.Messages()); n != 0 {
+				t.Errorf("mensajes guardados tras el rechazo = %d", n)
+			}
+
+			// El rechazo simulado se consume: el siguiente envío se acepta
+			if err := client.Send(context.Background(), msg.From.Address, msg.Recipients(), raw); err != nil {
+				t.Fatalf("reintento: %v", err)
+			}
+			if n := len(server.Messages()); n != 1 {
+				t.Errorf("mensajes guardados tras el reintento = %d, se esperaba 1", n)
+			}
+		})
+	}
+}
+
+func TestClientSendConnectionErrors(t *testing.T) {
+	server, client := startTestServer(t)
+	msg := testMessage()
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	t.Run("servidor sin STARTTLS", func(t *testing.T) {
+		tlsClient := *client
+		tlsClient.StartTLS = true
+		err := tlsClient.Send(context.Background(), msg.From.Address, msg.Recipients(), raw)
+		if err == nil || !strings.Contains(err.Error(), "no soporta STARTTLS") {
+			t.Errorf("Send = %v, se esperaba rechazo por falta de STARTTLS", err)
+		}
+		if n := len(server.Messages()); n != 0 {
+			t.Errorf("mensajes guardados = %d, se esperaba 0", n)
+		}
+	})
+
+	t.Run("servidor caído", func(t *testing.T) {
+		server.Close()
+		err := client.Send(context.Background(), msg.From.Address, msg.Recipients(), raw)
+		if err == nil {
+			t.Fatal("Send no retornó error con el servidor cerrado")
+		}
+		if IsPermanent(err) {
+			t.Errorf("IsPermanent(%v) = true; los errores de red se reintentan", err)
+		}
+	})
+}
+
+func TestMessageBytesRequiresAddresses(t *testing.T) {
+	msg := testMessage()
+	msg.To = nil
+	if _, err := msg.Bytes(); err == nil {
+		t.Error("Bytes sin destinatario no retornó error")
+	}
+
+	msg = testMessage()
+	msg.From = mail.Address{}
+	if _, err := msg.Bytes(); err == nil {
+		t.Error("Bytes sin remitente no retornó error")
+	}
+}
+
+func TestDKIMSignedMessageThroughServer(t *testing.T) {
+	for _, pkcs8 := range []bool{false, true} {
+		name := "PKCS#1"
+		if pkcs8 {
+			name = "PKCS#8"
+		}
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewDKIMSigner("gamc.gob.bo", "mail2024", testDKIMKey(t, pkcs8))
+			if err != nil {
+				t.Fatalf("NewDKIMSigner: %v", err)
+			}
+
+			server, client := startTestServer(t)
+			msg := testMessage()
+			raw, err := msg.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes: %v", err)
+			}
+			signed, err := signer.Sign(raw)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if err := client.Send(context.Background(), msg.From.Address, msg.Recipients(), signed); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			messages := server.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("mensajes recibidos = %d, se esperaba 1", len(messages))
+			}
+			data := messages[0].Data
+			if !bytes.HasPrefix(data, []byte("DKIM-Signature: ")) {
+				t.Fatalf("el mensaje no comienza con DKIM-Signature:\n%s", data)
+			}
+			parsed, err := mail.ReadMessage(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			tags := parseTags(parsed.Header.Get("DKIM-Signature"))
+			expected := map[string]string{"v": "1", "a": "rsa-sha256", "c": "relaxed/relaxed", "d": "gamc.gob.bo", "s": "mail2024"}
+			for tag, want := range expected {
+				if tags[tag] != want {
+					t.Errorf("%s= %q, se esperaba %q", tag, tags[tag], want)
+				}
+			}
+			for _, header := range []string{"from", "to", "subject", "message-id"} {
+				if !strings.Contains(strings.ToLower(tags["h"]), header) {
+					t.Errorf("h= %q no incluye %s", tags["h"], header)
+				}
+			}
+
+			if err := VerifyDKIM(data, signer.PublicKey()); err != nil {
+				t.Errorf("VerifyDKIM del mensaje recibido: %v", err)
+			}
+
+			record, err := signer.DNSRecord()
+			if err != nil {
+				t.Fatalf("DNSRecord: %v", err)
+			}
+			if !strings.HasPrefix(record, "v=DKIM1; k=rsa; p=") {
+				t.Errorf("DNSRecord = %q", record)
+			}
+		})
+	}
+}
+
+func TestVerifyDKIMRejectsTampering(t *testing.T) {
+	signer, err := NewDKIMSigner("gamc.gob.bo", "mail2024", testDKIMKey(t, false))
+	if err != nil {
+		t.Fatalf("NewDKIMSigner: %v", err)
+	}
+	raw, err := testMessage().Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	signed, err := signer.Sign(raw)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	t.Run("cuerpo modificado", func(t *testing.T) {
+		tampered := bytes.Replace(signed, []byte("Hola Ana"), []byte("Hola Eva"), 1)
+		if err := VerifyDKIM(tampered, signer.PublicKey()); err == nil {
+			t.Error("VerifyDKIM aceptó un cuerpo modificado")
+		}
+	})
+
+	t.Run("asunto modificado", func(t *testing.T) {
+		tampered := bytes.Replace(signed, []byte("Subject: "), []byte("Subject: RE: "), 1)
+		if err := VerifyDKIM(tampered, signer.PublicKey()); err == nil {
+			t.Error("VerifyDKIM aceptó un asunto modificado")
+		}
+	})
+
+	t.Run("clave de otro dominio", func(t *testing.T) {
+		other, err := NewDKIMSigner("otro.example", "mail2024", testDKIMKey(t, false))
+		if err != nil {
+			t.Fatalf("NewDKIMSigner: %v", err)
+		}
+		if err := VerifyDKIM(signed, other.PublicKey()); err == nil {
+			t.Error("VerifyDKIM aceptó la firma con otra clave pública")
+		}
+	})
+
+	t.Run("mensaje sin firma", func(t *testing.T) {
+		if err := VerifyDKIM(raw, signer.PublicKey()); err == nil {
+			t.Error("VerifyDKIM aceptó un mensaje sin DKIM-Signature")
+		}
+	})
+}
+
+func TestNewDKIMSignerRejectsInvalidKeys(t *testing.T) {
+	if _, err := NewDKIMSigner("gamc.gob.bo", "mail2024", []byte("no es PEM")); err == nil {
+		t.Error("NewDKIMSigner aceptó una clave que no es PEM")
+	}
+	if _, err := NewDKIMSigner("", "mail2024", testDKIMKey(t, false)); err == nil {
+		t.Error("NewDKIMSigner aceptó un dominio vacío")
+	}
+}
@@ -0,0 +1,142 @@
+// pkg/mailer/message.go
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message correo con versión en texto plano y HTML (multipart/alternative)
+type Message struct {
+	From      mail.Address
+	To        []mail.Address
+	Subject   string
+	Text      string
+	HTML      string
+	MessageID string            // Se genera si está vacío
+	Date      time.Time         // Ahora si es cero
+	Headers   map[string]string // Cabeceras adicionales (ej. Auto-Submitted)
+}
+
+// Recipients retorna las direcciones de destino para el sobre SMTP
+func (m *Message) Recipients() []string {
+	recipients := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		recipients = append(recipients, to.Address)
+	}
+	return recipients
+}
+
+// Bytes construye el mensaje RFC 5322 con fin de línea CRLF
+func (m *Message) Bytes() ([]byte, error) {
+	if m.From.Address == "" || len(m.To) == 0 {
+		return nil, fmt.Errorf("mailer: remitente y destinatario son obligatorios")
+	}
+	if m.Date.IsZero() {
+		m.Date = time.Now()
+	}
+	if m.MessageID == "" {
+		m.MessageID = NewMessageID(domainOf(m.From.Address))
+	}
+
+	boundary, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	boundary = "gamc-" + boundary
+
+	to := make([]string, 0, len(m.To))
+	for _, addr := range m.To {
+		to = append(to, addr.String())
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", m.From.String())
+	writeHeader(&buf, "To", strings.Join(to, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", m.Date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", m.MessageID)
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(&buf, name, mime.QEncoding.Encode("utf-8", m.Headers[name]))
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	if err := writePart(&buf, boundary, "text/plain; charset=utf-8", m.Text); err != nil {
+		return nil, err
+	}
+	if m.HTML != "" {
+		if err := writePart(&buf, boundary, "text/html; charset=utf-8", m.HTML); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// NewMessageID genera un Message-ID único para el dominio indicado
+func NewMessageID(domain string) string {
+	id, err := randomHex(16)
+	if err != nil {
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	if domain == "" {
+		domain = "localhost"
+	}
+	return fmt.Sprintf("<%s.%d@%s>", id, time.Now().Unix(), domain)
+}
+
+// writeHeader escribe una cabecera sin permitir saltos de línea en el valor
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+// writePart escribe una parte del multipart en quoted-printable
+func writePart(buf *bytes.Buffer, boundary, contentType, body string) error {
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader(buf, "Content-Type", contentType)
+	writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+// domainOf retorna el dominio de una dirección de correo
+func domainOf(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return ""
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
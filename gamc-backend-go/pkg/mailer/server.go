@@ -0,0 +1,240 @@
+// pkg/mailer/server.go
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReceivedMessage mensaje aceptado por el servidor en memoria
+type ReceivedMessage struct {
+	From       string
+	To         []string
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// Server servidor SMTP en memoria para desarrollo y pruebas de integración.
+// Acepta cualquier remitente, destinatario y credencial AUTH PLAIN; no implementa
+// STARTTLS ni reenvía correo. Puede simular rechazos para probar los reintentos.
+// No usar en producción.
+type Server struct {
+	mu        sync.Mutex
+	messages  []ReceivedMessage
+	failNext  int
+	failCode  int
+	onMessage func(ReceivedMessage)
+
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer crea un servidor sin mensajes
+func NewServer() *Server {
+	return &Server{conns: make(map[net.Conn]struct{})}
+}
+
+// OnMessage registra una función que se invoca con cada mensaje aceptado
+func (s *Server) OnMessage(fn func(ReceivedMessage)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onMessage = fn
+}
+
+// FailNext rechaza las próximas n entregas con el código indicado
+// (4xx: error temporal que se reintenta; 5xx: rechazo definitivo)
+func (s *Server) FailNext(n, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext, s.failCode = n, code
+}
+
+// Messages retorna una copia de los mensajes recibidos
+func (s *Server) Messages() []ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ReceivedMessage(nil), s.messages...)
+}
+
+// Reset descarta los mensajes recibidos y los rechazos pendientes
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages, s.failNext = nil, 0
+}
+
+// Start escucha en addr (ej. "127.0.0.1:0") y atiende conexiones en segundo plano.
+// Retorna la dirección efectiva.
+func (s *Server) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = struct{}{}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serveConn(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), nil
+}
+
+// Close detiene el servidor y cierra las conexiones abiertas
+func (s *Server) Close() error {
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// serveConn atiende una sesión SMTP hasta QUIT o error
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) bool {
+		_, err := fmt.Fprintf(conn, format+"\r\n", args...)
+		return err == nil
+	}
+
+	if !reply("220 localhost ESMTP GAMC (servidor de desarrollo)") {
+		return
+	}
+
+	var from string
+	var to []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		ok := true
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			ok = reply("250-localhost") && reply("250-8BITMIME") && reply("250-AUTH PLAIN") && reply("250 SIZE 10485760")
+		case "HELO":
+			ok = reply("250 localhost")
+		case "AUTH":
+			ok = reply("235 2.7.0 Autenticación aceptada")
+		case "MAIL":
+			from, to = extractPath(arg), nil
+			ok = reply("250 2.1.0 OK")
+		case "RCPT":
+			if from == "" {
+				ok = reply("503 5.5.1 MAIL requerido antes de RCPT")
+				break
+			}
+			to = append(to, extractPath(arg))
+			ok = reply("250 2.1.5 OK")
+		case "DATA":
+			if len(to) == 0 {
+				ok = reply("503 5.5.1 RCPT requerido antes de DATA")
+				break
+			}
+			if !reply("354 Termine con <CRLF>.<CRLF>") {
+				return
+			}
+			data, err := readData(reader)
+			if err != nil {
+				return
+			}
+			ok = s.deliver(ReceivedMessage{From: from, To: to, Data: data, ReceivedAt: time.Now()}, reply)
+			from, to = "", nil
+		case "RSET":
+			from, to = "", nil
+			ok = reply("250 2.0.0 OK")
+		case "NOOP":
+			ok = reply("250 2.0.0 OK")
+		case "QUIT":
+			reply("221 2.0.0 Adiós")
+			return
+		default:
+			ok = reply("502 5.5.2 Comando no soportado")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// deliver almacena el mensaje o simula un rechazo pendiente
+func (s *Server) deliver(msg ReceivedMessage, reply func(string, ...interface{}) bool) bool {
+	s.mu.Lock()
+	if s.failNext > 0 {
+		s.failNext--
+		code := s.failCode
+		s.mu.Unlock()
+		return reply("%d Rechazo simulado", code)
+	}
+	s.messages = append(s.messages, msg)
+	onMessage := s.onMessage
+	s.mu.Unlock()
+
+	if onMessage != nil {
+		onMessage(msg)
+	}
+	return reply("250 2.0.0 Mensaje aceptado")
+}
+
+// readData lee el contenido de DATA hasta la línea "." y revierte el dot-stuffing
+func readData(reader *bufio.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return buf.Bytes(), nil
+		}
+		buf.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+// extractPath obtiene la dirección de "FROM:<a@b>" o "TO:<a@b>"
+func extractPath(arg string) string {
+	start, end := strings.Index(arg, "<"), strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return arg[start+1 : end]
+}
@@ -0,0 +1,80 @@
+// pkg/validator/password_rules.go
+package validator
+
+import "sync"
+
+// PasswordRules reglas de complejidad de contraseñas
+type PasswordRules struct {
+	MinLength            int
+	MaxLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireNumbers       bool
+	RequireSpecialChars  bool
+	SpecialChars         string
+	BlockCommonPasswords bool
+	BlockSequentialChars bool
+}
+
+// DefaultPasswordRules reglas usadas mientras no se registre una política
+func DefaultPasswordRules() PasswordRules {
+	return PasswordRules{
+		MinLength:            8,
+		MaxLength:            128,
+		RequireUppercase:     true,
+		RequireLowercase:     true,
+		RequireNumbers:       true,
+		RequireSpecialChars:  true,
+		SpecialChars:         "@$!%*?&",
+		BlockCommonPasswords: true,
+		BlockSequentialChars: true,
+	}
+}
+
+var (
+	rulesMu       sync.RWMutex
+	rulesProvider func() PasswordRules
+)
+
+// SetPasswordRulesProvider registra la fuente de las reglas vigentes
+// (la política de seguridad persistida); se consulta en cada validación.
+func SetPasswordRulesProvider(provider func() PasswordRules) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rulesProvider = provider
+}
+
+// CurrentPasswordRules retorna las reglas vigentes
+func CurrentPasswordRules() PasswordRules {
+	rulesMu.RLock()
+	provider := rulesProvider
+	rulesMu.RUnlock()
+
+	if provider == nil {
+		return DefaultPasswordRules()
+	}
+	return provider()
+}
+
+var (
+	breachMu      sync.RWMutex
+	breachChecker func(password string) bool
+)
+
+// SetBreachedPasswordChecker registra la consulta al corpus local de contraseñas
+// filtradas; complementa la lista fija de contraseñas comunes y se aplica aunque
+// la política desactive BlockCommonPasswords.
+func SetBreachedPasswordChecker(checker func(password string) bool) {
+	breachMu.Lock()
+	defer breachMu.Unlock()
+	breachChecker = checker
+}
+
+// isBreachedPassword consulta el corpus registrado (false si no hay ninguno)
+func isBreachedPassword(password string) bool {
+	breachMu.RLock()
+	checker := breachChecker
+	breachMu.RUnlock()
+
+	return checker != nil && checker(password)
+}
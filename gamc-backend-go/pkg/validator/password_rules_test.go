@@ -0,0 +1,57 @@
+// pkg/validator/password_rules_test.go
+package validator
+
+import (
+	"testing"
+)
+
+const breachedMessage = "Aparece en filtraciones de contraseñas conocidas, elija otra"
+
+func containsMessage(errors []string, message string) bool {
+	for _, e := range errors {
+		if e == message {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidatePasswordBreachedCorpus(t *testing.T) {
+	SetBreachedPasswordChecker(func(password string) bool { return password == "Alcaldia!2019" })
+	t.Cleanup(func() { SetBreachedPasswordChecker(nil) })
+
+	tests := []struct {
+		name         string
+		blockCommon  bool
+		password     string
+		wantBreached bool
+	}{
+		{name: "lista común activada", blockCommon: true, password: "Alcaldia!2019", wantBreached: true},
+		{name: "lista común desactivada", blockCommon: false, password: "Alcaldia!2019", wantBreached: true},
+		{name: "contraseña no filtrada", blockCommon: false, password: "Cochabamba!7412", wantBreached: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultPasswordRules()
+			rules.BlockCommonPasswords = tt.blockCommon
+
+			valid, errors := ValidatePassword(tt.password, rules)
+			if got := containsMessage(errors, breachedMessage); got != tt.wantBreached {
+				t.Errorf("ValidatePassword(%q) = %v, se esperaba filtrada = %v", tt.password, errors, tt.wantBreached)
+			}
+			if valid == tt.wantBreached {
+				t.Errorf("ValidatePassword(%q) válida = %v", tt.password, valid)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordWithoutBreachedCorpus(t *testing.T) {
+	SetBreachedPasswordChecker(nil)
+
+	valid, errors := ValidatePassword("Alcaldia!2019", DefaultPasswordRules())
+	if !valid || containsMessage(errors, breachedMessage) {
+		t.Errorf("ValidatePassword sin corpus = %v, %v", valid, errors)
+	}
+}
@@ -144,18 +144,23 @@ func IsValidGAMCEmail(email string) bool {
 	return validate.Var(email, "email,gamc_email") == nil
 }
 
-// IsValidPassword verifica si una contraseña cumple requisitos
+// IsValidPassword verifica si una contraseña cumple las reglas vigentes
 func IsValidPassword(password string) (bool, []string) {
+	return ValidatePassword(password, CurrentPasswordRules())
+}
+
+// ValidatePassword verifica una contraseña contra un conjunto de reglas
+func ValidatePassword(password string, rules PasswordRules) (bool, []string) {
 	var errors []string
 
 	// Longitud mínima
-	if len(password) < 8 {
-		errors = append(errors, "Debe tener al menos 8 caracteres")
+	if len(password) < rules.MinLength {
+		errors = append(errors, fmt.Sprintf("Debe tener al menos %d caracteres", rules.MinLength))
 	}
 
 	// Longitud máxima
-	if len(password) > 128 {
-		errors = append(errors, "No puede tener más de 128 caracteres")
+	if len(password) > rules.MaxLength {
+		errors = append(errors, fmt.Sprintf("No puede tener más de %d caracteres", rules.MaxLength))
 	}
 
 	// Verificar tipos de caracteres
@@ -172,30 +177,33 @@ func IsValidPassword(password string) (bool, []string) {
 			hasUpper = true
 		case unicode.IsDigit(char):
 			hasDigit = true
-		case strings.ContainsRune("@$!%*?&", char):
+		case strings.ContainsRune(rules.SpecialChars, char):
 			hasSpecial = true
 		}
 	}
 
-	if !hasLower {
+	if rules.RequireLowercase && !hasLower {
 		errors = append(errors, "Debe contener al menos una letra minúscula")
 	}
-	if !hasUpper {
+	if rules.RequireUppercase && !hasUpper {
 		errors = append(errors, "Debe contener al menos una letra mayúscula")
 	}
-	if !hasDigit {
+	if rules.RequireNumbers && !hasDigit {
 		errors = append(errors, "Debe contener al menos un número")
 	}
-	if !hasSpecial {
-		errors = append(errors, "Debe contener al menos un carácter especial (@$!%*?&)")
+	if rules.RequireSpecialChars && !hasSpecial {
+		errors = append(errors, fmt.Sprintf("Debe contener al menos un carácter especial (%s)", rules.SpecialChars))
 	}
 
-	// Verificar patrones débiles
-	if isCommonPassword(password) {
+	// Verificar patrones débiles; el corpus de filtraciones se consulta siempre que esté
+	// cargado, aunque la política desactive la lista de contraseñas comunes
+	if rules.BlockCommonPasswords && isCommonPassword(password) {
 		errors = append(errors, "No puede ser una contraseña común")
+	} else if isBreachedPassword(password) {
+		errors = append(errors, "Aparece en filtraciones de contraseñas conocidas, elija otra")
 	}
 
-	if hasSequentialChars(password) {
+	if rules.BlockSequentialChars && hasSequentialChars(password) {
 		errors = append(errors, "No puede contener secuencias obvias (123, abc, etc.)")
 	}
 
@@ -0,0 +1,204 @@
+// pkg/webauthn/authenticator.go
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Authenticator autenticador de software con credenciales residentes ES256 en memoria.
+// Reproduce lo que hacen el navegador y el autenticador para probar las ceremonias
+// sin hardware, del mismo modo que ldap.Server y mailer.Server reemplazan a los
+// servidores reales.
+type Authenticator struct {
+	// UserVerification controla la bandera UV (PIN o biometría); true por defecto
+	UserVerification bool
+	// BackupEligible marca las credenciales como passkeys sincronizables
+	BackupEligible bool
+
+	mu          sync.Mutex
+	aaguid      []byte
+	credentials []*softCredential
+}
+
+type softCredential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// ErrNoCredential el autenticador no tiene una credencial utilizable para la petición
+var ErrNoCredential = errors.New("webauthn: no hay credenciales para este sitio")
+
+// NewAuthenticator crea un autenticador de software vacío
+func NewAuthenticator() *Authenticator {
+	return &Authenticator{
+		UserVerification: true,
+		aaguid:           make([]byte, 16),
+	}
+}
+
+// Create ejecuta navigator.credentials.create() desde origin
+func (a *Authenticator) Create(origin string, options *CreationOptions) (*RegistrationResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	supported := false
+	for _, param := range options.PubKeyCredParams {
+		if param.Type == "public-key" && param.Alg == AlgES256 {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(options.RP.ID, excluded.ID) != nil {
+			return nil, errors.New("webauthn: el autenticador ya tiene una credencial para esta cuenta")
+		}
+	}
+
+	userHandle, err := DecodeID(options.User.ID)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: user.id inválido: %w", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	credential := &softCredential{id: id, rpID: options.RP.ID, userHandle: userHandle, key: key}
+
+	publicKey, err := encodeEC2Key(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	attested := make([]byte, 0, 18+len(id)+len(publicKey))
+	attested = append(attested, a.aaguid...)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, publicKey...)
+	authData := a.authenticatorData(credential, flagAttestedCredData, attested)
+
+	clientDataJSON, err := clientData("webauthn.create", options.Challenge, origin)
+	if err != nil {
+		return nil, err
+	}
+	attestationObject, err := encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.credentials = append(a.credentials, credential)
+
+	return &RegistrationResponse{
+		ID:    EncodeID(id),
+		RawID: EncodeID(id),
+		Type:  "public-key",
+		Response: AttestationResponse{
+			ClientDataJSON:    EncodeID(clientDataJSON),
+			AttestationObject: EncodeID(attestationObject),
+			Transports:        []string{"internal"},
+		},
+		AuthenticatorAttachment: "platform",
+	}, nil
+}
+
+// Get ejecuta navigator.credentials.get() desde origin. Sin allowCredentials usa la
+// primera credencial residente del sitio, como al elegir una passkey en el diálogo.
+func (a *Authenticator) Get(origin string, options *RequestOptions) (*AuthenticationResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var credential *softCredential
+	if len(options.AllowCredentials) == 0 {
+		for _, c := range a.credentials {
+			if c.rpID == options.RPID {
+				credential = c
+				break
+			}
+		}
+	}
+	for _, allowed := range options.AllowCredentials {
+		if credential = a.find(options.RPID, allowed.ID); credential != nil {
+			break
+		}
+	}
+	if credential == nil {
+		return nil, ErrNoCredential
+	}
+
+	credential.signCount++
+	authData := a.authenticatorData(credential, 0, nil)
+
+	clientDataJSON, err := clientData("webauthn.get", options.Challenge, origin)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, credential.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthenticationResponse{
+		ID:    EncodeID(credential.id),
+		RawID: EncodeID(credential.id),
+		Type:  "public-key",
+		Response: AssertionResponse{
+			ClientDataJSON:    EncodeID(clientDataJSON),
+			AuthenticatorData: EncodeID(authData),
+			Signature:         EncodeID(signature),
+			UserHandle:        EncodeID(credential.userHandle),
+		},
+		AuthenticatorAttachment: "platform",
+	}, nil
+}
+
+// find busca una credencial por sitio e ID en base64url
+func (a *Authenticator) find(rpID, id string) *softCredential {
+	for _, c := range a.credentials {
+		if c.rpID == rpID && EncodeID(c.id) == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authenticatorData(credential *softCredential, extraFlags byte, attested []byte) []byte {
+	flags := flagUserPresent | extraFlags
+	if a.UserVerification {
+		flags |= flagUserVerified
+	}
+	if a.BackupEligible {
+		flags |= flagBackupEligible | flagBackedUp
+	}
+
+	rpIDHash := sha256.Sum256([]byte(credential.rpID))
+	data := make([]byte, 0, 37+len(attested))
+	data = append(data, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, credential.signCount)
+	return append(data, attested...)
+}
+
+func clientData(ceremony, challenge, origin string) ([]byte, error) {
+	return json.Marshal(collectedClientData{Type: ceremony, Challenge: challenge, Origin: origin})
+}
@@ -0,0 +1,281 @@
+// pkg/webauthn/cbor.go
+package webauthn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Subconjunto de CBOR (RFC 8949) suficiente para attestationObject, authenticatorData
+// y claves COSE: enteros, cadenas, arreglos, mapas, booleanos y null, siempre con
+// longitud definida. Los enteros se decodifican como int64 y las cadenas de bytes
+// como []byte; las claves de mapa conservan su tipo (int64 o string).
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorSimple   = 7
+)
+
+// maxCBORDepth evita recursión ilimitada con entradas maliciosas
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("webauthn: CBOR truncado")
+
+// decodeCBOR decodifica el primer elemento y retorna los bytes restantes.
+// authenticatorData concatena la clave COSE con las extensiones, por eso el resto importa.
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	d := &cborDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, d.data[d.pos:], nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("webauthn: CBOR demasiado anidado")
+	}
+	if d.pos >= len(d.data) {
+		return nil, errCBORTruncated
+	}
+
+	initial := d.data[d.pos]
+	d.pos++
+	major := initial >> 5
+	info := initial & 0x1f
+
+	if major == majorSimple {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("webauthn: valor CBOR simple no soportado (%d)", info)
+		}
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("webauthn: entero CBOR fuera de rango")
+		}
+		return int64(arg), nil
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("webauthn: entero CBOR fuera de rango")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		raw, err := d.take(arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case majorArray:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case majorMap:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		entries := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("webauthn: clave de mapa CBOR no soportada")
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := entries[key]; exists {
+				return nil, errors.New("webauthn: clave de mapa CBOR duplicada")
+			}
+			entries[key] = value
+		}
+		return entries, nil
+	default:
+		return nil, fmt.Errorf("webauthn: tipo CBOR no soportado (%d)", major)
+	}
+}
+
+// argument lee el argumento del encabezado; las longitudes indefinidas (31) no se aceptan
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.take(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.take(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.take(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.take(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	default:
+		return 0, errors.New("webauthn: longitud CBOR indefinida no soportada")
+	}
+}
+
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return raw, nil
+}
+
+// ========================================
+// CODIFICACIÓN (usada por el autenticador de software)
+// ========================================
+
+// encodeCBOR codifica en forma canónica: las claves de mapa se ordenan según RFC 8949 §4.2.1
+func encodeCBOR(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCBOR(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case int:
+		writeCBORInt(buf, int64(v))
+	case int64:
+		writeCBORInt(buf, v)
+	case []byte:
+		writeCBORHeader(buf, majorBytes, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		writeCBORHeader(buf, majorText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		writeCBORHeader(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			if err := writeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		type entry struct {
+			key   []byte
+			value interface{}
+		}
+		entries := make([]entry, 0, len(v))
+		for key, item := range v {
+			encodedKey, err := encodeCBOR(key)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{key: encodedKey, value: item})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		writeCBORHeader(buf, majorMap, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			if err := writeCBOR(buf, e.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("webauthn: tipo no codificable en CBOR: %T", value)
+	}
+	return nil
+}
+
+func writeCBORInt(buf *bytes.Buffer, v int64) {
+	if v >= 0 {
+		writeCBORHeader(buf, majorUnsigned, uint64(v))
+		return
+	}
+	writeCBORHeader(buf, majorNegative, uint64(-1-v))
+}
+
+func writeCBORHeader(buf *bytes.Buffer, major byte, arg uint64) {
+	prefix := major << 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(prefix | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(prefix | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(prefix | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(prefix | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(prefix | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
@@ -0,0 +1,552 @@
+// pkg/webauthn/webauthn.go
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Verificación de las ceremonias de registro y autenticación de WebAuthn Level 2
+// (https://www.w3.org/TR/webauthn-2/ §7). El servidor pide atestación "none": no se
+// confía en el fabricante del autenticador, solo en la posesión de la clave.
+
+// Valores de userVerification y residentKey
+const (
+	VerificationRequired  = "required"
+	VerificationPreferred = "preferred"
+
+	ResidentKeyRequired  = "required"
+	ResidentKeyPreferred = "preferred"
+)
+
+// DefaultTimeout tiempo sugerido al navegador para completar la ceremonia
+const DefaultTimeout = 5 * time.Minute
+
+// challengeSize bytes aleatorios de cada desafío (mínimo 16 según la especificación)
+const challengeSize = 32
+
+// Banderas de authenticatorData
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagBackupEligible   = 0x08
+	flagBackedUp         = 0x10
+	flagAttestedCredData = 0x40
+	flagExtensionData    = 0x80
+)
+
+// Errores de verificación
+var (
+	ErrInvalidResponse     = errors.New("webauthn: respuesta del autenticador inválida")
+	ErrChallengeMismatch   = errors.New("webauthn: el desafío no coincide")
+	ErrOriginMismatch      = errors.New("webauthn: origen no permitido")
+	ErrRPIDMismatch        = errors.New("webauthn: la credencial pertenece a otro sitio")
+	ErrUserNotPresent      = errors.New("webauthn: el autenticador no confirmó la presencia del usuario")
+	ErrUserNotVerified     = errors.New("webauthn: el autenticador no verificó al usuario")
+	ErrInvalidSignature    = errors.New("webauthn: firma inválida")
+	ErrSignCountRegression = errors.New("webauthn: el contador de firmas retrocedió (posible autenticador clonado)")
+)
+
+// RelyingParty identidad del sitio ante los autenticadores
+type RelyingParty struct {
+	ID      string   // Dominio registrable, p. ej. "gamc.gob.bo"
+	Name    string   // Nombre visible en el diálogo del navegador
+	Origins []string // Orígenes del frontend autorizados, p. ej. "https://gamc.gob.bo"
+}
+
+// ========================================
+// OPCIONES PARA EL NAVEGADOR (PublicKeyCredential*OptionsJSON)
+// ========================================
+
+// RelyingPartyEntity identidad del sitio en las opciones de creación
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity cuenta para la que se crea la credencial; ID es el user handle en base64url
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter algoritmo aceptado
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor credencial conocida (excluir al registrar, permitir al autenticar)
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection requisitos sobre el autenticador
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions argumento de navigator.credentials.create({ publicKey })
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions argumento de navigator.credentials.get({ publicKey }).
+// Sin AllowCredentials el navegador ofrece las passkeys guardadas para el sitio.
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// NewChallenge genera un desafío aleatorio en base64url
+func NewChallenge() (string, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", fmt.Errorf("webauthn: error al generar desafío: %w", err)
+	}
+	return EncodeID(challenge), nil
+}
+
+// EncodeID codifica IDs de credencial, user handles y desafíos en base64url sin relleno
+func EncodeID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// DecodeID decodifica base64url tolerando el relleno que agregan algunas librerías
+func DecodeID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// CreationOptions arma las opciones de registro de una passkey (credencial residente)
+func (rp *RelyingParty) CreationOptions(user UserEntity, challenge string, exclude []CredentialDescriptor) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+
+	return &CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:               user,
+		PubKeyCredParams:   params,
+		Timeout:            DefaultTimeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        ResidentKeyRequired,
+			RequireResidentKey: true,
+			UserVerification:   VerificationRequired,
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions arma las opciones de autenticación
+func (rp *RelyingParty) RequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) *RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          DefaultTimeout.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+// ========================================
+// RESPUESTAS DEL NAVEGADOR (PublicKeyCredential.toJSON())
+// ========================================
+
+// AttestationResponse respuesta del autenticador al registrar
+type AttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// RegistrationResponse resultado de navigator.credentials.create()
+type RegistrationResponse struct {
+	ID                      string              `json:"id"`
+	RawID                   string              `json:"rawId"`
+	Type                    string              `json:"type"`
+	Response                AttestationResponse `json:"response"`
+	AuthenticatorAttachment string              `json:"authenticatorAttachment,omitempty"`
+}
+
+// AssertionResponse respuesta del autenticador al autenticar
+type AssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// AuthenticationResponse resultado de navigator.credentials.get()
+type AuthenticationResponse struct {
+	ID                      string            `json:"id"`
+	RawID                   string            `json:"rawId"`
+	Type                    string            `json:"type"`
+	Response                AssertionResponse `json:"response"`
+	AuthenticatorAttachment string            `json:"authenticatorAttachment,omitempty"`
+}
+
+// CredentialID decodifica el ID de la credencial usada
+func (r *AuthenticationResponse) CredentialID() ([]byte, error) {
+	return credentialID(r.ID, r.RawID, r.Type)
+}
+
+// Credential credencial verificada lista para guardar
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // Clave COSE tal como la entregó el autenticador
+	Algorithm      int
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool // Passkey sincronizable entre dispositivos
+	BackedUp       bool
+}
+
+// AssertionResult datos de una autenticación verificada
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+	BackedUp     bool
+	UserHandle   []byte
+}
+
+// ========================================
+// VERIFICACIÓN
+// ========================================
+
+// VerifyRegistration valida la respuesta de create() contra el desafío emitido (§7.1)
+func (rp *RelyingParty) VerifyRegistration(resp *RegistrationResponse, challenge string, requireUserVerification bool) (*Credential, error) {
+	rawID, err := credentialID(resp.ID, resp.RawID, resp.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataJSON, err := DecodeID(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: clientDataJSON", ErrInvalidResponse)
+	}
+	if err := rp.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := DecodeID(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestationObject", ErrInvalidResponse)
+	}
+	decoded, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: attestationObject", ErrInvalidResponse)
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: attestationObject", ErrInvalidResponse)
+	}
+	format, _ := attestation["fmt"].(string)
+	rawAuthData, _ := attestation["authData"].([]byte)
+	statement, _ := attestation["attStmt"].(map[interface{}]interface{})
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedCredData == 0 {
+		return nil, fmt.Errorf("%w: faltan los datos de la credencial", ErrInvalidResponse)
+	}
+	if !bytes.Equal(authData.credentialID, rawID) {
+		return nil, fmt.Errorf("%w: el ID de la credencial no coincide", ErrInvalidResponse)
+	}
+
+	publicKey, err := ParsePublicKey(authData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if err := verifyAttestationStatement(format, statement, rawAuthData, clientDataHash[:], publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             rawID,
+		PublicKey:      authData.publicKey,
+		Algorithm:      publicKey.Algorithm,
+		SignCount:      authData.signCount,
+		AAGUID:         authData.aaguid,
+		Transports:     resp.Response.Transports,
+		UserVerified:   authData.flags&flagUserVerified != 0,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+		BackedUp:       authData.flags&flagBackedUp != 0,
+	}, nil
+}
+
+// VerifyAssertion valida la respuesta de get() con la clave guardada de la credencial (§7.2).
+// storedSignCount es el último contador conocido; los autenticadores que no llevan
+// contador (muchas passkeys sincronizadas) siempre informan 0.
+func (rp *RelyingParty) VerifyAssertion(resp *AuthenticationResponse, challenge string, publicKeyCOSE []byte, storedSignCount uint32, requireUserVerification bool) (*AssertionResult, error) {
+	if _, err := resp.CredentialID(); err != nil {
+		return nil, err
+	}
+
+	clientDataJSON, err := DecodeID(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: clientDataJSON", ErrInvalidResponse)
+	}
+	if err := rp.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeID(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: authenticatorData", ErrInvalidResponse)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+
+	signature, err := DecodeID(resp.Response.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature", ErrInvalidResponse)
+	}
+	publicKey, err := ParsePublicKey(publicKeyCOSE)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if !publicKey.Verify(append(append([]byte(nil), rawAuthData...), clientDataHash[:]...), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return nil, ErrSignCountRegression
+	}
+
+	var userHandle []byte
+	if resp.Response.UserHandle != "" {
+		if userHandle, err = DecodeID(resp.Response.UserHandle); err != nil {
+			return nil, fmt.Errorf("%w: userHandle", ErrInvalidResponse)
+		}
+	}
+
+	return &AssertionResult{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+		BackedUp:     authData.flags&flagBackedUp != 0,
+		UserHandle:   userHandle,
+	}, nil
+}
+
+// collectedClientData campos de clientDataJSON que verifica el servidor
+type collectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony, challenge string) error {
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("%w: clientDataJSON", ErrInvalidResponse)
+	}
+	if clientData.Type != ceremony {
+		return fmt.Errorf("%w: tipo de ceremonia %q", ErrInvalidResponse, clientData.Type)
+	}
+	if clientData.Challenge == "" || strings.TrimRight(clientData.Challenge, "=") != strings.TrimRight(challenge, "=") {
+		return ErrChallengeMismatch
+	}
+	if clientData.CrossOrigin {
+		return ErrOriginMismatch
+	}
+	for _, origin := range rp.Origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+	return ErrOriginMismatch
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *authenticatorData, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return ErrRPIDMismatch
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if requireUserVerification && authData.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+// verifyAttestationStatement acepta "none" y "packed" (autoatestación o con certificado).
+// La cadena del certificado no se valida: la atestación no se usa para decidir confianza.
+func verifyAttestationStatement(format string, statement map[interface{}]interface{}, authData, clientDataHash []byte, credentialKey *PublicKey) error {
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return fmt.Errorf("%w: atestación none con contenido", ErrInvalidResponse)
+		}
+		return nil
+
+	case "packed":
+		alg, _ := statement["alg"].(int64)
+		signature, _ := statement["sig"].([]byte)
+		signed := append(append([]byte(nil), authData...), clientDataHash...)
+
+		if chain, ok := statement["x5c"].([]interface{}); ok && len(chain) > 0 {
+			leaf, _ := chain[0].([]byte)
+			cert, err := x509.ParseCertificate(leaf)
+			if err != nil {
+				return fmt.Errorf("%w: certificado de atestación", ErrInvalidResponse)
+			}
+			if cert.CheckSignature(x509SignatureAlgorithm(int(alg)), signed, signature) != nil {
+				return ErrInvalidSignature
+			}
+			return nil
+		}
+
+		if int(alg) != credentialKey.Algorithm || !credentialKey.Verify(signed, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: formato de atestación %q no soportado", ErrInvalidResponse, format)
+	}
+}
+
+func x509SignatureAlgorithm(alg int) x509.SignatureAlgorithm {
+	switch alg {
+	case AlgES256:
+		return x509.ECDSAWithSHA256
+	case AlgEdDSA:
+		return x509.PureEd25519
+	case AlgRS256:
+		return x509.SHA256WithRSA
+	default:
+		return x509.UnknownSignatureAlgorithm
+	}
+}
+
+// credentialID decodifica rawId y comprueba que coincida con id
+func credentialID(id, rawID, credentialType string) ([]byte, error) {
+	if credentialType != "public-key" {
+		return nil, fmt.Errorf("%w: tipo de credencial %q", ErrInvalidResponse, credentialType)
+	}
+	if rawID == "" {
+		rawID = id
+	}
+	decoded, err := DecodeID(rawID)
+	if err != nil || len(decoded) == 0 || len(decoded) > 1023 {
+		return nil, fmt.Errorf("%w: ID de credencial", ErrInvalidResponse)
+	}
+	if id != "" && strings.TrimRight(id, "=") != EncodeID(decoded) {
+		return nil, fmt.Errorf("%w: id y rawId no coinciden", ErrInvalidResponse)
+	}
+	return decoded, nil
+}
+
+// ========================================
+// AUTHENTICATOR DATA (§6.1)
+// ========================================
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, fmt.Errorf("%w: authenticatorData demasiado corto", ErrInvalidResponse)
+	}
+
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if data.flags&flagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: datos de credencial truncados", ErrInvalidResponse)
+		}
+		data.aaguid = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, fmt.Errorf("%w: ID de credencial truncado", ErrInvalidResponse)
+		}
+		data.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, afterKey, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: clave pública", ErrInvalidResponse)
+		}
+		data.publicKey = rest[:len(rest)-len(afterKey)]
+		rest = afterKey
+	}
+
+	if data.flags&flagExtensionData != 0 {
+		extensions, afterExtensions, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: extensiones", ErrInvalidResponse)
+		}
+		if _, ok := extensions.(map[interface{}]interface{}); !ok {
+			return nil, fmt.Errorf("%w: extensiones", ErrInvalidResponse)
+		}
+		rest = afterExtensions
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: datos sobrantes en authenticatorData", ErrInvalidResponse)
+	}
+	return data, nil
+}
@@ -0,0 +1,358 @@
+// pkg/webauthn/webauthn_test.go
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+const (
+	testRPID   = "gamc.gob.bo"
+	testOrigin = "https://gamc.gob.bo"
+)
+
+var testUserHandle = []byte("0123456789abcdef")
+
+func testRelyingParty() *RelyingParty {
+	return &RelyingParty{ID: testRPID, Name: "GAMC", Origins: []string{testOrigin}}
+}
+
+func newChallenge(t *testing.T) string {
+	t.Helper()
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge: %v", err)
+	}
+	return challenge
+}
+
+// register ejecuta la ceremonia de registro completa con el autenticador de software
+func register(t *testing.T, rp *RelyingParty, authenticator *Authenticator) *Credential {
+	t.Helper()
+	challenge := newChallenge(t)
+	options := rp.CreationOptions(UserEntity{ID: EncodeID(testUserHandle), Name: "ana@gamc.gob.bo", DisplayName: "Ana"}, challenge, nil)
+
+	resp, err := authenticator.Create(testOrigin, options)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	credential, err := rp.VerifyRegistration(resp, challenge, true)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+	return credential
+}
+
+// assert ejecuta navigator.credentials.get() y retorna la respuesta con su desafío
+func assert(t *testing.T, rp *RelyingParty, authenticator *Authenticator, origin string, credential *Credential) (*AuthenticationResponse, string) {
+	t.Helper()
+	challenge := newChallenge(t)
+	var allow []CredentialDescriptor
+	if credential != nil {
+		allow = []CredentialDescriptor{{Type: "public-key", ID: EncodeID(credential.ID)}}
+	}
+
+	resp, err := authenticator.Get(origin, rp.RequestOptions(challenge, allow, VerificationRequired))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return resp, challenge
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	authenticator.BackupEligible = true
+
+	credential := register(t, rp, authenticator)
+	if credential.Algorithm != AlgES256 {
+		t.Errorf("Algorithm = %d, se esperaba %d", credential.Algorithm, AlgES256)
+	}
+	if !credential.UserVerified || !credential.BackupEligible || !credential.BackedUp {
+		t.Errorf("banderas = UV %v BE %v BS %v, se esperaban todas", credential.UserVerified, credential.BackupEligible, credential.BackedUp)
+	}
+	if len(credential.AAGUID) != 16 {
+		t.Errorf("AAGUID de %d bytes", len(credential.AAGUID))
+	}
+
+	// Dos logins seguidos con la passkey residente (sin allowCredentials): el contador avanza
+	storedSignCount := credential.SignCount
+	for i := 1; i <= 2; i++ {
+		resp, challenge := assert(t, rp, authenticator, testOrigin, nil)
+
+		rawID, err := resp.CredentialID()
+		if err != nil || !bytes.Equal(rawID, credential.ID) {
+			t.Fatalf("login %d: CredentialID = %x, %v", i, rawID, err)
+		}
+
+		result, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, storedSignCount, true)
+		if err != nil {
+			t.Fatalf("login %d: VerifyAssertion: %v", i, err)
+		}
+		if result.SignCount != storedSignCount+1 {
+			t.Errorf("login %d: SignCount = %d, se esperaba %d", i, result.SignCount, storedSignCount+1)
+		}
+		if !bytes.Equal(result.UserHandle, testUserHandle) {
+			t.Errorf("login %d: UserHandle = %q", i, result.UserHandle)
+		}
+		if !result.UserVerified {
+			t.Errorf("login %d: UserVerified = false", i)
+		}
+		storedSignCount = result.SignCount
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		origin    string
+		rpID      string
+		noUV      bool
+		challenge string // vacío = el desafío emitido
+		want      error
+	}{
+		{name: "desafío distinto", challenge: "otro-desafio", want: ErrChallengeMismatch},
+		{name: "origen no permitido", origin: "https://gamc.gob.bo.evil.example", want: ErrOriginMismatch},
+		{name: "rpId de otro sitio", rpID: "evil.example", want: ErrRPIDMismatch},
+		{name: "sin verificación del usuario", noUV: true, want: ErrUserNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := testRelyingParty()
+			authenticator := NewAuthenticator()
+			authenticator.UserVerification = !tt.noUV
+
+			challenge := newChallenge(t)
+			options := rp.CreationOptions(UserEntity{ID: EncodeID(testUserHandle), Name: "ana@gamc.gob.bo"}, challenge, nil)
+			if tt.rpID != "" {
+				options.RP.ID = tt.rpID
+			}
+			origin := testOrigin
+			if tt.origin != "" {
+				origin = tt.origin
+			}
+
+			resp, err := authenticator.Create(origin, options)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			expected := challenge
+			if tt.challenge != "" {
+				expected = tt.challenge
+			}
+			if _, err := rp.VerifyRegistration(resp, expected, true); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyRegistration = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejectsTamperedID(t *testing.T) {
+	rp := testRelyingParty()
+	challenge := newChallenge(t)
+	resp, err := NewAuthenticator().Create(testOrigin, rp.CreationOptions(UserEntity{ID: EncodeID(testUserHandle)}, challenge, nil))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// El ID declarado debe ser el de los datos atestados
+	other := make([]byte, 32)
+	rand.Read(other)
+	resp.ID = EncodeID(other)
+	resp.RawID = resp.ID
+	if _, err := rp.VerifyRegistration(resp, challenge, true); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("VerifyRegistration = %v, se esperaba %v", err, ErrInvalidResponse)
+	}
+}
+
+func TestExcludeCredentials(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	credential := register(t, rp, authenticator)
+
+	exclude := []CredentialDescriptor{{Type: "public-key", ID: EncodeID(credential.ID)}}
+	options := rp.CreationOptions(UserEntity{ID: EncodeID(testUserHandle)}, newChallenge(t), exclude)
+	if _, err := authenticator.Create(testOrigin, options); err == nil {
+		t.Error("Create con la credencial excluida no falló")
+	}
+}
+
+func TestVerifyAssertionSignature(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	credential := register(t, rp, authenticator)
+	other := register(t, rp, NewAuthenticator())
+
+	t.Run("firma alterada", func(t *testing.T) {
+		resp, challenge := assert(t, rp, authenticator, testOrigin, credential)
+		signature, _ := DecodeID(resp.Response.Signature)
+		signature[len(signature)-1] ^= 0xff
+		resp.Response.Signature = EncodeID(signature)
+
+		if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrInvalidSignature)
+		}
+	})
+
+	t.Run("clave de otra credencial", func(t *testing.T) {
+		resp, challenge := assert(t, rp, authenticator, testOrigin, credential)
+		if _, err := rp.VerifyAssertion(resp, challenge, other.PublicKey, 0, true); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrInvalidSignature)
+		}
+	})
+
+	t.Run("contador alterado tras firmar", func(t *testing.T) {
+		resp, challenge := assert(t, rp, authenticator, testOrigin, credential)
+		authData, _ := DecodeID(resp.Response.AuthenticatorData)
+		binary.BigEndian.PutUint32(authData[33:37], 1000)
+		resp.Response.AuthenticatorData = EncodeID(authData)
+
+		if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrInvalidSignature)
+		}
+	})
+}
+
+func TestVerifyAssertionSignCountRegression(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	credential := register(t, rp, authenticator)
+
+	resp, challenge := assert(t, rp, authenticator, testOrigin, credential)
+	result, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true)
+	if err != nil {
+		t.Fatalf("VerifyAssertion: %v", err)
+	}
+
+	// Un clon del autenticador repite un contador ya visto
+	resp, challenge = assert(t, rp, authenticator, testOrigin, credential)
+	if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, result.SignCount+1, true); !errors.Is(err, ErrSignCountRegression) {
+		t.Errorf("contador repetido: VerifyAssertion = %v, se esperaba %v", err, ErrSignCountRegression)
+	}
+
+	resp, challenge = assert(t, rp, authenticator, testOrigin, credential)
+	if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 1000, true); !errors.Is(err, ErrSignCountRegression) {
+		t.Errorf("contador menor: VerifyAssertion = %v, se esperaba %v", err, ErrSignCountRegression)
+	}
+}
+
+func TestVerifyAssertionOriginAndRPID(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	credential := register(t, rp, authenticator)
+
+	t.Run("origen no permitido", func(t *testing.T) {
+		resp, challenge := assert(t, rp, authenticator, "https://phishing.example", credential)
+		if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true); !errors.Is(err, ErrOriginMismatch) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrOriginMismatch)
+		}
+	})
+
+	t.Run("desafío de otra ceremonia", func(t *testing.T) {
+		resp, _ := assert(t, rp, authenticator, testOrigin, credential)
+		if _, err := rp.VerifyAssertion(resp, newChallenge(t), credential.PublicKey, 0, true); !errors.Is(err, ErrChallengeMismatch) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrChallengeMismatch)
+		}
+	})
+
+	t.Run("credencial de otro sitio", func(t *testing.T) {
+		evil := &RelyingParty{ID: "evil.example", Name: "Evil", Origins: []string{testOrigin}}
+		evilCredential := register(t, evil, authenticator)
+
+		resp, challenge := assert(t, evil, authenticator, testOrigin, evilCredential)
+		if _, err := rp.VerifyAssertion(resp, challenge, evilCredential.PublicKey, 0, true); !errors.Is(err, ErrRPIDMismatch) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrRPIDMismatch)
+		}
+	})
+
+	t.Run("ceremonia de registro presentada como login", func(t *testing.T) {
+		challenge := newChallenge(t)
+		created, err := NewAuthenticator().Create(testOrigin, rp.CreationOptions(UserEntity{ID: EncodeID(testUserHandle)}, challenge, nil))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		resp, _ := assert(t, rp, authenticator, testOrigin, credential)
+		resp.Response.ClientDataJSON = created.Response.ClientDataJSON
+
+		if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("VerifyAssertion = %v, se esperaba %v", err, ErrInvalidResponse)
+		}
+	})
+}
+
+func TestVerifyAssertionUserVerification(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := NewAuthenticator()
+	credential := register(t, rp, authenticator)
+
+	// El dispositivo deja de pedir PIN o biometría: solo confirma la presencia
+	authenticator.UserVerification = false
+
+	resp, challenge := assert(t, rp, authenticator, testOrigin, credential)
+	if _, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, true); !errors.Is(err, ErrUserNotVerified) {
+		t.Errorf("UV exigida: VerifyAssertion = %v, se esperaba %v", err, ErrUserNotVerified)
+	}
+
+	resp, challenge = assert(t, rp, authenticator, testOrigin, credential)
+	result, err := rp.VerifyAssertion(resp, challenge, credential.PublicKey, 0, false)
+	if err != nil {
+		t.Fatalf("UV preferida: VerifyAssertion: %v", err)
+	}
+	if result.UserVerified {
+		t.Error("UserVerified = true sin verificación en el dispositivo")
+	}
+}
+
+func TestAuthenticatorWithoutCredential(t *testing.T) {
+	rp := testRelyingParty()
+	options := rp.RequestOptions(newChallenge(t), nil, VerificationRequired)
+	if _, err := NewAuthenticator().Get(testOrigin, options); !errors.Is(err, ErrNoCredential) {
+		t.Errorf("Get = %v, se esperaba %v", err, ErrNoCredential)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	okp, err := encodeCBOR(map[interface{}]interface{}{
+		int64(coseKeyType):   int64(coseKeyTypeOKP),
+		int64(coseAlgorithm): int64(AlgEdDSA),
+		int64(coseCurve):     int64(coseCurveEd25519),
+		int64(coseX):         []byte(publicKey),
+	})
+	if err != nil {
+		t.Fatalf("encodeCBOR: %v", err)
+	}
+
+	key, err := ParsePublicKey(okp)
+	if err != nil {
+		t.Fatalf("ParsePublicKey(Ed25519): %v", err)
+	}
+	data := []byte("authenticatorData || clientDataHash")
+	if !key.Verify(data, ed25519.Sign(privateKey, data)) {
+		t.Error("firma Ed25519 válida rechazada")
+	}
+	if key.Verify(data, ed25519.Sign(privateKey, []byte("otro mensaje"))) {
+		t.Error("firma Ed25519 de otro mensaje aceptada")
+	}
+
+	unsupported, _ := encodeCBOR(map[interface{}]interface{}{
+		int64(coseKeyType):   int64(coseKeyTypeEC2),
+		int64(coseAlgorithm): int64(-35), // ES384
+	})
+	if _, err := ParsePublicKey(unsupported); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("ParsePublicKey(ES384) = %v, se esperaba %v", err, ErrUnsupportedAlgorithm)
+	}
+
+	if _, err := ParsePublicKey(append(okp, 0x00)); err == nil {
+		t.Error("ParsePublicKey aceptó datos sobrantes")
+	}
+}